@@ -0,0 +1,78 @@
+package main
+
+import (
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	"todo-list-api/repositories"
+	"todo-list-api/services"
+	"todo-list-api/utils"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// application has the helpers, repositories and services shared by the server and the other subcommands.
+type application struct {
+	config               config.Config
+	postgresUtil         utils.PostgresUtil
+	jwtHelper            helpers.JwtHelper
+	metricsHelper        helpers.MetricsHelper
+	mailQueue            helpers.MailQueue
+	userRepository       repositories.UserRepository
+	principalService     services.PrincipalService
+	userService          services.UserService
+	oidcService          services.OidcService
+	twoFactorService     services.TwoFactorService
+	passwordService      services.PasswordService
+	profileService       services.ProfileService
+	todoService          services.TodoService
+	accountService       services.AccountService
+	adminService         services.AdminService
+	securityEventService services.SecurityEventService
+	oauthClientService   services.OauthClientService
+	oauthService         services.OauthService
+}
+
+func newApplication(cfg config.Config, postgresUtil utils.PostgresUtil) application {
+	validate := validator.New()
+	passwordHasher := helpers.NewPasswordHasher(cfg.PasswordHash)
+	jwtHelper := helpers.NewJwtHelper(cfg.Jwt)
+	mailer := helpers.NewMailer(cfg.Mail)
+	mailQueue := helpers.NewMailQueue(mailer)
+	totpHelper := helpers.NewTotpHelper(cfg.Totp)
+	passwordPolicyHelper := helpers.NewPasswordPolicyHelper(cfg.PasswordPolicy)
+	oidcHelper := helpers.NewOidcHelper(cfg.Oidc)
+	metricsHelper := helpers.NewMetricsHelper()
+
+	userRepository := repositories.NewUserRepository()
+	todoRepository := repositories.NewTodoRepository()
+	oauthGrantRepository := repositories.NewOauthGrantRepository()
+	loginAttemptRepository := repositories.NewLoginAttemptRepository()
+	securityEventRepository := repositories.NewSecurityEventRepository()
+	userIdentityRepository := repositories.NewUserIdentityRepository()
+	recoveryCodeRepository := repositories.NewRecoveryCodeRepository()
+	mfaChallengeRepository := repositories.NewMfaChallengeRepository()
+	passwordResetTokenRepository := repositories.NewPasswordResetTokenRepository()
+	oauthClientRepository := repositories.NewOauthClientRepository()
+	oauthAuthorizationCodeRepository := repositories.NewOauthAuthorizationCodeRepository()
+
+	return application{
+		config:               cfg,
+		postgresUtil:         postgresUtil,
+		jwtHelper:            jwtHelper,
+		metricsHelper:        metricsHelper,
+		mailQueue:            mailQueue,
+		userRepository:       userRepository,
+		principalService:     services.NewPrincipalService(postgresUtil, userRepository, oauthGrantRepository),
+		userService:          services.NewUserService(postgresUtil, validate, userRepository, passwordHasher, jwtHelper, mailer, loginAttemptRepository, passwordPolicyHelper, securityEventRepository, metricsHelper, cfg),
+		oidcService:          services.NewOidcService(postgresUtil, userRepository, userIdentityRepository, passwordHasher, jwtHelper, oidcHelper, securityEventRepository, metricsHelper, cfg),
+		twoFactorService:     services.NewTwoFactorService(postgresUtil, validate, userRepository, recoveryCodeRepository, loginAttemptRepository, mfaChallengeRepository, passwordHasher, jwtHelper, totpHelper, securityEventRepository, metricsHelper, cfg),
+		passwordService:      services.NewPasswordService(postgresUtil, validate, userRepository, passwordResetTokenRepository, passwordHasher, mailQueue, passwordPolicyHelper, securityEventRepository, cfg),
+		profileService:       services.NewProfileService(postgresUtil, validate, userRepository, passwordHasher, passwordPolicyHelper, jwtHelper, mailer, securityEventRepository, cfg),
+		todoService:          services.NewTodoService(postgresUtil, validate, todoRepository, userRepository, metricsHelper, cfg),
+		accountService:       services.NewAccountService(postgresUtil, validate, userRepository, todoRepository, oauthGrantRepository, securityEventRepository, passwordHasher, cfg),
+		adminService:         services.NewAdminService(postgresUtil, userRepository, todoRepository, securityEventRepository),
+		securityEventService: services.NewSecurityEventService(postgresUtil, securityEventRepository),
+		oauthClientService:   services.NewOauthClientService(postgresUtil, validate, oauthClientRepository),
+		oauthService:         services.NewOauthService(postgresUtil, userRepository, oauthClientRepository, oauthAuthorizationCodeRepository, oauthGrantRepository, jwtHelper, cfg),
+	}
+}
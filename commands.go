@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"todo-list-api/helpers"
+	modelrequests "todo-list-api/models/requests"
+	"todo-list-api/utils"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// operatorContext is what the commands call the services with, the operator acts as an admin that is no account.
+func operatorContext() context.Context {
+	requestId := "cli-" + helpers.NewRequestId()
+	ctx := helpers.ContextWithRequestId(context.Background(), requestId)
+	ctx = helpers.ContextWithLogger(ctx, slog.Default().With(slog.String("request_id", requestId)))
+	ctx = helpers.ContextWithClientInfo(ctx, helpers.ClientInfo{IpAddress: "cli", UserAgent: "todo-api cli"})
+	return helpers.ContextWithPrincipal(ctx, helpers.Principal{Name: "cli", Role: helpers.RoleAdmin})
+}
+
+// printResponse prints the response of a service and returns the exit code for its http code.
+func printResponse(httpCode int, response interface{}) int {
+	message := ""
+	if messageResponse, ok := response.(helpers.Response); ok {
+		message = messageResponse.Message
+	} else {
+		body, _ := json.Marshal(response)
+		message = string(body)
+	}
+	if httpCode >= http.StatusBadRequest {
+		fmt.Fprintln(os.Stderr, message)
+		return 1
+	}
+	fmt.Println(message)
+	return 0
+}
+
+func requireFlag(name string, value string) bool {
+	if value == "" {
+		fmt.Fprintln(os.Stderr, "-"+name+" is required")
+		return false
+	}
+	return true
+}
+
+func findUserIdByEmail(ctx context.Context, app application, email string) (id int, err error) {
+	tx, err := app.postgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := app.postgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			err = errCommitOrRollback
+		}
+	}()
+
+	user, err := app.userRepository.FindByEmail(tx, ctx, email)
+	if err == pgx.ErrNoRows {
+		err = errors.New("cannot find user " + email)
+		return
+	} else if err != nil {
+		return
+	}
+	id = int(user.Id.Int32)
+	return
+}
+
+// createUser registers an account like POST /register does, the password is read from stdin when -password is not given
+// so it does not end up in the shell history.
+func createUser(args []string) int {
+	var registerRequest modelrequests.RegisterRequest
+	cfg, exitCode, ok := loadConfig(args, func(flagSet *flag.FlagSet) {
+		flagSet.StringVar(&registerRequest.Name, "name", "", "name of the account")
+		flagSet.StringVar(&registerRequest.Email, "email", "", "email of the account")
+		flagSet.StringVar(&registerRequest.Password, "password", "", "password of the account, read from stdin when empty")
+	})
+	if !ok {
+		return exitCode
+	}
+	if !requireFlag("name", registerRequest.Name) || !requireFlag("email", registerRequest.Email) {
+		return 2
+	}
+	if registerRequest.Password == "" {
+		fmt.Fprint(os.Stderr, "password: ")
+		password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && password == "" {
+			fmt.Fprintln(os.Stderr, "reading the password:", err)
+			return 1
+		}
+		registerRequest.Password = strings.TrimRight(password, "\r\n")
+	}
+
+	postgresUtil := utils.NewPostgresConnection(cfg.Postgres)
+	defer postgresUtil.Close()
+	app := newApplication(cfg, postgresUtil)
+
+	httpCode, _, _, response := app.userService.Register(operatorContext(), registerRequest)
+	if httpCode >= http.StatusBadRequest {
+		return printResponse(httpCode, response)
+	}
+	fmt.Println("created user " + registerRequest.Email)
+	return 0
+}
+
+func disableUser(args []string) int {
+	var email string
+	cfg, exitCode, ok := loadConfig(args, func(flagSet *flag.FlagSet) {
+		flagSet.StringVar(&email, "email", "", "email of the account")
+	})
+	if !ok {
+		return exitCode
+	}
+	if !requireFlag("email", email) {
+		return 2
+	}
+
+	postgresUtil := utils.NewPostgresConnection(cfg.Postgres)
+	defer postgresUtil.Close()
+	app := newApplication(cfg, postgresUtil)
+
+	ctx := operatorContext()
+	id, err := findUserIdByEmail(ctx, app, email)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return printResponse(app.adminService.Disable(ctx, id))
+}
+
+// resetPassword sends the same password reset link as POST /password/forgot, the operator never sees the password.
+func resetPassword(args []string) int {
+	var email string
+	cfg, exitCode, ok := loadConfig(args, func(flagSet *flag.FlagSet) {
+		flagSet.StringVar(&email, "email", "", "email of the account")
+	})
+	if !ok {
+		return exitCode
+	}
+	if !requireFlag("email", email) {
+		return 2
+	}
+
+	postgresUtil := utils.NewPostgresConnection(cfg.Postgres)
+	defer postgresUtil.Close()
+	app := newApplication(cfg, postgresUtil)
+
+	ctx := operatorContext()
+	// the service answers the same for unknown emails, the operator should know
+	_, err := findUserIdByEmail(ctx, app, email)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	httpCode, response := app.passwordService.ForgotPassword(ctx, modelrequests.ForgotPasswordRequest{Email: email})
+	if httpCode >= http.StatusBadRequest {
+		return printResponse(httpCode, response)
+	}
+	// the mail is sent in the background, a failure is logged
+	app.mailQueue.Wait()
+	fmt.Println("sent a password reset link to " + email)
+	return 0
+}
+
+// revokeTokens ends every session of the account, its refresh token is removed and its access tokens are refused.
+func revokeTokens(args []string) int {
+	var email string
+	cfg, exitCode, ok := loadConfig(args, func(flagSet *flag.FlagSet) {
+		flagSet.StringVar(&email, "email", "", "email of the account")
+	})
+	if !ok {
+		return exitCode
+	}
+	if !requireFlag("email", email) {
+		return 2
+	}
+
+	postgresUtil := utils.NewPostgresConnection(cfg.Postgres)
+	defer postgresUtil.Close()
+	app := newApplication(cfg, postgresUtil)
+
+	ctx := operatorContext()
+	id, err := findUserIdByEmail(ctx, app, email)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return printResponse(app.adminService.Logout(ctx, id))
+}
+
+// seed creates a demo account with a few todos for local development.
+func seed(args []string) int {
+	registerRequest := modelrequests.RegisterRequest{Name: "John Doe"}
+	cfg, exitCode, ok := loadConfig(args, func(flagSet *flag.FlagSet) {
+		flagSet.StringVar(&registerRequest.Email, "email", "john@doe.com", "email of the demo account")
+		flagSet.StringVar(&registerRequest.Password, "password", "Todo-list-1234", "password of the demo account")
+	})
+	if !ok {
+		return exitCode
+	}
+
+	postgresUtil := utils.NewPostgresConnection(cfg.Postgres)
+	defer postgresUtil.Close()
+	app := newApplication(cfg, postgresUtil)
+
+	ctx := operatorContext()
+	httpCode, _, _, response := app.userService.Register(ctx, registerRequest)
+	if httpCode >= http.StatusBadRequest {
+		return printResponse(httpCode, response)
+	}
+	id, err := findUserIdByEmail(ctx, app, registerRequest.Email)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	ctx = helpers.ContextWithPrincipal(ctx, helpers.Principal{Id: id, Name: registerRequest.Name, Email: registerRequest.Email, Role: helpers.RoleUser})
+	createTodoRequests := []modelrequests.CreateTodoRequest{
+		{Title: "Buy groceries", Description: "Buy milk, eggs, and bread"},
+		{Title: "Pay the bills", Description: "Electricity, water and internet"},
+		{Title: "Call mom", Description: "Ask about the weekend"},
+	}
+	for _, createTodoRequest := range createTodoRequests {
+		httpCode, response := app.todoService.Create(ctx, createTodoRequest)
+		if httpCode >= http.StatusBadRequest {
+			return printResponse(httpCode, response)
+		}
+	}
+	fmt.Printf("created user %s with password %s and %d todos\n", registerRequest.Email, registerRequest.Password, len(createTodoRequests))
+	return 0
+}
+
+// purgeTrash deletes the accounts whose grace period is over now, without waiting for the server to do it.
+func purgeTrash(args []string) int {
+	cfg, exitCode, ok := loadConfig(args)
+	if !ok {
+		return exitCode
+	}
+
+	postgresUtil := utils.NewPostgresConnection(cfg.Postgres)
+	defer postgresUtil.Close()
+	app := newApplication(cfg, postgresUtil)
+
+	deletedAccounts, err := app.accountService.PurgeDeleted(operatorContext())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "purging deleted accounts:", err)
+		return 1
+	}
+	fmt.Printf("purged %d deleted accounts\n", deletedAccounts)
+	return 0
+}
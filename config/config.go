@@ -0,0 +1,264 @@
+package config
+
+// Config is every setting of the api. Each setting is read, in increasing order of precedence, from its default,
+// the config file, the environment variable in its env tag and the command line flag of the same name, lower case with
+// dashes, so JWT_ACCESS_TOKEN_TIME can also be given as -jwt-access-token-time. Times are in the unit the setting
+// always had, see the README.
+type Config struct {
+	Server            ServerConfig            `yaml:"server" toml:"server"`
+	Log               LogConfig               `yaml:"log" toml:"log"`
+	Postgres          PostgresConfig          `yaml:"postgres" toml:"postgres"`
+	Jwt               JwtConfig               `yaml:"jwt" toml:"jwt"`
+	Cookie            CookieConfig            `yaml:"cookie" toml:"cookie"`
+	Mail              MailConfig              `yaml:"mail" toml:"mail"`
+	Totp              TotpConfig              `yaml:"totp" toml:"totp"`
+	PasswordHash      PasswordHashConfig      `yaml:"passwordHash" toml:"passwordHash"`
+	PasswordPolicy    PasswordPolicyConfig    `yaml:"passwordPolicy" toml:"passwordPolicy"`
+	Login             LoginConfig             `yaml:"login" toml:"login"`
+	EmailVerification EmailVerificationConfig `yaml:"emailVerification" toml:"emailVerification"`
+	PasswordReset     PasswordResetConfig     `yaml:"passwordReset" toml:"passwordReset"`
+	Account           AccountConfig           `yaml:"account" toml:"account"`
+	Oidc              OidcConfig              `yaml:"oidc" toml:"oidc"`
+	Oauth             OauthConfig             `yaml:"oauth" toml:"oauth"`
+	RateLimit         RateLimitConfig         `yaml:"rateLimit" toml:"rateLimit"`
+	Concurrency       ConcurrencyConfig       `yaml:"concurrency" toml:"concurrency"`
+	Metrics           MetricsConfig           `yaml:"metrics" toml:"metrics"`
+	Tracing           TracingConfig           `yaml:"tracing" toml:"tracing"`
+}
+
+type ServerConfig struct {
+	Host       string `yaml:"host" toml:"host" env:"ECHO_HOST"`
+	TrustProxy bool   `yaml:"trustProxy" toml:"trustProxy" env:"TRUST_PROXY"`
+}
+
+type LogConfig struct {
+	Level string `yaml:"level" toml:"level" env:"LOG_LEVEL"`
+}
+
+type PostgresConfig struct {
+	Host          string `yaml:"host" toml:"host" env:"POSTGRES_HOST"`
+	Username      string `yaml:"username" toml:"username" env:"POSTGRES_USERNAME"`
+	Password      string `yaml:"password" toml:"password" env:"POSTGRES_PASSWORD"`
+	Database      string `yaml:"database" toml:"database" env:"POSTGRES_DATABASE"`
+	MaxConnection int    `yaml:"maxConnection" toml:"maxConnection" env:"POSTGRES_MAX_CONNECTION"`
+	MaxIdletime   int    `yaml:"maxIdletime" toml:"maxIdletime" env:"POSTGRES_MAX_IDLETIME"`
+	MaxLifetime   int    `yaml:"maxLifetime" toml:"maxLifetime" env:"POSTGRES_MAX_LIFETIME"`
+	AutoMigrate   bool   `yaml:"autoMigrate" toml:"autoMigrate" env:"POSTGRES_AUTO_MIGRATE"`
+}
+
+type JwtConfig struct {
+	Secret              string `yaml:"secret" toml:"secret" env:"JWT_SECRET"`
+	SigningKeys         string `yaml:"signingKeys" toml:"signingKeys" env:"JWT_SIGNING_KEYS"`
+	VerificationKeys    string `yaml:"verificationKeys" toml:"verificationKeys" env:"JWT_VERIFICATION_KEYS"`
+	ActiveKeyId         string `yaml:"activeKeyId" toml:"activeKeyId" env:"JWT_ACTIVE_KEY_ID"`
+	Issuer              string `yaml:"issuer" toml:"issuer" env:"JWT_ISSUER"`
+	Audience            string `yaml:"audience" toml:"audience" env:"JWT_AUDIENCE"`
+	AccessTokenTime     int    `yaml:"accessTokenTime" toml:"accessTokenTime" env:"JWT_ACCESS_TOKEN_TIME"`
+	RefreshTokenTime    int    `yaml:"refreshTokenTime" toml:"refreshTokenTime" env:"JWT_REFRESH_TOKEN_TIME"`
+	MfaPendingTokenTime int    `yaml:"mfaPendingTokenTime" toml:"mfaPendingTokenTime" env:"MFA_PENDING_TOKEN_TIME"`
+	MfaMaxAttempts      int    `yaml:"mfaMaxAttempts" toml:"mfaMaxAttempts" env:"MFA_MAX_ATTEMPTS"`
+}
+
+type CookieConfig struct {
+	Secure bool `yaml:"secure" toml:"secure" env:"COOKIE_SECURE"`
+}
+
+type MailConfig struct {
+	Mailer       string `yaml:"mailer" toml:"mailer" env:"MAILER"`
+	From         string `yaml:"from" toml:"from" env:"MAIL_FROM"`
+	FilePath     string `yaml:"filePath" toml:"filePath" env:"MAIL_FILE_PATH"`
+	SmtpHost     string `yaml:"smtpHost" toml:"smtpHost" env:"SMTP_HOST"`
+	SmtpUsername string `yaml:"smtpUsername" toml:"smtpUsername" env:"SMTP_USERNAME"`
+	SmtpPassword string `yaml:"smtpPassword" toml:"smtpPassword" env:"SMTP_PASSWORD"`
+}
+
+type TotpConfig struct {
+	Issuer string `yaml:"issuer" toml:"issuer" env:"TOTP_ISSUER"`
+}
+
+type PasswordHashConfig struct {
+	Algorithm         string `yaml:"algorithm" toml:"algorithm" env:"PASSWORD_HASH_ALGORITHM"`
+	Argon2Memory      int    `yaml:"argon2Memory" toml:"argon2Memory" env:"ARGON2_MEMORY"`
+	Argon2Iterations  int    `yaml:"argon2Iterations" toml:"argon2Iterations" env:"ARGON2_ITERATIONS"`
+	Argon2Parallelism int    `yaml:"argon2Parallelism" toml:"argon2Parallelism" env:"ARGON2_PARALLELISM"`
+	BcryptCost        int    `yaml:"bcryptCost" toml:"bcryptCost" env:"BCRYPT_COST"`
+}
+
+type PasswordPolicyConfig struct {
+	MinLength             int    `yaml:"minLength" toml:"minLength" env:"PASSWORD_MIN_LENGTH"`
+	MaxLength             int    `yaml:"maxLength" toml:"maxLength" env:"PASSWORD_MAX_LENGTH"`
+	RequiredClasses       string `yaml:"requiredClasses" toml:"requiredClasses" env:"PASSWORD_REQUIRED_CLASSES"`
+	BreachedPasswordsPath string `yaml:"breachedPasswordsPath" toml:"breachedPasswordsPath" env:"BREACHED_PASSWORDS_PATH"`
+}
+
+type LoginConfig struct {
+	MaxAttempts      int `yaml:"maxAttempts" toml:"maxAttempts" env:"LOGIN_MAX_ATTEMPTS"`
+	MaxAttemptsPerIp int `yaml:"maxAttemptsPerIp" toml:"maxAttemptsPerIp" env:"LOGIN_MAX_ATTEMPTS_PER_IP"`
+	LockoutTime      int `yaml:"lockoutTime" toml:"lockoutTime" env:"LOGIN_LOCKOUT_TIME"`
+	LockoutMaxTime   int `yaml:"lockoutMaxTime" toml:"lockoutMaxTime" env:"LOGIN_LOCKOUT_MAX_TIME"`
+	AttemptWindow    int `yaml:"attemptWindow" toml:"attemptWindow" env:"LOGIN_ATTEMPT_WINDOW"`
+}
+
+type EmailVerificationConfig struct {
+	Required       bool   `yaml:"required" toml:"required" env:"REQUIRE_EMAIL_VERIFICATION"`
+	Url            string `yaml:"url" toml:"url" env:"EMAIL_VERIFICATION_URL"`
+	TokenTime      int    `yaml:"tokenTime" toml:"tokenTime" env:"EMAIL_VERIFICATION_TOKEN_TIME"`
+	ResendInterval int    `yaml:"resendInterval" toml:"resendInterval" env:"EMAIL_VERIFICATION_RESEND_INTERVAL"`
+}
+
+type PasswordResetConfig struct {
+	Url       string `yaml:"url" toml:"url" env:"PASSWORD_RESET_URL"`
+	TokenTime int    `yaml:"tokenTime" toml:"tokenTime" env:"PASSWORD_RESET_TOKEN_TIME"`
+}
+
+type AccountConfig struct {
+	DeletionGraceDays int `yaml:"deletionGraceDays" toml:"deletionGraceDays" env:"ACCOUNT_DELETION_GRACE_DAYS"`
+	PurgeInterval     int `yaml:"purgeInterval" toml:"purgeInterval" env:"ACCOUNT_PURGE_INTERVAL"`
+}
+
+// OidcConfig has no env tag on Providers, the providers come from OIDC_PROVIDERS, a comma separated list of names,
+// and OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID and OIDC_<NAME>_CLIENT_SECRET, see loadOidcProvidersFromEnv.
+type OidcConfig struct {
+	Providers       map[string]OidcProviderConfig `yaml:"providers" toml:"providers"`
+	RedirectBaseUrl string                        `yaml:"redirectBaseUrl" toml:"redirectBaseUrl" env:"OIDC_REDIRECT_BASE_URL"`
+	StateTokenTime  int                           `yaml:"stateTokenTime" toml:"stateTokenTime" env:"OIDC_STATE_TOKEN_TIME"`
+}
+
+type OidcProviderConfig struct {
+	Issuer       string `yaml:"issuer" toml:"issuer"`
+	ClientId     string `yaml:"clientId" toml:"clientId"`
+	ClientSecret string `yaml:"clientSecret" toml:"clientSecret"`
+}
+
+type OauthConfig struct {
+	AuthorizationCodeTime int `yaml:"authorizationCodeTime" toml:"authorizationCodeTime" env:"OAUTH_AUTHORIZATION_CODE_TIME"`
+	AccessTokenTime       int `yaml:"accessTokenTime" toml:"accessTokenTime" env:"OAUTH_ACCESS_TOKEN_TIME"`
+	RefreshTokenTime      int `yaml:"refreshTokenTime" toml:"refreshTokenTime" env:"OAUTH_REFRESH_TOKEN_TIME"`
+}
+
+type RateLimitConfig struct {
+	Store        string `yaml:"store" toml:"store" env:"RATE_LIMIT_STORE"`
+	Requests     int    `yaml:"requests" toml:"requests" env:"RATE_LIMIT_REQUESTS"`
+	Window       int    `yaml:"window" toml:"window" env:"RATE_LIMIT_WINDOW"`
+	AuthRequests int    `yaml:"authRequests" toml:"authRequests" env:"RATE_LIMIT_AUTH_REQUESTS"`
+	AuthWindow   int    `yaml:"authWindow" toml:"authWindow" env:"RATE_LIMIT_AUTH_WINDOW"`
+}
+
+type ConcurrencyConfig struct {
+	Limit      int `yaml:"limit" toml:"limit" env:"CONCURRENCY_LIMIT"`
+	Queue      int `yaml:"queue" toml:"queue" env:"CONCURRENCY_QUEUE"`
+	QueueWait  int `yaml:"queueWait" toml:"queueWait" env:"CONCURRENCY_QUEUE_WAIT"`
+	RetryAfter int `yaml:"retryAfter" toml:"retryAfter" env:"CONCURRENCY_RETRY_AFTER"`
+}
+
+// MetricsConfig serves GET /metrics when Enabled, Token is asked as a bearer token when it is set.
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled" toml:"enabled" env:"METRICS_ENABLED"`
+	Token   string `yaml:"token" toml:"token" env:"METRICS_TOKEN"`
+}
+
+// TracingConfig sends opentelemetry traces to Exporter, one of none, otlp, stdout or file. SamplePercent of the traces
+// started here are kept, a trace started by the caller keeps the decision of the caller.
+type TracingConfig struct {
+	Exporter      string `yaml:"exporter" toml:"exporter" env:"TRACING_EXPORTER"`
+	ServiceName   string `yaml:"serviceName" toml:"serviceName" env:"TRACING_SERVICE_NAME"`
+	OtlpEndpoint  string `yaml:"otlpEndpoint" toml:"otlpEndpoint" env:"TRACING_OTLP_ENDPOINT"`
+	FilePath      string `yaml:"filePath" toml:"filePath" env:"TRACING_FILE_PATH"`
+	SamplePercent int    `yaml:"samplePercent" toml:"samplePercent" env:"TRACING_SAMPLE_PERCENT"`
+}
+
+// Default has a value for everything except the postgres credentials and the jwt secret, which have to be set.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Host: ":8080",
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+		Postgres: PostgresConfig{
+			Host:          "localhost:5432",
+			MaxConnection: 10,
+			MaxIdletime:   10,
+			MaxLifetime:   10,
+		},
+		Jwt: JwtConfig{
+			Issuer:              "todo-list-api",
+			Audience:            "todo-list-api",
+			AccessTokenTime:     15,
+			RefreshTokenTime:    1,
+			MfaPendingTokenTime: 5,
+			MfaMaxAttempts:      3,
+		},
+		Mail: MailConfig{
+			Mailer: "file",
+			From:   "no-reply@todo-list-api.local",
+		},
+		Totp: TotpConfig{
+			Issuer: "todo-list-api",
+		},
+		PasswordHash: PasswordHashConfig{
+			Algorithm:         "argon2id",
+			Argon2Memory:      19456,
+			Argon2Iterations:  2,
+			Argon2Parallelism: 1,
+			BcryptCost:        10,
+		},
+		PasswordPolicy: PasswordPolicyConfig{
+			MinLength:       8,
+			MaxLength:       72,
+			RequiredClasses: "lowercase,uppercase,digit",
+		},
+		Login: LoginConfig{
+			MaxAttempts:      5,
+			MaxAttemptsPerIp: 20,
+			LockoutTime:      60,
+			LockoutMaxTime:   3600,
+			AttemptWindow:    900,
+		},
+		EmailVerification: EmailVerificationConfig{
+			Url:            "http://localhost:8080/verify-email?token=",
+			TokenTime:      1440,
+			ResendInterval: 60,
+		},
+		PasswordReset: PasswordResetConfig{
+			Url:       "http://localhost:3000/reset-password?token=",
+			TokenTime: 30,
+		},
+		Account: AccountConfig{
+			DeletionGraceDays: 30,
+			PurgeInterval:     60,
+		},
+		Oidc: OidcConfig{
+			Providers:       map[string]OidcProviderConfig{},
+			RedirectBaseUrl: "http://localhost:8080",
+			StateTokenTime:  10,
+		},
+		Oauth: OauthConfig{
+			AuthorizationCodeTime: 60,
+			AccessTokenTime:       15,
+			RefreshTokenTime:      30,
+		},
+		RateLimit: RateLimitConfig{
+			Store:        "memory",
+			Requests:     100,
+			Window:       60,
+			AuthRequests: 10,
+			AuthWindow:   60,
+		},
+		Concurrency: ConcurrencyConfig{
+			Limit:      200,
+			Queue:      100,
+			QueueWait:  200,
+			RetryAfter: 1,
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+		},
+		Tracing: TracingConfig{
+			Exporter:      "none",
+			ServiceName:   "todo-list-api",
+			SamplePercent: 100,
+		},
+	}
+}
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads the config from the defaults, the yaml or toml file given by -config or CONFIG_FILE, the environment and the
+// flags in args, and validates it. Every environment variable can also be read from a file by appending _FILE to its
+// name, JWT_SECRET_FILE=/run/secrets/jwt_secret for example, which is how docker and kubernetes mount secrets.
+// commandFlags lets a subcommand add its own flags next to the flags of the config.
+func Load(args []string, commandFlags ...func(flagSet *flag.FlagSet)) (config Config, err error) {
+	flagSet, flagValues, configFile := newFlagSet()
+	for _, commandFlag := range commandFlags {
+		commandFlag(flagSet)
+	}
+	err = flagSet.Parse(args)
+	if err != nil {
+		return
+	}
+	if flagSet.NArg() > 0 {
+		err = errors.New("unexpected argument " + strconv.Quote(flagSet.Arg(0)))
+		return
+	}
+
+	config = Default()
+	if *configFile == "" {
+		*configFile = os.Getenv("CONFIG_FILE")
+	}
+	if *configFile != "" {
+		err = loadFile(&config, *configFile)
+		if err != nil {
+			return
+		}
+	}
+
+	var errs []error
+	settingsByEnv := map[string]setting{}
+	for _, setting := range settings(&config) {
+		settingsByEnv[setting.env] = setting
+		value, ok, errEnv := lookupEnv(setting.env)
+		if errEnv != nil {
+			errs = append(errs, errEnv)
+			continue
+		}
+		if ok {
+			errs = append(errs, setting.set(value))
+		}
+	}
+	errs = append(errs, loadOidcProvidersFromEnv(&config))
+	flagSet.Visit(func(visited *flag.Flag) {
+		if flagValue, ok := flagValues[visited.Name]; ok {
+			errs = append(errs, settingsByEnv[flagValue.env].set(flagValue.value))
+		}
+	})
+	err = errors.Join(errs...)
+	if err != nil {
+		return
+	}
+
+	err = config.Validate()
+	return
+}
+
+func loadFile(config *Config, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+		decoder.KnownFields(true)
+		err = decoder.Decode(config)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+		return nil
+	case ".toml":
+		metaData, err := toml.Decode(string(content), config)
+		if err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+		// like KnownFields of yaml, a misspelled key is an error instead of a setting that is silently left out
+		if undecoded := metaData.Undecoded(); len(undecoded) > 0 {
+			keys := make([]string, 0, len(undecoded))
+			for _, key := range undecoded {
+				keys = append(keys, key.String())
+			}
+			return errors.New("config file " + path + ": unknown keys " + strings.Join(keys, ", "))
+		}
+		return nil
+	default:
+		return errors.New("config file " + path + ": only .yaml, .yml and .toml files are supported")
+	}
+}
+
+// lookupEnv prefers NAME_FILE over NAME, a trailing newline in the file is dropped.
+func lookupEnv(name string) (value string, ok bool, err error) {
+	if path, ok := os.LookupEnv(name + "_FILE"); ok {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), true, nil
+	}
+	value, ok = os.LookupEnv(name)
+	return value, ok, nil
+}
+
+func loadOidcProvidersFromEnv(config *Config) error {
+	names, ok, err := lookupEnv("OIDC_PROVIDERS")
+	if err != nil || !ok {
+		return err
+	}
+	if config.Oidc.Providers == nil {
+		config.Oidc.Providers = map[string]OidcProviderConfig{}
+	}
+	var errs []error
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "" {
+			continue
+		}
+		provider := config.Oidc.Providers[name]
+		envPrefix := "OIDC_" + strings.ToUpper(name) + "_"
+		fields := []struct {
+			env   string
+			value *string
+		}{{"ISSUER", &provider.Issuer}, {"CLIENT_ID", &provider.ClientId}, {"CLIENT_SECRET", &provider.ClientSecret}}
+		for _, field := range fields {
+			value, ok, err := lookupEnv(envPrefix + field.env)
+			if err != nil {
+				errs = append(errs, err)
+			} else if ok {
+				*field.value = value
+			}
+		}
+		config.Oidc.Providers[name] = provider
+	}
+	return errors.Join(errs...)
+}
+
+type setting struct {
+	env   string
+	field reflect.Value
+}
+
+// settings lists every field of config with an env tag, in the order of the struct.
+func settings(config *Config) (result []setting) {
+	sections := reflect.ValueOf(config).Elem()
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Field(i)
+		for j := 0; j < section.NumField(); j++ {
+			env := section.Type().Field(j).Tag.Get("env")
+			if env != "" {
+				result = append(result, setting{env: env, field: section.Field(j)})
+			}
+		}
+	}
+	return
+}
+
+func (setting setting) set(value string) error {
+	switch setting.field.Kind() {
+	case reflect.String:
+		setting.field.SetString(value)
+	case reflect.Int:
+		number, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return errors.New(setting.env + ": " + strconv.Quote(value) + " is not a number")
+		}
+		setting.field.SetInt(int64(number))
+	case reflect.Bool:
+		boolean, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return errors.New(setting.env + ": " + strconv.Quote(value) + " is not true or false")
+		}
+		setting.field.SetBool(boolean)
+	default:
+		panic("config: unsupported type of " + setting.env)
+	}
+	return nil
+}
+
+func flagName(env string) string {
+	return strings.ReplaceAll(strings.ToLower(env), "_", "-")
+}
+
+// flagValue keeps the raw value, it is only applied after the file and the environment so a flag always wins.
+type flagValue struct {
+	env    string
+	isBool bool
+	value  string
+}
+
+func (flagValue *flagValue) String() string {
+	return flagValue.value
+}
+
+func (flagValue *flagValue) Set(value string) error {
+	flagValue.value = value
+	return nil
+}
+
+func (flagValue *flagValue) IsBoolFlag() bool {
+	return flagValue.isBool
+}
+
+func newFlagSet() (flagSet *flag.FlagSet, flagValues map[string]*flagValue, configFile *string) {
+	flagSet = flag.NewFlagSet("todo-list-api", flag.ContinueOnError)
+	configFile = flagSet.String("config", "", "path of a yaml or toml config file, CONFIG_FILE")
+	flagValues = map[string]*flagValue{}
+	for _, setting := range settings(&Config{}) {
+		name := flagName(setting.env)
+		flagValues[name] = &flagValue{env: setting.env, isBool: setting.field.Kind() == reflect.Bool}
+		flagSet.Var(flagValues[name], name, setting.env)
+	}
+	return
+}
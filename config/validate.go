@@ -0,0 +1,130 @@
+package config
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Validate returns every problem at once, each naming the environment variable to fix.
+func (config Config) Validate() error {
+	var errs []error
+	required := func(env string, value string) {
+		if value == "" {
+			errs = append(errs, errors.New(env+" is required"))
+		}
+	}
+	atLeast := func(env string, value int, minimum int) {
+		if value < minimum {
+			errs = append(errs, errors.New(env+" must be at least "+strconv.Itoa(minimum)+", got "+strconv.Itoa(value)))
+		}
+	}
+	oneOf := func(env string, value string, allowed ...string) {
+		for _, allowedValue := range allowed {
+			if value == allowedValue {
+				return
+			}
+		}
+		errs = append(errs, errors.New(env+" must be one of "+strings.Join(allowed, ", ")+", got "+strconv.Quote(value)))
+	}
+
+	required("ECHO_HOST", config.Server.Host)
+	oneOf("LOG_LEVEL", config.Log.Level, "debug", "info", "warn", "error")
+
+	required("POSTGRES_HOST", config.Postgres.Host)
+	required("POSTGRES_USERNAME", config.Postgres.Username)
+	required("POSTGRES_DATABASE", config.Postgres.Database)
+	atLeast("POSTGRES_MAX_CONNECTION", config.Postgres.MaxConnection, 1)
+	atLeast("POSTGRES_MAX_IDLETIME", config.Postgres.MaxIdletime, 1)
+	atLeast("POSTGRES_MAX_LIFETIME", config.Postgres.MaxLifetime, 1)
+
+	if config.Jwt.SigningKeys == "" {
+		required("JWT_SECRET or JWT_SIGNING_KEYS", config.Jwt.Secret)
+	}
+	required("JWT_ISSUER", config.Jwt.Issuer)
+	required("JWT_AUDIENCE", config.Jwt.Audience)
+	atLeast("JWT_ACCESS_TOKEN_TIME", config.Jwt.AccessTokenTime, 1)
+	atLeast("JWT_REFRESH_TOKEN_TIME", config.Jwt.RefreshTokenTime, 1)
+	atLeast("MFA_PENDING_TOKEN_TIME", config.Jwt.MfaPendingTokenTime, 1)
+	atLeast("MFA_MAX_ATTEMPTS", config.Jwt.MfaMaxAttempts, 1)
+
+	oneOf("MAILER", config.Mail.Mailer, "file", "smtp")
+	required("MAIL_FROM", config.Mail.From)
+	if config.Mail.Mailer == "smtp" {
+		required("SMTP_HOST", config.Mail.SmtpHost)
+	}
+
+	oneOf("PASSWORD_HASH_ALGORITHM", config.PasswordHash.Algorithm, "argon2id", "bcrypt")
+	atLeast("ARGON2_MEMORY", config.PasswordHash.Argon2Memory, 1)
+	atLeast("ARGON2_ITERATIONS", config.PasswordHash.Argon2Iterations, 1)
+	atLeast("ARGON2_PARALLELISM", config.PasswordHash.Argon2Parallelism, 1)
+	if config.PasswordHash.Argon2Parallelism > 255 {
+		errs = append(errs, errors.New("ARGON2_PARALLELISM must be at most 255"))
+	}
+	atLeast("BCRYPT_COST", config.PasswordHash.BcryptCost, 4)
+
+	atLeast("PASSWORD_MIN_LENGTH", config.PasswordPolicy.MinLength, 1)
+	atLeast("PASSWORD_MAX_LENGTH", config.PasswordPolicy.MaxLength, config.PasswordPolicy.MinLength)
+	for _, requiredClass := range strings.Split(config.PasswordPolicy.RequiredClasses, ",") {
+		oneOf("PASSWORD_REQUIRED_CLASSES", strings.TrimSpace(requiredClass), "lowercase", "uppercase", "digit", "symbol", "none")
+	}
+
+	atLeast("LOGIN_MAX_ATTEMPTS", config.Login.MaxAttempts, 1)
+	atLeast("LOGIN_MAX_ATTEMPTS_PER_IP", config.Login.MaxAttemptsPerIp, 1)
+	atLeast("LOGIN_LOCKOUT_TIME", config.Login.LockoutTime, 1)
+	atLeast("LOGIN_LOCKOUT_MAX_TIME", config.Login.LockoutMaxTime, config.Login.LockoutTime)
+	atLeast("LOGIN_ATTEMPT_WINDOW", config.Login.AttemptWindow, 1)
+
+	required("EMAIL_VERIFICATION_URL", config.EmailVerification.Url)
+	atLeast("EMAIL_VERIFICATION_TOKEN_TIME", config.EmailVerification.TokenTime, 1)
+	atLeast("EMAIL_VERIFICATION_RESEND_INTERVAL", config.EmailVerification.ResendInterval, 0)
+
+	required("PASSWORD_RESET_URL", config.PasswordReset.Url)
+	atLeast("PASSWORD_RESET_TOKEN_TIME", config.PasswordReset.TokenTime, 1)
+
+	atLeast("ACCOUNT_DELETION_GRACE_DAYS", config.Account.DeletionGraceDays, 0)
+	atLeast("ACCOUNT_PURGE_INTERVAL", config.Account.PurgeInterval, 1)
+
+	if len(config.Oidc.Providers) > 0 {
+		required("OIDC_REDIRECT_BASE_URL", config.Oidc.RedirectBaseUrl)
+	}
+	providerNames := make([]string, 0, len(config.Oidc.Providers))
+	for name := range config.Oidc.Providers {
+		providerNames = append(providerNames, name)
+	}
+	sort.Strings(providerNames)
+	for _, name := range providerNames {
+		envPrefix := "OIDC_" + strings.ToUpper(name) + "_"
+		required(envPrefix+"ISSUER", config.Oidc.Providers[name].Issuer)
+		required(envPrefix+"CLIENT_ID", config.Oidc.Providers[name].ClientId)
+	}
+	atLeast("OIDC_STATE_TOKEN_TIME", config.Oidc.StateTokenTime, 1)
+
+	atLeast("OAUTH_AUTHORIZATION_CODE_TIME", config.Oauth.AuthorizationCodeTime, 1)
+	atLeast("OAUTH_ACCESS_TOKEN_TIME", config.Oauth.AccessTokenTime, 1)
+	atLeast("OAUTH_REFRESH_TOKEN_TIME", config.Oauth.RefreshTokenTime, 1)
+
+	oneOf("RATE_LIMIT_STORE", config.RateLimit.Store, "memory", "postgres")
+	atLeast("RATE_LIMIT_REQUESTS", config.RateLimit.Requests, 1)
+	atLeast("RATE_LIMIT_WINDOW", config.RateLimit.Window, 1)
+	atLeast("RATE_LIMIT_AUTH_REQUESTS", config.RateLimit.AuthRequests, 1)
+	atLeast("RATE_LIMIT_AUTH_WINDOW", config.RateLimit.AuthWindow, 1)
+
+	atLeast("CONCURRENCY_LIMIT", config.Concurrency.Limit, 1)
+	atLeast("CONCURRENCY_QUEUE", config.Concurrency.Queue, 0)
+	atLeast("CONCURRENCY_QUEUE_WAIT", config.Concurrency.QueueWait, 0)
+	atLeast("CONCURRENCY_RETRY_AFTER", config.Concurrency.RetryAfter, 1)
+
+	oneOf("TRACING_EXPORTER", config.Tracing.Exporter, "none", "otlp", "stdout", "file")
+	required("TRACING_SERVICE_NAME", config.Tracing.ServiceName)
+	if config.Tracing.Exporter == "file" {
+		required("TRACING_FILE_PATH", config.Tracing.FilePath)
+	}
+	atLeast("TRACING_SAMPLE_PERCENT", config.Tracing.SamplePercent, 0)
+	if config.Tracing.SamplePercent > 100 {
+		errs = append(errs, errors.New("TRACING_SAMPLE_PERCENT must be at most 100"))
+	}
+
+	return errors.Join(errs...)
+}
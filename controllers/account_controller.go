@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+	modelrequests "todo-list-api/models/requests"
+	"todo-list-api/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AccountController interface {
+	Delete(c echo.Context) error
+	Export(c echo.Context) error
+}
+
+type AccountControllerImplementation struct {
+	AccountService services.AccountService
+}
+
+func NewAccountController(accountService services.AccountService) AccountController {
+	return &AccountControllerImplementation{
+		AccountService: accountService,
+	}
+}
+
+func (controller *AccountControllerImplementation) Delete(c echo.Context) error {
+	var deleteAccountRequest modelrequests.DeleteAccountRequest
+	err := c.Bind(&deleteAccountRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	httpCode, response := controller.AccountService.Delete(c.Request().Context(), deleteAccountRequest)
+	return c.JSON(httpCode, response)
+}
+
+func (controller *AccountControllerImplementation) Export(c echo.Context) error {
+	httpCode, archive, response := controller.AccountService.Export(c.Request().Context())
+	if archive == nil {
+		return c.JSON(httpCode, response)
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="todo-list-export-`+time.Now().UTC().Format("20060102")+`.zip"`)
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.Blob(httpCode, "application/zip", archive)
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"todo-list-api/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AdminController interface {
+	FindUsers(c echo.Context) error
+	Disable(c echo.Context) error
+	Enable(c echo.Context) error
+	Logout(c echo.Context) error
+	TodoStatistics(c echo.Context) error
+}
+
+type AdminControllerImplementation struct {
+	AdminService services.AdminService
+}
+
+func NewAdminController(adminService services.AdminService) AdminController {
+	return &AdminControllerImplementation{
+		AdminService: adminService,
+	}
+}
+
+func (controller *AdminControllerImplementation) FindUsers(c echo.Context) error {
+	page, limit := 1, 20
+	var err error
+	if pageQueryParam := c.QueryParam("page"); pageQueryParam != "" {
+		page, err = strconv.Atoi(pageQueryParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": err.Error(),
+			})
+		}
+	}
+	if limitQueryParam := c.QueryParam("limit"); limitQueryParam != "" {
+		limit, err = strconv.Atoi(limitQueryParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": err.Error(),
+			})
+		}
+	}
+	httpCode, response := controller.AdminService.FindUsers(c.Request().Context(), c.QueryParam("search"), page, limit)
+	return c.JSON(httpCode, response)
+}
+
+func (controller *AdminControllerImplementation) Disable(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	httpCode, response := controller.AdminService.Disable(c.Request().Context(), id)
+	return c.JSON(httpCode, response)
+}
+
+func (controller *AdminControllerImplementation) Enable(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	httpCode, response := controller.AdminService.Enable(c.Request().Context(), id)
+	return c.JSON(httpCode, response)
+}
+
+func (controller *AdminControllerImplementation) Logout(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	httpCode, response := controller.AdminService.Logout(c.Request().Context(), id)
+	return c.JSON(httpCode, response)
+}
+
+func (controller *AdminControllerImplementation) TodoStatistics(c echo.Context) error {
+	httpCode, response := controller.AdminService.TodoStatistics(c.Request().Context())
+	return c.JSON(httpCode, response)
+}
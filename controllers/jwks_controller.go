@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"net/http"
+	"todo-list-api/helpers"
+
+	"github.com/labstack/echo/v4"
+)
+
+type JwksController interface {
+	Jwks(c echo.Context) error
+}
+
+type JwksControllerImplementation struct {
+	JwtHelper helpers.JwtHelper
+}
+
+func NewJwksController(jwtHelper helpers.JwtHelper) JwksController {
+	return &JwksControllerImplementation{
+		JwtHelper: jwtHelper,
+	}
+}
+
+func (controller *JwksControllerImplementation) Jwks(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, controller.JwtHelper.GetJwks())
+}
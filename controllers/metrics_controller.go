@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"todo-list-api/helpers"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MetricsController interface {
+	Metrics(c echo.Context) error
+}
+
+type MetricsControllerImplementation struct {
+	MetricsHelper helpers.MetricsHelper
+	Token         string
+}
+
+// NewMetricsController asks for token as a bearer token when it is not empty, the metrics tell how the api is used.
+func NewMetricsController(metricsHelper helpers.MetricsHelper, token string) MetricsController {
+	return &MetricsControllerImplementation{
+		MetricsHelper: metricsHelper,
+		Token:         token,
+	}
+}
+
+func (controller *MetricsControllerImplementation) Metrics(c echo.Context) error {
+	if controller.Token != "" {
+		token, _ := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(controller.Token)) != 1 {
+			return c.JSON(http.StatusUnauthorized, helpers.ToResponse("unauthorized"))
+		}
+	}
+	controller.MetricsHelper.Handler().ServeHTTP(c.Response(), c.Request())
+	return nil
+}
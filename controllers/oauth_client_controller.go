@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"net/http"
+	modelrequests "todo-list-api/models/requests"
+	"todo-list-api/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+type OauthClientController interface {
+	Create(c echo.Context) error
+	FindAll(c echo.Context) error
+	Delete(c echo.Context) error
+}
+
+type OauthClientControllerImplementation struct {
+	OauthClientService services.OauthClientService
+}
+
+func NewOauthClientController(oauthClientService services.OauthClientService) OauthClientController {
+	return &OauthClientControllerImplementation{
+		OauthClientService: oauthClientService,
+	}
+}
+
+func (controller *OauthClientControllerImplementation) Create(c echo.Context) error {
+	var createOauthClientRequest modelrequests.CreateOauthClientRequest
+	err := c.Bind(&createOauthClientRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	httpCode, response := controller.OauthClientService.Create(c.Request().Context(), createOauthClientRequest)
+	return c.JSON(httpCode, response)
+}
+
+func (controller *OauthClientControllerImplementation) FindAll(c echo.Context) error {
+	httpCode, response := controller.OauthClientService.FindAll(c.Request().Context())
+	return c.JSON(httpCode, response)
+}
+
+func (controller *OauthClientControllerImplementation) Delete(c echo.Context) error {
+	httpCode, response := controller.OauthClientService.Delete(c.Request().Context(), c.Param("clientId"))
+	return c.JSON(httpCode, response)
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"net/http"
+	"net/url"
+	modelrequests "todo-list-api/models/requests"
+	modelresponses "todo-list-api/models/responses"
+	"todo-list-api/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+type OauthController interface {
+	Authorize(c echo.Context) error
+	Approve(c echo.Context) error
+	Token(c echo.Context) error
+	Revoke(c echo.Context) error
+	Introspect(c echo.Context) error
+}
+
+type OauthControllerImplementation struct {
+	OauthService services.OauthService
+}
+
+func NewOauthController(oauthService services.OauthService) OauthController {
+	return &OauthControllerImplementation{
+		OauthService: oauthService,
+	}
+}
+
+func (controller *OauthControllerImplementation) Authorize(c echo.Context) error {
+	var authorizeRequest modelrequests.AuthorizeRequest
+	err := c.Bind(&authorizeRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	httpCode, response := controller.OauthService.Authorize(c.Request().Context(), authorizeRequest)
+	return c.JSON(httpCode, response)
+}
+
+func (controller *OauthControllerImplementation) Approve(c echo.Context) error {
+	var authorizeRequest modelrequests.AuthorizeRequest
+	err := c.Bind(&authorizeRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	httpCode, response := controller.OauthService.Approve(c.Request().Context(), authorizeRequest)
+	return c.JSON(httpCode, response)
+}
+
+func (controller *OauthControllerImplementation) Token(c echo.Context) error {
+	var tokenRequest modelrequests.TokenRequest
+	err := c.Bind(&tokenRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, modelresponses.OauthErrorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+	}
+	tokenRequest.ClientId, tokenRequest.ClientSecret = clientCredentials(c, tokenRequest.ClientId, tokenRequest.ClientSecret)
+	httpCode, response := controller.OauthService.Token(c.Request().Context(), tokenRequest)
+	return oauthJSON(c, httpCode, response)
+}
+
+func (controller *OauthControllerImplementation) Revoke(c echo.Context) error {
+	var revokeTokenRequest modelrequests.RevokeTokenRequest
+	err := c.Bind(&revokeTokenRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, modelresponses.OauthErrorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+	}
+	revokeTokenRequest.ClientId, revokeTokenRequest.ClientSecret = clientCredentials(c, revokeTokenRequest.ClientId, revokeTokenRequest.ClientSecret)
+	httpCode, response := controller.OauthService.Revoke(c.Request().Context(), revokeTokenRequest)
+	return oauthJSON(c, httpCode, response)
+}
+
+func (controller *OauthControllerImplementation) Introspect(c echo.Context) error {
+	var introspectTokenRequest modelrequests.IntrospectTokenRequest
+	err := c.Bind(&introspectTokenRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, modelresponses.OauthErrorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+	}
+	introspectTokenRequest.ClientId, introspectTokenRequest.ClientSecret = clientCredentials(c, introspectTokenRequest.ClientId, introspectTokenRequest.ClientSecret)
+	httpCode, response := controller.OauthService.Introspect(c.Request().Context(), introspectTokenRequest)
+	return oauthJSON(c, httpCode, response)
+}
+
+// clientCredentials prefers http basic authentication, where rfc 6749 has the client id and secret form encoded,
+// over the client_id and client_secret form parameters.
+func clientCredentials(c echo.Context, clientId string, clientSecret string) (string, string) {
+	username, password, ok := c.Request().BasicAuth()
+	if !ok {
+		return clientId, clientSecret
+	}
+	if unescaped, err := url.QueryUnescape(username); err == nil {
+		username = unescaped
+	}
+	if unescaped, err := url.QueryUnescape(password); err == nil {
+		password = unescaped
+	}
+	return username, password
+}
+
+func oauthJSON(c echo.Context, httpCode int, response interface{}) error {
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	c.Response().Header().Set("Pragma", "no-cache")
+	if httpCode == http.StatusUnauthorized {
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+	}
+	return c.JSON(httpCode, response)
+}
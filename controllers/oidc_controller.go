@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"net/http"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	"todo-list-api/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+const oidcStateCookieName = "oidcState"
+
+type OidcController interface {
+	Start(c echo.Context) error
+	Callback(c echo.Context) error
+}
+
+type OidcControllerImplementation struct {
+	OidcService  services.OidcService
+	CookieConfig config.CookieConfig
+}
+
+func NewOidcController(oidcService services.OidcService, cookieConfig config.CookieConfig) OidcController {
+	return &OidcControllerImplementation{
+		OidcService:  oidcService,
+		CookieConfig: cookieConfig,
+	}
+}
+
+func (controller *OidcControllerImplementation) Start(c echo.Context) error {
+	httpCode, authCodeUrl, oidcStateToken, response := controller.OidcService.Start(c.Request().Context(), c.Param("provider"))
+	if authCodeUrl == "" {
+		return c.JSON(httpCode, response)
+	}
+
+	// SameSite=Lax still sends the cookie on the top level redirect back from the provider
+	c.SetCookie(helpers.NewCookie(controller.CookieConfig, oidcStateCookieName, oidcStateToken, "/oidc/"))
+	return c.Redirect(httpCode, authCodeUrl)
+}
+
+func (controller *OidcControllerImplementation) Callback(c echo.Context) error {
+	var oidcStateToken string
+	if cookie, err := c.Cookie(oidcStateCookieName); err == nil {
+		oidcStateToken = cookie.Value
+	}
+	// the state is single use, the cookie goes whatever the outcome
+	c.SetCookie(helpers.NewExpiredCookie(controller.CookieConfig, oidcStateCookieName, "/oidc/"))
+
+	if errorQueryParam := c.QueryParam("error"); errorQueryParam != "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"message": "oidc login failed: " + errorQueryParam,
+		})
+	}
+
+	httpCode, accessToken, refreshToken, response := controller.OidcService.Callback(c.Request().Context(), c.Param("provider"), c.QueryParam("code"), c.QueryParam("state"), oidcStateToken)
+
+	if accessToken != "" {
+		c.SetCookie(helpers.NewCookie(controller.CookieConfig, helpers.AccessTokenCookieName, accessToken, "/"))
+	}
+
+	if refreshToken != "" {
+		c.SetCookie(helpers.NewCookie(controller.CookieConfig, helpers.RefreshTokenCookieName, refreshToken, "/"))
+	}
+
+	return c.JSON(httpCode, response)
+}
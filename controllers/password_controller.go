@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"net/http"
+	modelrequests "todo-list-api/models/requests"
+	"todo-list-api/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PasswordController interface {
+	ForgotPassword(c echo.Context) error
+	ResetPassword(c echo.Context) error
+}
+
+type PasswordControllerImplementation struct {
+	PasswordService services.PasswordService
+}
+
+func NewPasswordController(passwordService services.PasswordService) PasswordController {
+	return &PasswordControllerImplementation{
+		PasswordService: passwordService,
+	}
+}
+
+func (controller *PasswordControllerImplementation) ForgotPassword(c echo.Context) error {
+	var forgotPasswordRequest modelrequests.ForgotPasswordRequest
+	err := c.Bind(&forgotPasswordRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	httpCode, response := controller.PasswordService.ForgotPassword(c.Request().Context(), forgotPasswordRequest)
+	return c.JSON(httpCode, response)
+}
+
+func (controller *PasswordControllerImplementation) ResetPassword(c echo.Context) error {
+	var resetPasswordRequest modelrequests.ResetPasswordRequest
+	err := c.Bind(&resetPasswordRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	httpCode, response := controller.PasswordService.ResetPassword(c.Request().Context(), resetPasswordRequest)
+	return c.JSON(httpCode, response)
+}
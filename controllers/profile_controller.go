@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"net/http"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	modelrequests "todo-list-api/models/requests"
+	"todo-list-api/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ProfileController interface {
+	Get(c echo.Context) error
+	Update(c echo.Context) error
+	ChangePassword(c echo.Context) error
+	ChangeEmail(c echo.Context) error
+}
+
+type ProfileControllerImplementation struct {
+	ProfileService services.ProfileService
+	CookieConfig   config.CookieConfig
+}
+
+func NewProfileController(profileService services.ProfileService, cookieConfig config.CookieConfig) ProfileController {
+	return &ProfileControllerImplementation{
+		ProfileService: profileService,
+		CookieConfig:   cookieConfig,
+	}
+}
+
+func (controller *ProfileControllerImplementation) Get(c echo.Context) error {
+	httpCode, response := controller.ProfileService.Get(c.Request().Context())
+	return c.JSON(httpCode, response)
+}
+
+func (controller *ProfileControllerImplementation) Update(c echo.Context) error {
+	var updateProfileRequest modelrequests.UpdateProfileRequest
+	err := c.Bind(&updateProfileRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	httpCode, response := controller.ProfileService.Update(c.Request().Context(), updateProfileRequest)
+	return c.JSON(httpCode, response)
+}
+
+func (controller *ProfileControllerImplementation) ChangePassword(c echo.Context) error {
+	var changePasswordRequest modelrequests.ChangePasswordRequest
+	err := c.Bind(&changePasswordRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	httpCode, accessToken, refreshToken, response := controller.ProfileService.ChangePassword(c.Request().Context(), changePasswordRequest)
+
+	if accessToken != "" {
+		c.SetCookie(helpers.NewCookie(controller.CookieConfig, helpers.AccessTokenCookieName, accessToken, "/"))
+	}
+
+	if refreshToken != "" {
+		c.SetCookie(helpers.NewCookie(controller.CookieConfig, helpers.RefreshTokenCookieName, refreshToken, "/"))
+	}
+
+	return c.JSON(httpCode, response)
+}
+
+func (controller *ProfileControllerImplementation) ChangeEmail(c echo.Context) error {
+	var changeEmailRequest modelrequests.ChangeEmailRequest
+	err := c.Bind(&changeEmailRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	httpCode, response := controller.ProfileService.ChangeEmail(c.Request().Context(), changeEmailRequest)
+	return c.JSON(httpCode, response)
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	modelrequests "todo-list-api/models/requests"
+	"todo-list-api/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+type SecurityEventController interface {
+	FindMine(c echo.Context) error
+	FindAll(c echo.Context) error
+}
+
+type SecurityEventControllerImplementation struct {
+	SecurityEventService services.SecurityEventService
+}
+
+func NewSecurityEventController(securityEventService services.SecurityEventService) SecurityEventController {
+	return &SecurityEventControllerImplementation{
+		SecurityEventService: securityEventService,
+	}
+}
+
+func (controller *SecurityEventControllerImplementation) FindMine(c echo.Context) error {
+	page, limit := 1, 20
+	var err error
+	if pageQueryParam := c.QueryParam("page"); pageQueryParam != "" {
+		page, err = strconv.Atoi(pageQueryParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": err.Error(),
+			})
+		}
+	}
+	if limitQueryParam := c.QueryParam("limit"); limitQueryParam != "" {
+		limit, err = strconv.Atoi(limitQueryParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": err.Error(),
+			})
+		}
+	}
+	httpCode, response := controller.SecurityEventService.FindMine(c.Request().Context(), page, limit)
+	return c.JSON(httpCode, response)
+}
+
+func (controller *SecurityEventControllerImplementation) FindAll(c echo.Context) error {
+	findSecurityEventsRequest := modelrequests.FindSecurityEventsRequest{Page: 1, Limit: 20}
+	err := c.Bind(&findSecurityEventsRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	httpCode, response := controller.SecurityEventService.FindAll(c.Request().Context(), findSecurityEventsRequest)
+	return c.JSON(httpCode, response)
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"net/http"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	modelrequests "todo-list-api/models/requests"
+	"todo-list-api/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TwoFactorController interface {
+	Enroll(c echo.Context) error
+	Confirm(c echo.Context) error
+	Disable(c echo.Context) error
+	Login(c echo.Context) error
+}
+
+type TwoFactorControllerImplementation struct {
+	TwoFactorService services.TwoFactorService
+	CookieConfig     config.CookieConfig
+}
+
+func NewTwoFactorController(twoFactorService services.TwoFactorService, cookieConfig config.CookieConfig) TwoFactorController {
+	return &TwoFactorControllerImplementation{
+		TwoFactorService: twoFactorService,
+		CookieConfig:     cookieConfig,
+	}
+}
+
+func (controller *TwoFactorControllerImplementation) Enroll(c echo.Context) error {
+	httpCode, response := controller.TwoFactorService.Enroll(c.Request().Context())
+	return c.JSON(httpCode, response)
+}
+
+func (controller *TwoFactorControllerImplementation) Confirm(c echo.Context) error {
+	var confirmTwoFactorRequest modelrequests.ConfirmTwoFactorRequest
+	err := c.Bind(&confirmTwoFactorRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	httpCode, response := controller.TwoFactorService.Confirm(c.Request().Context(), confirmTwoFactorRequest)
+	return c.JSON(httpCode, response)
+}
+
+func (controller *TwoFactorControllerImplementation) Disable(c echo.Context) error {
+	var disableTwoFactorRequest modelrequests.DisableTwoFactorRequest
+	err := c.Bind(&disableTwoFactorRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	httpCode, response := controller.TwoFactorService.Disable(c.Request().Context(), disableTwoFactorRequest)
+	return c.JSON(httpCode, response)
+}
+
+func (controller *TwoFactorControllerImplementation) Login(c echo.Context) error {
+	var loginTwoFactorRequest modelrequests.LoginTwoFactorRequest
+	err := c.Bind(&loginTwoFactorRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	httpCode, accessToken, refreshToken, response := controller.TwoFactorService.Login(c.Request().Context(), loginTwoFactorRequest)
+
+	if accessToken != "" {
+		c.SetCookie(helpers.NewCookie(controller.CookieConfig, helpers.AccessTokenCookieName, accessToken, "/"))
+	}
+
+	if refreshToken != "" {
+		c.SetCookie(helpers.NewCookie(controller.CookieConfig, helpers.RefreshTokenCookieName, refreshToken, "/"))
+	}
+
+	return c.JSON(httpCode, response)
+}
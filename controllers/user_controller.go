@@ -2,7 +2,11 @@ package controllers
 
 import (
 	"net/http"
+	"strconv"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
 	modelrequests "todo-list-api/models/requests"
+	modelresponses "todo-list-api/models/responses"
 	"todo-list-api/services"
 
 	"github.com/labstack/echo/v4"
@@ -12,15 +16,20 @@ type UserController interface {
 	Register(c echo.Context) error
 	Login(c echo.Context) error
 	RefershToken(c echo.Context) error
+	VerifyEmail(c echo.Context) error
+	ResendVerificationEmail(c echo.Context) error
+	Logout(c echo.Context) error
 }
 
 type UserControllerImplementation struct {
-	UserService services.UserService
+	UserService  services.UserService
+	CookieConfig config.CookieConfig
 }
 
-func NewUserController(userService services.UserService) UserController {
+func NewUserController(userService services.UserService, cookieConfig config.CookieConfig) UserController {
 	return &UserControllerImplementation{
-		UserService: userService,
+		UserService:  userService,
+		CookieConfig: cookieConfig,
 	}
 }
 
@@ -35,17 +44,11 @@ func (controller *UserControllerImplementation) Register(c echo.Context) error {
 	httpCode, accessToken, refreshToken, response := controller.UserService.Register(c.Request().Context(), registerRequest)
 
 	if accessToken != "" {
-		cookie := new(http.Cookie)
-		cookie.Name = "Authorization"
-		cookie.Value = accessToken
-		c.SetCookie(cookie)
+		c.SetCookie(helpers.NewCookie(controller.CookieConfig, helpers.AccessTokenCookieName, accessToken, "/"))
 	}
 
 	if refreshToken != "" {
-		cookie := new(http.Cookie)
-		cookie.Name = "refreshToken"
-		cookie.Value = refreshToken
-		c.SetCookie(cookie)
+		c.SetCookie(helpers.NewCookie(controller.CookieConfig, helpers.RefreshTokenCookieName, refreshToken, "/"))
 	}
 
 	return c.JSON(httpCode, response)
@@ -60,22 +63,21 @@ func (controller *UserControllerImplementation) Login(c echo.Context) error {
 		})
 	}
 	httpCode, accessToken, refreshToken, response := controller.UserService.Login(c.Request().Context(), loginRequest)
+	setRetryAfter(c, response)
 
-	cookie := new(http.Cookie)
-	cookie.Name = "Authorization"
-	cookie.Value = accessToken
-	c.SetCookie(cookie)
+	if accessToken != "" {
+		c.SetCookie(helpers.NewCookie(controller.CookieConfig, helpers.AccessTokenCookieName, accessToken, "/"))
+	}
 
-	cookie = new(http.Cookie)
-	cookie.Name = "refreshToken"
-	cookie.Value = refreshToken
-	c.SetCookie(cookie)
+	if refreshToken != "" {
+		c.SetCookie(helpers.NewCookie(controller.CookieConfig, helpers.RefreshTokenCookieName, refreshToken, "/"))
+	}
 
 	return c.JSON(httpCode, response)
 }
 
 func (controller *UserControllerImplementation) RefershToken(c echo.Context) error {
-	refreshTokenCookie, err := c.Cookie("refreshToken")
+	refreshTokenCookie, err := c.Cookie(helpers.RefreshTokenCookieName)
 	if err != nil && err != http.ErrNoCookie {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"message": err.Error(),
@@ -87,9 +89,34 @@ func (controller *UserControllerImplementation) RefershToken(c echo.Context) err
 	}
 
 	httpCode, accessToken, response := controller.UserService.RefreshToken(c.Request().Context(), refreshTokenCookie.Value)
-	cookie := new(http.Cookie)
-	cookie.Name = "accessToken"
-	cookie.Value = accessToken
-	c.SetCookie(cookie)
+	if accessToken != "" {
+		c.SetCookie(helpers.NewCookie(controller.CookieConfig, helpers.AccessTokenCookieName, accessToken, "/"))
+	}
 	return c.JSON(httpCode, response)
 }
+
+func (controller *UserControllerImplementation) VerifyEmail(c echo.Context) error {
+	httpCode, response := controller.UserService.VerifyEmail(c.Request().Context(), c.QueryParam("token"))
+	return c.JSON(httpCode, response)
+}
+
+func (controller *UserControllerImplementation) ResendVerificationEmail(c echo.Context) error {
+	httpCode, response := controller.UserService.ResendVerificationEmail(c.Request().Context())
+	setRetryAfter(c, response)
+	return c.JSON(httpCode, response)
+}
+
+func (controller *UserControllerImplementation) Logout(c echo.Context) error {
+	httpCode, response := controller.UserService.Logout(c.Request().Context())
+	if httpCode == http.StatusOK {
+		c.SetCookie(helpers.NewExpiredCookie(controller.CookieConfig, helpers.AccessTokenCookieName, "/"))
+		c.SetCookie(helpers.NewExpiredCookie(controller.CookieConfig, helpers.RefreshTokenCookieName, "/"))
+	}
+	return c.JSON(httpCode, response)
+}
+
+func setRetryAfter(c echo.Context, response interface{}) {
+	if retryAfterResponse, ok := response.(modelresponses.RetryAfterResponse); ok {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfterResponse.RetryAfter))
+	}
+}
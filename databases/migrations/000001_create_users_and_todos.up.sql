@@ -0,0 +1,18 @@
+-- the baseline, a database made by hand before the migrations already has both tables and only gets the wider
+-- password column, an argon2id hash does not fit in the old VARCHAR(100) once its parameters grow
+CREATE TABLE IF NOT EXISTS users (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(50) NOT NULL,
+	email VARCHAR(100) NOT NULL UNIQUE,
+	password TEXT NOT NULL,
+	refresh_token TEXT NULL
+);
+
+ALTER TABLE users ALTER COLUMN password TYPE TEXT;
+
+CREATE TABLE IF NOT EXISTS todos (
+	id SERIAL PRIMARY KEY,
+	user_id INT REFERENCES users(id) NOT NULL,
+	title VARCHAR(50) NOT NULL,
+	description TEXT NOT NULL
+);
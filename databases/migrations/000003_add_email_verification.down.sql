@@ -0,0 +1,2 @@
+ALTER TABLE users DROP email_verification_sent_at;
+ALTER TABLE users DROP email_verified_at;
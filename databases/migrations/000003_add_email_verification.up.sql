@@ -0,0 +1,2 @@
+ALTER TABLE users ADD email_verified_at TIMESTAMPTZ NULL;
+ALTER TABLE users ADD email_verification_sent_at TIMESTAMPTZ NULL;
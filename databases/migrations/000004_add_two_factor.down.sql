@@ -0,0 +1,5 @@
+DROP TABLE user_recovery_codes;
+
+ALTER TABLE users DROP totp_last_used_step;
+ALTER TABLE users DROP totp_enabled_at;
+ALTER TABLE users DROP totp_secret;
@@ -0,0 +1,13 @@
+ALTER TABLE users ADD totp_secret TEXT NULL;
+ALTER TABLE users ADD totp_enabled_at TIMESTAMPTZ NULL;
+ALTER TABLE users ADD totp_last_used_step BIGINT NULL;
+
+CREATE TABLE user_recovery_codes (
+	id SERIAL PRIMARY KEY,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+	code_hash VARCHAR(64) NOT NULL,
+	used_at TIMESTAMPTZ NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX user_recovery_codes_user_id_idx ON user_recovery_codes (user_id);
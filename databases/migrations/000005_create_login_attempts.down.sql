@@ -0,0 +1,2 @@
+DROP TABLE login_lockout_events;
+DROP TABLE login_attempts;
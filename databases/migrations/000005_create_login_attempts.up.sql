@@ -0,0 +1,17 @@
+CREATE TABLE login_attempts (
+	attempt_key VARCHAR(320) PRIMARY KEY,
+	failed_count INT NOT NULL,
+	locked_until TIMESTAMPTZ NULL,
+	last_failed_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE login_lockout_events (
+	id SERIAL PRIMARY KEY,
+	attempt_key VARCHAR(320) NOT NULL,
+	ip_address VARCHAR(45) NULL,
+	failed_count INT NOT NULL,
+	locked_until TIMESTAMPTZ NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX login_lockout_events_attempt_key_idx ON login_lockout_events (attempt_key);
@@ -0,0 +1,5 @@
+ALTER TABLE todos DROP CONSTRAINT todos_user_id_fkey;
+ALTER TABLE todos ADD CONSTRAINT todos_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
+
+DROP INDEX users_deletion_scheduled_at_idx;
+ALTER TABLE users DROP deletion_scheduled_at;
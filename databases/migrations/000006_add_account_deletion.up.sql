@@ -0,0 +1,5 @@
+ALTER TABLE users ADD deletion_scheduled_at TIMESTAMPTZ NULL;
+CREATE INDEX users_deletion_scheduled_at_idx ON users (deletion_scheduled_at) WHERE deletion_scheduled_at IS NOT NULL;
+
+ALTER TABLE todos DROP CONSTRAINT todos_user_id_fkey;
+ALTER TABLE todos ADD CONSTRAINT todos_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
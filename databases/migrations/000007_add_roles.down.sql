@@ -0,0 +1,3 @@
+ALTER TABLE users DROP sessions_revoked_at;
+ALTER TABLE users DROP disabled_at;
+ALTER TABLE users DROP role;
@@ -0,0 +1,3 @@
+ALTER TABLE users ADD role VARCHAR(20) NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'admin'));
+ALTER TABLE users ADD disabled_at TIMESTAMPTZ NULL;
+ALTER TABLE users ADD sessions_revoked_at TIMESTAMPTZ NULL;
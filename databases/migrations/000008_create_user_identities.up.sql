@@ -0,0 +1,12 @@
+CREATE TABLE user_identities (
+	id SERIAL PRIMARY KEY,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+	provider VARCHAR(50) NOT NULL,
+	subject VARCHAR(255) NOT NULL,
+	email VARCHAR(320) NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	last_login_at TIMESTAMPTZ NULL,
+	UNIQUE (provider, subject)
+);
+
+CREATE INDEX user_identities_user_id_idx ON user_identities (user_id);
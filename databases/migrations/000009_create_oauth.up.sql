@@ -0,0 +1,36 @@
+CREATE TABLE oauth_clients (
+	id SERIAL PRIMARY KEY,
+	client_id VARCHAR(64) NOT NULL UNIQUE,
+	client_secret_hash VARCHAR(64) NULL,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+	name VARCHAR(100) NOT NULL,
+	redirect_uris TEXT[] NOT NULL,
+	scopes TEXT[] NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX oauth_clients_user_id_idx ON oauth_clients (user_id);
+
+CREATE TABLE oauth_grants (
+	id SERIAL PRIMARY KEY,
+	client_id VARCHAR(64) REFERENCES oauth_clients(client_id) ON DELETE CASCADE NOT NULL,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+	scopes TEXT[] NOT NULL,
+	refresh_token_hash VARCHAR(64) NOT NULL UNIQUE,
+	refresh_token_expires_at TIMESTAMPTZ NOT NULL,
+	revoked_at TIMESTAMPTZ NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE oauth_authorization_codes (
+	id SERIAL PRIMARY KEY,
+	code_hash VARCHAR(64) NOT NULL UNIQUE,
+	client_id VARCHAR(64) REFERENCES oauth_clients(client_id) ON DELETE CASCADE NOT NULL,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+	redirect_uri TEXT NOT NULL,
+	scopes TEXT[] NOT NULL,
+	code_challenge VARCHAR(128) NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL,
+	used_at TIMESTAMPTZ NULL,
+	grant_id INT REFERENCES oauth_grants(id) ON DELETE CASCADE NULL
+);
@@ -0,0 +1,22 @@
+CREATE TABLE security_events (
+	id SERIAL PRIMARY KEY,
+	user_id INT NULL,
+	event_type VARCHAR(50) NOT NULL,
+	email VARCHAR(320) NULL,
+	detail VARCHAR(320) NULL,
+	ip_address VARCHAR(45) NOT NULL,
+	user_agent TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX security_events_user_id_created_at_idx ON security_events (user_id, created_at);
+CREATE INDEX security_events_created_at_idx ON security_events (created_at);
+
+CREATE FUNCTION security_events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+	RAISE EXCEPTION 'security_events is append only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER security_events_append_only BEFORE UPDATE OR DELETE ON security_events
+	FOR EACH ROW EXECUTE FUNCTION security_events_append_only();
@@ -0,0 +1,9 @@
+CREATE TABLE mfa_challenges (
+	jti VARCHAR(64) PRIMARY KEY,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+	failed_count INT NOT NULL DEFAULT 0,
+	expires_at TIMESTAMPTZ NOT NULL,
+	used_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX mfa_challenges_user_id_idx ON mfa_challenges (user_id);
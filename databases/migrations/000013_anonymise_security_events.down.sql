@@ -0,0 +1,13 @@
+CREATE OR REPLACE FUNCTION security_events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+	RAISE EXCEPTION 'security_events is append only';
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE security_events DISABLE TRIGGER security_events_append_only;
+UPDATE security_events SET ip_address = '' WHERE ip_address IS NULL;
+UPDATE security_events SET user_agent = '' WHERE user_agent IS NULL;
+ALTER TABLE security_events ENABLE TRIGGER security_events_append_only;
+
+ALTER TABLE security_events ALTER COLUMN ip_address SET NOT NULL;
+ALTER TABLE security_events ALTER COLUMN user_agent SET NOT NULL;
@@ -0,0 +1,21 @@
+ALTER TABLE security_events ALTER COLUMN ip_address DROP NOT NULL;
+ALTER TABLE security_events ALTER COLUMN user_agent DROP NOT NULL;
+
+-- the purge of a deleted account may blank the email, the ip address and the user agent of its events, and a detail
+-- that names the email or the ip of a lockout, everything else stays as it was written
+CREATE OR REPLACE FUNCTION security_events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+	IF TG_OP = 'UPDATE'
+		AND NEW.id = OLD.id
+		AND NEW.user_id IS NOT DISTINCT FROM OLD.user_id
+		AND NEW.event_type = OLD.event_type
+		AND NEW.created_at = OLD.created_at
+		AND NEW.email IS NULL
+		AND NEW.ip_address IS NULL
+		AND NEW.user_agent IS NULL
+		AND (NEW.detail IS NOT DISTINCT FROM OLD.detail OR NEW.detail IS NULL) THEN
+		RETURN NEW;
+	END IF;
+	RAISE EXCEPTION 'security_events is append only';
+END;
+$$ LANGUAGE plpgsql;
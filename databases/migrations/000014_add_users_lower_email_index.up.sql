@@ -0,0 +1,2 @@
+-- fails when two accounts only differ in the case of their email, merge or rename one of them first
+CREATE UNIQUE INDEX users_lower_email_idx ON users (LOWER(email));
@@ -0,0 +1,8 @@
+package migrations
+
+import "embed"
+
+// Files has every migration of the schema, NNNNNN_name.up.sql and NNNNNN_name.down.sql, applied in the order of NNNNNN.
+//
+//go:embed *.sql
+var Files embed.FS
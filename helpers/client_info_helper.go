@@ -0,0 +1,19 @@
+package helpers
+
+import "context"
+
+type ClientInfo struct {
+	IpAddress string
+	UserAgent string
+}
+
+type clientInfoContextKey struct{}
+
+func ContextWithClientInfo(ctx context.Context, clientInfo ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoContextKey{}, clientInfo)
+}
+
+func ClientInfoFromContext(ctx context.Context) (clientInfo ClientInfo, ok bool) {
+	clientInfo, ok = ctx.Value(clientInfoContextKey{}).(ClientInfo)
+	return
+}
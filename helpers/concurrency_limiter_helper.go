@@ -0,0 +1,127 @@
+package helpers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	PriorityLow    = 0
+	PriorityNormal = 1
+	PriorityHigh   = 2
+)
+
+// ConcurrencyLimiter lets at most maxInFlight requests run at once. The others wait in a queue of at most maxQueue,
+// higher priority first and in arrival order within a priority, for at most queueWait. When the queue is full a new
+// request takes the place of the lowest priority one waiting, if that one has a lower priority, or is refused.
+// release has to be called once the request is done, and only when ok is true.
+type ConcurrencyLimiter interface {
+	Acquire(ctx context.Context, priority int) (release func(), ok bool)
+}
+
+type concurrencyWaiter struct {
+	priority int
+	granted  chan bool
+}
+
+type ConcurrencyLimiterImplementation struct {
+	mutex       sync.Mutex
+	maxInFlight int
+	maxQueue    int
+	queueWait   time.Duration
+	inFlight    int
+	queue       []*concurrencyWaiter
+}
+
+func NewConcurrencyLimiter(maxInFlight int, maxQueue int, queueWait time.Duration) ConcurrencyLimiter {
+	return &ConcurrencyLimiterImplementation{
+		maxInFlight: maxInFlight,
+		maxQueue:    maxQueue,
+		queueWait:   queueWait,
+	}
+}
+
+func (helper *ConcurrencyLimiterImplementation) Acquire(ctx context.Context, priority int) (release func(), ok bool) {
+	helper.mutex.Lock()
+	if helper.inFlight < helper.maxInFlight && len(helper.queue) == 0 {
+		helper.inFlight++
+		helper.mutex.Unlock()
+		return helper.release, true
+	}
+	waiter := &concurrencyWaiter{priority: priority, granted: make(chan bool, 1)}
+	if !helper.enqueue(waiter) {
+		helper.mutex.Unlock()
+		return nil, false
+	}
+	helper.mutex.Unlock()
+
+	timer := time.NewTimer(helper.queueWait)
+	defer timer.Stop()
+	select {
+	case ok = <-waiter.granted:
+		if ok {
+			return helper.release, true
+		}
+		return nil, false
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	helper.mutex.Lock()
+	defer helper.mutex.Unlock()
+	if helper.remove(waiter) {
+		return nil, false
+	}
+	// the slot or the refusal was sent while the wait ended, take whatever it was
+	if <-waiter.granted {
+		return helper.release, true
+	}
+	return nil, false
+}
+
+// enqueue keeps the queue ordered by priority, highest first, and evicts the last waiter when the queue is full.
+func (helper *ConcurrencyLimiterImplementation) enqueue(waiter *concurrencyWaiter) bool {
+	if len(helper.queue) >= helper.maxQueue {
+		if helper.maxQueue == 0 {
+			return false
+		}
+		last := helper.queue[len(helper.queue)-1]
+		if last.priority >= waiter.priority {
+			return false
+		}
+		helper.queue = helper.queue[:len(helper.queue)-1]
+		last.granted <- false
+	}
+	index := len(helper.queue)
+	for index > 0 && helper.queue[index-1].priority < waiter.priority {
+		index--
+	}
+	helper.queue = append(helper.queue, nil)
+	copy(helper.queue[index+1:], helper.queue[index:])
+	helper.queue[index] = waiter
+	return true
+}
+
+func (helper *ConcurrencyLimiterImplementation) remove(waiter *concurrencyWaiter) bool {
+	for index, queuedWaiter := range helper.queue {
+		if queuedWaiter == waiter {
+			helper.queue = append(helper.queue[:index], helper.queue[index+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// release hands the slot straight to the first waiter, so a request arriving now cannot jump the queue.
+func (helper *ConcurrencyLimiterImplementation) release() {
+	helper.mutex.Lock()
+	defer helper.mutex.Unlock()
+	if len(helper.queue) > 0 {
+		waiter := helper.queue[0]
+		helper.queue = helper.queue[1:]
+		waiter.granted <- true
+		return
+	}
+	helper.inFlight--
+}
@@ -0,0 +1,33 @@
+package helpers
+
+import (
+	"net/http"
+	"todo-list-api/config"
+)
+
+const (
+	AccessTokenCookieName  = "Authorization"
+	RefreshTokenCookieName = "refreshToken"
+	CsrfTokenCookieName    = "csrfToken"
+	CsrfTokenHeaderName    = "X-CSRF-Token"
+)
+
+// NewCookie sets the same attributes on every cookie of the api. Secure follows COOKIE_SECURE so the api still works
+// over plain http in development, and SameSite=Lax keeps the cookies off cross site requests other than top level
+// navigations. Only the csrf token cookie is readable by javascript, the frontend has to copy it into a header.
+func NewCookie(cookieConfig config.CookieConfig, name string, value string, path string) *http.Cookie {
+	cookie := new(http.Cookie)
+	cookie.Name = name
+	cookie.Value = value
+	cookie.Path = path
+	cookie.HttpOnly = name != CsrfTokenCookieName
+	cookie.Secure = cookieConfig.Secure
+	cookie.SameSite = http.SameSiteLaxMode
+	return cookie
+}
+
+func NewExpiredCookie(cookieConfig config.CookieConfig, name string, path string) *http.Cookie {
+	cookie := NewCookie(cookieConfig, name, "", path)
+	cookie.MaxAge = -1
+	return cookie
+}
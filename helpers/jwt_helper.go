@@ -1,61 +1,480 @@
 package helpers
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+	"todo-list-api/config"
+	modelresponses "todo-list-api/models/responses"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	AccessTokenType            = "access"
+	RefreshTokenType           = "refresh"
+	EmailVerificationTokenType = "email_verification"
+	MfaPendingTokenType        = "mfa_pending"
+	OidcStateTokenType         = "oidc_state"
+	OauthAccessTokenType       = "oauth_access"
+)
+
 type JwtHelper interface {
-	GenerateAccessToken(id int, name string, email string, jwtAccessTokenTime int, secret string) (accessToken string, err error)
-	GenerateRefreshToken(id int, jwtRefreshTokenTime int, secret string) (refreshToken string, err error)
+	GenerateAccessToken(id int, name string, email string, jwtAccessTokenTime int) (accessToken string, err error)
+	GenerateRefreshToken(id int, jwtRefreshTokenTime int) (refreshToken string, err error)
+	ParseAccessToken(accessToken string) (claims *AccessTokenCustomClaims, err error)
+	GenerateEmailVerificationToken(id int, email string, emailVerificationTokenTime int) (emailVerificationToken string, err error)
+	ParseEmailVerificationToken(emailVerificationToken string) (claims *EmailVerificationTokenCustomClaims, err error)
+	GenerateMfaPendingToken(id int, mfaPendingTokenTime int) (mfaPendingToken string, err error)
+	ParseMfaPendingToken(mfaPendingToken string) (claims *MfaPendingTokenCustomClaims, err error)
+	GenerateOidcStateToken(provider string, state string, nonce string, codeVerifier string, oidcStateTokenTime int) (oidcStateToken string, err error)
+	ParseOidcStateToken(oidcStateToken string) (claims *OidcStateTokenCustomClaims, err error)
+	GenerateOauthAccessToken(id int, clientId string, scope string, grantId int, oauthAccessTokenTime int) (oauthAccessToken string, err error)
+	ParseOauthAccessToken(oauthAccessToken string) (claims *OauthAccessTokenCustomClaims, err error)
+	GetJwks() modelresponses.JwksResponse
+}
+
+type JwtKey struct {
+	Id            string
+	SigningMethod jwt.SigningMethod
+	// nil for retired keys that are only kept to verify tokens issued before a rotation
+	SigningKey   interface{}
+	VerifyingKey interface{}
 }
 
 type JwtHelperImplementation struct {
+	activeKey JwtKey
+	keys      map[string]JwtKey
+	issuer    string
+	audience  string
+}
+
+// NewJwtHelper loads the key set from JWT_SIGNING_KEYS and JWT_VERIFICATION_KEYS, both written as kid=path.pem pairs separated by commas.
+// JWT_ACTIVE_KEY_ID picks the signing key, when it is empty the first signing key is used.
+// Without JWT_SIGNING_KEYS it falls back to HS256 with JWT_SECRET.
+func NewJwtHelper(jwtConfig config.JwtConfig) JwtHelper {
+	var keys []JwtKey
+	if jwtConfig.SigningKeys == "" {
+		keys = append(keys, JwtKey{
+			Id:            "default",
+			SigningMethod: jwt.SigningMethodHS256,
+			SigningKey:    []byte(jwtConfig.Secret),
+			VerifyingKey:  []byte(jwtConfig.Secret),
+		})
+	} else {
+		signingKeys, err := loadJwtKeys(jwtConfig.SigningKeys, true)
+		if err != nil {
+			log.Fatalln("error when loading jwt signing keys: " + err.Error())
+		}
+		keys = append(keys, signingKeys...)
+		verificationKeys, err := loadJwtKeys(jwtConfig.VerificationKeys, false)
+		if err != nil {
+			log.Fatalln("error when loading jwt verification keys: " + err.Error())
+		}
+		keys = append(keys, verificationKeys...)
+	}
+
+	jwtHelper, err := NewJwtHelperWithKeys(keys, jwtConfig.ActiveKeyId, jwtConfig.Issuer, jwtConfig.Audience)
+	if err != nil {
+		log.Fatalln("error when creating jwt helper: " + err.Error())
+	}
+	return jwtHelper
+}
+
+func NewJwtHelperWithKeys(keys []JwtKey, activeKeyId string, issuer string, audience string) (JwtHelper, error) {
+	helper := &JwtHelperImplementation{
+		keys:     make(map[string]JwtKey),
+		issuer:   issuer,
+		audience: audience,
+	}
+	for _, key := range keys {
+		if _, ok := helper.keys[key.Id]; ok {
+			return nil, errors.New("duplicate jwt key id: " + key.Id)
+		}
+		helper.keys[key.Id] = key
+		if helper.activeKey.Id == "" && key.SigningKey != nil && (activeKeyId == "" || activeKeyId == key.Id) {
+			helper.activeKey = key
+		}
+	}
+	if helper.activeKey.Id == "" {
+		return nil, errors.New("cannot find active jwt signing key: " + activeKeyId)
+	}
+	return helper, nil
+}
+
+func loadJwtKeys(keyPaths string, withPrivateKey bool) (keys []JwtKey, err error) {
+	for _, keyPath := range strings.Split(keyPaths, ",") {
+		keyPath = strings.TrimSpace(keyPath)
+		if keyPath == "" {
+			continue
+		}
+		kid, path, ok := strings.Cut(keyPath, "=")
+		if !ok || kid == "" || path == "" {
+			err = errors.New("jwt key must be written as kid=path: " + keyPath)
+			return
+		}
+		var pemBytes []byte
+		pemBytes, err = os.ReadFile(path)
+		if err != nil {
+			return
+		}
+		var key JwtKey
+		if withPrivateKey {
+			key, err = parseJwtPrivateKey(pemBytes)
+		} else {
+			key, err = parseJwtPublicKey(pemBytes)
+		}
+		if err != nil {
+			err = errors.New(kid + ": " + err.Error())
+			return
+		}
+		key.Id = kid
+		keys = append(keys, key)
+	}
+	return
+}
+
+func parseJwtPrivateKey(pemBytes []byte) (key JwtKey, err error) {
+	if rsaKey, errRsa := jwt.ParseRSAPrivateKeyFromPEM(pemBytes); errRsa == nil {
+		key.SigningMethod = jwt.SigningMethodRS256
+		key.SigningKey = rsaKey
+		key.VerifyingKey = &rsaKey.PublicKey
+		return
+	}
+	edKey, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes)
+	if err != nil {
+		err = errors.New("private key is neither RSA nor Ed25519")
+		return
+	}
+	key.SigningMethod = jwt.SigningMethodEdDSA
+	key.SigningKey = edKey
+	key.VerifyingKey = edKey.(ed25519.PrivateKey).Public()
+	return
+}
+
+func parseJwtPublicKey(pemBytes []byte) (key JwtKey, err error) {
+	if rsaKey, errRsa := jwt.ParseRSAPublicKeyFromPEM(pemBytes); errRsa == nil {
+		key.SigningMethod = jwt.SigningMethodRS256
+		key.VerifyingKey = rsaKey
+		return
+	}
+	edKey, err := jwt.ParseEdPublicKeyFromPEM(pemBytes)
+	if err != nil {
+		err = errors.New("public key is neither RSA nor Ed25519")
+		return
+	}
+	key.SigningMethod = jwt.SigningMethodEdDSA
+	key.VerifyingKey = edKey
+	return
 }
 
-func NewJwtHelper() JwtHelper {
-	return &JwtHelperImplementation{}
+func (helper *JwtHelperImplementation) newRegisteredClaims(id int, expiresIn time.Duration) (registeredClaims jwt.RegisteredClaims, err error) {
+	jti := make([]byte, 16)
+	_, err = rand.Read(jti)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	registeredClaims = jwt.RegisteredClaims{
+		Issuer:    helper.issuer,
+		Subject:   strconv.Itoa(id),
+		Audience:  jwt.ClaimStrings{helper.audience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ID:        hex.EncodeToString(jti),
+	}
+	return
+}
+
+func (helper *JwtHelperImplementation) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(helper.activeKey.SigningMethod, claims)
+	token.Header["kid"] = helper.activeKey.Id
+	return token.SignedString(helper.activeKey.SigningKey)
 }
 
 type AccessTokenCustomClaims struct {
-	Id    int    `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	Id        int    `json:"id"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	TokenType string `json:"typ"`
 	jwt.RegisteredClaims
 }
 
-func (helper *JwtHelperImplementation) GenerateAccessToken(id int, name string, email string, jwtAccessTokenTime int, secret string) (accessToken string, err error) {
+func (helper *JwtHelperImplementation) GenerateAccessToken(id int, name string, email string, jwtAccessTokenTime int) (accessToken string, err error) {
+	registeredClaims, err := helper.newRegisteredClaims(id, time.Duration(jwtAccessTokenTime)*time.Minute)
+	if err != nil {
+		return
+	}
 	claims := AccessTokenCustomClaims{
 		id,
 		name,
 		email,
-		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(jwtAccessTokenTime) * time.Minute)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
+		AccessTokenType,
+		registeredClaims,
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	accessToken, err = token.SignedString([]byte(secret))
+	accessToken, err = helper.sign(claims)
 	return
 }
 
 type refreshTokenCustomClaims struct {
-	Id int
+	Id        int
+	TokenType string `json:"typ"`
 	jwt.RegisteredClaims
 }
 
-func (helper *JwtHelperImplementation) GenerateRefreshToken(id int, jwtRefreshTokenTime int, secret string) (refreshToken string, err error) {
+func (helper *JwtHelperImplementation) GenerateRefreshToken(id int, jwtRefreshTokenTime int) (refreshToken string, err error) {
+	registeredClaims, err := helper.newRegisteredClaims(id, time.Duration(jwtRefreshTokenTime)*(time.Hour*24))
+	if err != nil {
+		return
+	}
 	claims := refreshTokenCustomClaims{
 		id,
-		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(jwtRefreshTokenTime) * (time.Hour * 24))),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
+		RefreshTokenType,
+		registeredClaims,
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	refreshToken, err = token.SignedString([]byte(secret))
+	refreshToken, err = helper.sign(claims)
 	return
 }
+
+// keyFunc returns the verifying key named by the kid header, but only for the algorithm that key was made for,
+// so a token cannot pick a weaker algorithm, or HS256 with a public key as the secret.
+func (helper *JwtHelperImplementation) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := helper.keys[kid]
+	if !ok {
+		return nil, errors.New("unknown jwt key id: " + kid)
+	}
+	if token.Method.Alg() != key.SigningMethod.Alg() {
+		return nil, errors.New("unexpected jwt signing method: " + token.Method.Alg())
+	}
+	return key.VerifyingKey, nil
+}
+
+func (helper *JwtHelperImplementation) parse(tokenString string, claims jwt.Claims) error {
+	_, err := jwt.ParseWithClaims(tokenString, claims, helper.keyFunc,
+		jwt.WithIssuer(helper.issuer),
+		jwt.WithAudience(helper.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	return err
+}
+
+func (helper *JwtHelperImplementation) ParseAccessToken(accessToken string) (claims *AccessTokenCustomClaims, err error) {
+	claims = &AccessTokenCustomClaims{}
+	err = helper.parse(accessToken, claims)
+	if err != nil {
+		claims = nil
+		return
+	}
+	if claims.TokenType != AccessTokenType {
+		claims = nil
+		err = errors.New("token is not an access token")
+		return
+	}
+	if claims.Subject != strconv.Itoa(claims.Id) {
+		claims = nil
+		err = errors.New("token subject does not match user id")
+		return
+	}
+	return
+}
+
+type EmailVerificationTokenCustomClaims struct {
+	Id        int    `json:"id"`
+	Email     string `json:"email"`
+	TokenType string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+func (helper *JwtHelperImplementation) GenerateEmailVerificationToken(id int, email string, emailVerificationTokenTime int) (emailVerificationToken string, err error) {
+	registeredClaims, err := helper.newRegisteredClaims(id, time.Duration(emailVerificationTokenTime)*time.Minute)
+	if err != nil {
+		return
+	}
+	claims := EmailVerificationTokenCustomClaims{
+		id,
+		email,
+		EmailVerificationTokenType,
+		registeredClaims,
+	}
+	emailVerificationToken, err = helper.sign(claims)
+	return
+}
+
+func (helper *JwtHelperImplementation) ParseEmailVerificationToken(emailVerificationToken string) (claims *EmailVerificationTokenCustomClaims, err error) {
+	claims = &EmailVerificationTokenCustomClaims{}
+	err = helper.parse(emailVerificationToken, claims)
+	if err != nil {
+		claims = nil
+		return
+	}
+	if claims.TokenType != EmailVerificationTokenType || claims.Subject != strconv.Itoa(claims.Id) {
+		claims = nil
+		err = errors.New("token is not an email verification token")
+		return
+	}
+	return
+}
+
+// MfaPendingTokenCustomClaims proves the password has been checked, it is only accepted by the second login step.
+type MfaPendingTokenCustomClaims struct {
+	Id        int    `json:"id"`
+	TokenType string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+func (helper *JwtHelperImplementation) GenerateMfaPendingToken(id int, mfaPendingTokenTime int) (mfaPendingToken string, err error) {
+	registeredClaims, err := helper.newRegisteredClaims(id, time.Duration(mfaPendingTokenTime)*time.Minute)
+	if err != nil {
+		return
+	}
+	claims := MfaPendingTokenCustomClaims{
+		id,
+		MfaPendingTokenType,
+		registeredClaims,
+	}
+	mfaPendingToken, err = helper.sign(claims)
+	return
+}
+
+func (helper *JwtHelperImplementation) ParseMfaPendingToken(mfaPendingToken string) (claims *MfaPendingTokenCustomClaims, err error) {
+	claims = &MfaPendingTokenCustomClaims{}
+	err = helper.parse(mfaPendingToken, claims)
+	if err != nil {
+		claims = nil
+		return
+	}
+	if claims.TokenType != MfaPendingTokenType || claims.Subject != strconv.Itoa(claims.Id) {
+		claims = nil
+		err = errors.New("token is not an mfa pending token")
+		return
+	}
+	return
+}
+
+// OidcStateTokenCustomClaims keeps what the oidc callback needs to check between the redirect to the provider and back,
+// it travels in a cookie so no login state has to be stored on the server.
+type OidcStateTokenCustomClaims struct {
+	Provider     string `json:"provider"`
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"codeVerifier"`
+	TokenType    string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+func (helper *JwtHelperImplementation) GenerateOidcStateToken(provider string, state string, nonce string, codeVerifier string, oidcStateTokenTime int) (oidcStateToken string, err error) {
+	registeredClaims, err := helper.newRegisteredClaims(0, time.Duration(oidcStateTokenTime)*time.Minute)
+	if err != nil {
+		return
+	}
+	claims := OidcStateTokenCustomClaims{
+		provider,
+		state,
+		nonce,
+		codeVerifier,
+		OidcStateTokenType,
+		registeredClaims,
+	}
+	oidcStateToken, err = helper.sign(claims)
+	return
+}
+
+func (helper *JwtHelperImplementation) ParseOidcStateToken(oidcStateToken string) (claims *OidcStateTokenCustomClaims, err error) {
+	claims = &OidcStateTokenCustomClaims{}
+	err = helper.parse(oidcStateToken, claims)
+	if err != nil {
+		claims = nil
+		return
+	}
+	if claims.TokenType != OidcStateTokenType {
+		claims = nil
+		err = errors.New("token is not an oidc state token")
+		return
+	}
+	return
+}
+
+// OauthAccessTokenCustomClaims is what a third party client gets, it only grants the scopes the user consented to
+// and stops working as soon as its grant is revoked.
+type OauthAccessTokenCustomClaims struct {
+	Id        int    `json:"id"`
+	ClientId  string `json:"client_id"`
+	Scope     string `json:"scope"`
+	GrantId   int    `json:"gid"`
+	TokenType string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+func (helper *JwtHelperImplementation) GenerateOauthAccessToken(id int, clientId string, scope string, grantId int, oauthAccessTokenTime int) (oauthAccessToken string, err error) {
+	registeredClaims, err := helper.newRegisteredClaims(id, time.Duration(oauthAccessTokenTime)*time.Minute)
+	if err != nil {
+		return
+	}
+	claims := OauthAccessTokenCustomClaims{
+		id,
+		clientId,
+		scope,
+		grantId,
+		OauthAccessTokenType,
+		registeredClaims,
+	}
+	oauthAccessToken, err = helper.sign(claims)
+	return
+}
+
+func (helper *JwtHelperImplementation) ParseOauthAccessToken(oauthAccessToken string) (claims *OauthAccessTokenCustomClaims, err error) {
+	claims = &OauthAccessTokenCustomClaims{}
+	err = helper.parse(oauthAccessToken, claims)
+	if err != nil {
+		claims = nil
+		return
+	}
+	if claims.TokenType != OauthAccessTokenType || claims.Subject != strconv.Itoa(claims.Id) {
+		claims = nil
+		err = errors.New("token is not an oauth access token")
+		return
+	}
+	return
+}
+
+func (helper *JwtHelperImplementation) GetJwks() modelresponses.JwksResponse {
+	jwksResponse := modelresponses.JwksResponse{
+		Keys: []modelresponses.JwkResponse{},
+	}
+	for _, key := range helper.keys {
+		var jwkResponse modelresponses.JwkResponse
+		switch verifyingKey := key.VerifyingKey.(type) {
+		case *rsa.PublicKey:
+			jwkResponse.Kty = "RSA"
+			jwkResponse.N = base64.RawURLEncoding.EncodeToString(verifyingKey.N.Bytes())
+			jwkResponse.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(verifyingKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwkResponse.Kty = "OKP"
+			jwkResponse.Crv = "Ed25519"
+			jwkResponse.X = base64.RawURLEncoding.EncodeToString(verifyingKey)
+		default:
+			continue
+		}
+		jwkResponse.Kid = key.Id
+		jwkResponse.Use = "sig"
+		jwkResponse.Alg = key.SigningMethod.Alg()
+		jwksResponse.Keys = append(jwksResponse.Keys, jwkResponse)
+	}
+	sort.Slice(jwksResponse.Keys, func(i, j int) bool {
+		return jwksResponse.Keys[i].Kid < jwksResponse.Keys[j].Kid
+	})
+	return jwksResponse
+}
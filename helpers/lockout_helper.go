@@ -0,0 +1,20 @@
+package helpers
+
+// LockoutDuration returns how many seconds a key stays locked after failedCount failures.
+// Nothing is locked below maxAttempts, after that the lock doubles with every further failure up to maxSeconds.
+func LockoutDuration(failedCount int, maxAttempts int, baseSeconds int, maxSeconds int) int {
+	if maxAttempts <= 0 || failedCount < maxAttempts {
+		return 0
+	}
+	seconds := baseSeconds
+	for i := maxAttempts; i < failedCount; i++ {
+		seconds *= 2
+		if seconds >= maxSeconds {
+			return maxSeconds
+		}
+	}
+	if seconds > maxSeconds {
+		return maxSeconds
+	}
+	return seconds
+}
@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"todo-list-api/config"
+)
+
+// redactedKeys are the parts of attribute names whose values never reach the log, whoever logs them.
+var redactedKeys = []string{"password", "token", "secret", "cookie", "authorization"}
+
+// NewLogger writes json lines to writer from the level in logConfig up.
+func NewLogger(logConfig config.LogConfig, writer io.Writer) *slog.Logger {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(logConfig.Level))
+	return slog.New(slog.NewJSONHandler(writer, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	}))
+}
+
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+	for _, redactedKey := range redactedKeys {
+		if strings.Contains(key, redactedKey) {
+			return slog.String(attr.Key, "[REDACTED]")
+		}
+	}
+	return attr
+}
+
+type loggerContextKey struct{}
+
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// LoggerFromContext returns the logger of the request, with its request id, or the default logger outside a request.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,104 @@
+package helpers
+
+import (
+	"log"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"todo-list-api/config"
+)
+
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+// NewMailer picks the mailer from MAILER, smtp sends through SMTP_HOST and file, the default,
+// appends every mail to MAIL_FILE_PATH or writes it to the log when the path is empty.
+func NewMailer(mailConfig config.MailConfig) Mailer {
+	switch mailConfig.Mailer {
+	case "smtp":
+		return NewSmtpMailer(mailConfig.SmtpHost, mailConfig.SmtpUsername, mailConfig.SmtpPassword, mailConfig.From)
+	case "", "file":
+		return NewFileMailer(mailConfig.FilePath, mailConfig.From)
+	default:
+		log.Fatalln("unknown mailer: " + mailConfig.Mailer)
+		return nil
+	}
+}
+
+func buildMail(from string, to string, subject string, body string) []byte {
+	var message strings.Builder
+	message.WriteString("From: " + from + "\r\n")
+	message.WriteString("To: " + to + "\r\n")
+	message.WriteString("Subject: " + subject + "\r\n")
+	message.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(body)
+	message.WriteString("\r\n")
+	return []byte(message.String())
+}
+
+type SmtpMailerImplementation struct {
+	address  string
+	username string
+	password string
+	from     string
+}
+
+func NewSmtpMailer(address string, username string, password string, from string) Mailer {
+	return &SmtpMailerImplementation{
+		address:  address,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (mailer *SmtpMailerImplementation) Send(to string, subject string, body string) error {
+	var auth smtp.Auth
+	if mailer.username != "" {
+		host, _, err := net.SplitHostPort(mailer.address)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", mailer.username, mailer.password, host)
+	}
+	return smtp.SendMail(mailer.address, auth, mailer.from, []string{to}, buildMail(mailer.from, to, subject, body))
+}
+
+type FileMailerImplementation struct {
+	mutex sync.Mutex
+	path  string
+	from  string
+}
+
+func NewFileMailer(path string, from string) Mailer {
+	return &FileMailerImplementation{
+		path: path,
+		from: from,
+	}
+}
+
+func (mailer *FileMailerImplementation) Send(to string, subject string, body string) error {
+	message := buildMail(mailer.from, to, subject, body)
+	if mailer.path == "" {
+		slog.Info("mail", slog.String("to", to), slog.String("subject", subject), slog.String("message", string(message)))
+		return nil
+	}
+
+	mailer.mutex.Lock()
+	defer mailer.mutex.Unlock()
+	file, err := os.OpenFile(mailer.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(message, []byte("\r\n")...))
+	return err
+}
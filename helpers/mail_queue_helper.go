@@ -0,0 +1,43 @@
+package helpers
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// MailQueue sends mails off the request path, the answer does not wait for the mailer, so its time cannot tell
+// whether a mail was sent. A failed mail is logged with the logger of ctx, the caller never sees it.
+type MailQueue interface {
+	Send(ctx context.Context, to string, subject string, body string)
+	Wait()
+}
+
+type MailQueueImplementation struct {
+	mailer  Mailer
+	pending sync.WaitGroup
+}
+
+func NewMailQueue(mailer Mailer) MailQueue {
+	return &MailQueueImplementation{
+		mailer: mailer,
+	}
+}
+
+func (queue *MailQueueImplementation) Send(ctx context.Context, to string, subject string, body string) {
+	logger := LoggerFromContext(ctx)
+	queue.pending.Add(1)
+	go func() {
+		defer queue.pending.Done()
+		err := queue.mailer.Send(to, subject, body)
+		if err != nil {
+			logger.Error("mail: cannot send", slog.String("subject", subject), slog.String("error", err.Error()))
+		}
+	}()
+}
+
+// Wait blocks until every queued mail was sent or failed, the server waits on shutdown and the subcommands before
+// they exit.
+func (queue *MailQueueImplementation) Wait() {
+	queue.pending.Wait()
+}
@@ -0,0 +1,177 @@
+package helpers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	LoginSucceeded = "succeeded"
+	LoginFailed    = "failed"
+)
+
+// MetricsHelper counts what the api does for GET /metrics. route is the route of echo, like /todos/:id, never the path,
+// so the number of series stays bounded.
+type MetricsHelper interface {
+	ObserveRequest(method string, route string, status int, duration time.Duration)
+	CountRateLimitRejection(limiter string, policy string)
+	CountLogin(result string, reason string)
+	CountTodoCreated()
+	CollectPostgresPool(stat func() *pgxpool.Stat)
+	CollectTodos(count func(ctx context.Context) (int, error))
+	Handler() http.Handler
+}
+
+type MetricsHelperImplementation struct {
+	registry            *prometheus.Registry
+	httpRequests        *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+	rateLimitRejections *prometheus.CounterVec
+	logins              *prometheus.CounterVec
+	todosCreated        prometheus.Counter
+}
+
+// NewMetricsHelper has its own registry instead of the global one, so every test gets fresh counters. The go and
+// process collectors are added like the global registry does.
+func NewMetricsHelper() MetricsHelper {
+	helper := &MetricsHelperImplementation{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of http requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Time to answer an http request by method, route and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		rateLimitRejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rate_limit_rejections_total",
+			Help: "Number of requests refused by the rate limiter or the concurrency limiter.",
+		}, []string{"limiter", "policy"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "logins_total",
+			Help: "Number of logins by result, the reason is the detail of the security event.",
+		}, []string{"result", "reason"}),
+		todosCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "todos_created_total",
+			Help: "Number of todos created.",
+		}),
+	}
+	helper.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		helper.httpRequests,
+		helper.httpRequestDuration,
+		helper.rateLimitRejections,
+		helper.logins,
+		helper.todosCreated,
+	)
+	return helper
+}
+
+func (helper *MetricsHelperImplementation) ObserveRequest(method string, route string, status int, duration time.Duration) {
+	statusLabel := strconv.Itoa(status)
+	helper.httpRequests.WithLabelValues(method, route, statusLabel).Inc()
+	helper.httpRequestDuration.WithLabelValues(method, route, statusLabel).Observe(duration.Seconds())
+}
+
+// CountRateLimitRejection counts a refused request, limiter is "rate" or "concurrency", policy is the rate limit
+// policy, like "POST /login" or "default", and empty for the concurrency limiter.
+func (helper *MetricsHelperImplementation) CountRateLimitRejection(limiter string, policy string) {
+	helper.rateLimitRejections.WithLabelValues(limiter, policy).Inc()
+}
+
+func (helper *MetricsHelperImplementation) CountLogin(result string, reason string) {
+	helper.logins.WithLabelValues(result, reason).Inc()
+}
+
+func (helper *MetricsHelperImplementation) CountTodoCreated() {
+	helper.todosCreated.Inc()
+}
+
+// CollectPostgresPool reads the statistics of the pool on every scrape.
+func (helper *MetricsHelperImplementation) CollectPostgresPool(stat func() *pgxpool.Stat) {
+	helper.registry.MustRegister(&postgresPoolCollector{stat: stat})
+}
+
+// CollectTodos counts the todos on every scrape. A todo has no done state, so there is nothing like a completed todo to
+// count next to it.
+func (helper *MetricsHelperImplementation) CollectTodos(count func(ctx context.Context) (int, error)) {
+	helper.registry.MustRegister(&todosCollector{count: count})
+}
+
+func (helper *MetricsHelperImplementation) Handler() http.Handler {
+	return promhttp.HandlerFor(helper.registry, promhttp.HandlerOpts{})
+}
+
+var (
+	postgresPoolAcquiredConnections = prometheus.NewDesc("pgxpool_acquired_connections", "Number of connections in use.", nil, nil)
+	postgresPoolIdleConnections     = prometheus.NewDesc("pgxpool_idle_connections", "Number of idle connections.", nil, nil)
+	postgresPoolTotalConnections    = prometheus.NewDesc("pgxpool_total_connections", "Number of connections, in use, idle or being opened.", nil, nil)
+	postgresPoolMaxConnections      = prometheus.NewDesc("pgxpool_max_connections", "Maximum number of connections.", nil, nil)
+	postgresPoolAcquires            = prometheus.NewDesc("pgxpool_acquires_total", "Number of connections acquired.", nil, nil)
+	postgresPoolEmptyAcquires       = prometheus.NewDesc("pgxpool_empty_acquires_total", "Number of acquires that had to wait for a connection.", nil, nil)
+	postgresPoolCanceledAcquires    = prometheus.NewDesc("pgxpool_canceled_acquires_total", "Number of acquires canceled by their context.", nil, nil)
+	postgresPoolAcquireDuration     = prometheus.NewDesc("pgxpool_acquire_duration_seconds_total", "Time spent acquiring connections.", nil, nil)
+	postgresPoolNewConnections      = prometheus.NewDesc("pgxpool_new_connections_total", "Number of connections opened.", nil, nil)
+)
+
+type postgresPoolCollector struct {
+	stat func() *pgxpool.Stat
+}
+
+func (collector *postgresPoolCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- postgresPoolAcquiredConnections
+	descs <- postgresPoolIdleConnections
+	descs <- postgresPoolTotalConnections
+	descs <- postgresPoolMaxConnections
+	descs <- postgresPoolAcquires
+	descs <- postgresPoolEmptyAcquires
+	descs <- postgresPoolCanceledAcquires
+	descs <- postgresPoolAcquireDuration
+	descs <- postgresPoolNewConnections
+}
+
+func (collector *postgresPoolCollector) Collect(metrics chan<- prometheus.Metric) {
+	stat := collector.stat()
+	metrics <- prometheus.MustNewConstMetric(postgresPoolAcquiredConnections, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	metrics <- prometheus.MustNewConstMetric(postgresPoolIdleConnections, prometheus.GaugeValue, float64(stat.IdleConns()))
+	metrics <- prometheus.MustNewConstMetric(postgresPoolTotalConnections, prometheus.GaugeValue, float64(stat.TotalConns()))
+	metrics <- prometheus.MustNewConstMetric(postgresPoolMaxConnections, prometheus.GaugeValue, float64(stat.MaxConns()))
+	metrics <- prometheus.MustNewConstMetric(postgresPoolAcquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	metrics <- prometheus.MustNewConstMetric(postgresPoolEmptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	metrics <- prometheus.MustNewConstMetric(postgresPoolCanceledAcquires, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	metrics <- prometheus.MustNewConstMetric(postgresPoolAcquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	metrics <- prometheus.MustNewConstMetric(postgresPoolNewConnections, prometheus.CounterValue, float64(stat.NewConnsCount()))
+}
+
+var todos = prometheus.NewDesc("todos", "Number of todos of every user.", nil, nil)
+
+type todosCollector struct {
+	count func(ctx context.Context) (int, error)
+}
+
+func (collector *todosCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- todos
+}
+
+// Collect leaves the gauge out when the count fails, so the other metrics are still scraped.
+func (collector *todosCollector) Collect(metrics chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	numberOfTodos, err := collector.count(ctx)
+	if err != nil {
+		LoggerFromContext(ctx).Error("metrics: cannot count todos", slog.String("error", err.Error()))
+		return
+	}
+	metrics <- prometheus.MustNewConstMetric(todos, prometheus.GaugeValue, float64(numberOfTodos))
+}
@@ -0,0 +1,62 @@
+package helpers
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"sort"
+	"strings"
+)
+
+const (
+	OauthScopeTodosRead   = "todos:read"
+	OauthScopeTodosWrite  = "todos:write"
+	OauthScopeProfileRead = "profile:read"
+)
+
+// OauthScopes are the scopes a client can ask for, with the text the consent screen shows the user.
+var OauthScopes = map[string]string{
+	OauthScopeTodosRead:   "Read your todos",
+	OauthScopeTodosWrite:  "Create, change and delete your todos",
+	OauthScopeProfileRead: "Read your name and email",
+}
+
+// ParseOauthScopes splits a space separated scope string, dropping duplicates and sorting what is left
+// so the same set of scopes is always written the same way.
+func ParseOauthScopes(scope string) (scopes []string) {
+	seen := map[string]bool{}
+	for _, field := range strings.Fields(scope) {
+		if !seen[field] {
+			seen[field] = true
+			scopes = append(scopes, field)
+		}
+	}
+	sort.Strings(scopes)
+	return
+}
+
+func ContainsOauthScopes(allowed []string, requested []string) bool {
+	for _, scope := range requested {
+		found := false
+		for _, allowedScope := range allowed {
+			if scope == allowedScope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifyPkce checks a code verifier against an S256 code challenge, the plain method is not accepted.
+func VerifyPkce(codeVerifier string, codeChallenge string) bool {
+	if len(codeVerifier) < 43 || len(codeVerifier) > 128 {
+		return false
+	}
+	codeVerifierHash := sha256.Sum256([]byte(codeVerifier))
+	expected := base64.RawURLEncoding.EncodeToString(codeVerifierHash[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(codeChallenge)) == 1
+}
@@ -0,0 +1,169 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"todo-list-api/config"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+var ErrUnknownOidcProvider = errors.New("unknown oidc provider")
+
+type OidcProvider struct {
+	Name         string
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	RedirectUrl  string
+}
+
+type OidcIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type OidcHelper interface {
+	AuthCodeUrl(ctx context.Context, provider string, state string, nonce string, codeVerifier string) (authCodeUrl string, err error)
+	Exchange(ctx context.Context, provider string, code string, codeVerifier string, nonce string) (identity OidcIdentity, err error)
+}
+
+type discoveredOidcProvider struct {
+	oauth2Config oauth2.Config
+	verifier     *oidc.IDTokenVerifier
+}
+
+type OidcHelperImplementation struct {
+	providers  map[string]OidcProvider
+	httpClient *http.Client
+	mutex      sync.Mutex
+	discovered map[string]discoveredOidcProvider
+}
+
+// NewOidcHelper creates a provider for every configured provider. The provider has to redirect back to
+// OIDC_REDIRECT_BASE_URL/oidc/<name>/callback.
+func NewOidcHelper(oidcConfig config.OidcConfig) OidcHelper {
+	var providers []OidcProvider
+	redirectBaseUrl := strings.TrimSuffix(oidcConfig.RedirectBaseUrl, "/")
+	for name, providerConfig := range oidcConfig.Providers {
+		providers = append(providers, OidcProvider{
+			Name:         name,
+			Issuer:       providerConfig.Issuer,
+			ClientId:     providerConfig.ClientId,
+			ClientSecret: providerConfig.ClientSecret,
+			RedirectUrl:  redirectBaseUrl + "/oidc/" + name + "/callback",
+		})
+	}
+	return NewOidcHelperWithProviders(providers, &http.Client{Timeout: 10 * time.Second, Transport: TracingTransport{}})
+}
+
+func NewOidcHelperWithProviders(providers []OidcProvider, httpClient *http.Client) OidcHelper {
+	helper := &OidcHelperImplementation{
+		providers:  map[string]OidcProvider{},
+		httpClient: httpClient,
+		discovered: map[string]discoveredOidcProvider{},
+	}
+	for _, provider := range providers {
+		helper.providers[provider.Name] = provider
+	}
+	return helper
+}
+
+// discover fetches the discovery document the first time a provider is used, so a provider that is down
+// does not stop the api from starting. A failed discovery is not cached and is tried again on the next login.
+func (helper *OidcHelperImplementation) discover(name string) (discovered discoveredOidcProvider, err error) {
+	provider, ok := helper.providers[name]
+	if !ok {
+		err = ErrUnknownOidcProvider
+		return
+	}
+
+	helper.mutex.Lock()
+	defer helper.mutex.Unlock()
+	discovered, ok = helper.discovered[name]
+	if ok {
+		return
+	}
+
+	// the provider keeps this context to refresh its signing keys later, so it must not be a request context
+	ctx := oidc.ClientContext(context.Background(), helper.httpClient)
+	oidcProvider, err := oidc.NewProvider(ctx, provider.Issuer)
+	if err != nil {
+		return
+	}
+	discovered = discoveredOidcProvider{
+		oauth2Config: oauth2.Config{
+			ClientID:     provider.ClientId,
+			ClientSecret: provider.ClientSecret,
+			Endpoint:     oidcProvider.Endpoint(),
+			RedirectURL:  provider.RedirectUrl,
+			Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+		},
+		verifier: oidcProvider.Verifier(&oidc.Config{ClientID: provider.ClientId}),
+	}
+	helper.discovered[name] = discovered
+	return
+}
+
+func (helper *OidcHelperImplementation) AuthCodeUrl(ctx context.Context, provider string, state string, nonce string, codeVerifier string) (authCodeUrl string, err error) {
+	discovered, err := helper.discover(provider)
+	if err != nil {
+		return
+	}
+	authCodeUrl = discovered.oauth2Config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(codeVerifier))
+	return
+}
+
+func (helper *OidcHelperImplementation) Exchange(ctx context.Context, provider string, code string, codeVerifier string, nonce string) (identity OidcIdentity, err error) {
+	discovered, err := helper.discover(provider)
+	if err != nil {
+		return
+	}
+
+	ctx = oidc.ClientContext(ctx, helper.httpClient)
+	token, err := discovered.oauth2Config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return
+	}
+	rawIdToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		err = errors.New("token response has no id token")
+		return
+	}
+	idToken, err := discovered.verifier.Verify(ctx, rawIdToken)
+	if err != nil {
+		return
+	}
+	if idToken.Nonce != nonce {
+		err = errors.New("id token nonce does not match")
+		return
+	}
+
+	var claims struct {
+		Email         string      `json:"email"`
+		EmailVerified interface{} `json:"email_verified"`
+		Name          string      `json:"name"`
+	}
+	err = idToken.Claims(&claims)
+	if err != nil {
+		return
+	}
+	identity.Subject = idToken.Subject
+	identity.Email = strings.ToLower(claims.Email)
+	identity.Name = claims.Name
+	// some providers send email_verified as a string
+	switch emailVerified := claims.EmailVerified.(type) {
+	case bool:
+		identity.EmailVerified = emailVerified
+	case string:
+		identity.EmailVerified = emailVerified == "true"
+	}
+	return
+}
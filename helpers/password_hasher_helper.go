@@ -0,0 +1,205 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"todo-list-api/config"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	Argon2idAlgorithm = "argon2id"
+	BcryptAlgorithm   = "bcrypt"
+)
+
+var ErrPasswordMismatch = errors.New("password does not match")
+
+type PasswordHasher interface {
+	Hash(password string) (passwordHash string, err error)
+	// Verify returns ErrPasswordMismatch for a wrong password. needsRehash is only meaningful when err is nil and tells
+	// the caller to store a new hash because the stored one uses another algorithm or weaker parameters.
+	Verify(passwordHash string, password string) (needsRehash bool, err error)
+	// DummyHash is a hash made with the current parameters to verify against when there is no user, so a request for
+	// an unknown account takes as long as one for a known account.
+	DummyHash() string
+}
+
+type PasswordHasherOptions struct {
+	Algorithm         string
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	Argon2SaltLength  uint32
+	Argon2KeyLength   uint32
+	BcryptCost        int
+}
+
+type PasswordHasherImplementation struct {
+	options   PasswordHasherOptions
+	dummyHash string
+}
+
+type argon2idParameters struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+// NewPasswordHasher takes the algorithm, argon2id or bcrypt, and its parameters from the config, ARGON2_MEMORY is in KiB.
+func NewPasswordHasher(passwordHashConfig config.PasswordHashConfig) PasswordHasher {
+	options := DefaultPasswordHasherOptions()
+	options.Algorithm = passwordHashConfig.Algorithm
+	options.Argon2Memory = uint32(passwordHashConfig.Argon2Memory)
+	options.Argon2Iterations = uint32(passwordHashConfig.Argon2Iterations)
+	options.Argon2Parallelism = uint8(passwordHashConfig.Argon2Parallelism)
+	options.BcryptCost = passwordHashConfig.BcryptCost
+	passwordHasher, err := NewPasswordHasherWithOptions(options)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	return passwordHasher
+}
+
+// DefaultPasswordHasherOptions follows the OWASP recommendation for argon2id.
+func DefaultPasswordHasherOptions() PasswordHasherOptions {
+	return PasswordHasherOptions{
+		Algorithm:         Argon2idAlgorithm,
+		Argon2Memory:      19456,
+		Argon2Iterations:  2,
+		Argon2Parallelism: 1,
+		Argon2SaltLength:  16,
+		Argon2KeyLength:   32,
+		BcryptCost:        bcrypt.DefaultCost,
+	}
+}
+
+func NewPasswordHasherWithOptions(options PasswordHasherOptions) (PasswordHasher, error) {
+	switch options.Algorithm {
+	case Argon2idAlgorithm:
+		if options.Argon2Memory == 0 || options.Argon2Iterations == 0 || options.Argon2Parallelism == 0 || options.Argon2SaltLength == 0 || options.Argon2KeyLength == 0 {
+			return nil, errors.New("argon2id parameters must be greater than zero")
+		}
+	case BcryptAlgorithm:
+		if options.BcryptCost < bcrypt.MinCost || options.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return nil, errors.New("unknown password hash algorithm " + options.Algorithm)
+	}
+	passwordHasher := &PasswordHasherImplementation{
+		options: options,
+	}
+	dummyPassword := make([]byte, 16)
+	_, err := rand.Read(dummyPassword)
+	if err != nil {
+		return nil, err
+	}
+	passwordHasher.dummyHash, err = passwordHasher.Hash(base64.RawStdEncoding.EncodeToString(dummyPassword))
+	if err != nil {
+		return nil, err
+	}
+	return passwordHasher, nil
+}
+
+func (helper *PasswordHasherImplementation) Hash(password string) (passwordHash string, err error) {
+	if helper.options.Algorithm == BcryptAlgorithm {
+		var passwordByte []byte
+		passwordByte, err = bcrypt.GenerateFromPassword([]byte(password), helper.options.BcryptCost)
+		passwordHash = string(passwordByte)
+		return
+	}
+	salt := make([]byte, helper.options.Argon2SaltLength)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return
+	}
+	key := argon2.IDKey([]byte(password), salt, helper.options.Argon2Iterations, helper.options.Argon2Memory, helper.options.Argon2Parallelism, helper.options.Argon2KeyLength)
+	passwordHash = fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, helper.options.Argon2Memory, helper.options.Argon2Iterations, helper.options.Argon2Parallelism, base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+	return
+}
+
+func (helper *PasswordHasherImplementation) Verify(passwordHash string, password string) (needsRehash bool, err error) {
+	switch {
+	case strings.HasPrefix(passwordHash, "$argon2id$"):
+		var parameters argon2idParameters
+		parameters, err = parseArgon2idHash(passwordHash)
+		if err != nil {
+			return
+		}
+		key := argon2.IDKey([]byte(password), parameters.salt, parameters.iterations, parameters.memory, parameters.parallelism, uint32(len(parameters.key)))
+		if subtle.ConstantTimeCompare(key, parameters.key) != 1 {
+			err = ErrPasswordMismatch
+			return
+		}
+		needsRehash = helper.options.Algorithm != Argon2idAlgorithm ||
+			parameters.memory < helper.options.Argon2Memory ||
+			parameters.iterations < helper.options.Argon2Iterations ||
+			parameters.parallelism < helper.options.Argon2Parallelism ||
+			uint32(len(parameters.salt)) < helper.options.Argon2SaltLength ||
+			uint32(len(parameters.key)) < helper.options.Argon2KeyLength
+	case strings.HasPrefix(passwordHash, "$2a$"), strings.HasPrefix(passwordHash, "$2b$"), strings.HasPrefix(passwordHash, "$2y$"):
+		err = bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password))
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			err = ErrPasswordMismatch
+			return
+		} else if err != nil {
+			return
+		}
+		var cost int
+		cost, err = bcrypt.Cost([]byte(passwordHash))
+		if err != nil {
+			return
+		}
+		needsRehash = helper.options.Algorithm != BcryptAlgorithm || cost < helper.options.BcryptCost
+	default:
+		err = errors.New("unknown password hash format")
+	}
+	return
+}
+
+func (helper *PasswordHasherImplementation) DummyHash() string {
+	return helper.dummyHash
+}
+
+// parseArgon2idHash reads the PHC string format $argon2id$v=19$m=19456,t=2,p=1$salt$key.
+func parseArgon2idHash(passwordHash string) (parameters argon2idParameters, err error) {
+	parts := strings.Split(passwordHash, "$")
+	if len(parts) != 6 {
+		err = errors.New("invalid argon2id hash")
+		return
+	}
+	var version int
+	_, err = fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil {
+		return
+	}
+	if version != argon2.Version {
+		err = errors.New("unsupported argon2 version")
+		return
+	}
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &parameters.memory, &parameters.iterations, &parameters.parallelism)
+	if err != nil {
+		return
+	}
+	parameters.salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return
+	}
+	parameters.key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return
+	}
+	if len(parameters.key) == 0 {
+		err = errors.New("invalid argon2id hash")
+	}
+	return
+}
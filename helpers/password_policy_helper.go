@@ -0,0 +1,175 @@
+package helpers
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"todo-list-api/config"
+	modelresponses "todo-list-api/models/responses"
+	"unicode"
+)
+
+// bcrypt ignores everything after the first 72 bytes, a longer password would only look stronger than it is
+const bcryptMaxPasswordLength = 72
+
+type PasswordPolicyHelper interface {
+	Check(password string, name string, email string) (violations []modelresponses.PasswordPolicyViolation, err error)
+}
+
+type PasswordPolicy struct {
+	MinLength             int
+	MaxLength             int
+	RequireLowercase      bool
+	RequireUppercase      bool
+	RequireDigit          bool
+	RequireSymbol         bool
+	BreachedPasswordsPath string
+}
+
+type PasswordPolicyHelperImplementation struct {
+	policy PasswordPolicy
+}
+
+// NewPasswordPolicyHelper takes PASSWORD_MAX_LENGTH up to 72, PASSWORD_REQUIRED_CLASSES, a comma separated list of
+// lowercase, uppercase, digit and symbol, and BREACHED_PASSWORDS_PATH, the directory of a downloaded breached password
+// range list, from the config. The breach check is off without it.
+func NewPasswordPolicyHelper(passwordPolicyConfig config.PasswordPolicyConfig) PasswordPolicyHelper {
+	policy := PasswordPolicy{
+		MinLength:             passwordPolicyConfig.MinLength,
+		MaxLength:             passwordPolicyConfig.MaxLength,
+		BreachedPasswordsPath: passwordPolicyConfig.BreachedPasswordsPath,
+	}
+	for _, requiredClass := range strings.Split(passwordPolicyConfig.RequiredClasses, ",") {
+		switch strings.TrimSpace(requiredClass) {
+		case "lowercase":
+			policy.RequireLowercase = true
+		case "uppercase":
+			policy.RequireUppercase = true
+		case "digit":
+			policy.RequireDigit = true
+		case "symbol":
+			policy.RequireSymbol = true
+		case "none":
+		default:
+			log.Fatalln("PASSWORD_REQUIRED_CLASSES: unknown class", requiredClass)
+		}
+	}
+	passwordPolicyHelper, err := NewPasswordPolicyHelperWithPolicy(policy)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	return passwordPolicyHelper
+}
+
+func NewPasswordPolicyHelperWithPolicy(policy PasswordPolicy) (PasswordPolicyHelper, error) {
+	if policy.MaxLength <= 0 || policy.MaxLength > bcryptMaxPasswordLength {
+		policy.MaxLength = bcryptMaxPasswordLength
+	}
+	if policy.MinLength > policy.MaxLength {
+		return nil, errors.New("password min length is longer than the max length")
+	}
+	if policy.BreachedPasswordsPath != "" {
+		fileInfo, err := os.Stat(policy.BreachedPasswordsPath)
+		if err != nil {
+			return nil, err
+		}
+		if !fileInfo.IsDir() {
+			return nil, errors.New("breached passwords path is not a directory")
+		}
+	}
+	return &PasswordPolicyHelperImplementation{
+		policy: policy,
+	}, nil
+}
+
+func (helper *PasswordPolicyHelperImplementation) Check(password string, name string, email string) (violations []modelresponses.PasswordPolicyViolation, err error) {
+	if len([]rune(password)) < helper.policy.MinLength {
+		violations = append(violations, modelresponses.PasswordPolicyViolation{Code: "too_short", Message: "password must be at least " + strconv.Itoa(helper.policy.MinLength) + " characters"})
+	}
+	if len(password) > helper.policy.MaxLength {
+		violations = append(violations, modelresponses.PasswordPolicyViolation{Code: "too_long", Message: "password must be at most " + strconv.Itoa(helper.policy.MaxLength) + " bytes"})
+	}
+
+	var hasLowercase, hasUppercase, hasDigit, hasSymbol bool
+	for _, character := range password {
+		switch {
+		case unicode.IsLower(character):
+			hasLowercase = true
+		case unicode.IsUpper(character):
+			hasUppercase = true
+		case unicode.IsDigit(character):
+			hasDigit = true
+		case !unicode.IsSpace(character):
+			hasSymbol = true
+		}
+	}
+	if helper.policy.RequireLowercase && !hasLowercase {
+		violations = append(violations, modelresponses.PasswordPolicyViolation{Code: "missing_lowercase", Message: "password must contain a lowercase letter"})
+	}
+	if helper.policy.RequireUppercase && !hasUppercase {
+		violations = append(violations, modelresponses.PasswordPolicyViolation{Code: "missing_uppercase", Message: "password must contain an uppercase letter"})
+	}
+	if helper.policy.RequireDigit && !hasDigit {
+		violations = append(violations, modelresponses.PasswordPolicyViolation{Code: "missing_digit", Message: "password must contain a digit"})
+	}
+	if helper.policy.RequireSymbol && !hasSymbol {
+		violations = append(violations, modelresponses.PasswordPolicyViolation{Code: "missing_symbol", Message: "password must contain a symbol"})
+	}
+
+	lowerPassword := strings.ToLower(password)
+	emailLocalPart, _, _ := strings.Cut(strings.ToLower(email), "@")
+	if len(emailLocalPart) >= 3 && strings.Contains(lowerPassword, emailLocalPart) {
+		violations = append(violations, modelresponses.PasswordPolicyViolation{Code: "contains_email", Message: "password must not contain the email"})
+	}
+	for _, namePart := range strings.Fields(strings.ToLower(name)) {
+		if len([]rune(namePart)) >= 3 && strings.Contains(lowerPassword, namePart) {
+			violations = append(violations, modelresponses.PasswordPolicyViolation{Code: "contains_name", Message: "password must not contain the name"})
+			break
+		}
+	}
+
+	breached, err := helper.isBreached(password)
+	if err != nil {
+		violations = nil
+		return
+	}
+	if breached {
+		violations = append(violations, modelresponses.PasswordPolicyViolation{Code: "breached", Message: "password has appeared in a data breach, choose another one"})
+	}
+	return
+}
+
+// isBreached looks the password up in a range list as served by the pwned passwords api: one file per first five hex
+// characters of the SHA-1 hash, named like 21BD1.txt, holding SUFFIX:COUNT lines. Only the matching range file is read.
+func (helper *PasswordPolicyHelperImplementation) isBreached(password string) (breached bool, err error) {
+	if helper.policy.BreachedPasswordsPath == "" {
+		return
+	}
+	passwordHash := sha1.Sum([]byte(password))
+	hexHash := strings.ToUpper(hex.EncodeToString(passwordHash[:]))
+	prefix, suffix := hexHash[:5], hexHash[5:]
+	file, err := os.Open(filepath.Join(helper.policy.BreachedPasswordsPath, prefix+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		err = nil
+		return
+	} else if err != nil {
+		return
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineSuffix, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(lineSuffix, suffix) {
+			breached = true
+			return
+		}
+	}
+	err = scanner.Err()
+	return
+}
@@ -0,0 +1,27 @@
+package helpers
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+const (
+	PermissionManageUsers        = "users:manage"
+	PermissionViewStatistics     = "statistics:view"
+	PermissionViewSecurityEvents = "security_events:view"
+)
+
+// rolePermissions lists what each role may do on top of managing its own account and todos, which every role can.
+var rolePermissions = map[string][]string{
+	RoleUser:  {},
+	RoleAdmin: {PermissionManageUsers, PermissionViewStatistics, PermissionViewSecurityEvents},
+}
+
+func HasPermission(role string, permission string) bool {
+	for _, rolePermission := range rolePermissions[role] {
+		if rolePermission == permission {
+			return true
+		}
+	}
+	return false
+}
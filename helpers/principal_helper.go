@@ -0,0 +1,25 @@
+package helpers
+
+import "context"
+
+// Principal is who the request acts for. ClientId and Scopes are only set when a third party client
+// calls with an oauth access token, a request with the session cookie is not limited by scopes.
+type Principal struct {
+	Id       int
+	Name     string
+	Email    string
+	Role     string
+	ClientId string
+	Scopes   []string
+}
+
+type principalContextKey struct{}
+
+func ContextWithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (principal Principal, ok bool) {
+	principal, ok = ctx.Value(principalContextKey{}).(Principal)
+	return
+}
@@ -0,0 +1,111 @@
+package helpers
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type RateLimitPolicy struct {
+	Limit  int
+	Window time.Duration
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
+
+// RateLimitStore keeps a token bucket per key. A bucket holds policy.Limit tokens and refills policy.Limit of them
+// every policy.Window, so a burst up to the limit passes and the rate over time stays at the limit.
+// The memory store only counts the requests of this instance, the postgres store is shared by every instance.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, policy RateLimitPolicy, now time.Time) (result RateLimitResult, err error)
+}
+
+type rateLimitBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	window    time.Duration
+}
+
+type MemoryRateLimitStoreImplementation struct {
+	mutex   sync.Mutex
+	buckets map[string]*rateLimitBucket
+	sweptAt time.Time
+}
+
+func NewMemoryRateLimitStore() RateLimitStore {
+	return &MemoryRateLimitStoreImplementation{
+		buckets: make(map[string]*rateLimitBucket),
+	}
+}
+
+func (helper *MemoryRateLimitStoreImplementation) Take(ctx context.Context, key string, policy RateLimitPolicy, now time.Time) (result RateLimitResult, err error) {
+	helper.mutex.Lock()
+	defer helper.mutex.Unlock()
+
+	helper.sweep(now)
+	bucket, ok := helper.buckets[key]
+	if !ok {
+		bucket = &rateLimitBucket{tokens: float64(policy.Limit), updatedAt: now}
+		helper.buckets[key] = bucket
+	}
+	bucket.tokens, result = TakeRateLimitToken(bucket.tokens, bucket.updatedAt, policy, now)
+	bucket.updatedAt = now
+	bucket.window = policy.Window
+	return
+}
+
+// sweep drops, at most once a minute, the buckets that had time to fill up again, they behave like a missing bucket.
+func (helper *MemoryRateLimitStoreImplementation) sweep(now time.Time) {
+	if now.Sub(helper.sweptAt) < time.Minute {
+		return
+	}
+	helper.sweptAt = now
+	for key, bucket := range helper.buckets {
+		if now.Sub(bucket.updatedAt) >= bucket.window {
+			delete(helper.buckets, key)
+		}
+	}
+}
+
+// TakeRateLimitToken refills a bucket that held tokens at updatedAt up to now and takes one token from it when it can.
+func TakeRateLimitToken(tokens float64, updatedAt time.Time, policy RateLimitPolicy, now time.Time) (remainingTokens float64, result RateLimitResult) {
+	limit := float64(policy.Limit)
+	tokensPerSecond := limit / policy.Window.Seconds()
+	elapsed := now.Sub(updatedAt).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	remainingTokens = math.Min(limit, tokens+elapsed*tokensPerSecond)
+	allowed := remainingTokens >= 1
+	if allowed {
+		remainingTokens--
+	}
+	result = NewRateLimitResult(remainingTokens, allowed, policy)
+	return
+}
+
+// NewRateLimitResult describes a bucket left with remainingTokens after a request that was allowed or not.
+func NewRateLimitResult(remainingTokens float64, allowed bool, policy RateLimitPolicy) (result RateLimitResult) {
+	limit := float64(policy.Limit)
+	tokensPerSecond := limit / policy.Window.Seconds()
+	result.Allowed = allowed
+	result.Limit = policy.Limit
+	result.Remaining = int(remainingTokens)
+	result.ResetAfter = time.Duration((limit - remainingTokens) / tokensPerSecond * float64(time.Second))
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - remainingTokens) / tokensPerSecond * float64(time.Second))
+	}
+	return
+}
+
+// NewRateLimitPolicy allows requests per window seconds, as RATE_LIMIT_REQUESTS and RATE_LIMIT_WINDOW are configured.
+func NewRateLimitPolicy(requests int, windowSeconds int) RateLimitPolicy {
+	return RateLimitPolicy{Limit: requests, Window: time.Duration(windowSeconds) * time.Second}
+}
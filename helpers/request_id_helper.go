@@ -0,0 +1,38 @@
+package helpers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+)
+
+// validRequestId is what is accepted from X-Request-ID, anything else is replaced so it cannot break the logs or
+// the application_name in postgres, which is at most 63 bytes.
+var validRequestId = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,48}$`)
+
+func NewRequestId() string {
+	requestIdBytes := make([]byte, 16)
+	_, _ = rand.Read(requestIdBytes)
+	return hex.EncodeToString(requestIdBytes)
+}
+
+// RequestIdOrNew keeps the request id of the caller when it is valid, so a request can be followed through a proxy
+// or another service.
+func RequestIdOrNew(requestId string) string {
+	if validRequestId.MatchString(requestId) {
+		return requestId
+	}
+	return NewRequestId()
+}
+
+type requestIdContextKey struct{}
+
+func ContextWithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdContextKey{}, requestId)
+}
+
+func RequestIdFromContext(ctx context.Context) (requestId string, ok bool) {
+	requestId, ok = ctx.Value(requestIdContextKey{}).(string)
+	return
+}
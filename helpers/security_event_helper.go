@@ -0,0 +1,36 @@
+package helpers
+
+const (
+	SecurityEventRegistered        = "registered"
+	SecurityEventLoginSucceeded    = "login_succeeded"
+	SecurityEventLoginFailed       = "login_failed"
+	SecurityEventAccountLocked     = "account_locked"
+	SecurityEventTokenRefreshed    = "token_refreshed"
+	SecurityEventLoggedOut         = "logged_out"
+	SecurityEventPasswordChanged   = "password_changed"
+	SecurityEventPasswordReset     = "password_reset"
+	SecurityEventTwoFactorEnabled  = "two_factor_enabled"
+	SecurityEventTwoFactorDisabled = "two_factor_disabled"
+	SecurityEventAccountDisabled   = "account_disabled"
+	SecurityEventAccountEnabled    = "account_enabled"
+	SecurityEventSessionsRevoked   = "sessions_revoked"
+	SecurityEventDeletionScheduled = "deletion_scheduled"
+)
+
+// SecurityEventTypes holds every event type, the admin listing rejects a filter outside of it.
+var SecurityEventTypes = map[string]struct{}{
+	SecurityEventRegistered:        {},
+	SecurityEventLoginSucceeded:    {},
+	SecurityEventLoginFailed:       {},
+	SecurityEventAccountLocked:     {},
+	SecurityEventTokenRefreshed:    {},
+	SecurityEventLoggedOut:         {},
+	SecurityEventPasswordChanged:   {},
+	SecurityEventPasswordReset:     {},
+	SecurityEventTwoFactorEnabled:  {},
+	SecurityEventTwoFactorDisabled: {},
+	SecurityEventAccountDisabled:   {},
+	SecurityEventAccountEnabled:    {},
+	SecurityEventSessionsRevoked:   {},
+	SecurityEventDeletionScheduled: {},
+}
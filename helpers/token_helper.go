@@ -0,0 +1,25 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// GenerateRandomToken returns a url safe token to hand to the user and the hash to store in place of it.
+func GenerateRandomToken() (token string, tokenHash string, err error) {
+	tokenBytes := make([]byte, 32)
+	_, err = rand.Read(tokenBytes)
+	if err != nil {
+		return
+	}
+	token = base64.RawURLEncoding.EncodeToString(tokenBytes)
+	tokenHash = HashToken(token)
+	return
+}
+
+func HashToken(token string) string {
+	tokenHash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(tokenHash[:])
+}
@@ -0,0 +1,107 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"todo-list-api/config"
+)
+
+const (
+	totpPeriod        = 30
+	totpDigits        = 6
+	totpSkew          = 1
+	recoveryCodeCount = 10
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type TotpHelper interface {
+	GenerateSecret() (secret string, err error)
+	GenerateUri(secret string, accountName string) string
+	Validate(secret string, code string, now time.Time) (step int64, ok bool)
+	GenerateRecoveryCodes() (recoveryCodes []string, err error)
+}
+
+type TotpHelperImplementation struct {
+	issuer string
+}
+
+// NewTotpHelper implements RFC 6238 with SHA1, 6 digits and 30 second steps, the defaults every authenticator app supports.
+// TOTP_ISSUER is the name shown in the authenticator app.
+func NewTotpHelper(totpConfig config.TotpConfig) TotpHelper {
+	return &TotpHelperImplementation{
+		issuer: totpConfig.Issuer,
+	}
+}
+
+func (helper *TotpHelperImplementation) GenerateSecret() (secret string, err error) {
+	secretBytes := make([]byte, 20)
+	_, err = rand.Read(secretBytes)
+	if err != nil {
+		return
+	}
+	secret = totpEncoding.EncodeToString(secretBytes)
+	return
+}
+
+func (helper *TotpHelperImplementation) GenerateUri(secret string, accountName string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", helper.issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(totpPeriod))
+	label := url.PathEscape(helper.issuer) + ":" + url.PathEscape(accountName)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// Validate accepts the code of the current step and of one step before or after it to allow for clock drift.
+// The matching step is returned so the caller can refuse a code that has already been used.
+func (helper *TotpHelperImplementation) Validate(secret string, code string, now time.Time) (step int64, ok bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimSpace(secret)))
+	if err != nil || len(code) != totpDigits {
+		return
+	}
+	currentStep := now.Unix() / totpPeriod
+	for skew := int64(-totpSkew); skew <= totpSkew; skew++ {
+		if subtle.ConstantTimeCompare([]byte(GenerateTotpCode(key, currentStep+skew)), []byte(code)) == 1 {
+			step = currentStep + skew
+			ok = true
+			return
+		}
+	}
+	return
+}
+
+func GenerateTotpCode(key []byte, step int64) string {
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+func (helper *TotpHelperImplementation) GenerateRecoveryCodes() (recoveryCodes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		codeBytes := make([]byte, 10)
+		_, err = rand.Read(codeBytes)
+		if err != nil {
+			recoveryCodes = nil
+			return
+		}
+		code := strings.ToLower(totpEncoding.EncodeToString(codeBytes))
+		recoveryCodes = append(recoveryCodes, code[:8]+"-"+code[8:16])
+	}
+	return
+}
@@ -0,0 +1,74 @@
+package helpers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartSpan starts a span as a child of the span in ctx, the services name theirs after the method, like
+// TodoService.Create. The tracer comes from the global provider, a no-op until utils.NewTracing sets one up. A span
+// that is not recorded, because tracing is off or the trace is not sampled, is left out of the context, its children
+// would not be recorded either.
+func StartSpan(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	spanCtx, span := otel.Tracer("todo-list-api").Start(ctx, name, options...)
+	if !span.IsRecording() {
+		return ctx, span
+	}
+	return spanCtx, span
+}
+
+// EndSpan ends the span of a service method with the http code it answered, a 5xx marks the span as failed with the
+// message of the response.
+func EndSpan(span trace.Span, httpCode int, response interface{}) {
+	span.SetAttributes(attribute.Int("http_code", httpCode))
+	if httpCode >= http.StatusInternalServerError {
+		message := http.StatusText(httpCode)
+		if messageResponse, ok := response.(Response); ok {
+			message = messageResponse.Message
+		}
+		span.SetStatus(codes.Error, message)
+	}
+	span.End()
+}
+
+// TracingTransport traces the requests the api makes itself, to the oidc providers, and passes the trace on in the
+// traceparent header. Only the scheme, host and path of the url are recorded, the query can carry codes.
+type TracingTransport struct {
+	Base http.RoundTripper
+}
+
+func (transport TracingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	base := transport.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	recordedUrl := url.URL{Scheme: request.URL.Scheme, Host: request.URL.Host, Path: request.URL.Path}
+	ctx, span := StartSpan(request.Context(), request.Method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		semconv.HTTPRequestMethodKey.String(request.Method),
+		semconv.ServerAddress(request.URL.Hostname()),
+		semconv.URLFull(recordedUrl.String()),
+	))
+	defer span.End()
+
+	request = request.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+	response, err := base.RoundTrip(request)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(response.StatusCode))
+	if response.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, response.Status)
+	}
+	return response, nil
+}
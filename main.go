@@ -2,54 +2,220 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
+	"strings"
 	"time"
+	"todo-list-api/config"
 	"todo-list-api/controllers"
+	"todo-list-api/databases/migrations"
 	"todo-list-api/helpers"
 	"todo-list-api/middlewares"
 	"todo-list-api/repositories"
 	"todo-list-api/routes"
-	"todo-list-api/services"
 	"todo-list-api/utils"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
 
+const usage = `usage: todo-api <command> [arguments] [flags]
+  serve                    run the api, the default when no command is given
+  migrate                  apply, revert or create migrations, see todo-api migrate
+  user create              create an account
+  user disable             disable an account and end its sessions
+  user reset-password      send a password reset link
+  token revoke             end every session of an account
+  seed                     create a demo account with a few todos
+  purge-trash              delete the accounts whose grace period is over
+every command takes the flags of the configuration, e.g. -config config.yaml, see todo-api serve -help`
+
 func main() {
-	postgresUtil := utils.NewPostgresConnection()
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+	if (command == "user" || command == "token") && len(args) > 0 {
+		command, args = command+" "+args[0], args[1:]
+	}
 
-	numberOfLimitEnv := os.Getenv("NUMBER_OF_LIMIT")
-	numberOfLimit, err := strconv.Atoi(numberOfLimitEnv)
+	switch command {
+	case "serve":
+		os.Exit(serve(args))
+	case "migrate":
+		os.Exit(migrate(args))
+	case "user create":
+		os.Exit(createUser(args))
+	case "user disable":
+		os.Exit(disableUser(args))
+	case "user reset-password":
+		os.Exit(resetPassword(args))
+	case "token revoke":
+		os.Exit(revokeTokens(args))
+	case "seed":
+		os.Exit(seed(args))
+	case "purge-trash":
+		os.Exit(purgeTrash(args))
+	case "help":
+		fmt.Println(usage)
+	default:
+		fmt.Fprintln(os.Stderr, "unknown command "+command)
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// loadConfig loads the config for a command, ok is false when the command has to stop with exitCode.
+func loadConfig(args []string, commandFlags ...func(flagSet *flag.FlagSet)) (cfg config.Config, exitCode int, ok bool) {
+	cfg, err := config.Load(args, commandFlags...)
+	if errors.Is(err, flag.ErrHelp) {
+		return cfg, 0, false
+	}
 	if err != nil {
-		panic(err.Error())
+		fmt.Fprintln(os.Stderr, "invalid configuration:")
+		fmt.Fprintln(os.Stderr, err)
+		return cfg, 2, false
 	}
-	middlewares.NumberOfLimit = numberOfLimit
+	// the commands print their result on stdout, so their logs go to stderr
+	slog.SetDefault(helpers.NewLogger(cfg.Log, os.Stderr))
+	// what is left of the log package is fatal
+	slog.SetLogLoggerLevel(slog.LevelError)
+	return cfg, 0, true
+}
 
-	e := echo.New()
-	e.Use(middlewares.SetRateLimiter)
-	validate := validator.New()
-	bcryptHelper := helpers.NewBcryptHelper()
-	jwtHelper := helpers.NewJwtHelper()
+// serve runs the api until it is interrupted.
+func serve(args []string) int {
+	cfg, exitCode, ok := loadConfig(args)
+	if !ok {
+		return exitCode
+	}
+	logger := helpers.NewLogger(cfg.Log, os.Stdout)
+	slog.SetDefault(logger)
+	tracingUtil, err := utils.NewTracing(cfg.Tracing)
+	if err != nil {
+		log.Fatalln("error when setting up tracing: " + err.Error())
+	}
 
-	userRepository := repositories.NewUserRepository()
-	userService := services.NewUserService(postgresUtil, validate, userRepository, bcryptHelper, jwtHelper)
-	userController := controllers.NewUserController(userService)
-	routes.UserRoute(e, userController)
+	postgresUtil := utils.NewPostgresConnection(cfg.Postgres)
+	defer postgresUtil.Close()
+	if cfg.Postgres.AutoMigrate {
+		migrationUtil, err := utils.NewMigrationUtil(postgresUtil, migrations.Files)
+		if err != nil {
+			log.Fatalln("error when reading the migrations: " + err.Error())
+		}
+		applied, err := migrationUtil.Up(context.Background())
+		for _, migration := range applied {
+			slog.Info("postgres: applied migration", slog.Int("version", migration.Version), slog.String("name", migration.Name))
+		}
+		if err != nil {
+			log.Fatalln("error when migrating: " + err.Error())
+		}
+	}
+	app := newApplication(cfg, postgresUtil)
 
+	rateLimitPolicy := helpers.NewRateLimitPolicy(cfg.RateLimit.Requests, cfg.RateLimit.Window)
+	authRateLimitPolicy := helpers.NewRateLimitPolicy(cfg.RateLimit.AuthRequests, cfg.RateLimit.AuthWindow)
+	// routes that check a password, a code or a token, or send mail, get their own stricter bucket
+	authRoutes := []string{"POST /register", "POST /login", "POST /login/2fa", "POST /refresh-token", "POST /verify-email/resend", "POST /password/forgot", "POST /password/reset", "POST /oauth/token"}
+	authRateLimitPolicies := map[string]helpers.RateLimitPolicy{}
+	for _, route := range authRoutes {
+		authRateLimitPolicies[route] = authRateLimitPolicy
+	}
+
+	concurrencyLimiter := helpers.NewConcurrencyLimiter(cfg.Concurrency.Limit, cfg.Concurrency.Queue, time.Duration(cfg.Concurrency.QueueWait)*time.Millisecond)
+	// when the api is overloaded people should still be able to log in, the long lists can wait
+	routePriorities := map[string]int{}
+	for _, route := range authRoutes {
+		routePriorities[route] = helpers.PriorityHigh
+	}
+	for _, route := range []string{"GET /todos", "GET /me/export", "GET /me/security-events", "GET /oauth/clients", "GET /admin/users", "GET /admin/security-events"} {
+		routePriorities[route] = helpers.PriorityLow
+	}
+
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.JSONSerializer = middlewares.RequestIdJsonSerializer{}
+	// X-Forwarded-For can be set by anyone, it is only trusted when the api runs behind a proxy that overwrites it
+	if cfg.Server.TrustProxy {
+		e.IPExtractor = echo.ExtractIPFromXFFHeader()
+	} else {
+		e.IPExtractor = echo.ExtractIPDirect()
+	}
+	var rateLimitStore helpers.RateLimitStore
+	if cfg.RateLimit.Store == "postgres" {
+		rateLimitStore = utils.NewPostgresRateLimitStore(postgresUtil, repositories.NewRateLimitRepository())
+	} else {
+		rateLimitStore = helpers.NewMemoryRateLimitStore()
+	}
+	app.metricsHelper.CollectPostgresPool(postgresUtil.GetPool().Stat)
 	todoRepository := repositories.NewTodoRepository()
-	todoService := services.NewTodoService(postgresUtil, validate, todoRepository)
-	todoController := controllers.NewTodoController(todoService)
-	routes.TodoRoute(e, todoController)
+	app.metricsHelper.CollectTodos(func(ctx context.Context) (int, error) {
+		return todoRepository.Count(postgresUtil.GetPool(), ctx)
+	})
+	e.Use(middlewares.SetRequestId)
+	e.Use(middlewares.Trace)
+	e.Use(middlewares.CollectMetrics(app.metricsHelper))
+	e.Use(middlewares.LogRequests(logger))
+	e.Use(middlewares.LimitConcurrency(concurrencyLimiter, app.metricsHelper, routePriorities, time.Duration(cfg.Concurrency.RetryAfter)*time.Second))
+	e.Use(middlewares.RateLimit(rateLimitStore, app.jwtHelper, app.metricsHelper, rateLimitPolicy, authRateLimitPolicies))
+	e.Use(middlewares.SetClientInfo)
+	e.Use(middlewares.CsrfProtect(cfg.Cookie))
+
+	authenticate := middlewares.Authenticate(app.jwtHelper, app.principalService)
+	authenticateWithScope := func(scope string) echo.MiddlewareFunc {
+		return middlewares.AuthenticateWithScope(app.jwtHelper, app.principalService, scope)
+	}
+
+	routes.UserRoute(e, controllers.NewUserController(app.userService, cfg.Cookie), authenticate)
+	routes.OidcRoute(e, controllers.NewOidcController(app.oidcService, cfg.Cookie))
+	routes.TwoFactorRoute(e, controllers.NewTwoFactorController(app.twoFactorService, cfg.Cookie), authenticate)
+	routes.PasswordRoute(e, controllers.NewPasswordController(app.passwordService))
+	routes.ProfileRoute(e, controllers.NewProfileController(app.profileService, cfg.Cookie), authenticate, authenticateWithScope)
+	routes.TodoRoute(e, controllers.NewTodoController(app.todoService), authenticateWithScope)
+	routes.AccountRoute(e, controllers.NewAccountController(app.accountService), authenticate)
+	routes.AdminRoute(e, controllers.NewAdminController(app.adminService), authenticate)
+	routes.SecurityEventRoute(e, controllers.NewSecurityEventController(app.securityEventService), authenticate)
+	routes.OauthClientRoute(e, controllers.NewOauthClientController(app.oauthClientService), authenticate)
+	routes.OauthRoute(e, controllers.NewOauthController(app.oauthService), authenticate)
+	routes.JwksRoute(e, controllers.NewJwksController(app.jwtHelper))
+	if cfg.Metrics.Enabled {
+		routes.MetricsRoute(e, controllers.NewMetricsController(app.metricsHelper, cfg.Metrics.Token))
+	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 	go func() {
-		if err := e.Start(os.Getenv("ECHO_HOST")); err != nil && err != http.ErrServerClosed {
-			e.Logger.Fatal("shutting down the server")
+		ticker := time.NewTicker(time.Duration(cfg.Account.PurgeInterval) * time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				requestId := "purge-" + helpers.NewRequestId()
+				purgeCtx := helpers.ContextWithRequestId(ctx, requestId)
+				purgeCtx = helpers.ContextWithLogger(purgeCtx, slog.Default().With(slog.String("request_id", requestId)))
+				logger := helpers.LoggerFromContext(purgeCtx)
+				deletedAccounts, err := app.accountService.PurgeDeleted(purgeCtx)
+				if err != nil {
+					logger.Error("purging deleted accounts", slog.String("error", err.Error()))
+				} else if deletedAccounts > 0 {
+					logger.Info("purged deleted accounts", slog.Int64("deleted_accounts", deletedAccounts))
+				}
+			}
+		}
+	}()
+	go func() {
+		slog.Info("server: listening", slog.String("host", cfg.Server.Host))
+		if err := e.Start(cfg.Server.Host); err != nil && err != http.ErrServerClosed {
+			slog.Error("server: cannot listen", slog.String("error", err.Error()))
+			os.Exit(1)
 		}
 	}()
 
@@ -57,7 +223,13 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	if err := e.Shutdown(ctx); err != nil {
-		e.Logger.Fatal(err)
+		slog.Error("server: shutting down", slog.String("error", err.Error()))
+		return 1
 	}
-
+	app.mailQueue.Wait()
+	if err := tracingUtil.Shutdown(ctx); err != nil {
+		slog.Error("tracing: shutting down", slog.String("error", err.Error()))
+	}
+	slog.Info("server: stopped")
+	return 0
 }
@@ -1,42 +1,87 @@
 package middlewares
 
 import (
-	"context"
 	"net/http"
-	"os"
+	"strings"
 	"todo-list-api/helpers"
+	"todo-list-api/services"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 )
 
-func Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		authorizationToken, err := c.Cookie("Authorization")
-		if err != nil && err != http.ErrNoCookie {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"message": err.Error(),
-			})
-		} else if err != nil && err == http.ErrNoCookie {
-			return c.JSON(http.StatusNotFound, map[string]string{
-				"message": "token not found",
-			})
+func Authenticate(jwtHelper helpers.JwtHelper, principalService services.PrincipalService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			return authenticateCookie(c, next, jwtHelper, principalService)
 		}
-		token, err := jwt.ParseWithClaims(authorizationToken.Value, &helpers.AccessTokenCustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-		if err != nil {
-			return c.JSON(http.StatusUnauthorized, map[string]string{
-				"message": "Unauthorized",
-			})
-		} else if claims, ok := token.Claims.(*helpers.AccessTokenCustomClaims); ok {
-			ctx := context.WithValue(c.Request().Context(), "userId", claims.Id)
+	}
+}
+
+// AuthenticateWithScope also lets a third party client in with an oauth access token in the Authorization header,
+// as long as the token carries the scope. Without the header it is the same as Authenticate.
+func AuthenticateWithScope(jwtHelper helpers.JwtHelper, principalService services.PrincipalService, scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authorizationHeader := c.Request().Header.Get(echo.HeaderAuthorization)
+			if authorizationHeader == "" {
+				return authenticateCookie(c, next, jwtHelper, principalService)
+			}
+			oauthAccessToken, ok := strings.CutPrefix(authorizationHeader, "Bearer ")
+			if !ok {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_request"`)
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"message": "Unauthorized",
+				})
+			}
+			claims, err := jwtHelper.ParseOauthAccessToken(oauthAccessToken)
+			if err != nil {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"message": "Unauthorized",
+				})
+			}
+			httpCode, principal, response := principalService.FindByOauthAccessToken(c.Request().Context(), claims)
+			if httpCode == http.StatusUnauthorized {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+			}
+			if httpCode != http.StatusOK {
+				return c.JSON(httpCode, response)
+			}
+			if !helpers.ContainsOauthScopes(principal.Scopes, []string{scope}) {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="insufficient_scope", scope="`+scope+`"`)
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"message": "insufficient scope",
+				})
+			}
+			ctx := helpers.ContextWithPrincipal(c.Request().Context(), principal)
 			c.SetRequest(c.Request().WithContext(ctx))
 			return next(c)
-		} else {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"message": "internal server error",
-			})
 		}
 	}
 }
+
+func authenticateCookie(c echo.Context, next echo.HandlerFunc, jwtHelper helpers.JwtHelper, principalService services.PrincipalService) error {
+	authorizationToken, err := c.Cookie(helpers.AccessTokenCookieName)
+	if err != nil && err != http.ErrNoCookie {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": err.Error(),
+		})
+	} else if err != nil && err == http.ErrNoCookie {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"message": "token not found",
+		})
+	}
+	claims, err := jwtHelper.ParseAccessToken(authorizationToken.Value)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"message": "Unauthorized",
+		})
+	}
+	httpCode, principal, response := principalService.FindByAccessToken(c.Request().Context(), claims)
+	if httpCode != http.StatusOK {
+		return c.JSON(httpCode, response)
+	}
+	ctx := helpers.ContextWithPrincipal(c.Request().Context(), principal)
+	c.SetRequest(c.Request().WithContext(ctx))
+	return next(c)
+}
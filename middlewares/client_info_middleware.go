@@ -0,0 +1,19 @@
+package middlewares
+
+import (
+	"todo-list-api/helpers"
+
+	"github.com/labstack/echo/v4"
+)
+
+func SetClientInfo(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		clientInfo := helpers.ClientInfo{
+			IpAddress: c.RealIP(),
+			UserAgent: c.Request().UserAgent(),
+		}
+		ctx := helpers.ContextWithClientInfo(c.Request().Context(), clientInfo)
+		c.SetRequest(c.Request().WithContext(ctx))
+		return next(c)
+	}
+}
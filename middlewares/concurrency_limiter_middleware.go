@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+	"todo-list-api/helpers"
+	modelresponses "todo-list-api/models/responses"
+
+	"github.com/labstack/echo/v4"
+)
+
+// LimitConcurrency sheds load before any work is done. routePriorities is keyed by method and route, like
+// "POST /login", every other route has helpers.PriorityNormal. A request that gets no slot is answered with a 503
+// right away, retryAfter tells the client when to come back.
+func LimitConcurrency(concurrencyLimiter helpers.ConcurrencyLimiter, metricsHelper helpers.MetricsHelper, routePriorities map[string]int, retryAfter time.Duration) echo.MiddlewareFunc {
+	retryAfterSeconds := int(math.Ceil(retryAfter.Seconds()))
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			priority, ok := routePriorities[c.Request().Method+" "+c.Path()]
+			if !ok {
+				priority = helpers.PriorityNormal
+			}
+			release, ok := concurrencyLimiter.Acquire(c.Request().Context(), priority)
+			if !ok {
+				c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds))
+				metricsHelper.CountRateLimitRejection("concurrency", "")
+				return c.JSON(http.StatusServiceUnavailable, modelresponses.RetryAfterResponse{
+					Message:    "server is busy, try again later",
+					RetryAfter: retryAfterSeconds,
+				})
+			}
+			defer release()
+			return next(c)
+		}
+	}
+}
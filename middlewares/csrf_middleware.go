@@ -0,0 +1,60 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CsrfProtect is a double submit cookie check. Every response to a request without the csrf token cookie sets one,
+// and an unsafe request that carries the session cookies has to send the same token in the X-CSRF-Token header.
+// Another site can make the browser send the cookies but cannot read them to fill the header.
+// A request with a bearer token is not checked, a browser never adds that header to a cross site request on its own.
+func CsrfProtect(cookieConfig config.CookieConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			csrfTokenCookie, err := c.Cookie(helpers.CsrfTokenCookieName)
+			if err != nil || csrfTokenCookie.Value == "" {
+				csrfToken, _, err := helpers.GenerateRandomToken()
+				if err != nil {
+					return c.JSON(http.StatusInternalServerError, map[string]string{
+						"message": err.Error(),
+					})
+				}
+				c.SetCookie(helpers.NewCookie(cookieConfig, helpers.CsrfTokenCookieName, csrfToken, "/"))
+				csrfTokenCookie = nil
+			}
+
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return next(c)
+			}
+			if strings.HasPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ") || !hasSessionCookie(c) {
+				return next(c)
+			}
+
+			csrfTokenHeader := c.Request().Header.Get(helpers.CsrfTokenHeaderName)
+			if csrfTokenCookie == nil || csrfTokenHeader == "" || subtle.ConstantTimeCompare([]byte(csrfTokenHeader), []byte(csrfTokenCookie.Value)) != 1 {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"message": "invalid csrf token",
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+// hasSessionCookie leaves requests without the session cookies alone, they cannot act as anyone,
+// so a server calling the oauth token endpoint or a first login does not need a csrf token.
+func hasSessionCookie(c echo.Context) bool {
+	for _, name := range []string{helpers.AccessTokenCookieName, helpers.RefreshTokenCookieName} {
+		if _, err := c.Cookie(name); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"time"
+	"todo-list-api/helpers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CollectMetrics counts every request by method, route and status and observes how long it took. A request that
+// matched no route is counted under the route "unmatched", so scanners cannot make a series per path.
+func CollectMetrics(metricsHelper helpers.MetricsHelper) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+			route := c.Path()
+			if route == "" || route == "/*" {
+				route = "unmatched"
+			}
+			metricsHelper.ObserveRequest(c.Request().Method, route, c.Response().Status, time.Since(start))
+			return nil
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"net/http"
+	"todo-list-api/helpers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Authorize has to run after Authenticate, it reads the role of the principal that Authenticate put in the context.
+func Authorize(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := helpers.PrincipalFromContext(c.Request().Context())
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"message": "Unauthorized",
+				})
+			}
+			if !helpers.HasPermission(principal.Role, permission) {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"message": "forbidden",
+				})
+			}
+			return next(c)
+		}
+	}
+}
@@ -1,27 +1,68 @@
 package middlewares
 
 import (
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"todo-list-api/helpers"
+	modelresponses "todo-list-api/models/responses"
 
 	"github.com/labstack/echo/v4"
 )
 
-var NumberOfLimit int
-var NumberOfRequest int
+// RateLimit gives every client a bucket per policy. routePolicies is keyed by method and route, like "POST /login",
+// every other route shares defaultPolicy. The client is the user of a valid access token, so a user gets the same
+// limit from every device and address, and the ip address otherwise. The token is only parsed here, it is checked
+// against the database later by the authenticate middleware. When the store cannot be reached the request goes through,
+// an outage of the limiter should not take the api down with it.
+func RateLimit(rateLimitStore helpers.RateLimitStore, jwtHelper helpers.JwtHelper, metricsHelper helpers.MetricsHelper, defaultPolicy helpers.RateLimitPolicy, routePolicies map[string]helpers.RateLimitPolicy) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			policyName := c.Request().Method + " " + c.Path()
+			policy, ok := routePolicies[policyName]
+			if !ok {
+				policyName = "default"
+				policy = defaultPolicy
+			}
 
-func SetRateLimiter(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		NumberOfRequest++
-		if NumberOfRequest > NumberOfLimit {
-			return c.JSON(http.StatusTooManyRequests, map[string]string{
-				"message": "too many request",
-			})
+			result, err := rateLimitStore.Take(c.Request().Context(), policyName+"|"+rateLimitClient(c, jwtHelper), policy, time.Now())
+			if err != nil {
+				helpers.LoggerFromContext(c.Request().Context()).Error("rate limit", slog.String("error", err.Error()))
+				return next(c)
+			}
+			header := c.Response().Header()
+			header.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+			header.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			header.Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(result.ResetAfter.Seconds()))))
+			if !result.Allowed {
+				retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+				header.Set("Retry-After", strconv.Itoa(retryAfter))
+				metricsHelper.CountRateLimitRejection("rate", policyName)
+				return c.JSON(http.StatusTooManyRequests, modelresponses.RetryAfterResponse{
+					Message:    "too many requests",
+					RetryAfter: retryAfter,
+				})
+			}
+			return next(c)
 		}
-		err := next(c)
-		if err != nil {
-			c.Error(err)
+	}
+}
+
+func rateLimitClient(c echo.Context, jwtHelper helpers.JwtHelper) string {
+	if oauthAccessToken, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer "); ok {
+		claims, err := jwtHelper.ParseOauthAccessToken(oauthAccessToken)
+		if err == nil {
+			return "user:" + strconv.Itoa(claims.Id)
+		}
+	}
+	if authorizationToken, err := c.Cookie(helpers.AccessTokenCookieName); err == nil {
+		claims, err := jwtHelper.ParseAccessToken(authorizationToken.Value)
+		if err == nil {
+			return "user:" + strconv.Itoa(claims.Id)
 		}
-		NumberOfRequest--
-		return nil
 	}
+	return "ip:" + c.RealIP()
 }
@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"todo-list-api/helpers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SetRequestId takes the X-Request-ID of the caller or makes one, puts it in the request context and returns it in
+// the X-Request-ID header of the response.
+func SetRequestId(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		requestId := helpers.RequestIdOrNew(c.Request().Header.Get(echo.HeaderXRequestID))
+		ctx := helpers.ContextWithRequestId(c.Request().Context(), requestId)
+		c.SetRequest(c.Request().WithContext(ctx))
+		c.Response().Header().Set(echo.HeaderXRequestID, requestId)
+		return next(c)
+	}
+}
+
+// RequestIdJsonSerializer adds the request id to every json error body, so a user reporting an error can give it.
+type RequestIdJsonSerializer struct {
+	echo.DefaultJSONSerializer
+}
+
+func (serializer RequestIdJsonSerializer) Serialize(c echo.Context, i interface{}, indent string) error {
+	requestId, ok := helpers.RequestIdFromContext(c.Request().Context())
+	if !ok || c.Response().Status < http.StatusBadRequest {
+		return serializer.DefaultJSONSerializer.Serialize(c, i, indent)
+	}
+	body, err := json.Marshal(i)
+	if err != nil {
+		return serializer.DefaultJSONSerializer.Serialize(c, i, indent)
+	}
+	// a body that is not an object is left alone
+	var object map[string]json.RawMessage
+	if json.Unmarshal(body, &object) != nil {
+		return serializer.DefaultJSONSerializer.Serialize(c, i, indent)
+	}
+	object["request_id"], _ = json.Marshal(requestId)
+	return serializer.DefaultJSONSerializer.Serialize(c, object, indent)
+}
@@ -0,0 +1,79 @@
+package middlewares
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"time"
+	"todo-list-api/helpers"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxLoggedErrorBody is how much of the body of a 5xx response is logged, the services put the cause in the message.
+const maxLoggedErrorBody = 512
+
+// LogRequests logs one line per request and puts a logger with the request id, the route and the trace id, when the
+// request is traced, in the request context, for the services and repositories. Only the path is logged, the query string can carry tokens.
+func LogRequests(logger *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			requestId, _ := helpers.RequestIdFromContext(c.Request().Context())
+			requestLogger := logger.With(
+				slog.String("request_id", requestId),
+				slog.String("method", c.Request().Method),
+				slog.String("route", c.Path()),
+			)
+			if spanContext := trace.SpanContextFromContext(c.Request().Context()); spanContext.IsValid() {
+				requestLogger = requestLogger.With(slog.String("trace_id", spanContext.TraceID().String()))
+			}
+			c.SetRequest(c.Request().WithContext(helpers.ContextWithLogger(c.Request().Context(), requestLogger)))
+			bodyRecorder := &bodyRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = bodyRecorder
+
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			attrs := []slog.Attr{
+				slog.String("path", c.Request().URL.Path),
+				slog.Int("status", c.Response().Status),
+				slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.Int64("bytes", c.Response().Size),
+				slog.String("ip", c.RealIP()),
+			}
+			if principal, ok := helpers.PrincipalFromContext(c.Request().Context()); ok {
+				attrs = append(attrs, slog.Int("user_id", principal.Id))
+			}
+			level := slog.LevelInfo
+			if c.Response().Status >= http.StatusInternalServerError {
+				level = slog.LevelError
+				attrs = append(attrs, slog.String("error", bodyRecorder.body.String()))
+			} else if c.Response().Status >= http.StatusBadRequest {
+				level = slog.LevelWarn
+			}
+			requestLogger.LogAttrs(c.Request().Context(), level, "request", attrs...)
+			return nil
+		}
+	}
+}
+
+// bodyRecorder keeps the start of the body so the message of a 5xx response can be logged.
+type bodyRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (bodyRecorder *bodyRecorder) Write(content []byte) (int, error) {
+	if remaining := maxLoggedErrorBody - bodyRecorder.body.Len(); remaining > 0 {
+		bodyRecorder.body.Write(content[:min(remaining, len(content))])
+	}
+	return bodyRecorder.ResponseWriter.Write(content)
+}
+
+func (bodyRecorder *bodyRecorder) Unwrap() http.ResponseWriter {
+	return bodyRecorder.ResponseWriter
+}
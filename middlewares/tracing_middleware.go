@@ -0,0 +1,55 @@
+package middlewares
+
+import (
+	"net/http"
+	"todo-list-api/helpers"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Trace starts a server span per request, as a child of the traceparent header of the caller when there is one, and
+// puts it in the request context, so the services, the queries and the requests to the oidc providers are its children.
+func Trace(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		request := c.Request()
+		ctx := otel.GetTextMapPropagator().Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+		// a path that matched no route would make a span name per path
+		name := request.Method
+		if route := c.Path(); route != "" && route != "/*" {
+			name += " " + route
+		}
+		attributes := []attribute.KeyValue{
+			semconv.HTTPRequestMethodKey.String(request.Method),
+			semconv.HTTPRoute(c.Path()),
+			semconv.URLPath(request.URL.Path),
+			semconv.ClientAddress(c.RealIP()),
+			semconv.UserAgentOriginal(request.UserAgent()),
+		}
+		if requestId, ok := helpers.RequestIdFromContext(ctx); ok {
+			attributes = append(attributes, attribute.String("request_id", requestId))
+		}
+		ctx, span := helpers.StartSpan(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attributes...))
+		defer span.End()
+		c.SetRequest(request.WithContext(ctx))
+
+		err := next(c)
+		if err != nil {
+			c.Error(err)
+		}
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(c.Response().Status))
+		if c.Response().Status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(c.Response().Status))
+		}
+		if principal, ok := helpers.PrincipalFromContext(c.Request().Context()); ok {
+			span.SetAttributes(attribute.Int("user_id", principal.Id))
+		}
+		return nil
+	}
+}
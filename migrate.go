@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"todo-list-api/databases/migrations"
+	"todo-list-api/utils"
+)
+
+const migrateUsage = `usage: todo-api migrate <command> [flags]
+  migrate up [flags]         apply every migration that is not applied yet
+  migrate down [n] [flags]   revert the last n applied migrations, 1 by default
+  migrate status [flags]     list the migrations and when they were applied
+  migrate create <name>      write an empty up and down file to databases/migrations
+the flags are the same as for the server, e.g. -config config.yaml`
+
+// migrate runs the migrate subcommand and returns the exit code.
+func migrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	command, args := args[0], args[1:]
+
+	if command == "create" {
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+		upPath, downPath, err := utils.CreateMigration("databases/migrations", args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "creating the migration:", err)
+			return 1
+		}
+		fmt.Println("created", upPath)
+		fmt.Println("created", downPath)
+		return 0
+	}
+
+	steps := 1
+	if command == "down" && len(args) > 0 {
+		if number, err := strconv.Atoi(args[0]); err == nil {
+			steps, args = number, args[1:]
+		}
+	}
+	if command != "up" && command != "down" && command != "status" || steps < 1 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	cfg, exitCode, ok := loadConfig(args)
+	if !ok {
+		return exitCode
+	}
+	postgresUtil := utils.NewPostgresConnection(cfg.Postgres)
+	defer postgresUtil.Close()
+	migrationUtil, err := utils.NewMigrationUtil(postgresUtil, migrations.Files)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "reading the migrations:", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		applied, err := migrationUtil.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied %06d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("the database is up to date")
+		}
+	case "down":
+		reverted, err := migrationUtil.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %06d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(reverted) == 0 {
+			fmt.Println("no migration is applied")
+		}
+	case "status":
+		migrationStatuses, err := migrationUtil.Status(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, migrationStatus := range migrationStatuses {
+			appliedAt := "pending"
+			if migrationStatus.AppliedAt.Valid {
+				appliedAt = "applied at " + migrationStatus.AppliedAt.Time.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%06d_%s %s\n", migrationStatus.Version, migrationStatus.Name, appliedAt)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,18 @@
+package modelentities
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+type LoginAttempt struct {
+	AttemptKey   pgtype.Text
+	FailedCount  pgtype.Int4
+	LockedUntil  pgtype.Timestamptz
+	LastFailedAt pgtype.Timestamptz
+}
+
+type LoginLockoutEvent struct {
+	Id          pgtype.Int4
+	AttemptKey  pgtype.Text
+	IpAddress   pgtype.Text
+	FailedCount pgtype.Int4
+	LockedUntil pgtype.Timestamptz
+}
@@ -0,0 +1,11 @@
+package modelentities
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+type MfaChallenge struct {
+	Jti         pgtype.Text
+	UserId      pgtype.Int4
+	FailedCount pgtype.Int4
+	ExpiresAt   pgtype.Timestamptz
+	UsedAt      pgtype.Timestamptz
+}
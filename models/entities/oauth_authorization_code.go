@@ -0,0 +1,16 @@
+package modelentities
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+type OauthAuthorizationCode struct {
+	Id            pgtype.Int4
+	CodeHash      pgtype.Text
+	ClientId      pgtype.Text
+	UserId        pgtype.Int4
+	RedirectUri   pgtype.Text
+	Scopes        []string
+	CodeChallenge pgtype.Text
+	ExpiresAt     pgtype.Timestamptz
+	UsedAt        pgtype.Timestamptz
+	GrantId       pgtype.Int4
+}
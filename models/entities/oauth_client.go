@@ -0,0 +1,14 @@
+package modelentities
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+type OauthClient struct {
+	Id               pgtype.Int4
+	ClientId         pgtype.Text
+	ClientSecretHash pgtype.Text
+	UserId           pgtype.Int4
+	Name             pgtype.Text
+	RedirectUris     []string
+	Scopes           []string
+	CreatedAt        pgtype.Timestamptz
+}
@@ -0,0 +1,13 @@
+package modelentities
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+type OauthGrant struct {
+	Id                    pgtype.Int4
+	ClientId              pgtype.Text
+	UserId                pgtype.Int4
+	Scopes                []string
+	RefreshTokenHash      pgtype.Text
+	RefreshTokenExpiresAt pgtype.Timestamptz
+	RevokedAt             pgtype.Timestamptz
+}
@@ -0,0 +1,11 @@
+package modelentities
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+type PasswordResetToken struct {
+	Id        pgtype.Int4
+	UserId    pgtype.Int4
+	TokenHash pgtype.Text
+	ExpiresAt pgtype.Timestamptz
+	UsedAt    pgtype.Timestamptz
+}
@@ -0,0 +1,10 @@
+package modelentities
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+type RecoveryCode struct {
+	Id       pgtype.Int4
+	UserId   pgtype.Int4
+	CodeHash pgtype.Text
+	UsedAt   pgtype.Timestamptz
+}
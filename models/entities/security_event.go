@@ -0,0 +1,22 @@
+package modelentities
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+type SecurityEvent struct {
+	Id        pgtype.Int4
+	UserId    pgtype.Int4
+	EventType pgtype.Text
+	Email     pgtype.Text
+	Detail    pgtype.Text
+	IpAddress pgtype.Text
+	UserAgent pgtype.Text
+	CreatedAt pgtype.Timestamptz
+}
+
+type SecurityEventFilter struct {
+	UserId    int
+	EventType string
+	IpAddress string
+	From      pgtype.Timestamptz
+	To        pgtype.Timestamptz
+}
@@ -0,0 +1,8 @@
+package modelentities
+
+type TodoStatistics struct {
+	NumberOfUsers          int
+	NumberOfDisabledUsers  int
+	NumberOfUsersWithTodos int
+	NumberOfTodos          int
+}
@@ -3,9 +3,17 @@ package modelentities
 import "github.com/jackc/pgx/v5/pgtype"
 
 type User struct {
-	Id           pgtype.Int4
-	Name         pgtype.Text
-	Email        pgtype.Text
-	Password     pgtype.Text
-	RefreshToken pgtype.Text
+	Id                      pgtype.Int4
+	Name                    pgtype.Text
+	Email                   pgtype.Text
+	Password                pgtype.Text
+	RefreshToken            pgtype.Text
+	EmailVerifiedAt         pgtype.Timestamptz
+	EmailVerificationSentAt pgtype.Timestamptz
+	TotpSecret              pgtype.Text
+	TotpEnabledAt           pgtype.Timestamptz
+	DeletionScheduledAt     pgtype.Timestamptz
+	Role                    pgtype.Text
+	DisabledAt              pgtype.Timestamptz
+	SessionsRevokedAt       pgtype.Timestamptz
 }
@@ -0,0 +1,12 @@
+package modelentities
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+type UserIdentity struct {
+	Id          pgtype.Int4
+	UserId      pgtype.Int4
+	Provider    pgtype.Text
+	Subject     pgtype.Text
+	Email       pgtype.Text
+	LastLoginAt pgtype.Timestamptz
+}
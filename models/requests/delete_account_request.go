@@ -0,0 +1,5 @@
+package modelrequests
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
@@ -0,0 +1,5 @@
+package modelrequests
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
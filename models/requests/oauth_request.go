@@ -0,0 +1,46 @@
+package modelrequests
+
+type CreateOauthClientRequest struct {
+	Name         string   `json:"name" validate:"required,max=100"`
+	RedirectUris []string `json:"redirectUris" validate:"required,min=1,max=10,dive,required,url"`
+	Scopes       []string `json:"scopes" validate:"required,min=1"`
+	Confidential bool     `json:"confidential"`
+}
+
+// AuthorizeRequest comes as query parameters to read the consent screen and as a json body to answer it,
+// with the parameter names of the oauth2 specification in both cases.
+type AuthorizeRequest struct {
+	ResponseType        string `json:"response_type" query:"response_type"`
+	ClientId            string `json:"client_id" query:"client_id"`
+	RedirectUri         string `json:"redirect_uri" query:"redirect_uri"`
+	Scope               string `json:"scope" query:"scope"`
+	State               string `json:"state" query:"state"`
+	CodeChallenge       string `json:"code_challenge" query:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method" query:"code_challenge_method"`
+	Approve             bool   `json:"approve"`
+}
+
+type TokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectUri  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+	ClientId     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+type RevokeTokenRequest struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientId      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+type IntrospectTokenRequest struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientId      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
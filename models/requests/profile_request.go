@@ -0,0 +1,15 @@
+package modelrequests
+
+type UpdateProfileRequest struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" validate:"required"`
+	NewPassword     string `json:"newPassword" validate:"required"`
+}
+
+type ChangeEmailRequest struct {
+	Email    string `json:"email" validate:"required,email,max=100"`
+	Password string `json:"password" validate:"required"`
+}
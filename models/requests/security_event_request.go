@@ -0,0 +1,12 @@
+package modelrequests
+
+// FindSecurityEventsRequest comes as query parameters, from and to are RFC 3339 times and every filter is optional.
+type FindSecurityEventsRequest struct {
+	UserId    int    `query:"userId"`
+	EventType string `query:"eventType"`
+	IpAddress string `query:"ipAddress"`
+	From      string `query:"from"`
+	To        string `query:"to"`
+	Page      int    `query:"page"`
+	Limit     int    `query:"limit"`
+}
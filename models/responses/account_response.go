@@ -0,0 +1,17 @@
+package modelresponses
+
+import "time"
+
+type DeleteAccountResponse struct {
+	Message             string    `json:"message"`
+	DeletionScheduledAt time.Time `json:"deletionScheduledAt"`
+}
+
+type ExportProfileResponse struct {
+	Id                  int        `json:"id"`
+	Name                string     `json:"name"`
+	Email               string     `json:"email"`
+	EmailVerifiedAt     *time.Time `json:"emailVerifiedAt"`
+	TwoFactorEnabledAt  *time.Time `json:"twoFactorEnabledAt"`
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt"`
+}
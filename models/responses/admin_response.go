@@ -0,0 +1,30 @@
+package modelresponses
+
+import "time"
+
+type AdminUserResponse struct {
+	Id                  int        `json:"id"`
+	Name                string     `json:"name"`
+	Email               string     `json:"email"`
+	Role                string     `json:"role"`
+	EmailVerified       bool       `json:"emailVerified"`
+	TwoFactorEnabled    bool       `json:"twoFactorEnabled"`
+	DisabledAt          *time.Time `json:"disabledAt"`
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt"`
+	NumberOfTodos       int        `json:"numberOfTodos"`
+}
+
+type GetAdminUserResponse struct {
+	Data  []AdminUserResponse `json:"data"`
+	Page  int                 `json:"page"`
+	Limit int                 `json:"limit"`
+	Total int                 `json:"total"`
+}
+
+type TodoStatisticsResponse struct {
+	NumberOfUsers          int     `json:"numberOfUsers"`
+	NumberOfDisabledUsers  int     `json:"numberOfDisabledUsers"`
+	NumberOfUsersWithTodos int     `json:"numberOfUsersWithTodos"`
+	NumberOfTodos          int     `json:"numberOfTodos"`
+	AverageTodosPerUser    float64 `json:"averageTodosPerUser"`
+}
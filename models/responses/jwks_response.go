@@ -0,0 +1,16 @@
+package modelresponses
+
+type JwkResponse struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JwksResponse struct {
+	Keys []JwkResponse `json:"keys"`
+}
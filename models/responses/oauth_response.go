@@ -0,0 +1,64 @@
+package modelresponses
+
+import "time"
+
+type OauthClientResponse struct {
+	ClientId     string    `json:"clientId"`
+	Name         string    `json:"name"`
+	RedirectUris []string  `json:"redirectUris"`
+	Scopes       []string  `json:"scopes"`
+	Confidential bool      `json:"confidential"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type CreateOauthClientResponse struct {
+	OauthClientResponse
+	ClientSecret string `json:"clientSecret,omitempty"`
+}
+
+type OauthScopeResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type OauthConsentClientResponse struct {
+	ClientId string `json:"clientId"`
+	Name     string `json:"name"`
+}
+
+type OauthConsentResponse struct {
+	Client      OauthConsentClientResponse `json:"client"`
+	Scopes      []OauthScopeResponse       `json:"scopes"`
+	RedirectUri string                     `json:"redirectUri"`
+	State       string                     `json:"state"`
+}
+
+type OauthRedirectResponse struct {
+	RedirectUri string `json:"redirectUri"`
+}
+
+// the token, revocation and introspection responses use the field names of the oauth2 specifications
+
+type OauthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+	RedirectUri      string `json:"redirect_uri,omitempty"`
+}
+
+type OauthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope"`
+}
+
+type IntrospectTokenResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientId  string `json:"client_id,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+}
@@ -0,0 +1,11 @@
+package modelresponses
+
+type PasswordPolicyViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type PasswordPolicyResponse struct {
+	Message    string                    `json:"message"`
+	Violations []PasswordPolicyViolation `json:"violations"`
+}
@@ -0,0 +1,9 @@
+package modelresponses
+
+type ProfileResponse struct {
+	Id               int    `json:"id"`
+	Name             string `json:"name"`
+	Email            string `json:"email"`
+	EmailVerified    bool   `json:"emailVerified"`
+	TwoFactorEnabled bool   `json:"twoFactorEnabled"`
+}
@@ -0,0 +1,6 @@
+package modelresponses
+
+type RetryAfterResponse struct {
+	Message    string `json:"message"`
+	RetryAfter int    `json:"retryAfter"`
+}
@@ -0,0 +1,21 @@
+package modelresponses
+
+import "time"
+
+type SecurityEventResponse struct {
+	Id        int       `json:"id"`
+	UserId    *int      `json:"userId"`
+	EventType string    `json:"eventType"`
+	Email     string    `json:"email,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+	IpAddress string    `json:"ipAddress"`
+	UserAgent string    `json:"userAgent"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type GetSecurityEventResponse struct {
+	Data  []SecurityEventResponse `json:"data"`
+	Page  int                     `json:"page"`
+	Limit int                     `json:"limit"`
+	Total int                     `json:"total"`
+}
@@ -0,0 +1,17 @@
+package modelresponses
+
+type EnrollTwoFactorResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthUri string `json:"otpauthUri"`
+}
+
+type ConfirmTwoFactorResponse struct {
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type MfaRequiredResponse struct {
+	Message     string `json:"message"`
+	MfaRequired bool   `json:"mfaRequired"`
+	MfaToken    string `json:"mfaToken"`
+}
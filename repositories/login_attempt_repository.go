@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	modelentities "todo-list-api/models/entities"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type LoginAttemptRepository interface {
+	FindByAttemptKey(tx pgx.Tx, ctx context.Context, attemptKey string) (loginAttempt modelentities.LoginAttempt, err error)
+	IncrementFailedCount(tx pgx.Tx, ctx context.Context, attemptKey string, attemptWindow int) (failedCount int, err error)
+	UpdateLockedUntil(tx pgx.Tx, ctx context.Context, attemptKey string, lockSeconds int) (rowsAffected int64, err error)
+	DeleteByAttemptKey(tx pgx.Tx, ctx context.Context, attemptKey string) (rowsAffected int64, err error)
+	CreateLockoutEvent(tx pgx.Tx, ctx context.Context, loginLockoutEvent modelentities.LoginLockoutEvent) (lastInsertedId int, err error)
+}
+
+type LoginAttemptRepositoryImplementation struct {
+}
+
+func NewLoginAttemptRepository() LoginAttemptRepository {
+	return &LoginAttemptRepositoryImplementation{}
+}
+
+func (repository *LoginAttemptRepositoryImplementation) FindByAttemptKey(tx pgx.Tx, ctx context.Context, attemptKey string) (loginAttempt modelentities.LoginAttempt, err error) {
+	query := `SELECT attempt_key,failed_count,locked_until,last_failed_at FROM login_attempts WHERE attempt_key = $1 FOR UPDATE;`
+	err = tx.QueryRow(ctx, query, attemptKey).Scan(&loginAttempt.AttemptKey, &loginAttempt.FailedCount, &loginAttempt.LockedUntil, &loginAttempt.LastFailedAt)
+	return
+}
+
+// IncrementFailedCount starts counting again from one when the previous failure is older than attemptWindow seconds.
+func (repository *LoginAttemptRepositoryImplementation) IncrementFailedCount(tx pgx.Tx, ctx context.Context, attemptKey string, attemptWindow int) (failedCount int, err error) {
+	query := `INSERT INTO login_attempts (attempt_key,failed_count,last_failed_at) VALUES ($1,1,NOW())
+		ON CONFLICT (attempt_key) DO UPDATE SET
+		failed_count = CASE WHEN login_attempts.last_failed_at < NOW() - make_interval(secs => $2) THEN 1 ELSE login_attempts.failed_count + 1 END,
+		last_failed_at = NOW()
+		RETURNING failed_count;`
+	err = tx.QueryRow(ctx, query, attemptKey, attemptWindow).Scan(&failedCount)
+	return
+}
+
+func (repository *LoginAttemptRepositoryImplementation) UpdateLockedUntil(tx pgx.Tx, ctx context.Context, attemptKey string, lockSeconds int) (rowsAffected int64, err error) {
+	query := `UPDATE login_attempts SET locked_until = NOW() + make_interval(secs => $1) WHERE attempt_key = $2;`
+	result, err := tx.Exec(ctx, query, lockSeconds, attemptKey)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
+
+func (repository *LoginAttemptRepositoryImplementation) DeleteByAttemptKey(tx pgx.Tx, ctx context.Context, attemptKey string) (rowsAffected int64, err error) {
+	query := `DELETE FROM login_attempts WHERE attempt_key = $1;`
+	result, err := tx.Exec(ctx, query, attemptKey)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
+
+func (repository *LoginAttemptRepositoryImplementation) CreateLockoutEvent(tx pgx.Tx, ctx context.Context, loginLockoutEvent modelentities.LoginLockoutEvent) (lastInsertedId int, err error) {
+	query := `INSERT INTO login_lockout_events (attempt_key,ip_address,failed_count,locked_until) VALUES ($1,$2,$3,$4) RETURNING id;`
+	err = tx.QueryRow(ctx, query, loginLockoutEvent.AttemptKey, loginLockoutEvent.IpAddress, loginLockoutEvent.FailedCount, loginLockoutEvent.LockedUntil).Scan(&lastInsertedId)
+	return
+}
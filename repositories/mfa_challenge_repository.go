@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	modelentities "todo-list-api/models/entities"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type MfaChallengeRepository interface {
+	FindOrCreate(tx pgx.Tx, ctx context.Context, jti string, userId int, expiresAt time.Time) (mfaChallenge modelentities.MfaChallenge, err error)
+	IncrementFailedCount(tx pgx.Tx, ctx context.Context, jti string) (failedCount int, err error)
+	UpdateUsedAt(tx pgx.Tx, ctx context.Context, jti string) (rowsAffected int64, err error)
+	DeleteExpiredByUserId(tx pgx.Tx, ctx context.Context, userId int) (rowsAffected int64, err error)
+}
+
+type MfaChallengeRepositoryImplementation struct {
+}
+
+func NewMfaChallengeRepository() MfaChallengeRepository {
+	return &MfaChallengeRepositoryImplementation{}
+}
+
+// FindOrCreate starts tracking an mfa pending token the first time it is used and locks its row, two requests with
+// the same token wait for each other.
+func (repository *MfaChallengeRepositoryImplementation) FindOrCreate(tx pgx.Tx, ctx context.Context, jti string, userId int, expiresAt time.Time) (mfaChallenge modelentities.MfaChallenge, err error) {
+	query := `INSERT INTO mfa_challenges (jti,user_id,expires_at) VALUES ($1,$2,$3)
+		ON CONFLICT (jti) DO UPDATE SET jti = EXCLUDED.jti
+		RETURNING jti,user_id,failed_count,expires_at,used_at;`
+	err = tx.QueryRow(ctx, query, jti, userId, expiresAt).Scan(&mfaChallenge.Jti, &mfaChallenge.UserId, &mfaChallenge.FailedCount, &mfaChallenge.ExpiresAt, &mfaChallenge.UsedAt)
+	return
+}
+
+func (repository *MfaChallengeRepositoryImplementation) IncrementFailedCount(tx pgx.Tx, ctx context.Context, jti string) (failedCount int, err error) {
+	query := `UPDATE mfa_challenges SET failed_count = failed_count + 1 WHERE jti = $1 RETURNING failed_count;`
+	err = tx.QueryRow(ctx, query, jti).Scan(&failedCount)
+	return
+}
+
+func (repository *MfaChallengeRepositoryImplementation) UpdateUsedAt(tx pgx.Tx, ctx context.Context, jti string) (rowsAffected int64, err error) {
+	query := `UPDATE mfa_challenges SET used_at = NOW() WHERE jti = $1 AND used_at IS NULL;`
+	result, err := tx.Exec(ctx, query, jti)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
+
+// DeleteExpiredByUserId removes the challenges of tokens that cannot be used anymore, an expired token is refused
+// before its challenge is looked at.
+func (repository *MfaChallengeRepositoryImplementation) DeleteExpiredByUserId(tx pgx.Tx, ctx context.Context, userId int) (rowsAffected int64, err error) {
+	query := `DELETE FROM mfa_challenges WHERE user_id = $1 AND expires_at < NOW();`
+	result, err := tx.Exec(ctx, query, userId)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
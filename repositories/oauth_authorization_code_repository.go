@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+	modelentities "todo-list-api/models/entities"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type OauthAuthorizationCodeRepository interface {
+	Create(tx pgx.Tx, ctx context.Context, oauthAuthorizationCode modelentities.OauthAuthorizationCode) (lastInsertedId int, err error)
+	FindByCodeHash(tx pgx.Tx, ctx context.Context, codeHash string) (oauthAuthorizationCode modelentities.OauthAuthorizationCode, err error)
+	UpdateUsedAt(tx pgx.Tx, ctx context.Context, grantId int, id int) (rowsAffected int64, err error)
+}
+
+type OauthAuthorizationCodeRepositoryImplementation struct {
+}
+
+func NewOauthAuthorizationCodeRepository() OauthAuthorizationCodeRepository {
+	return &OauthAuthorizationCodeRepositoryImplementation{}
+}
+
+func (repository *OauthAuthorizationCodeRepositoryImplementation) Create(tx pgx.Tx, ctx context.Context, oauthAuthorizationCode modelentities.OauthAuthorizationCode) (lastInsertedId int, err error) {
+	query := `INSERT INTO oauth_authorization_codes (code_hash,client_id,user_id,redirect_uri,scopes,code_challenge,expires_at) VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id;`
+	err = tx.QueryRow(ctx, query, oauthAuthorizationCode.CodeHash, oauthAuthorizationCode.ClientId, oauthAuthorizationCode.UserId, oauthAuthorizationCode.RedirectUri, oauthAuthorizationCode.Scopes, oauthAuthorizationCode.CodeChallenge, oauthAuthorizationCode.ExpiresAt).Scan(&lastInsertedId)
+	return
+}
+
+func (repository *OauthAuthorizationCodeRepositoryImplementation) FindByCodeHash(tx pgx.Tx, ctx context.Context, codeHash string) (oauthAuthorizationCode modelentities.OauthAuthorizationCode, err error) {
+	query := `SELECT id,code_hash,client_id,user_id,redirect_uri,scopes,code_challenge,expires_at,used_at,grant_id FROM oauth_authorization_codes WHERE code_hash = $1 FOR UPDATE;`
+	err = tx.QueryRow(ctx, query, codeHash).Scan(&oauthAuthorizationCode.Id, &oauthAuthorizationCode.CodeHash, &oauthAuthorizationCode.ClientId, &oauthAuthorizationCode.UserId, &oauthAuthorizationCode.RedirectUri, &oauthAuthorizationCode.Scopes, &oauthAuthorizationCode.CodeChallenge, &oauthAuthorizationCode.ExpiresAt, &oauthAuthorizationCode.UsedAt, &oauthAuthorizationCode.GrantId)
+	return
+}
+
+// UpdateUsedAt remembers the grant the code was exchanged for, so the grant can be revoked if the code shows up again.
+func (repository *OauthAuthorizationCodeRepositoryImplementation) UpdateUsedAt(tx pgx.Tx, ctx context.Context, grantId int, id int) (rowsAffected int64, err error) {
+	query := `UPDATE oauth_authorization_codes SET used_at = NOW(), grant_id = $1 WHERE id = $2 AND used_at IS NULL;`
+	result, err := tx.Exec(ctx, query, grantId, id)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	modelentities "todo-list-api/models/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OauthClientRepository interface {
+	Create(tx pgx.Tx, ctx context.Context, oauthClient modelentities.OauthClient) (lastInsertedId int, err error)
+	FindByClientId(tx pgx.Tx, ctx context.Context, clientId string) (oauthClient modelentities.OauthClient, err error)
+	FindByUserId(pool *pgxpool.Pool, ctx context.Context, userId int) (oauthClients []modelentities.OauthClient, err error)
+	DeleteByClientIdAndUserId(tx pgx.Tx, ctx context.Context, clientId string, userId int) (rowsAffected int64, err error)
+}
+
+type OauthClientRepositoryImplementation struct {
+}
+
+func NewOauthClientRepository() OauthClientRepository {
+	return &OauthClientRepositoryImplementation{}
+}
+
+func (repository *OauthClientRepositoryImplementation) Create(tx pgx.Tx, ctx context.Context, oauthClient modelentities.OauthClient) (lastInsertedId int, err error) {
+	query := `INSERT INTO oauth_clients (client_id,client_secret_hash,user_id,name,redirect_uris,scopes) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id;`
+	err = tx.QueryRow(ctx, query, oauthClient.ClientId, oauthClient.ClientSecretHash, oauthClient.UserId, oauthClient.Name, oauthClient.RedirectUris, oauthClient.Scopes).Scan(&lastInsertedId)
+	return
+}
+
+func (repository *OauthClientRepositoryImplementation) FindByClientId(tx pgx.Tx, ctx context.Context, clientId string) (oauthClient modelentities.OauthClient, err error) {
+	query := `SELECT id,client_id,client_secret_hash,user_id,name,redirect_uris,scopes,created_at FROM oauth_clients WHERE client_id = $1;`
+	err = tx.QueryRow(ctx, query, clientId).Scan(&oauthClient.Id, &oauthClient.ClientId, &oauthClient.ClientSecretHash, &oauthClient.UserId, &oauthClient.Name, &oauthClient.RedirectUris, &oauthClient.Scopes, &oauthClient.CreatedAt)
+	return
+}
+
+func (repository *OauthClientRepositoryImplementation) FindByUserId(pool *pgxpool.Pool, ctx context.Context, userId int) (oauthClients []modelentities.OauthClient, err error) {
+	query := `SELECT id,client_id,client_secret_hash,user_id,name,redirect_uris,scopes,created_at FROM oauth_clients WHERE user_id = $1 ORDER BY id ASC;`
+	rows, err := pool.Query(ctx, query, userId)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var oauthClient modelentities.OauthClient
+		err = rows.Scan(&oauthClient.Id, &oauthClient.ClientId, &oauthClient.ClientSecretHash, &oauthClient.UserId, &oauthClient.Name, &oauthClient.RedirectUris, &oauthClient.Scopes, &oauthClient.CreatedAt)
+		if err != nil {
+			oauthClients = []modelentities.OauthClient{}
+			return
+		}
+		oauthClients = append(oauthClients, oauthClient)
+	}
+
+	if rows.Err() != nil {
+		oauthClients = []modelentities.OauthClient{}
+		err = rows.Err()
+		return
+	}
+	return
+}
+
+func (repository *OauthClientRepositoryImplementation) DeleteByClientIdAndUserId(tx pgx.Tx, ctx context.Context, clientId string, userId int) (rowsAffected int64, err error) {
+	query := `DELETE FROM oauth_clients WHERE client_id = $1 AND user_id = $2;`
+	result, err := tx.Exec(ctx, query, clientId, userId)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
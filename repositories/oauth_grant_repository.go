@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	modelentities "todo-list-api/models/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OauthGrantRepository interface {
+	Create(tx pgx.Tx, ctx context.Context, oauthGrant modelentities.OauthGrant) (lastInsertedId int, err error)
+	FindByRefreshTokenHash(tx pgx.Tx, ctx context.Context, refreshTokenHash string) (oauthGrant modelentities.OauthGrant, err error)
+	FindActiveById(pool *pgxpool.Pool, ctx context.Context, id int) (oauthGrant modelentities.OauthGrant, err error)
+	UpdateRefreshToken(tx pgx.Tx, ctx context.Context, refreshTokenHash string, refreshTokenExpiresAt pgtype.Timestamptz, id int) (rowsAffected int64, err error)
+	Revoke(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error)
+	RevokeByUserId(tx pgx.Tx, ctx context.Context, userId int) (rowsAffected int64, err error)
+}
+
+type OauthGrantRepositoryImplementation struct {
+}
+
+func NewOauthGrantRepository() OauthGrantRepository {
+	return &OauthGrantRepositoryImplementation{}
+}
+
+func (repository *OauthGrantRepositoryImplementation) Create(tx pgx.Tx, ctx context.Context, oauthGrant modelentities.OauthGrant) (lastInsertedId int, err error) {
+	query := `INSERT INTO oauth_grants (client_id,user_id,scopes,refresh_token_hash,refresh_token_expires_at) VALUES ($1,$2,$3,$4,$5) RETURNING id;`
+	err = tx.QueryRow(ctx, query, oauthGrant.ClientId, oauthGrant.UserId, oauthGrant.Scopes, oauthGrant.RefreshTokenHash, oauthGrant.RefreshTokenExpiresAt).Scan(&lastInsertedId)
+	return
+}
+
+func (repository *OauthGrantRepositoryImplementation) FindByRefreshTokenHash(tx pgx.Tx, ctx context.Context, refreshTokenHash string) (oauthGrant modelentities.OauthGrant, err error) {
+	query := `SELECT id,client_id,user_id,scopes,refresh_token_hash,refresh_token_expires_at,revoked_at FROM oauth_grants WHERE refresh_token_hash = $1 FOR UPDATE;`
+	err = tx.QueryRow(ctx, query, refreshTokenHash).Scan(&oauthGrant.Id, &oauthGrant.ClientId, &oauthGrant.UserId, &oauthGrant.Scopes, &oauthGrant.RefreshTokenHash, &oauthGrant.RefreshTokenExpiresAt, &oauthGrant.RevokedAt)
+	return
+}
+
+func (repository *OauthGrantRepositoryImplementation) FindActiveById(pool *pgxpool.Pool, ctx context.Context, id int) (oauthGrant modelentities.OauthGrant, err error) {
+	query := `SELECT id,client_id,user_id,scopes,refresh_token_hash,refresh_token_expires_at,revoked_at FROM oauth_grants WHERE id = $1 AND revoked_at IS NULL;`
+	err = pool.QueryRow(ctx, query, id).Scan(&oauthGrant.Id, &oauthGrant.ClientId, &oauthGrant.UserId, &oauthGrant.Scopes, &oauthGrant.RefreshTokenHash, &oauthGrant.RefreshTokenExpiresAt, &oauthGrant.RevokedAt)
+	return
+}
+
+// UpdateRefreshToken rotates the refresh token, the old one stops matching any grant.
+func (repository *OauthGrantRepositoryImplementation) UpdateRefreshToken(tx pgx.Tx, ctx context.Context, refreshTokenHash string, refreshTokenExpiresAt pgtype.Timestamptz, id int) (rowsAffected int64, err error) {
+	query := `UPDATE oauth_grants SET refresh_token_hash = $1, refresh_token_expires_at = $2 WHERE id = $3 AND revoked_at IS NULL;`
+	result, err := tx.Exec(ctx, query, refreshTokenHash, refreshTokenExpiresAt, id)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
+
+func (repository *OauthGrantRepositoryImplementation) Revoke(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error) {
+	query := `UPDATE oauth_grants SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL;`
+	result, err := tx.Exec(ctx, query, id)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
+
+func (repository *OauthGrantRepositoryImplementation) RevokeByUserId(tx pgx.Tx, ctx context.Context, userId int) (rowsAffected int64, err error) {
+	query := `UPDATE oauth_grants SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL;`
+	result, err := tx.Exec(ctx, query, userId)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
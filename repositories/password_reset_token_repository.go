@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	modelentities "todo-list-api/models/entities"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PasswordResetTokenRepository interface {
+	Create(tx pgx.Tx, ctx context.Context, passwordResetToken modelentities.PasswordResetToken) (lastInsertedId int, err error)
+	FindByTokenHash(tx pgx.Tx, ctx context.Context, tokenHash string) (passwordResetToken modelentities.PasswordResetToken, err error)
+	UpdateUsedAtByUserId(tx pgx.Tx, ctx context.Context, userId int) (rowsAffected int64, err error)
+}
+
+type PasswordResetTokenRepositoryImplementation struct {
+}
+
+func NewPasswordResetTokenRepository() PasswordResetTokenRepository {
+	return &PasswordResetTokenRepositoryImplementation{}
+}
+
+func (repository *PasswordResetTokenRepositoryImplementation) Create(tx pgx.Tx, ctx context.Context, passwordResetToken modelentities.PasswordResetToken) (lastInsertedId int, err error) {
+	query := `INSERT INTO password_reset_tokens (user_id,token_hash,expires_at) VALUES ($1,$2,$3) RETURNING id;`
+	err = tx.QueryRow(ctx, query, passwordResetToken.UserId, passwordResetToken.TokenHash, passwordResetToken.ExpiresAt).Scan(&lastInsertedId)
+	return
+}
+
+func (repository *PasswordResetTokenRepositoryImplementation) FindByTokenHash(tx pgx.Tx, ctx context.Context, tokenHash string) (passwordResetToken modelentities.PasswordResetToken, err error) {
+	query := `SELECT id,user_id,token_hash,expires_at,used_at FROM password_reset_tokens WHERE token_hash = $1 FOR UPDATE;`
+	err = tx.QueryRow(ctx, query, tokenHash).Scan(&passwordResetToken.Id, &passwordResetToken.UserId, &passwordResetToken.TokenHash, &passwordResetToken.ExpiresAt, &passwordResetToken.UsedAt)
+	return
+}
+
+func (repository *PasswordResetTokenRepositoryImplementation) UpdateUsedAtByUserId(tx pgx.Tx, ctx context.Context, userId int) (rowsAffected int64, err error) {
+	query := `UPDATE password_reset_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL;`
+	result, err := tx.Exec(ctx, query, userId)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
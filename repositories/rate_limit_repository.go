@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RateLimitRepository interface {
+	TakeToken(pool *pgxpool.Pool, ctx context.Context, bucketKey string, limit int, windowSeconds float64, now time.Time) (remainingTokens float64, allowed bool, err error)
+	DeleteExpired(pool *pgxpool.Pool, ctx context.Context, now time.Time) (rowsAffected int64, err error)
+}
+
+type RateLimitRepositoryImplementation struct {
+}
+
+func NewRateLimitRepository() RateLimitRepository {
+	return &RateLimitRepositoryImplementation{}
+}
+
+// TakeToken refills the bucket for the time since its last request and takes a token when there is one, in a single
+// statement so concurrent requests from every instance queue on the row instead of reading the same count.
+// A bucket that does not exist yet starts full.
+func (repository *RateLimitRepositoryImplementation) TakeToken(pool *pgxpool.Pool, ctx context.Context, bucketKey string, limit int, windowSeconds float64, now time.Time) (remainingTokens float64, allowed bool, err error) {
+	refilledTokens := `LEAST($2::float8, rate_limit_buckets.tokens + GREATEST(0, EXTRACT(EPOCH FROM $4::timestamptz - rate_limit_buckets.updated_at)::float8) * $2::float8 / $3::float8)`
+	query := `INSERT INTO rate_limit_buckets (bucket_key,tokens,allowed,updated_at,expires_at)
+		VALUES ($1,$2::float8 - 1,TRUE,$4::timestamptz,$4::timestamptz + make_interval(secs => $3::float8))
+		ON CONFLICT (bucket_key) DO UPDATE SET
+		tokens = CASE WHEN ` + refilledTokens + ` >= 1 THEN ` + refilledTokens + ` - 1 ELSE ` + refilledTokens + ` END,
+		allowed = ` + refilledTokens + ` >= 1,
+		updated_at = GREATEST(rate_limit_buckets.updated_at, $4::timestamptz),
+		expires_at = GREATEST(rate_limit_buckets.updated_at, $4::timestamptz) + make_interval(secs => $3::float8)
+		RETURNING tokens,allowed;`
+	err = pool.QueryRow(ctx, query, bucketKey, float64(limit), windowSeconds, now).Scan(&remainingTokens, &allowed)
+	return
+}
+
+func (repository *RateLimitRepositoryImplementation) DeleteExpired(pool *pgxpool.Pool, ctx context.Context, now time.Time) (rowsAffected int64, err error) {
+	query := `DELETE FROM rate_limit_buckets WHERE expires_at < $1;`
+	result, err := pool.Exec(ctx, query, now)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
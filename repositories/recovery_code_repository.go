@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+	modelentities "todo-list-api/models/entities"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type RecoveryCodeRepository interface {
+	Create(tx pgx.Tx, ctx context.Context, recoveryCode modelentities.RecoveryCode) (lastInsertedId int, err error)
+	DeleteByUserId(tx pgx.Tx, ctx context.Context, userId int) (rowsAffected int64, err error)
+	UpdateUsedAtByUserIdAndCodeHash(tx pgx.Tx, ctx context.Context, userId int, codeHash string) (rowsAffected int64, err error)
+}
+
+type RecoveryCodeRepositoryImplementation struct {
+}
+
+func NewRecoveryCodeRepository() RecoveryCodeRepository {
+	return &RecoveryCodeRepositoryImplementation{}
+}
+
+func (repository *RecoveryCodeRepositoryImplementation) Create(tx pgx.Tx, ctx context.Context, recoveryCode modelentities.RecoveryCode) (lastInsertedId int, err error) {
+	query := `INSERT INTO user_recovery_codes (user_id,code_hash) VALUES ($1,$2) RETURNING id;`
+	err = tx.QueryRow(ctx, query, recoveryCode.UserId, recoveryCode.CodeHash).Scan(&lastInsertedId)
+	return
+}
+
+func (repository *RecoveryCodeRepositoryImplementation) DeleteByUserId(tx pgx.Tx, ctx context.Context, userId int) (rowsAffected int64, err error) {
+	query := `DELETE FROM user_recovery_codes WHERE user_id = $1;`
+	result, err := tx.Exec(ctx, query, userId)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
+
+func (repository *RecoveryCodeRepositoryImplementation) UpdateUsedAtByUserIdAndCodeHash(tx pgx.Tx, ctx context.Context, userId int, codeHash string) (rowsAffected int64, err error) {
+	query := `UPDATE user_recovery_codes SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;`
+	result, err := tx.Exec(ctx, query, userId, codeHash)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	modelentities "todo-list-api/models/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SecurityEventRepository interface {
+	Create(tx pgx.Tx, ctx context.Context, securityEvent modelentities.SecurityEvent) (lastInsertedId int, err error)
+	FindByFilterWithPagination(pool *pgxpool.Pool, ctx context.Context, securityEventFilter modelentities.SecurityEventFilter, offset int, limit int) (securityEvents []modelentities.SecurityEvent, err error)
+	CountByFilter(pool *pgxpool.Pool, ctx context.Context, securityEventFilter modelentities.SecurityEventFilter) (numberOfSecurityEvents int, err error)
+}
+
+type SecurityEventRepositoryImplementation struct {
+}
+
+func NewSecurityEventRepository() SecurityEventRepository {
+	return &SecurityEventRepositoryImplementation{}
+}
+
+// securityEventFilterCondition treats a zero user id, an empty string and a null time as no filter.
+const securityEventFilterCondition = `($1 = 0 OR user_id = $1) AND ($2 = '' OR event_type = $2) AND ($3 = '' OR ip_address = $3) AND ($4::timestamptz IS NULL OR created_at >= $4) AND ($5::timestamptz IS NULL OR created_at < $5)`
+
+func (repository *SecurityEventRepositoryImplementation) Create(tx pgx.Tx, ctx context.Context, securityEvent modelentities.SecurityEvent) (lastInsertedId int, err error) {
+	query := `INSERT INTO security_events (user_id,event_type,email,detail,ip_address,user_agent) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id;`
+	err = tx.QueryRow(ctx, query, securityEvent.UserId, securityEvent.EventType, securityEvent.Email, securityEvent.Detail, securityEvent.IpAddress, securityEvent.UserAgent).Scan(&lastInsertedId)
+	return
+}
+
+func (repository *SecurityEventRepositoryImplementation) FindByFilterWithPagination(pool *pgxpool.Pool, ctx context.Context, securityEventFilter modelentities.SecurityEventFilter, offset int, limit int) (securityEvents []modelentities.SecurityEvent, err error) {
+	query := `SELECT id,user_id,event_type,email,detail,ip_address,user_agent,created_at FROM security_events WHERE ` + securityEventFilterCondition + ` ORDER BY created_at DESC, id DESC OFFSET $6 LIMIT $7;`
+	rows, err := pool.Query(ctx, query, securityEventFilter.UserId, securityEventFilter.EventType, securityEventFilter.IpAddress, securityEventFilter.From, securityEventFilter.To, offset, limit)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var securityEvent modelentities.SecurityEvent
+		err = rows.Scan(&securityEvent.Id, &securityEvent.UserId, &securityEvent.EventType, &securityEvent.Email, &securityEvent.Detail, &securityEvent.IpAddress, &securityEvent.UserAgent, &securityEvent.CreatedAt)
+		if err != nil {
+			securityEvents = []modelentities.SecurityEvent{}
+			return
+		}
+		securityEvents = append(securityEvents, securityEvent)
+	}
+
+	if rows.Err() != nil {
+		securityEvents = []modelentities.SecurityEvent{}
+		err = rows.Err()
+		return
+	}
+	return
+}
+
+func (repository *SecurityEventRepositoryImplementation) CountByFilter(pool *pgxpool.Pool, ctx context.Context, securityEventFilter modelentities.SecurityEventFilter) (numberOfSecurityEvents int, err error) {
+	query := `SELECT COUNT(*) FROM security_events WHERE ` + securityEventFilterCondition + `;`
+	err = pool.QueryRow(ctx, query, securityEventFilter.UserId, securityEventFilter.EventType, securityEventFilter.IpAddress, securityEventFilter.From, securityEventFilter.To).Scan(&numberOfSecurityEvents)
+	return
+}
@@ -15,6 +15,9 @@ type TodoRepository interface {
 	Delete(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error)
 	FindByPagination(pool *pgxpool.Pool, ctx context.Context, userId int, offset int, limit int) (todos []modelentities.Todo, err error)
 	Count(pool *pgxpool.Pool, ctx context.Context) (numberOfTodos int, err error)
+	FindByUserId(tx pgx.Tx, ctx context.Context, userId int) (todos []modelentities.Todo, err error)
+	CountByUserIds(pool *pgxpool.Pool, ctx context.Context, userIds []int) (numberOfTodos map[int]int, err error)
+	FindStatistics(pool *pgxpool.Pool, ctx context.Context) (todoStatistics modelentities.TodoStatistics, err error)
 }
 
 type TodoRepositoryImplementation struct {
@@ -88,3 +91,64 @@ func (repository *TodoRepositoryImplementation) Count(pool *pgxpool.Pool, ctx co
 	err = pool.QueryRow(ctx, query).Scan(&numberOfTodos)
 	return
 }
+
+func (repository *TodoRepositoryImplementation) FindByUserId(tx pgx.Tx, ctx context.Context, userId int) (todos []modelentities.Todo, err error) {
+	query := `SELECT id, user_id, title, description FROM todos WHERE user_id = $1 ORDER BY id ASC;`
+	rows, err := tx.Query(ctx, query, userId)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var todo modelentities.Todo
+		err = rows.Scan(&todo.Id, &todo.UserId, &todo.Title, &todo.Description)
+		if err != nil {
+			todos = []modelentities.Todo{}
+			return
+		}
+		todos = append(todos, todo)
+	}
+
+	if rows.Err() != nil {
+		todos = []modelentities.Todo{}
+		err = rows.Err()
+		return
+	}
+	return
+}
+
+func (repository *TodoRepositoryImplementation) CountByUserIds(pool *pgxpool.Pool, ctx context.Context, userIds []int) (numberOfTodos map[int]int, err error) {
+	query := `SELECT user_id, COUNT(*) FROM todos WHERE user_id = ANY($1) GROUP BY user_id;`
+	rows, err := pool.Query(ctx, query, userIds)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	numberOfTodos = map[int]int{}
+	for rows.Next() {
+		var userId, count int
+		err = rows.Scan(&userId, &count)
+		if err != nil {
+			numberOfTodos = map[int]int{}
+			return
+		}
+		numberOfTodos[userId] = count
+	}
+
+	if rows.Err() != nil {
+		numberOfTodos = map[int]int{}
+		err = rows.Err()
+		return
+	}
+	return
+}
+
+func (repository *TodoRepositoryImplementation) FindStatistics(pool *pgxpool.Pool, ctx context.Context) (todoStatistics modelentities.TodoStatistics, err error) {
+	query := `SELECT (SELECT COUNT(*) FROM users), (SELECT COUNT(*) FROM users WHERE disabled_at IS NOT NULL), (SELECT COUNT(DISTINCT user_id) FROM todos), (SELECT COUNT(*) FROM todos);`
+	err = pool.QueryRow(ctx, query).Scan(&todoStatistics.NumberOfUsers, &todoStatistics.NumberOfDisabledUsers, &todoStatistics.NumberOfUsersWithTodos, &todoStatistics.NumberOfTodos)
+	return
+}
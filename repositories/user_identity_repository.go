@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	modelentities "todo-list-api/models/entities"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type UserIdentityRepository interface {
+	Create(tx pgx.Tx, ctx context.Context, userIdentity modelentities.UserIdentity) (lastInsertedId int, err error)
+	FindByProviderAndSubject(tx pgx.Tx, ctx context.Context, provider string, subject string) (userIdentity modelentities.UserIdentity, err error)
+	UpdateLastLoginAt(tx pgx.Tx, ctx context.Context, email string, id int) (rowsAffected int64, err error)
+}
+
+type UserIdentityRepositoryImplementation struct {
+}
+
+func NewUserIdentityRepository() UserIdentityRepository {
+	return &UserIdentityRepositoryImplementation{}
+}
+
+func (repository *UserIdentityRepositoryImplementation) Create(tx pgx.Tx, ctx context.Context, userIdentity modelentities.UserIdentity) (lastInsertedId int, err error) {
+	query := `INSERT INTO user_identities (user_id,provider,subject,email,last_login_at) VALUES ($1,$2,$3,$4,NOW()) RETURNING id;`
+	err = tx.QueryRow(ctx, query, userIdentity.UserId, userIdentity.Provider, userIdentity.Subject, userIdentity.Email).Scan(&lastInsertedId)
+	return
+}
+
+func (repository *UserIdentityRepositoryImplementation) FindByProviderAndSubject(tx pgx.Tx, ctx context.Context, provider string, subject string) (userIdentity modelentities.UserIdentity, err error) {
+	query := `SELECT id,user_id,provider,subject,email,last_login_at FROM user_identities WHERE provider = $1 AND subject = $2;`
+	err = tx.QueryRow(ctx, query, provider, subject).Scan(&userIdentity.Id, &userIdentity.UserId, &userIdentity.Provider, &userIdentity.Subject, &userIdentity.Email, &userIdentity.LastLoginAt)
+	return
+}
+
+func (repository *UserIdentityRepositoryImplementation) UpdateLastLoginAt(tx pgx.Tx, ctx context.Context, email string, id int) (rowsAffected int64, err error) {
+	query := `UPDATE user_identities SET email = $1, last_login_at = NOW() WHERE id = $2;`
+	result, err := tx.Exec(ctx, query, email, id)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
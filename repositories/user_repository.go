@@ -5,6 +5,7 @@ import (
 	modelentities "todo-list-api/models/entities"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -13,6 +14,25 @@ type UserRepository interface {
 	UpdateRefreshToken(tx pgx.Tx, ctx context.Context, refreshToken string, id int) (rowsAffected int64, err error)
 	FindByEmail(tx pgx.Tx, ctx context.Context, email string) (user modelentities.User, err error)
 	FindByRefreshToken(pool *pgxpool.Pool, ctx context.Context, refreshToken string) (user modelentities.User, err error)
+	UpdatePassword(tx pgx.Tx, ctx context.Context, password string, id int) (rowsAffected int64, err error)
+	FindById(tx pgx.Tx, ctx context.Context, id int) (user modelentities.User, err error)
+	UpdateEmailVerifiedAt(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error)
+	UpdateEmailVerificationSentAt(tx pgx.Tx, ctx context.Context, id int, resendInterval int) (rowsAffected int64, err error)
+	UpdateTotpSecret(tx pgx.Tx, ctx context.Context, totpSecret string, id int) (rowsAffected int64, err error)
+	EnableTotp(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error)
+	DisableTotp(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error)
+	UpdateTotpLastUsedStep(tx pgx.Tx, ctx context.Context, step int64, id int) (rowsAffected int64, err error)
+	UpdateName(tx pgx.Tx, ctx context.Context, name string, id int) (rowsAffected int64, err error)
+	UpdateEmail(tx pgx.Tx, ctx context.Context, email string, id int) (rowsAffected int64, err error)
+	ScheduleDeletion(tx pgx.Tx, ctx context.Context, gracePeriod int, id int) (deletionScheduledAt pgtype.Timestamptz, err error)
+	CancelDeletion(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error)
+	DeleteScheduled(tx pgx.Tx, ctx context.Context) (rowsAffected int64, err error)
+	FindSessionById(pool *pgxpool.Pool, ctx context.Context, id int) (user modelentities.User, err error)
+	FindBySearchWithPagination(pool *pgxpool.Pool, ctx context.Context, search string, offset int, limit int) (users []modelentities.User, err error)
+	CountBySearch(pool *pgxpool.Pool, ctx context.Context, search string) (numberOfUsers int, err error)
+	Disable(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error)
+	Enable(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error)
+	RevokeSessions(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error)
 }
 
 type UserRepositoryImplementation struct {
@@ -38,14 +58,225 @@ func (repository *UserRepositoryImplementation) UpdateRefreshToken(tx pgx.Tx, ct
 	return
 }
 
+// FindByEmail ignores the case of the email, the oidc providers and the users do not always write it the same way.
 func (repository *UserRepositoryImplementation) FindByEmail(tx pgx.Tx, ctx context.Context, email string) (user modelentities.User, err error) {
-	query := `SELECT id,name,email,password FROM users WHERE email = $1;`
-	err = tx.QueryRow(ctx, query, email).Scan(&user.Id, &user.Name, &user.Email, &user.Password)
+	query := `SELECT id,name,email,password,email_verified_at,totp_enabled_at,deletion_scheduled_at,disabled_at FROM users WHERE LOWER(email) = LOWER($1);`
+	err = tx.QueryRow(ctx, query, email).Scan(&user.Id, &user.Name, &user.Email, &user.Password, &user.EmailVerifiedAt, &user.TotpEnabledAt, &user.DeletionScheduledAt, &user.DisabledAt)
 	return
 }
 
 func (repository *UserRepositoryImplementation) FindByRefreshToken(pool *pgxpool.Pool, ctx context.Context, refreshToken string) (user modelentities.User, err error) {
-	query := `SELECT id,name,email,password,refresh_token FROM users WHERE refresh_token = $1;`
+	query := `SELECT id,name,email,password,refresh_token FROM users WHERE refresh_token = $1 AND disabled_at IS NULL;`
 	err = pool.QueryRow(ctx, query, refreshToken).Scan(&user.Id, &user.Name, &user.Email, &user.Password, &user.RefreshToken)
 	return
 }
+
+func (repository *UserRepositoryImplementation) UpdatePassword(tx pgx.Tx, ctx context.Context, password string, id int) (rowsAffected int64, err error) {
+	query := `UPDATE users SET password = $1 WHERE id = $2;`
+	result, err := tx.Exec(ctx, query, password, id)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
+
+func (repository *UserRepositoryImplementation) FindById(tx pgx.Tx, ctx context.Context, id int) (user modelentities.User, err error) {
+	query := `SELECT id,name,email,password,email_verified_at,email_verification_sent_at,totp_secret,totp_enabled_at,deletion_scheduled_at,role,disabled_at FROM users WHERE id = $1;`
+	err = tx.QueryRow(ctx, query, id).Scan(&user.Id, &user.Name, &user.Email, &user.Password, &user.EmailVerifiedAt, &user.EmailVerificationSentAt, &user.TotpSecret, &user.TotpEnabledAt, &user.DeletionScheduledAt, &user.Role, &user.DisabledAt)
+	return
+}
+
+func (repository *UserRepositoryImplementation) UpdateEmailVerifiedAt(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error) {
+	query := `UPDATE users SET email_verified_at = NOW() WHERE id = $1 AND email_verified_at IS NULL;`
+	result, err := tx.Exec(ctx, query, id)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
+
+// UpdateEmailVerificationSentAt only updates when the last verification email is older than resendInterval seconds,
+// zero rows affected means the caller has to wait before sending another one.
+func (repository *UserRepositoryImplementation) UpdateEmailVerificationSentAt(tx pgx.Tx, ctx context.Context, id int, resendInterval int) (rowsAffected int64, err error) {
+	query := `UPDATE users SET email_verification_sent_at = NOW() WHERE id = $1 AND (email_verification_sent_at IS NULL OR email_verification_sent_at <= NOW() - make_interval(secs => $2));`
+	result, err := tx.Exec(ctx, query, id, resendInterval)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
+
+func (repository *UserRepositoryImplementation) UpdateTotpSecret(tx pgx.Tx, ctx context.Context, totpSecret string, id int) (rowsAffected int64, err error) {
+	query := `UPDATE users SET totp_secret = $1, totp_enabled_at = NULL, totp_last_used_step = NULL WHERE id = $2 AND totp_enabled_at IS NULL;`
+	result, err := tx.Exec(ctx, query, totpSecret, id)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
+
+func (repository *UserRepositoryImplementation) EnableTotp(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error) {
+	query := `UPDATE users SET totp_enabled_at = NOW() WHERE id = $1 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL;`
+	result, err := tx.Exec(ctx, query, id)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
+
+func (repository *UserRepositoryImplementation) DisableTotp(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error) {
+	query := `UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_used_step = NULL WHERE id = $1;`
+	result, err := tx.Exec(ctx, query, id)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
+
+// UpdateTotpLastUsedStep only moves forward, zero rows affected means the code has already been used.
+func (repository *UserRepositoryImplementation) UpdateTotpLastUsedStep(tx pgx.Tx, ctx context.Context, step int64, id int) (rowsAffected int64, err error) {
+	query := `UPDATE users SET totp_last_used_step = $1 WHERE id = $2 AND (totp_last_used_step IS NULL OR totp_last_used_step < $1);`
+	result, err := tx.Exec(ctx, query, step, id)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
+
+func (repository *UserRepositoryImplementation) UpdateName(tx pgx.Tx, ctx context.Context, name string, id int) (rowsAffected int64, err error) {
+	query := `UPDATE users SET name = $1 WHERE id = $2;`
+	result, err := tx.Exec(ctx, query, name, id)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
+
+// UpdateEmail marks the new address as unverified, the verification mail for it counts as just sent.
+func (repository *UserRepositoryImplementation) UpdateEmail(tx pgx.Tx, ctx context.Context, email string, id int) (rowsAffected int64, err error) {
+	query := `UPDATE users SET email = $1, email_verified_at = NULL, email_verification_sent_at = NOW() WHERE id = $2;`
+	result, err := tx.Exec(ctx, query, email, id)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
+
+// ScheduleDeletion only sets the date, the sessions are ended with RevokeSessions.
+func (repository *UserRepositoryImplementation) ScheduleDeletion(tx pgx.Tx, ctx context.Context, gracePeriod int, id int) (deletionScheduledAt pgtype.Timestamptz, err error) {
+	query := `UPDATE users SET deletion_scheduled_at = NOW() + make_interval(days => $1) WHERE id = $2 RETURNING deletion_scheduled_at;`
+	err = tx.QueryRow(ctx, query, gracePeriod, id).Scan(&deletionScheduledAt)
+	return
+}
+
+func (repository *UserRepositoryImplementation) CancelDeletion(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error) {
+	query := `UPDATE users SET deletion_scheduled_at = NULL WHERE id = $1;`
+	result, err := tx.Exec(ctx, query, id)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
+
+// DeleteScheduled removes the users whose grace period is over. Their todos, tokens and recovery codes go with them through
+// ON DELETE CASCADE, the login attempt rows are keyed by email and are removed here. The security events stay for the
+// audit trail, but without the email, the ip address and the user agent, the events of failed logins with the email of
+// a deleted user included.
+func (repository *UserRepositoryImplementation) DeleteScheduled(tx pgx.Tx, ctx context.Context) (rowsAffected int64, err error) {
+	query := `WITH deleted_users AS (
+			DELETE FROM users WHERE deletion_scheduled_at <= NOW() RETURNING id, LOWER(email) AS email, 'email:' || LOWER(email) AS attempt_key
+		), deleted_login_attempts AS (
+			DELETE FROM login_attempts WHERE attempt_key IN (SELECT attempt_key FROM deleted_users)
+		), deleted_login_lockout_events AS (
+			DELETE FROM login_lockout_events WHERE attempt_key IN (SELECT attempt_key FROM deleted_users)
+		), anonymised_security_events AS (
+			UPDATE security_events SET email = NULL, ip_address = NULL, user_agent = NULL,
+			detail = CASE WHEN detail LIKE 'email:%' OR detail LIKE 'ip:%' THEN NULL ELSE detail END
+			WHERE user_id IN (SELECT id FROM deleted_users) OR LOWER(email) IN (SELECT email FROM deleted_users)
+		)
+		SELECT COUNT(*) FROM deleted_users;`
+	err = tx.QueryRow(ctx, query).Scan(&rowsAffected)
+	return
+}
+
+func (repository *UserRepositoryImplementation) FindSessionById(pool *pgxpool.Pool, ctx context.Context, id int) (user modelentities.User, err error) {
+	query := `SELECT id,name,email,role,disabled_at,sessions_revoked_at FROM users WHERE id = $1;`
+	err = pool.QueryRow(ctx, query, id).Scan(&user.Id, &user.Name, &user.Email, &user.Role, &user.DisabledAt, &user.SessionsRevokedAt)
+	return
+}
+
+// FindBySearchWithPagination matches search against the name and the email, an empty search lists every user.
+func (repository *UserRepositoryImplementation) FindBySearchWithPagination(pool *pgxpool.Pool, ctx context.Context, search string, offset int, limit int) (users []modelentities.User, err error) {
+	query := `SELECT id,name,email,role,email_verified_at,totp_enabled_at,deletion_scheduled_at,disabled_at FROM users WHERE $1 = '' OR name ILIKE '%' || $1 || '%' OR email ILIKE '%' || $1 || '%' ORDER BY id ASC OFFSET $2 LIMIT $3;`
+	rows, err := pool.Query(ctx, query, search, offset, limit)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var user modelentities.User
+		err = rows.Scan(&user.Id, &user.Name, &user.Email, &user.Role, &user.EmailVerifiedAt, &user.TotpEnabledAt, &user.DeletionScheduledAt, &user.DisabledAt)
+		if err != nil {
+			users = []modelentities.User{}
+			return
+		}
+		users = append(users, user)
+	}
+
+	if rows.Err() != nil {
+		users = []modelentities.User{}
+		err = rows.Err()
+		return
+	}
+	return
+}
+
+func (repository *UserRepositoryImplementation) CountBySearch(pool *pgxpool.Pool, ctx context.Context, search string) (numberOfUsers int, err error) {
+	query := `SELECT COUNT(*) FROM users WHERE $1 = '' OR name ILIKE '%' || $1 || '%' OR email ILIKE '%' || $1 || '%';`
+	err = pool.QueryRow(ctx, query, search).Scan(&numberOfUsers)
+	return
+}
+
+func (repository *UserRepositoryImplementation) Disable(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error) {
+	query := `UPDATE users SET disabled_at = NOW(), refresh_token = NULL, sessions_revoked_at = date_trunc('second', NOW()) WHERE id = $1 AND disabled_at IS NULL;`
+	result, err := tx.Exec(ctx, query, id)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
+
+func (repository *UserRepositoryImplementation) Enable(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error) {
+	query := `UPDATE users SET disabled_at = NULL WHERE id = $1 AND disabled_at IS NOT NULL;`
+	result, err := tx.Exec(ctx, query, id)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
+
+// RevokeSessions ends every session, access tokens issued before sessions_revoked_at are refused by the authenticate middleware.
+// sessions_revoked_at is cut to the second like the iat of a token, so a token issued right after the revocation works.
+func (repository *UserRepositoryImplementation) RevokeSessions(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error) {
+	query := `UPDATE users SET refresh_token = NULL, sessions_revoked_at = date_trunc('second', NOW()) WHERE id = $1;`
+	result, err := tx.Exec(ctx, query, id)
+	if err != nil {
+		return
+	}
+	rowsAffected = result.RowsAffected()
+	return
+}
@@ -2,20 +2,89 @@ package routes
 
 import (
 	"todo-list-api/controllers"
+	"todo-list-api/helpers"
 	"todo-list-api/middlewares"
 
 	"github.com/labstack/echo/v4"
 )
 
-func UserRoute(e *echo.Echo, controller controllers.UserController) {
+func UserRoute(e *echo.Echo, controller controllers.UserController, authenticate echo.MiddlewareFunc) {
 	e.POST("/register", controller.Register)
 	e.POST("/login", controller.Login)
 	e.POST("/refresh-token", controller.RefershToken)
+	e.GET("/verify-email", controller.VerifyEmail)
+	e.POST("/verify-email/resend", controller.ResendVerificationEmail, authenticate)
+	e.POST("/logout", controller.Logout, authenticate)
 }
 
-func TodoRoute(e *echo.Echo, controller controllers.TodoController) {
-	e.POST("/todos", controller.Create, middlewares.Authenticate)
-	e.PUT("/todos/:id", controller.Update, middlewares.Authenticate)
-	e.DELETE("/todos/:id", controller.Delete, middlewares.Authenticate)
-	e.GET("/todos", controller.FindWithPagination, middlewares.Authenticate)
+func TodoRoute(e *echo.Echo, controller controllers.TodoController, authenticateWithScope func(scope string) echo.MiddlewareFunc) {
+	e.POST("/todos", controller.Create, authenticateWithScope(helpers.OauthScopeTodosWrite))
+	e.PUT("/todos/:id", controller.Update, authenticateWithScope(helpers.OauthScopeTodosWrite))
+	e.DELETE("/todos/:id", controller.Delete, authenticateWithScope(helpers.OauthScopeTodosWrite))
+	e.GET("/todos", controller.FindWithPagination, authenticateWithScope(helpers.OauthScopeTodosRead))
+}
+
+func PasswordRoute(e *echo.Echo, controller controllers.PasswordController) {
+	e.POST("/password/forgot", controller.ForgotPassword)
+	e.POST("/password/reset", controller.ResetPassword)
+}
+
+func TwoFactorRoute(e *echo.Echo, controller controllers.TwoFactorController, authenticate echo.MiddlewareFunc) {
+	e.POST("/login/2fa", controller.Login)
+	e.POST("/2fa/enroll", controller.Enroll, authenticate)
+	e.POST("/2fa/confirm", controller.Confirm, authenticate)
+	e.POST("/2fa/disable", controller.Disable, authenticate)
+}
+
+func JwksRoute(e *echo.Echo, controller controllers.JwksController) {
+	e.GET("/.well-known/jwks.json", controller.Jwks)
+}
+
+func MetricsRoute(e *echo.Echo, controller controllers.MetricsController) {
+	e.GET("/metrics", controller.Metrics)
+}
+
+func ProfileRoute(e *echo.Echo, controller controllers.ProfileController, authenticate echo.MiddlewareFunc, authenticateWithScope func(scope string) echo.MiddlewareFunc) {
+	e.GET("/me", controller.Get, authenticateWithScope(helpers.OauthScopeProfileRead))
+	e.PATCH("/me", controller.Update, authenticate)
+	e.POST("/me/password", controller.ChangePassword, authenticate)
+	e.POST("/me/email", controller.ChangeEmail, authenticate)
+}
+
+func AccountRoute(e *echo.Echo, controller controllers.AccountController, authenticate echo.MiddlewareFunc) {
+	e.DELETE("/me", controller.Delete, authenticate)
+	e.GET("/me/export", controller.Export, authenticate)
+}
+
+func AdminRoute(e *echo.Echo, controller controllers.AdminController, authenticate echo.MiddlewareFunc) {
+	admin := e.Group("/admin", authenticate)
+	admin.GET("/users", controller.FindUsers, middlewares.Authorize(helpers.PermissionManageUsers))
+	admin.POST("/users/:id/disable", controller.Disable, middlewares.Authorize(helpers.PermissionManageUsers))
+	admin.POST("/users/:id/enable", controller.Enable, middlewares.Authorize(helpers.PermissionManageUsers))
+	admin.POST("/users/:id/logout", controller.Logout, middlewares.Authorize(helpers.PermissionManageUsers))
+	admin.GET("/statistics/todos", controller.TodoStatistics, middlewares.Authorize(helpers.PermissionViewStatistics))
+}
+
+func OidcRoute(e *echo.Echo, controller controllers.OidcController) {
+	e.GET("/oidc/:provider/login", controller.Start)
+	e.GET("/oidc/:provider/callback", controller.Callback)
+}
+
+func OauthRoute(e *echo.Echo, controller controllers.OauthController, authenticate echo.MiddlewareFunc) {
+	e.GET("/oauth/authorize", controller.Authorize, authenticate)
+	e.POST("/oauth/authorize", controller.Approve, authenticate)
+	e.POST("/oauth/token", controller.Token)
+	e.POST("/oauth/revoke", controller.Revoke)
+	e.POST("/oauth/introspect", controller.Introspect)
+}
+
+func OauthClientRoute(e *echo.Echo, controller controllers.OauthClientController, authenticate echo.MiddlewareFunc) {
+	e.POST("/oauth/clients", controller.Create, authenticate)
+	e.GET("/oauth/clients", controller.FindAll, authenticate)
+	e.DELETE("/oauth/clients/:clientId", controller.Delete, authenticate)
+}
+
+func SecurityEventRoute(e *echo.Echo, controller controllers.SecurityEventController, authenticate echo.MiddlewareFunc) {
+	e.GET("/me/security-events", controller.FindMine, authenticate)
+	e.GET("/admin/security-events", controller.FindAll, authenticate, middlewares.Authorize(helpers.PermissionViewSecurityEvents))
 }
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	modelrequests "todo-list-api/models/requests"
+	modelresponses "todo-list-api/models/responses"
+	"todo-list-api/repositories"
+	"todo-list-api/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type AccountService interface {
+	Delete(ctx context.Context, deleteAccountRequest modelrequests.DeleteAccountRequest) (httpCode int, response interface{})
+	Export(ctx context.Context) (httpCode int, archive []byte, response interface{})
+	PurgeDeleted(ctx context.Context) (deletedAccounts int64, err error)
+}
+
+type AccountServiceImplementation struct {
+	PostgresUtil            utils.PostgresUtil
+	Validate                *validator.Validate
+	UserRepository          repositories.UserRepository
+	TodoRepository          repositories.TodoRepository
+	OauthGrantRepository    repositories.OauthGrantRepository
+	SecurityEventRepository repositories.SecurityEventRepository
+	PasswordHasher          helpers.PasswordHasher
+	Config                  config.Config
+}
+
+func NewAccountService(postgresUtil utils.PostgresUtil, validate *validator.Validate, userRepository repositories.UserRepository, todoRepository repositories.TodoRepository, oauthGrantRepository repositories.OauthGrantRepository, securityEventRepository repositories.SecurityEventRepository, passwordHasher helpers.PasswordHasher, config config.Config) AccountService {
+	return &AccountServiceImplementation{
+		PostgresUtil:            postgresUtil,
+		Validate:                validate,
+		UserRepository:          userRepository,
+		TodoRepository:          todoRepository,
+		OauthGrantRepository:    oauthGrantRepository,
+		SecurityEventRepository: securityEventRepository,
+		PasswordHasher:          passwordHasher,
+		Config:                  config,
+	}
+}
+
+// Delete only schedules the deletion, logging in again before ACCOUNT_DELETION_GRACE_DAYS have passed cancels it.
+// Every session and every oauth grant ends right away, a client the user approved has to be approved again.
+func (service *AccountServiceImplementation) Delete(ctx context.Context, deleteAccountRequest modelrequests.DeleteAccountRequest) (httpCode int, response interface{}) {
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+
+	err := service.Validate.Struct(deleteAccountRequest)
+	if err != nil {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	user, err := service.UserRepository.FindById(tx, ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	_, err = service.PasswordHasher.Verify(user.Password.String, deleteAccountRequest.Password)
+	if err != nil && err != helpers.ErrPasswordMismatch {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == helpers.ErrPasswordMismatch {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("wrong password")
+		return
+	}
+
+	deletionScheduledAt, err := service.UserRepository.ScheduleDeletion(tx, ctx, service.Config.Account.DeletionGraceDays, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	_, err = service.UserRepository.RevokeSessions(tx, ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	_, err = service.OauthGrantRepository.RevokeByUserId(tx, ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	err = recordSecurityEvent(tx, ctx, service.SecurityEventRepository, principal.Id, user.Email.String, helpers.SecurityEventDeletionScheduled, actorDetail(principal))
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode = http.StatusAccepted
+	response = modelresponses.DeleteAccountResponse{
+		Message:             "account will be deleted, log in again before then to cancel",
+		DeletionScheduledAt: deletionScheduledAt.Time,
+	}
+	return
+}
+
+func (service *AccountServiceImplementation) Export(ctx context.Context) (httpCode int, archive []byte, response interface{}) {
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+
+	// a repeatable read transaction makes the profile and the todos one consistent snapshot
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			archive = nil
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	user, err := service.UserRepository.FindById(tx, ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	todos, err := service.TodoRepository.FindByUserId(tx, ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	exportProfileResponse := modelresponses.ExportProfileResponse{
+		Id:                  int(user.Id.Int32),
+		Name:                user.Name.String,
+		Email:               user.Email.String,
+		EmailVerifiedAt:     timePointer(user.EmailVerifiedAt),
+		TwoFactorEnabledAt:  timePointer(user.TotpEnabledAt),
+		DeletionScheduledAt: timePointer(user.DeletionScheduledAt),
+	}
+	todoResponses := []modelresponses.TodoResponse{}
+	for _, todo := range todos {
+		todoResponses = append(todoResponses, modelresponses.TodoResponse{
+			Id:          int(todo.Id.Int32),
+			Title:       todo.Title.String,
+			Description: todo.Description.String,
+		})
+	}
+
+	archive, err = buildExportArchive([]exportFile{
+		{name: "profile.json", content: exportProfileResponse},
+		{name: "todos.json", content: todoResponses},
+	})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode = http.StatusOK
+	return
+}
+
+func (service *AccountServiceImplementation) PurgeDeleted(ctx context.Context) (deletedAccounts int64, err error) {
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			deletedAccounts = 0
+			err = errCommitOrRollback
+		}
+	}()
+
+	deletedAccounts, err = service.UserRepository.DeleteScheduled(tx, ctx)
+	return
+}
+
+func timePointer(timestamptz pgtype.Timestamptz) *time.Time {
+	if !timestamptz.Valid {
+		return nil
+	}
+	return &timestamptz.Time
+}
+
+type exportFile struct {
+	name    string
+	content interface{}
+}
+
+func buildExportArchive(exportFiles []exportFile) (archive []byte, err error) {
+	var buffer bytes.Buffer
+	zipWriter := zip.NewWriter(&buffer)
+	for _, file := range exportFiles {
+		var fileWriter io.Writer
+		fileWriter, err = zipWriter.Create(file.name)
+		if err != nil {
+			return
+		}
+		encoder := json.NewEncoder(fileWriter)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(file.content)
+		if err != nil {
+			return
+		}
+	}
+	err = zipWriter.Close()
+	if err != nil {
+		return
+	}
+	archive = buffer.Bytes()
+	return
+}
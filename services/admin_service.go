@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	modelresponses "todo-list-api/models/responses"
+	"todo-list-api/repositories"
+	"todo-list-api/utils"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type AdminService interface {
+	FindUsers(ctx context.Context, search string, page int, limit int) (httpCode int, response interface{})
+	Disable(ctx context.Context, id int) (httpCode int, response interface{})
+	Enable(ctx context.Context, id int) (httpCode int, response interface{})
+	Logout(ctx context.Context, id int) (httpCode int, response interface{})
+	TodoStatistics(ctx context.Context) (httpCode int, response interface{})
+}
+
+type AdminServiceImplementation struct {
+	PostgresUtil            utils.PostgresUtil
+	UserRepository          repositories.UserRepository
+	TodoRepository          repositories.TodoRepository
+	SecurityEventRepository repositories.SecurityEventRepository
+}
+
+// NewAdminService records every change to an account as a security event of that account, with the admin who made it,
+// or cli for the commands, as detail.
+func NewAdminService(postgresUtil utils.PostgresUtil, userRepository repositories.UserRepository, todoRepository repositories.TodoRepository, securityEventRepository repositories.SecurityEventRepository) AdminService {
+	return &AdminServiceImplementation{
+		PostgresUtil:            postgresUtil,
+		UserRepository:          userRepository,
+		TodoRepository:          todoRepository,
+		SecurityEventRepository: securityEventRepository,
+	}
+}
+
+func (service *AdminServiceImplementation) FindUsers(ctx context.Context, search string, page int, limit int) (httpCode int, response interface{}) {
+	if page < 1 || limit < 1 || limit > 100 {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("page must be at least 1 and limit between 1 and 100")
+		return
+	}
+
+	offset := (page - 1) * limit
+	users, err := service.UserRepository.FindBySearchWithPagination(service.PostgresUtil.GetPool(), ctx, search, offset, limit)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	numberOfUsers, err := service.UserRepository.CountBySearch(service.PostgresUtil.GetPool(), ctx, search)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	userIds := make([]int, 0, len(users))
+	for _, user := range users {
+		userIds = append(userIds, int(user.Id.Int32))
+	}
+	numberOfTodos, err := service.TodoRepository.CountByUserIds(service.PostgresUtil.GetPool(), ctx, userIds)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	adminUserResponses := []modelresponses.AdminUserResponse{}
+	for _, user := range users {
+		adminUserResponses = append(adminUserResponses, toAdminUserResponse(user, numberOfTodos[int(user.Id.Int32)]))
+	}
+
+	var getAdminUserResponse modelresponses.GetAdminUserResponse
+	getAdminUserResponse.Data = adminUserResponses
+	getAdminUserResponse.Page = page
+	getAdminUserResponse.Limit = limit
+	getAdminUserResponse.Total = numberOfUsers
+
+	httpCode = http.StatusOK
+	response = getAdminUserResponse
+	return
+}
+
+func (service *AdminServiceImplementation) Disable(ctx context.Context, id int) (httpCode int, response interface{}) {
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+	// an admin disabling their own account could leave nobody able to enable it again
+	if principal.Id == id {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("cannot disable your own account")
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	user, err := service.UserRepository.FindById(tx, ctx, id)
+	if err != nil && err != pgx.ErrNoRows {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == pgx.ErrNoRows {
+		err = nil
+		httpCode = http.StatusNotFound
+		response = helpers.ToResponse("cannot find user")
+		return
+	}
+	if user.DisabledAt.Valid {
+		httpCode = http.StatusConflict
+		response = helpers.ToResponse("account is already disabled")
+		return
+	}
+
+	_, err = service.UserRepository.Disable(tx, ctx, id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	err = recordSecurityEvent(tx, ctx, service.SecurityEventRepository, id, user.Email.String, helpers.SecurityEventAccountDisabled, actorDetail(principal))
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode = http.StatusOK
+	response = helpers.ToResponse("account disabled")
+	return
+}
+
+func (service *AdminServiceImplementation) Enable(ctx context.Context, id int) (httpCode int, response interface{}) {
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	user, err := service.UserRepository.FindById(tx, ctx, id)
+	if err != nil && err != pgx.ErrNoRows {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == pgx.ErrNoRows {
+		err = nil
+		httpCode = http.StatusNotFound
+		response = helpers.ToResponse("cannot find user")
+		return
+	}
+	if !user.DisabledAt.Valid {
+		httpCode = http.StatusConflict
+		response = helpers.ToResponse("account is not disabled")
+		return
+	}
+
+	_, err = service.UserRepository.Enable(tx, ctx, id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	err = recordSecurityEvent(tx, ctx, service.SecurityEventRepository, id, user.Email.String, helpers.SecurityEventAccountEnabled, actorDetail(principal))
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode = http.StatusOK
+	response = helpers.ToResponse("account enabled")
+	return
+}
+
+func (service *AdminServiceImplementation) Logout(ctx context.Context, id int) (httpCode int, response interface{}) {
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	rowsAffected, err := service.UserRepository.RevokeSessions(tx, ctx, id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if rowsAffected != 1 {
+		httpCode = http.StatusNotFound
+		response = helpers.ToResponse("cannot find user")
+		return
+	}
+	err = recordSecurityEvent(tx, ctx, service.SecurityEventRepository, id, "", helpers.SecurityEventSessionsRevoked, actorDetail(principal))
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode = http.StatusOK
+	response = helpers.ToResponse("every session of the user has been logged out")
+	return
+}
+
+func (service *AdminServiceImplementation) TodoStatistics(ctx context.Context) (httpCode int, response interface{}) {
+	todoStatistics, err := service.TodoRepository.FindStatistics(service.PostgresUtil.GetPool(), ctx)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	var todoStatisticsResponse modelresponses.TodoStatisticsResponse
+	todoStatisticsResponse.NumberOfUsers = todoStatistics.NumberOfUsers
+	todoStatisticsResponse.NumberOfDisabledUsers = todoStatistics.NumberOfDisabledUsers
+	todoStatisticsResponse.NumberOfUsersWithTodos = todoStatistics.NumberOfUsersWithTodos
+	todoStatisticsResponse.NumberOfTodos = todoStatistics.NumberOfTodos
+	if todoStatistics.NumberOfUsers > 0 {
+		todoStatisticsResponse.AverageTodosPerUser = float64(todoStatistics.NumberOfTodos) / float64(todoStatistics.NumberOfUsers)
+	}
+
+	httpCode = http.StatusOK
+	response = todoStatisticsResponse
+	return
+}
+
+func toAdminUserResponse(user modelentities.User, numberOfTodos int) modelresponses.AdminUserResponse {
+	return modelresponses.AdminUserResponse{
+		Id:                  int(user.Id.Int32),
+		Name:                user.Name.String,
+		Email:               user.Email.String,
+		Role:                user.Role.String,
+		EmailVerified:       user.EmailVerifiedAt.Valid,
+		TwoFactorEnabled:    user.TotpEnabledAt.Valid,
+		DisabledAt:          timePointer(user.DisabledAt),
+		DeletionScheduledAt: timePointer(user.DeletionScheduledAt),
+		NumberOfTodos:       numberOfTodos,
+	}
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	modelrequests "todo-list-api/models/requests"
+	modelresponses "todo-list-api/models/responses"
+	"todo-list-api/repositories"
+	"todo-list-api/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type OauthClientService interface {
+	Create(ctx context.Context, createOauthClientRequest modelrequests.CreateOauthClientRequest) (httpCode int, response interface{})
+	FindAll(ctx context.Context) (httpCode int, response interface{})
+	Delete(ctx context.Context, clientId string) (httpCode int, response interface{})
+}
+
+type OauthClientServiceImplementation struct {
+	PostgresUtil          utils.PostgresUtil
+	Validate              *validator.Validate
+	OauthClientRepository repositories.OauthClientRepository
+}
+
+func NewOauthClientService(postgresUtil utils.PostgresUtil, validate *validator.Validate, oauthClientRepository repositories.OauthClientRepository) OauthClientService {
+	return &OauthClientServiceImplementation{
+		PostgresUtil:          postgresUtil,
+		Validate:              validate,
+		OauthClientRepository: oauthClientRepository,
+	}
+}
+
+// Create registers a client owned by the user. A confidential client gets a secret, shown only in this response,
+// a public client (a mobile or single page app that cannot keep a secret) relies on pkce alone.
+func (service *OauthClientServiceImplementation) Create(ctx context.Context, createOauthClientRequest modelrequests.CreateOauthClientRequest) (httpCode int, response interface{}) {
+	err := service.Validate.Struct(createOauthClientRequest)
+	if err != nil {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	for _, redirectUri := range createOauthClientRequest.RedirectUris {
+		if !validOauthRedirectUri(redirectUri) {
+			httpCode = http.StatusBadRequest
+			response = helpers.ToResponse("redirect uri " + redirectUri + " must use https, or http on localhost, and have no fragment")
+			return
+		}
+	}
+	scopes := helpers.ParseOauthScopes(strings.Join(createOauthClientRequest.Scopes, " "))
+	for _, scope := range scopes {
+		if _, ok := helpers.OauthScopes[scope]; !ok {
+			httpCode = http.StatusBadRequest
+			response = helpers.ToResponse("unknown scope " + scope)
+			return
+		}
+	}
+
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+
+	clientId, _, err := helpers.GenerateRandomToken()
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	var oauthClient modelentities.OauthClient
+	oauthClient.ClientId = pgtype.Text{Valid: true, String: clientId}
+	oauthClient.UserId = pgtype.Int4{Valid: true, Int32: int32(principal.Id)}
+	oauthClient.Name = pgtype.Text{Valid: true, String: createOauthClientRequest.Name}
+	oauthClient.RedirectUris = createOauthClientRequest.RedirectUris
+	oauthClient.Scopes = scopes
+	var clientSecret string
+	if createOauthClientRequest.Confidential {
+		var clientSecretHash string
+		clientSecret, clientSecretHash, err = helpers.GenerateRandomToken()
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+		oauthClient.ClientSecretHash = pgtype.Text{Valid: true, String: clientSecretHash}
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	_, err = service.OauthClientRepository.Create(tx, ctx, oauthClient)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	oauthClient.CreatedAt = pgtype.Timestamptz{Valid: true, Time: time.Now()}
+
+	httpCode = http.StatusCreated
+	response = modelresponses.CreateOauthClientResponse{
+		OauthClientResponse: toOauthClientResponse(oauthClient),
+		ClientSecret:        clientSecret,
+	}
+	return
+}
+
+func (service *OauthClientServiceImplementation) FindAll(ctx context.Context) (httpCode int, response interface{}) {
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+
+	oauthClients, err := service.OauthClientRepository.FindByUserId(service.PostgresUtil.GetPool(), ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	oauthClientResponses := []modelresponses.OauthClientResponse{}
+	for _, oauthClient := range oauthClients {
+		oauthClientResponses = append(oauthClientResponses, toOauthClientResponse(oauthClient))
+	}
+
+	httpCode = http.StatusOK
+	response = oauthClientResponses
+	return
+}
+
+// Delete removes the client together with its codes and grants, so every token it holds stops working.
+func (service *OauthClientServiceImplementation) Delete(ctx context.Context, clientId string) (httpCode int, response interface{}) {
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	rowsAffected, err := service.OauthClientRepository.DeleteByClientIdAndUserId(tx, ctx, clientId, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if rowsAffected != 1 {
+		httpCode = http.StatusNotFound
+		response = helpers.ToResponse("cannot find client")
+		return
+	}
+
+	httpCode = http.StatusNoContent
+	response = helpers.ToResponse("successfully deleted")
+	return
+}
+
+func validOauthRedirectUri(redirectUri string) bool {
+	parsedUrl, err := url.Parse(redirectUri)
+	if err != nil || parsedUrl.Fragment != "" || parsedUrl.Host == "" {
+		return false
+	}
+	if parsedUrl.Scheme == "https" {
+		return true
+	}
+	hostname := parsedUrl.Hostname()
+	return parsedUrl.Scheme == "http" && (hostname == "localhost" || hostname == "127.0.0.1" || hostname == "::1")
+}
+
+func toOauthClientResponse(oauthClient modelentities.OauthClient) modelresponses.OauthClientResponse {
+	return modelresponses.OauthClientResponse{
+		ClientId:     oauthClient.ClientId.String,
+		Name:         oauthClient.Name.String,
+		RedirectUris: oauthClient.RedirectUris,
+		Scopes:       oauthClient.Scopes,
+		Confidential: oauthClient.ClientSecretHash.Valid,
+		CreatedAt:    oauthClient.CreatedAt.Time,
+	}
+}
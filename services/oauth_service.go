@@ -0,0 +1,633 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	modelrequests "todo-list-api/models/requests"
+	modelresponses "todo-list-api/models/responses"
+	"todo-list-api/repositories"
+	"todo-list-api/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type OauthService interface {
+	Authorize(ctx context.Context, authorizeRequest modelrequests.AuthorizeRequest) (httpCode int, response interface{})
+	Approve(ctx context.Context, authorizeRequest modelrequests.AuthorizeRequest) (httpCode int, response interface{})
+	Token(ctx context.Context, tokenRequest modelrequests.TokenRequest) (httpCode int, response interface{})
+	Revoke(ctx context.Context, revokeTokenRequest modelrequests.RevokeTokenRequest) (httpCode int, response interface{})
+	Introspect(ctx context.Context, introspectTokenRequest modelrequests.IntrospectTokenRequest) (httpCode int, response interface{})
+}
+
+type OauthServiceImplementation struct {
+	PostgresUtil                     utils.PostgresUtil
+	UserRepository                   repositories.UserRepository
+	OauthClientRepository            repositories.OauthClientRepository
+	OauthAuthorizationCodeRepository repositories.OauthAuthorizationCodeRepository
+	OauthGrantRepository             repositories.OauthGrantRepository
+	JwtHelper                        helpers.JwtHelper
+	Config                           config.Config
+}
+
+func NewOauthService(postgresUtil utils.PostgresUtil, userRepository repositories.UserRepository, oauthClientRepository repositories.OauthClientRepository, oauthAuthorizationCodeRepository repositories.OauthAuthorizationCodeRepository, oauthGrantRepository repositories.OauthGrantRepository, jwtHelper helpers.JwtHelper, config config.Config) OauthService {
+	return &OauthServiceImplementation{
+		PostgresUtil:                     postgresUtil,
+		UserRepository:                   userRepository,
+		OauthClientRepository:            oauthClientRepository,
+		OauthAuthorizationCodeRepository: oauthAuthorizationCodeRepository,
+		OauthGrantRepository:             oauthGrantRepository,
+		JwtHelper:                        jwtHelper,
+		Config:                           config,
+	}
+}
+
+// Authorize returns what the consent screen shows. An unknown client or a redirect uri the client did not register
+// is answered without a redirect uri, sending the user there would make this api an open redirector.
+func (service *OauthServiceImplementation) Authorize(ctx context.Context, authorizeRequest modelrequests.AuthorizeRequest) (httpCode int, response interface{}) {
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	oauthClient, scopes, httpCode, response, err := service.validateAuthorizeRequest(tx, ctx, authorizeRequest)
+	if err != nil || httpCode != http.StatusOK {
+		return
+	}
+
+	scopeResponses := []modelresponses.OauthScopeResponse{}
+	for _, scope := range scopes {
+		scopeResponses = append(scopeResponses, modelresponses.OauthScopeResponse{
+			Name:        scope,
+			Description: helpers.OauthScopes[scope],
+		})
+	}
+	httpCode = http.StatusOK
+	response = modelresponses.OauthConsentResponse{
+		Client: modelresponses.OauthConsentClientResponse{
+			ClientId: oauthClient.ClientId.String,
+			Name:     oauthClient.Name.String,
+		},
+		Scopes:      scopeResponses,
+		RedirectUri: authorizeRequest.RedirectUri,
+		State:       authorizeRequest.State,
+	}
+	return
+}
+
+// Approve records the answer of the user. Either way the consent screen gets back the uri to send the browser to.
+func (service *OauthServiceImplementation) Approve(ctx context.Context, authorizeRequest modelrequests.AuthorizeRequest) (httpCode int, response interface{}) {
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	oauthClient, scopes, httpCode, response, err := service.validateAuthorizeRequest(tx, ctx, authorizeRequest)
+	if err != nil || httpCode != http.StatusOK {
+		return
+	}
+
+	if !authorizeRequest.Approve {
+		httpCode = http.StatusOK
+		response = modelresponses.OauthRedirectResponse{
+			RedirectUri: oauthRedirectUri(authorizeRequest.RedirectUri, map[string]string{"error": "access_denied", "state": authorizeRequest.State}),
+		}
+		return
+	}
+
+	code, codeHash, err := helpers.GenerateRandomToken()
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	var oauthAuthorizationCode modelentities.OauthAuthorizationCode
+	oauthAuthorizationCode.CodeHash = pgtype.Text{Valid: true, String: codeHash}
+	oauthAuthorizationCode.ClientId = oauthClient.ClientId
+	oauthAuthorizationCode.UserId = pgtype.Int4{Valid: true, Int32: int32(principal.Id)}
+	oauthAuthorizationCode.RedirectUri = pgtype.Text{Valid: true, String: authorizeRequest.RedirectUri}
+	oauthAuthorizationCode.Scopes = scopes
+	oauthAuthorizationCode.CodeChallenge = pgtype.Text{Valid: true, String: authorizeRequest.CodeChallenge}
+	oauthAuthorizationCode.ExpiresAt = pgtype.Timestamptz{Valid: true, Time: time.Now().Add(time.Duration(service.Config.Oauth.AuthorizationCodeTime) * time.Second)}
+	_, err = service.OauthAuthorizationCodeRepository.Create(tx, ctx, oauthAuthorizationCode)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode = http.StatusOK
+	response = modelresponses.OauthRedirectResponse{
+		RedirectUri: oauthRedirectUri(authorizeRequest.RedirectUri, map[string]string{"code": code, "state": authorizeRequest.State}),
+	}
+	return
+}
+
+func (service *OauthServiceImplementation) validateAuthorizeRequest(tx pgx.Tx, ctx context.Context, authorizeRequest modelrequests.AuthorizeRequest) (oauthClient modelentities.OauthClient, scopes []string, httpCode int, response interface{}, err error) {
+	oauthClient, err = service.OauthClientRepository.FindByClientId(tx, ctx, authorizeRequest.ClientId)
+	if err != nil && err != pgx.ErrNoRows {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == pgx.ErrNoRows {
+		err = nil
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("unknown client")
+		return
+	}
+	registered := false
+	for _, redirectUri := range oauthClient.RedirectUris {
+		if redirectUri == authorizeRequest.RedirectUri {
+			registered = true
+			break
+		}
+	}
+	if !registered {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("redirect uri is not registered for this client")
+		return
+	}
+
+	// from here on the errors go back to the client through its redirect uri
+	redirectError := func(oauthError string, errorDescription string) {
+		httpCode = http.StatusBadRequest
+		response = modelresponses.OauthErrorResponse{
+			Error:            oauthError,
+			ErrorDescription: errorDescription,
+			RedirectUri:      oauthRedirectUri(authorizeRequest.RedirectUri, map[string]string{"error": oauthError, "error_description": errorDescription, "state": authorizeRequest.State}),
+		}
+	}
+	if authorizeRequest.ResponseType != "code" {
+		redirectError("unsupported_response_type", "response_type must be code")
+		return
+	}
+	if authorizeRequest.CodeChallenge == "" || authorizeRequest.CodeChallengeMethod != "S256" {
+		redirectError("invalid_request", "code_challenge with code_challenge_method S256 is required")
+		return
+	}
+	scopes = helpers.ParseOauthScopes(authorizeRequest.Scope)
+	if len(scopes) == 0 {
+		scopes = oauthClient.Scopes
+	}
+	if !helpers.ContainsOauthScopes(oauthClient.Scopes, scopes) {
+		scopes = nil
+		redirectError("invalid_scope", "the client is not allowed to ask for this scope")
+		return
+	}
+
+	httpCode = http.StatusOK
+	return
+}
+
+// Token exchanges an authorization code or a refresh token. A code that is used twice was most likely stolen,
+// so the grant issued for it the first time is revoked along with every token it issued.
+func (service *OauthServiceImplementation) Token(ctx context.Context, tokenRequest modelrequests.TokenRequest) (httpCode int, response interface{}) {
+	if tokenRequest.GrantType != "authorization_code" && tokenRequest.GrantType != "refresh_token" {
+		httpCode = http.StatusBadRequest
+		response = toOauthErrorResponse("unsupported_grant_type", "grant_type must be authorization_code or refresh_token")
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	oauthClient, httpCode, response, err := service.authenticateClient(tx, ctx, tokenRequest.ClientId, tokenRequest.ClientSecret)
+	if err != nil || httpCode != http.StatusOK {
+		return
+	}
+
+	if tokenRequest.GrantType == "authorization_code" {
+		httpCode, response, err = service.exchangeAuthorizationCode(tx, ctx, oauthClient, tokenRequest)
+	} else {
+		httpCode, response, err = service.exchangeRefreshToken(tx, ctx, oauthClient, tokenRequest)
+	}
+	return
+}
+
+func (service *OauthServiceImplementation) exchangeAuthorizationCode(tx pgx.Tx, ctx context.Context, oauthClient modelentities.OauthClient, tokenRequest modelrequests.TokenRequest) (httpCode int, response interface{}, err error) {
+	if tokenRequest.Code == "" || tokenRequest.CodeVerifier == "" || tokenRequest.RedirectUri == "" {
+		httpCode = http.StatusBadRequest
+		response = toOauthErrorResponse("invalid_request", "code, code_verifier and redirect_uri are required")
+		return
+	}
+	oauthAuthorizationCode, err := service.OauthAuthorizationCodeRepository.FindByCodeHash(tx, ctx, helpers.HashToken(tokenRequest.Code))
+	if err != nil && err != pgx.ErrNoRows {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == pgx.ErrNoRows {
+		err = nil
+		httpCode = http.StatusBadRequest
+		response = toOauthErrorResponse("invalid_grant", "invalid authorization code")
+		return
+	}
+	if oauthAuthorizationCode.UsedAt.Valid {
+		if oauthAuthorizationCode.GrantId.Valid {
+			_, err = service.OauthGrantRepository.Revoke(tx, ctx, int(oauthAuthorizationCode.GrantId.Int32))
+			if err != nil {
+				httpCode = http.StatusInternalServerError
+				response = helpers.ToResponse(err.Error())
+				return
+			}
+		}
+		httpCode = http.StatusBadRequest
+		response = toOauthErrorResponse("invalid_grant", "invalid authorization code")
+		return
+	}
+	if !oauthAuthorizationCode.ExpiresAt.Time.After(time.Now()) ||
+		oauthAuthorizationCode.ClientId.String != oauthClient.ClientId.String ||
+		oauthAuthorizationCode.RedirectUri.String != tokenRequest.RedirectUri {
+		httpCode = http.StatusBadRequest
+		response = toOauthErrorResponse("invalid_grant", "invalid authorization code")
+		return
+	}
+	if !helpers.VerifyPkce(tokenRequest.CodeVerifier, oauthAuthorizationCode.CodeChallenge.String) {
+		httpCode = http.StatusBadRequest
+		response = toOauthErrorResponse("invalid_grant", "code_verifier does not match the code_challenge")
+		return
+	}
+
+	userId := int(oauthAuthorizationCode.UserId.Int32)
+	httpCode, response, err = service.checkUser(tx, ctx, userId)
+	if err != nil || httpCode != http.StatusOK {
+		return
+	}
+
+	refreshToken, refreshTokenHash, refreshTokenExpiresAt, err := newOauthRefreshToken(service.Config.Oauth.RefreshTokenTime)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	var oauthGrant modelentities.OauthGrant
+	oauthGrant.ClientId = oauthClient.ClientId
+	oauthGrant.UserId = oauthAuthorizationCode.UserId
+	oauthGrant.Scopes = oauthAuthorizationCode.Scopes
+	oauthGrant.RefreshTokenHash = pgtype.Text{Valid: true, String: refreshTokenHash}
+	oauthGrant.RefreshTokenExpiresAt = refreshTokenExpiresAt
+	grantId, err := service.OauthGrantRepository.Create(tx, ctx, oauthGrant)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	rowsAffected, err := service.OauthAuthorizationCodeRepository.UpdateUsedAt(tx, ctx, grantId, int(oauthAuthorizationCode.Id.Int32))
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if rowsAffected != 1 {
+		httpCode = http.StatusInternalServerError
+		err = errors.New("rows affected not one")
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode, response, err = service.toOauthTokenResponse(userId, oauthClient.ClientId.String, oauthAuthorizationCode.Scopes, grantId, refreshToken)
+	return
+}
+
+// exchangeRefreshToken rotates the refresh token. The client may ask for fewer scopes than the grant has,
+// the narrower scope only applies to the access token issued now.
+func (service *OauthServiceImplementation) exchangeRefreshToken(tx pgx.Tx, ctx context.Context, oauthClient modelentities.OauthClient, tokenRequest modelrequests.TokenRequest) (httpCode int, response interface{}, err error) {
+	if tokenRequest.RefreshToken == "" {
+		httpCode = http.StatusBadRequest
+		response = toOauthErrorResponse("invalid_request", "refresh_token is required")
+		return
+	}
+	oauthGrant, err := service.OauthGrantRepository.FindByRefreshTokenHash(tx, ctx, helpers.HashToken(tokenRequest.RefreshToken))
+	if err != nil && err != pgx.ErrNoRows {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == pgx.ErrNoRows {
+		err = nil
+		httpCode = http.StatusBadRequest
+		response = toOauthErrorResponse("invalid_grant", "invalid refresh token")
+		return
+	}
+	if oauthGrant.RevokedAt.Valid || !oauthGrant.RefreshTokenExpiresAt.Time.After(time.Now()) ||
+		oauthGrant.ClientId.String != oauthClient.ClientId.String {
+		httpCode = http.StatusBadRequest
+		response = toOauthErrorResponse("invalid_grant", "invalid refresh token")
+		return
+	}
+	scopes := oauthGrant.Scopes
+	if tokenRequest.Scope != "" {
+		scopes = helpers.ParseOauthScopes(tokenRequest.Scope)
+		if !helpers.ContainsOauthScopes(oauthGrant.Scopes, scopes) {
+			httpCode = http.StatusBadRequest
+			response = toOauthErrorResponse("invalid_scope", "scope must not be wider than the scope the user consented to")
+			return
+		}
+	}
+
+	userId := int(oauthGrant.UserId.Int32)
+	httpCode, response, err = service.checkUser(tx, ctx, userId)
+	if err != nil || httpCode != http.StatusOK {
+		return
+	}
+
+	refreshToken, refreshTokenHash, refreshTokenExpiresAt, err := newOauthRefreshToken(service.Config.Oauth.RefreshTokenTime)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	rowsAffected, err := service.OauthGrantRepository.UpdateRefreshToken(tx, ctx, refreshTokenHash, refreshTokenExpiresAt, int(oauthGrant.Id.Int32))
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if rowsAffected != 1 {
+		httpCode = http.StatusInternalServerError
+		err = errors.New("rows affected not one")
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode, response, err = service.toOauthTokenResponse(userId, oauthClient.ClientId.String, scopes, int(oauthGrant.Id.Int32), refreshToken)
+	return
+}
+
+// checkUser refuses to issue tokens for a user that was disabled or deleted after consenting.
+func (service *OauthServiceImplementation) checkUser(tx pgx.Tx, ctx context.Context, userId int) (httpCode int, response interface{}, err error) {
+	user, err := service.UserRepository.FindById(tx, ctx, userId)
+	if err != nil && err != pgx.ErrNoRows {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == pgx.ErrNoRows {
+		err = nil
+		httpCode = http.StatusBadRequest
+		response = toOauthErrorResponse("invalid_grant", "the user does not exist anymore")
+		return
+	}
+	if user.DisabledAt.Valid {
+		httpCode = http.StatusBadRequest
+		response = toOauthErrorResponse("invalid_grant", "account is disabled")
+		return
+	}
+	httpCode = http.StatusOK
+	return
+}
+
+func (service *OauthServiceImplementation) toOauthTokenResponse(userId int, clientId string, scopes []string, grantId int, refreshToken string) (httpCode int, response interface{}, err error) {
+	scope := strings.Join(scopes, " ")
+	accessToken, err := service.JwtHelper.GenerateOauthAccessToken(userId, clientId, scope, grantId, service.Config.Oauth.AccessTokenTime)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	httpCode = http.StatusOK
+	response = modelresponses.OauthTokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    service.Config.Oauth.AccessTokenTime * 60,
+		RefreshToken: refreshToken,
+		Scope:        scope,
+	}
+	return
+}
+
+// Revoke follows rfc 7009: it answers 200 for a token that is unknown, already revoked or belongs to another client,
+// so a client cannot use it to find out which tokens exist. Revoking either token revokes the whole grant.
+func (service *OauthServiceImplementation) Revoke(ctx context.Context, revokeTokenRequest modelrequests.RevokeTokenRequest) (httpCode int, response interface{}) {
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	oauthClient, httpCode, response, err := service.authenticateClient(tx, ctx, revokeTokenRequest.ClientId, revokeTokenRequest.ClientSecret)
+	if err != nil || httpCode != http.StatusOK {
+		return
+	}
+
+	grantId := 0
+	claims, errParse := service.JwtHelper.ParseOauthAccessToken(revokeTokenRequest.Token)
+	if errParse == nil && claims.ClientId == oauthClient.ClientId.String {
+		grantId = claims.GrantId
+	} else if errParse != nil {
+		var oauthGrant modelentities.OauthGrant
+		oauthGrant, err = service.OauthGrantRepository.FindByRefreshTokenHash(tx, ctx, helpers.HashToken(revokeTokenRequest.Token))
+		if err != nil && err != pgx.ErrNoRows {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		} else if err == nil && oauthGrant.ClientId.String == oauthClient.ClientId.String {
+			grantId = int(oauthGrant.Id.Int32)
+		}
+		err = nil
+	}
+	if grantId != 0 {
+		_, err = service.OauthGrantRepository.Revoke(tx, ctx, grantId)
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+	}
+
+	httpCode = http.StatusOK
+	response = helpers.ToResponse("successfully revoked")
+	return
+}
+
+// Introspect follows rfc 7662. A client can only introspect its own tokens, any other token is reported inactive.
+func (service *OauthServiceImplementation) Introspect(ctx context.Context, introspectTokenRequest modelrequests.IntrospectTokenRequest) (httpCode int, response interface{}) {
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	oauthClient, httpCode, response, err := service.authenticateClient(tx, ctx, introspectTokenRequest.ClientId, introspectTokenRequest.ClientSecret)
+	if err != nil || httpCode != http.StatusOK {
+		return
+	}
+
+	httpCode = http.StatusOK
+	response = modelresponses.IntrospectTokenResponse{Active: false}
+	claims, errParse := service.JwtHelper.ParseOauthAccessToken(introspectTokenRequest.Token)
+	if errParse == nil {
+		if claims.ClientId != oauthClient.ClientId.String {
+			return
+		}
+		_, err = service.OauthGrantRepository.FindActiveById(service.PostgresUtil.GetPool(), ctx, claims.GrantId)
+		if err != nil && err != pgx.ErrNoRows {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		} else if err != nil && err == pgx.ErrNoRows {
+			err = nil
+			return
+		}
+		response = modelresponses.IntrospectTokenResponse{
+			Active:    true,
+			Scope:     claims.Scope,
+			ClientId:  claims.ClientId,
+			Sub:       claims.Subject,
+			Exp:       claims.ExpiresAt.Unix(),
+			Iat:       claims.IssuedAt.Unix(),
+			TokenType: "Bearer",
+		}
+		return
+	}
+
+	oauthGrant, err := service.OauthGrantRepository.FindByRefreshTokenHash(tx, ctx, helpers.HashToken(introspectTokenRequest.Token))
+	if err != nil && err != pgx.ErrNoRows {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == pgx.ErrNoRows {
+		err = nil
+		return
+	}
+	if oauthGrant.RevokedAt.Valid || !oauthGrant.RefreshTokenExpiresAt.Time.After(time.Now()) ||
+		oauthGrant.ClientId.String != oauthClient.ClientId.String {
+		return
+	}
+	response = modelresponses.IntrospectTokenResponse{
+		Active:   true,
+		Scope:    strings.Join(oauthGrant.Scopes, " "),
+		ClientId: oauthGrant.ClientId.String,
+		Sub:      strconv.Itoa(int(oauthGrant.UserId.Int32)),
+		Exp:      oauthGrant.RefreshTokenExpiresAt.Time.Unix(),
+	}
+	return
+}
+
+// authenticateClient accepts a public client by its client id alone, a confidential client also has to send its secret.
+func (service *OauthServiceImplementation) authenticateClient(tx pgx.Tx, ctx context.Context, clientId string, clientSecret string) (oauthClient modelentities.OauthClient, httpCode int, response interface{}, err error) {
+	if clientId == "" {
+		httpCode = http.StatusUnauthorized
+		response = toOauthErrorResponse("invalid_client", "client authentication failed")
+		return
+	}
+	oauthClient, err = service.OauthClientRepository.FindByClientId(tx, ctx, clientId)
+	if err != nil && err != pgx.ErrNoRows {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == pgx.ErrNoRows {
+		err = nil
+		httpCode = http.StatusUnauthorized
+		response = toOauthErrorResponse("invalid_client", "client authentication failed")
+		return
+	}
+	if oauthClient.ClientSecretHash.Valid {
+		if clientSecret == "" || subtle.ConstantTimeCompare([]byte(helpers.HashToken(clientSecret)), []byte(oauthClient.ClientSecretHash.String)) != 1 {
+			httpCode = http.StatusUnauthorized
+			response = toOauthErrorResponse("invalid_client", "client authentication failed")
+			return
+		}
+	} else if clientSecret != "" {
+		httpCode = http.StatusUnauthorized
+		response = toOauthErrorResponse("invalid_client", "client authentication failed")
+		return
+	}
+	httpCode = http.StatusOK
+	return
+}
+
+func newOauthRefreshToken(oauthRefreshTokenTime int) (refreshToken string, refreshTokenHash string, refreshTokenExpiresAt pgtype.Timestamptz, err error) {
+	refreshToken, refreshTokenHash, err = helpers.GenerateRandomToken()
+	if err != nil {
+		return
+	}
+	refreshTokenExpiresAt = pgtype.Timestamptz{Valid: true, Time: time.Now().AddDate(0, 0, oauthRefreshTokenTime)}
+	return
+}
+
+// oauthRedirectUri adds the parameters to the query the redirect uri may already have, leaving out empty ones.
+func oauthRedirectUri(redirectUri string, parameters map[string]string) string {
+	parsedUrl, err := url.Parse(redirectUri)
+	if err != nil {
+		return redirectUri
+	}
+	query := parsedUrl.Query()
+	for key, value := range parameters {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	parsedUrl.RawQuery = query.Encode()
+	return parsedUrl.String()
+}
+
+func toOauthErrorResponse(oauthError string, errorDescription string) modelresponses.OauthErrorResponse {
+	return modelresponses.OauthErrorResponse{
+		Error:            oauthError,
+		ErrorDescription: errorDescription,
+	}
+}
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	modelresponses "todo-list-api/models/responses"
+	"todo-list-api/repositories"
+	"todo-list-api/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type OidcService interface {
+	Start(ctx context.Context, provider string) (httpCode int, authCodeUrl string, oidcStateToken string, response interface{})
+	Callback(ctx context.Context, provider string, code string, state string, oidcStateToken string) (httpCode int, accessToken string, refreshToken string, response interface{})
+}
+
+type OidcServiceImplementation struct {
+	PostgresUtil            utils.PostgresUtil
+	UserRepository          repositories.UserRepository
+	UserIdentityRepository  repositories.UserIdentityRepository
+	PasswordHasher          helpers.PasswordHasher
+	JwtHelper               helpers.JwtHelper
+	OidcHelper              helpers.OidcHelper
+	SecurityEventRepository repositories.SecurityEventRepository
+	MetricsHelper           helpers.MetricsHelper
+	Config                  config.Config
+}
+
+func NewOidcService(postgresUtil utils.PostgresUtil, userRepository repositories.UserRepository, userIdentityRepository repositories.UserIdentityRepository, passwordHasher helpers.PasswordHasher, jwtHelper helpers.JwtHelper, oidcHelper helpers.OidcHelper, securityEventRepository repositories.SecurityEventRepository, metricsHelper helpers.MetricsHelper, config config.Config) OidcService {
+	return &OidcServiceImplementation{
+		PostgresUtil:            postgresUtil,
+		UserRepository:          userRepository,
+		UserIdentityRepository:  userIdentityRepository,
+		PasswordHasher:          passwordHasher,
+		JwtHelper:               jwtHelper,
+		OidcHelper:              oidcHelper,
+		SecurityEventRepository: securityEventRepository,
+		MetricsHelper:           metricsHelper,
+		Config:                  config,
+	}
+}
+
+func (service *OidcServiceImplementation) Start(ctx context.Context, provider string) (httpCode int, authCodeUrl string, oidcStateToken string, response interface{}) {
+	state, _, err := helpers.GenerateRandomToken()
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	nonce, _, err := helpers.GenerateRandomToken()
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	// 32 random bytes in base64url are 43 characters, the shortest code verifier pkce allows
+	codeVerifier, _, err := helpers.GenerateRandomToken()
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	authCodeUrl, err = service.OidcHelper.AuthCodeUrl(ctx, provider, state, nonce, codeVerifier)
+	if err != nil && err == helpers.ErrUnknownOidcProvider {
+		httpCode = http.StatusNotFound
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil {
+		httpCode = http.StatusBadGateway
+		response = helpers.ToResponse("cannot reach the oidc provider: " + err.Error())
+		return
+	}
+
+	oidcStateToken, err = service.JwtHelper.GenerateOidcStateToken(provider, state, nonce, codeVerifier, service.Config.Oidc.StateTokenTime)
+	if err != nil {
+		authCodeUrl = ""
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode = http.StatusFound
+	return
+}
+
+// Callback signs in the user the provider identity is linked to. An identity seen for the first time is linked to the
+// user with the same email, or to a new user, but only when the provider says the email is verified.
+func (service *OidcServiceImplementation) Callback(ctx context.Context, provider string, code string, state string, oidcStateToken string) (httpCode int, accessToken string, refreshToken string, response interface{}) {
+	if code == "" || state == "" || oidcStateToken == "" {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("invalid oidc state")
+		return
+	}
+	claims, err := service.JwtHelper.ParseOidcStateToken(oidcStateToken)
+	if err != nil {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("invalid oidc state")
+		return
+	}
+	if claims.Provider != provider || subtle.ConstantTimeCompare([]byte(claims.State), []byte(state)) != 1 {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("invalid oidc state")
+		return
+	}
+
+	identity, err := service.OidcHelper.Exchange(ctx, provider, code, claims.CodeVerifier, claims.Nonce)
+	if err != nil && err == helpers.ErrUnknownOidcProvider {
+		httpCode = http.StatusNotFound
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil {
+		httpCode = http.StatusUnauthorized
+		response = helpers.ToResponse("cannot verify the oidc login: " + err.Error())
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			accessToken = ""
+			refreshToken = ""
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	var user modelentities.User
+	userIdentity, err := service.UserIdentityRepository.FindByProviderAndSubject(tx, ctx, provider, identity.Subject)
+	if err != nil && err != pgx.ErrNoRows {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err == nil {
+		user, err = service.UserRepository.FindById(tx, ctx, int(userIdentity.UserId.Int32))
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+		_, err = service.UserIdentityRepository.UpdateLastLoginAt(tx, ctx, identity.Email, int(userIdentity.Id.Int32))
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+	} else {
+		err = nil
+		if identity.Email == "" || !identity.EmailVerified {
+			httpCode = http.StatusBadRequest
+			response = helpers.ToResponse("the oidc provider did not return a verified email")
+			return
+		}
+		user, err = service.UserRepository.FindByEmail(tx, ctx, identity.Email)
+		if err != nil && err != pgx.ErrNoRows {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		} else if err == nil && !user.EmailVerifiedAt.Valid {
+			// anyone can register an unverified email, linking to it would let them sign in as the real owner later
+			httpCode = http.StatusConflict
+			response = helpers.ToResponse("an account with this email exists but its email is not verified, log in with the password and verify it first")
+			return
+		} else if err != nil && err == pgx.ErrNoRows {
+			user, err = service.createUser(tx, ctx, identity)
+			if err != nil {
+				httpCode = http.StatusInternalServerError
+				response = helpers.ToResponse(err.Error())
+				return
+			}
+			err = recordSecurityEvent(tx, ctx, service.SecurityEventRepository, int(user.Id.Int32), user.Email.String, helpers.SecurityEventRegistered, "oidc:"+provider)
+			if err != nil {
+				httpCode = http.StatusInternalServerError
+				response = helpers.ToResponse(err.Error())
+				return
+			}
+		}
+		userIdentity.UserId = user.Id
+		userIdentity.Provider = pgtype.Text{Valid: true, String: provider}
+		userIdentity.Subject = pgtype.Text{Valid: true, String: identity.Subject}
+		userIdentity.Email = pgtype.Text{Valid: true, String: identity.Email}
+		_, err = service.UserIdentityRepository.Create(tx, ctx, userIdentity)
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+	}
+
+	if user.DisabledAt.Valid {
+		err = recordLoginEvent(tx, ctx, service.SecurityEventRepository, service.MetricsHelper, int(user.Id.Int32), user.Email.String, helpers.SecurityEventLoginFailed, "disabled")
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+		httpCode = http.StatusForbidden
+		response = helpers.ToResponse("account is disabled")
+		return
+	}
+
+	if user.TotpEnabledAt.Valid {
+		var mfaPendingToken string
+		mfaPendingToken, err = service.JwtHelper.GenerateMfaPendingToken(int(user.Id.Int32), service.Config.Jwt.MfaPendingTokenTime)
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+		httpCode = http.StatusOK
+		response = modelresponses.MfaRequiredResponse{
+			Message:     "two factor authentication code required",
+			MfaRequired: true,
+			MfaToken:    mfaPendingToken,
+		}
+		return
+	}
+
+	if user.DeletionScheduledAt.Valid {
+		_, err = service.UserRepository.CancelDeletion(tx, ctx, int(user.Id.Int32))
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+	}
+
+	accessToken, err = service.JwtHelper.GenerateAccessToken(int(user.Id.Int32), user.Name.String, user.Email.String, service.Config.Jwt.AccessTokenTime)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	refreshToken, err = service.JwtHelper.GenerateRefreshToken(int(user.Id.Int32), service.Config.Jwt.RefreshTokenTime)
+	if err != nil {
+		accessToken = ""
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	rowsAffected, err := service.UserRepository.UpdateRefreshToken(tx, ctx, refreshToken, int(user.Id.Int32))
+	if err != nil {
+		accessToken = ""
+		refreshToken = ""
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if rowsAffected != 1 {
+		accessToken = ""
+		refreshToken = ""
+		httpCode = http.StatusInternalServerError
+		err = errors.New("rows affected not one")
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	err = recordLoginEvent(tx, ctx, service.SecurityEventRepository, service.MetricsHelper, int(user.Id.Int32), user.Email.String, helpers.SecurityEventLoginSucceeded, "oidc:"+provider)
+	if err != nil {
+		accessToken = ""
+		refreshToken = ""
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode = http.StatusOK
+	response = helpers.ToResponse("successfully login")
+	return
+}
+
+// createUser gives the new user a random password nobody knows, they can set one with the forgot password flow.
+func (service *OidcServiceImplementation) createUser(tx pgx.Tx, ctx context.Context, identity helpers.OidcIdentity) (user modelentities.User, err error) {
+	name := identity.Name
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+	if len([]rune(name)) > 50 {
+		name = string([]rune(name)[:50])
+	}
+	randomPassword, _, err := helpers.GenerateRandomToken()
+	if err != nil {
+		return
+	}
+	passwordHash, err := service.PasswordHasher.Hash(randomPassword)
+	if err != nil {
+		return
+	}
+
+	user.Name = pgtype.Text{Valid: true, String: name}
+	user.Email = pgtype.Text{Valid: true, String: identity.Email}
+	user.Password = pgtype.Text{Valid: true, String: passwordHash}
+	lastInsertedId, err := service.UserRepository.Create(tx, ctx, user)
+	if err != nil {
+		return
+	}
+	user.Id = pgtype.Int4{Valid: true, Int32: int32(lastInsertedId)}
+
+	_, err = service.UserRepository.UpdateEmailVerifiedAt(tx, ctx, lastInsertedId)
+	return
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	modelrequests "todo-list-api/models/requests"
+	modelresponses "todo-list-api/models/responses"
+	"todo-list-api/repositories"
+	"todo-list-api/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type PasswordService interface {
+	ForgotPassword(ctx context.Context, forgotPasswordRequest modelrequests.ForgotPasswordRequest) (httpCode int, response interface{})
+	ResetPassword(ctx context.Context, resetPasswordRequest modelrequests.ResetPasswordRequest) (httpCode int, response interface{})
+}
+
+type PasswordServiceImplementation struct {
+	PostgresUtil                 utils.PostgresUtil
+	Validate                     *validator.Validate
+	UserRepository               repositories.UserRepository
+	PasswordResetTokenRepository repositories.PasswordResetTokenRepository
+	PasswordHasher               helpers.PasswordHasher
+	MailQueue                    helpers.MailQueue
+	PasswordPolicyHelper         helpers.PasswordPolicyHelper
+	SecurityEventRepository      repositories.SecurityEventRepository
+	Config                       config.Config
+}
+
+func NewPasswordService(postgresUtil utils.PostgresUtil, validate *validator.Validate, userRepository repositories.UserRepository, passwordResetTokenRepository repositories.PasswordResetTokenRepository, passwordHasher helpers.PasswordHasher, mailQueue helpers.MailQueue, passwordPolicyHelper helpers.PasswordPolicyHelper, securityEventRepository repositories.SecurityEventRepository, config config.Config) PasswordService {
+	return &PasswordServiceImplementation{
+		PostgresUtil:                 postgresUtil,
+		Validate:                     validate,
+		UserRepository:               userRepository,
+		PasswordResetTokenRepository: passwordResetTokenRepository,
+		PasswordHasher:               passwordHasher,
+		MailQueue:                    mailQueue,
+		PasswordPolicyHelper:         passwordPolicyHelper,
+		SecurityEventRepository:      securityEventRepository,
+		Config:                       config,
+	}
+}
+
+func (service *PasswordServiceImplementation) ForgotPassword(ctx context.Context, forgotPasswordRequest modelrequests.ForgotPasswordRequest) (httpCode int, response interface{}) {
+	err := service.Validate.Struct(forgotPasswordRequest)
+	if err != nil {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	var user modelentities.User
+	var body string
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+			return
+		}
+		// the mail is queued once the token is stored, sending it here would make known emails answer slower
+		if err == nil && body != "" {
+			service.MailQueue.Send(ctx, user.Email.String, "Reset your password", body)
+		}
+	}()
+
+	// the same answer is given for unknown emails so this endpoint cannot be used to find registered accounts
+	user, err = service.UserRepository.FindByEmail(tx, ctx, forgotPasswordRequest.Email)
+	if err != nil && err != pgx.ErrNoRows {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == pgx.ErrNoRows {
+		httpCode = http.StatusOK
+		response = helpers.ToResponse("if the email is registered, a password reset link has been sent")
+		return
+	}
+
+	token, tokenHash, err := helpers.GenerateRandomToken()
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	var passwordResetToken modelentities.PasswordResetToken
+	passwordResetToken.UserId = user.Id
+	passwordResetToken.TokenHash = pgtype.Text{Valid: true, String: tokenHash}
+	passwordResetToken.ExpiresAt = pgtype.Timestamptz{Valid: true, Time: time.Now().Add(time.Duration(service.Config.PasswordReset.TokenTime) * time.Minute)}
+	_, err = service.PasswordResetTokenRepository.Create(tx, ctx, passwordResetToken)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	body = "Hi " + user.Name.String + ",\r\n\r\n" +
+		"use the link below to reset your password, it expires in " + strconv.Itoa(service.Config.PasswordReset.TokenTime) + " minutes and can only be used once.\r\n\r\n" +
+		service.Config.PasswordReset.Url + token + "\r\n\r\n" +
+		"If you did not ask for a password reset, you can ignore this email."
+
+	httpCode = http.StatusOK
+	response = helpers.ToResponse("if the email is registered, a password reset link has been sent")
+	return
+}
+
+func (service *PasswordServiceImplementation) ResetPassword(ctx context.Context, resetPasswordRequest modelrequests.ResetPasswordRequest) (httpCode int, response interface{}) {
+	err := service.Validate.Struct(resetPasswordRequest)
+	if err != nil {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	passwordResetToken, err := service.PasswordResetTokenRepository.FindByTokenHash(tx, ctx, helpers.HashToken(resetPasswordRequest.Token))
+	if err != nil && err != pgx.ErrNoRows {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == pgx.ErrNoRows {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("invalid or expired token")
+		return
+	}
+	if passwordResetToken.UsedAt.Valid || !passwordResetToken.ExpiresAt.Time.After(time.Now()) {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("invalid or expired token")
+		return
+	}
+
+	userId := int(passwordResetToken.UserId.Int32)
+	user, err := service.UserRepository.FindById(tx, ctx, userId)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	violations, err := service.PasswordPolicyHelper.Check(resetPasswordRequest.Password, user.Name.String, user.Email.String)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if len(violations) > 0 {
+		httpCode = http.StatusBadRequest
+		response = modelresponses.PasswordPolicyResponse{
+			Message:    "password does not meet the password policy",
+			Violations: violations,
+		}
+		return
+	}
+
+	passwordHash, err := service.PasswordHasher.Hash(resetPasswordRequest.Password)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	rowsAffected, err := service.UserRepository.UpdatePassword(tx, ctx, passwordHash, userId)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if rowsAffected != 1 {
+		httpCode = http.StatusInternalServerError
+		err = errors.New("rows affected not one")
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	_, err = service.PasswordResetTokenRepository.UpdateUsedAtByUserId(tx, ctx, userId)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	// every session ends, the refresh tokens and the access tokens issued before the reset
+	_, err = service.UserRepository.RevokeSessions(tx, ctx, userId)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	err = recordSecurityEvent(tx, ctx, service.SecurityEventRepository, userId, user.Email.String, helpers.SecurityEventPasswordReset, "")
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode = http.StatusOK
+	response = helpers.ToResponse("successfully reset password")
+	return
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	"todo-list-api/repositories"
+	"todo-list-api/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+type PrincipalService interface {
+	FindByAccessToken(ctx context.Context, claims *helpers.AccessTokenCustomClaims) (httpCode int, principal helpers.Principal, response interface{})
+	FindByOauthAccessToken(ctx context.Context, claims *helpers.OauthAccessTokenCustomClaims) (httpCode int, principal helpers.Principal, response interface{})
+}
+
+type PrincipalServiceImplementation struct {
+	PostgresUtil         utils.PostgresUtil
+	UserRepository       repositories.UserRepository
+	OauthGrantRepository repositories.OauthGrantRepository
+}
+
+func NewPrincipalService(postgresUtil utils.PostgresUtil, userRepository repositories.UserRepository, oauthGrantRepository repositories.OauthGrantRepository) PrincipalService {
+	return &PrincipalServiceImplementation{
+		PostgresUtil:         postgresUtil,
+		UserRepository:       userRepository,
+		OauthGrantRepository: oauthGrantRepository,
+	}
+}
+
+// FindByAccessToken loads the user on every request, so disabling an account, a forced logout or a role change applies
+// to access tokens that are already out instead of waiting for them to expire.
+func (service *PrincipalServiceImplementation) FindByAccessToken(ctx context.Context, claims *helpers.AccessTokenCustomClaims) (httpCode int, principal helpers.Principal, response interface{}) {
+	httpCode, user, response := service.findSession(ctx, claims.Id, claims.IssuedAt)
+	if httpCode != http.StatusOK {
+		return
+	}
+
+	principal = helpers.Principal{
+		Id:    int(user.Id.Int32),
+		Name:  user.Name.String,
+		Email: user.Email.String,
+		Role:  user.Role.String,
+	}
+	return
+}
+
+// FindByOauthAccessToken also checks the grant, a token stops working once the user or the client revokes it.
+// The principal gets no role, a third party client never acts as an admin.
+func (service *PrincipalServiceImplementation) FindByOauthAccessToken(ctx context.Context, claims *helpers.OauthAccessTokenCustomClaims) (httpCode int, principal helpers.Principal, response interface{}) {
+	oauthGrant, err := service.OauthGrantRepository.FindActiveById(service.PostgresUtil.GetPool(), ctx, claims.GrantId)
+	if err != nil && err != pgx.ErrNoRows {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if (err != nil && err == pgx.ErrNoRows) || int(oauthGrant.UserId.Int32) != claims.Id || oauthGrant.ClientId.String != claims.ClientId {
+		httpCode = http.StatusUnauthorized
+		response = helpers.ToResponse("Unauthorized")
+		return
+	}
+
+	httpCode, user, response := service.findSession(ctx, claims.Id, claims.IssuedAt)
+	if httpCode != http.StatusOK {
+		return
+	}
+
+	principal = helpers.Principal{
+		Id:       int(user.Id.Int32),
+		Name:     user.Name.String,
+		Email:    user.Email.String,
+		ClientId: claims.ClientId,
+		Scopes:   helpers.ParseOauthScopes(claims.Scope),
+	}
+	return
+}
+
+func (service *PrincipalServiceImplementation) findSession(ctx context.Context, id int, issuedAt *jwt.NumericDate) (httpCode int, user modelentities.User, response interface{}) {
+	user, err := service.UserRepository.FindSessionById(service.PostgresUtil.GetPool(), ctx, id)
+	if err != nil && err != pgx.ErrNoRows {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == pgx.ErrNoRows {
+		httpCode = http.StatusUnauthorized
+		response = helpers.ToResponse("Unauthorized")
+		return
+	}
+	if user.DisabledAt.Valid {
+		httpCode = http.StatusForbidden
+		response = helpers.ToResponse("account is disabled")
+		return
+	}
+	// iat only has seconds and sessions_revoked_at is cut to the second, a token issued in the second of the revocation
+	// is kept so the login right after a reset or a logout of every session works
+	if user.SessionsRevokedAt.Valid && (issuedAt == nil || issuedAt.Time.Before(user.SessionsRevokedAt.Time)) {
+		httpCode = http.StatusUnauthorized
+		response = helpers.ToResponse("Unauthorized")
+		return
+	}
+	httpCode = http.StatusOK
+	return
+}
@@ -0,0 +1,394 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	modelrequests "todo-list-api/models/requests"
+	modelresponses "todo-list-api/models/responses"
+	"todo-list-api/repositories"
+	"todo-list-api/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ProfileService interface {
+	Get(ctx context.Context) (httpCode int, response interface{})
+	Update(ctx context.Context, updateProfileRequest modelrequests.UpdateProfileRequest) (httpCode int, response interface{})
+	ChangePassword(ctx context.Context, changePasswordRequest modelrequests.ChangePasswordRequest) (httpCode int, accessToken string, refreshToken string, response interface{})
+	ChangeEmail(ctx context.Context, changeEmailRequest modelrequests.ChangeEmailRequest) (httpCode int, response interface{})
+}
+
+type ProfileServiceImplementation struct {
+	PostgresUtil            utils.PostgresUtil
+	Validate                *validator.Validate
+	UserRepository          repositories.UserRepository
+	PasswordHasher          helpers.PasswordHasher
+	PasswordPolicyHelper    helpers.PasswordPolicyHelper
+	JwtHelper               helpers.JwtHelper
+	Mailer                  helpers.Mailer
+	SecurityEventRepository repositories.SecurityEventRepository
+	Config                  config.Config
+}
+
+func NewProfileService(postgresUtil utils.PostgresUtil, validate *validator.Validate, userRepository repositories.UserRepository, passwordHasher helpers.PasswordHasher, passwordPolicyHelper helpers.PasswordPolicyHelper, jwtHelper helpers.JwtHelper, mailer helpers.Mailer, securityEventRepository repositories.SecurityEventRepository, config config.Config) ProfileService {
+	return &ProfileServiceImplementation{
+		PostgresUtil:            postgresUtil,
+		Validate:                validate,
+		UserRepository:          userRepository,
+		PasswordHasher:          passwordHasher,
+		PasswordPolicyHelper:    passwordPolicyHelper,
+		JwtHelper:               jwtHelper,
+		Mailer:                  mailer,
+		SecurityEventRepository: securityEventRepository,
+		Config:                  config,
+	}
+}
+
+func toProfileResponse(user modelentities.User) modelresponses.ProfileResponse {
+	return modelresponses.ProfileResponse{
+		Id:               int(user.Id.Int32),
+		Name:             user.Name.String,
+		Email:            user.Email.String,
+		EmailVerified:    user.EmailVerifiedAt.Valid,
+		TwoFactorEnabled: user.TotpEnabledAt.Valid,
+	}
+}
+
+func (service *ProfileServiceImplementation) Get(ctx context.Context) (httpCode int, response interface{}) {
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	user, err := service.UserRepository.FindById(tx, ctx, principal.Id)
+	if err != nil && err != pgx.ErrNoRows {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == pgx.ErrNoRows {
+		httpCode = http.StatusNotFound
+		response = helpers.ToResponse("cannot find user")
+		return
+	}
+
+	httpCode = http.StatusOK
+	response = toProfileResponse(user)
+	return
+}
+
+func (service *ProfileServiceImplementation) Update(ctx context.Context, updateProfileRequest modelrequests.UpdateProfileRequest) (httpCode int, response interface{}) {
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+
+	err := service.Validate.Struct(updateProfileRequest)
+	if err != nil {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	rowsAffected, err := service.UserRepository.UpdateName(tx, ctx, updateProfileRequest.Name, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if rowsAffected != 1 {
+		httpCode = http.StatusInternalServerError
+		err = errors.New("rows affected not one")
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	user, err := service.UserRepository.FindById(tx, ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode = http.StatusOK
+	response = toProfileResponse(user)
+	return
+}
+
+// ChangePassword ends every session, the refresh tokens and the access tokens issued before, and hands new tokens to the caller.
+func (service *ProfileServiceImplementation) ChangePassword(ctx context.Context, changePasswordRequest modelrequests.ChangePasswordRequest) (httpCode int, accessToken string, refreshToken string, response interface{}) {
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+
+	err := service.Validate.Struct(changePasswordRequest)
+	if err != nil {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			accessToken = ""
+			refreshToken = ""
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	user, err := service.UserRepository.FindById(tx, ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	_, err = service.PasswordHasher.Verify(user.Password.String, changePasswordRequest.CurrentPassword)
+	if err != nil && err != helpers.ErrPasswordMismatch {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == helpers.ErrPasswordMismatch {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("wrong password")
+		return
+	}
+
+	violations, err := service.PasswordPolicyHelper.Check(changePasswordRequest.NewPassword, user.Name.String, user.Email.String)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if len(violations) > 0 {
+		httpCode = http.StatusBadRequest
+		response = modelresponses.PasswordPolicyResponse{
+			Message:    "password does not meet the password policy",
+			Violations: violations,
+		}
+		return
+	}
+
+	passwordHash, err := service.PasswordHasher.Hash(changePasswordRequest.NewPassword)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	rowsAffected, err := service.UserRepository.UpdatePassword(tx, ctx, passwordHash, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if rowsAffected != 1 {
+		httpCode = http.StatusInternalServerError
+		err = errors.New("rows affected not one")
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	_, err = service.UserRepository.RevokeSessions(tx, ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	accessToken, err = service.JwtHelper.GenerateAccessToken(int(user.Id.Int32), user.Name.String, user.Email.String, service.Config.Jwt.AccessTokenTime)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	refreshToken, err = service.JwtHelper.GenerateRefreshToken(int(user.Id.Int32), service.Config.Jwt.RefreshTokenTime)
+	if err != nil {
+		accessToken = ""
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	rowsAffected, err = service.UserRepository.UpdateRefreshToken(tx, ctx, refreshToken, int(user.Id.Int32))
+	if err != nil {
+		accessToken = ""
+		refreshToken = ""
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if rowsAffected != 1 {
+		accessToken = ""
+		refreshToken = ""
+		httpCode = http.StatusInternalServerError
+		err = errors.New("rows affected not one")
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	err = recordSecurityEvent(tx, ctx, service.SecurityEventRepository, principal.Id, user.Email.String, helpers.SecurityEventPasswordChanged, "")
+	if err != nil {
+		accessToken = ""
+		refreshToken = ""
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode = http.StatusOK
+	response = helpers.ToResponse("successfully changed password")
+	return
+}
+
+func (service *ProfileServiceImplementation) ChangeEmail(ctx context.Context, changeEmailRequest modelrequests.ChangeEmailRequest) (httpCode int, response interface{}) {
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+
+	err := service.Validate.Struct(changeEmailRequest)
+	if err != nil {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	user, err := service.UserRepository.FindById(tx, ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	_, err = service.PasswordHasher.Verify(user.Password.String, changeEmailRequest.Password)
+	if err != nil && err != helpers.ErrPasswordMismatch {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == helpers.ErrPasswordMismatch {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("wrong password")
+		return
+	}
+	if strings.EqualFold(user.Email.String, changeEmailRequest.Email) {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("new email is the same as the current email")
+		return
+	}
+
+	_, err = service.UserRepository.FindByEmail(tx, ctx, changeEmailRequest.Email)
+	if err != nil && err != pgx.ErrNoRows {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err == nil {
+		httpCode = http.StatusConflict
+		response = helpers.ToResponse("email already registered")
+		return
+	}
+
+	rowsAffected, err := service.UserRepository.UpdateEmail(tx, ctx, changeEmailRequest.Email, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if rowsAffected != 1 {
+		httpCode = http.StatusInternalServerError
+		err = errors.New("rows affected not one")
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	oldEmail := user.Email.String
+	user.Email = pgtype.Text{Valid: true, String: changeEmailRequest.Email}
+	err = sendVerificationEmail(service.JwtHelper, service.Mailer, service.Config.EmailVerification, user)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	// the old address is told about the change so a hijacked session cannot move the account away unnoticed
+	body := "Hi " + user.Name.String + ",\r\n\r\n" +
+		"the email of your account was changed to " + changeEmailRequest.Email + ".\r\n\r\n" +
+		"If you did not do this, reset your password and contact us."
+	err = service.Mailer.Send(oldEmail, "Your email was changed", body)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode = http.StatusOK
+	response = helpers.ToResponse("successfully changed email, check the new address for a verification link")
+	return
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	modelrequests "todo-list-api/models/requests"
+	modelresponses "todo-list-api/models/responses"
+	"todo-list-api/repositories"
+	"todo-list-api/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type SecurityEventService interface {
+	FindMine(ctx context.Context, page int, limit int) (httpCode int, response interface{})
+	FindAll(ctx context.Context, findSecurityEventsRequest modelrequests.FindSecurityEventsRequest) (httpCode int, response interface{})
+}
+
+type SecurityEventServiceImplementation struct {
+	PostgresUtil            utils.PostgresUtil
+	SecurityEventRepository repositories.SecurityEventRepository
+}
+
+func NewSecurityEventService(postgresUtil utils.PostgresUtil, securityEventRepository repositories.SecurityEventRepository) SecurityEventService {
+	return &SecurityEventServiceImplementation{
+		PostgresUtil:            postgresUtil,
+		SecurityEventRepository: securityEventRepository,
+	}
+}
+
+func (service *SecurityEventServiceImplementation) FindMine(ctx context.Context, page int, limit int) (httpCode int, response interface{}) {
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+
+	var securityEventFilter modelentities.SecurityEventFilter
+	securityEventFilter.UserId = principal.Id
+	return service.findByFilter(ctx, securityEventFilter, page, limit)
+}
+
+func (service *SecurityEventServiceImplementation) FindAll(ctx context.Context, findSecurityEventsRequest modelrequests.FindSecurityEventsRequest) (httpCode int, response interface{}) {
+	if findSecurityEventsRequest.EventType != "" {
+		if _, ok := helpers.SecurityEventTypes[findSecurityEventsRequest.EventType]; !ok {
+			httpCode = http.StatusBadRequest
+			response = helpers.ToResponse("unknown event type " + findSecurityEventsRequest.EventType)
+			return
+		}
+	}
+
+	var securityEventFilter modelentities.SecurityEventFilter
+	securityEventFilter.UserId = findSecurityEventsRequest.UserId
+	securityEventFilter.EventType = findSecurityEventsRequest.EventType
+	securityEventFilter.IpAddress = findSecurityEventsRequest.IpAddress
+	var err error
+	securityEventFilter.From, err = parseSecurityEventTime(findSecurityEventsRequest.From)
+	if err != nil {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("from must be an RFC 3339 time")
+		return
+	}
+	securityEventFilter.To, err = parseSecurityEventTime(findSecurityEventsRequest.To)
+	if err != nil {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("to must be an RFC 3339 time")
+		return
+	}
+	return service.findByFilter(ctx, securityEventFilter, findSecurityEventsRequest.Page, findSecurityEventsRequest.Limit)
+}
+
+func (service *SecurityEventServiceImplementation) findByFilter(ctx context.Context, securityEventFilter modelentities.SecurityEventFilter, page int, limit int) (httpCode int, response interface{}) {
+	if page < 1 || limit < 1 || limit > 100 {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("page must be at least 1 and limit between 1 and 100")
+		return
+	}
+
+	offset := (page - 1) * limit
+	securityEvents, err := service.SecurityEventRepository.FindByFilterWithPagination(service.PostgresUtil.GetPool(), ctx, securityEventFilter, offset, limit)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	numberOfSecurityEvents, err := service.SecurityEventRepository.CountByFilter(service.PostgresUtil.GetPool(), ctx, securityEventFilter)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	securityEventResponses := []modelresponses.SecurityEventResponse{}
+	for _, securityEvent := range securityEvents {
+		securityEventResponses = append(securityEventResponses, toSecurityEventResponse(securityEvent))
+	}
+
+	var getSecurityEventResponse modelresponses.GetSecurityEventResponse
+	getSecurityEventResponse.Data = securityEventResponses
+	getSecurityEventResponse.Page = page
+	getSecurityEventResponse.Limit = limit
+	getSecurityEventResponse.Total = numberOfSecurityEvents
+
+	httpCode = http.StatusOK
+	response = getSecurityEventResponse
+	return
+}
+
+func parseSecurityEventTime(value string) (timestamptz pgtype.Timestamptz, err error) {
+	if value == "" {
+		return
+	}
+	parsedTime, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return
+	}
+	timestamptz = pgtype.Timestamptz{Valid: true, Time: parsedTime}
+	return
+}
+
+// recordSecurityEvent writes the event in the transaction of the action it describes, so neither exists without the other.
+// userId is 0 when the attempt does not belong to a known user, email then keeps what was tried.
+func recordSecurityEvent(tx pgx.Tx, ctx context.Context, securityEventRepository repositories.SecurityEventRepository, userId int, email string, eventType string, detail string) (err error) {
+	var securityEvent modelentities.SecurityEvent
+	if userId != 0 {
+		securityEvent.UserId = pgtype.Int4{Valid: true, Int32: int32(userId)}
+	}
+	securityEvent.EventType = pgtype.Text{Valid: true, String: eventType}
+	securityEvent.Email = pgtype.Text{Valid: email != "", String: email}
+	securityEvent.Detail = pgtype.Text{Valid: detail != "", String: detail}
+	clientInfo, _ := helpers.ClientInfoFromContext(ctx)
+	securityEvent.IpAddress = pgtype.Text{Valid: true, String: clientInfo.IpAddress}
+	securityEvent.UserAgent = pgtype.Text{Valid: true, String: clientInfo.UserAgent}
+	_, err = securityEventRepository.Create(tx, ctx, securityEvent)
+	if err != nil {
+		return
+	}
+	helpers.LoggerFromContext(ctx).Info("security event", slog.String("event_type", eventType), slog.Int("user_id", userId), slog.String("detail", detail))
+	return
+}
+
+// actorDetail names who acted on an account, for the detail of the security event: the role and the id of the
+// principal, like admin:7, or cli for the commands, which have no account.
+func actorDetail(principal helpers.Principal) string {
+	if principal.Id == 0 {
+		return "cli"
+	}
+	return principal.Role + ":" + strconv.Itoa(principal.Id)
+}
+
+// recordLoginEvent records a login_succeeded or login_failed event and counts it in the metrics, detail is the login
+// method or the reason of the failure.
+func recordLoginEvent(tx pgx.Tx, ctx context.Context, securityEventRepository repositories.SecurityEventRepository, metricsHelper helpers.MetricsHelper, userId int, email string, eventType string, detail string) (err error) {
+	err = recordSecurityEvent(tx, ctx, securityEventRepository, userId, email, eventType, detail)
+	if err != nil {
+		return
+	}
+	result := helpers.LoginFailed
+	if eventType == helpers.SecurityEventLoginSucceeded {
+		result = helpers.LoginSucceeded
+	}
+	metricsHelper.CountLogin(result, detail)
+	return
+}
+
+func toSecurityEventResponse(securityEvent modelentities.SecurityEvent) modelresponses.SecurityEventResponse {
+	var userId *int
+	if securityEvent.UserId.Valid {
+		id := int(securityEvent.UserId.Int32)
+		userId = &id
+	}
+	return modelresponses.SecurityEventResponse{
+		Id:        int(securityEvent.Id.Int32),
+		UserId:    userId,
+		EventType: securityEvent.EventType.String,
+		Email:     securityEvent.Email.String,
+		Detail:    securityEvent.Detail.String,
+		IpAddress: securityEvent.IpAddress.String,
+		UserAgent: securityEvent.UserAgent.String,
+		CreatedAt: securityEvent.CreatedAt.Time,
+	}
+}
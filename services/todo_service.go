@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"net/http"
+	"todo-list-api/config"
 	"todo-list-api/helpers"
 	modelentities "todo-list-api/models/entities"
 	modelrequests "todo-list-api/models/requests"
@@ -26,17 +27,26 @@ type TodoServiceImplementation struct {
 	PostgresUtil   utils.PostgresUtil
 	Validate       *validator.Validate
 	TodoRepository repositories.TodoRepository
+	UserRepository repositories.UserRepository
+	MetricsHelper  helpers.MetricsHelper
+	Config         config.Config
 }
 
-func NewTodoService(postgresUtil utils.PostgresUtil, validate *validator.Validate, todoRepository repositories.TodoRepository) TodoService {
+func NewTodoService(postgresUtil utils.PostgresUtil, validate *validator.Validate, todoRepository repositories.TodoRepository, userRepository repositories.UserRepository, metricsHelper helpers.MetricsHelper, config config.Config) TodoService {
 	return &TodoServiceImplementation{
 		PostgresUtil:   postgresUtil,
 		Validate:       validate,
 		TodoRepository: todoRepository,
+		UserRepository: userRepository,
+		MetricsHelper:  metricsHelper,
+		Config:         config,
 	}
 }
 
 func (service *TodoServiceImplementation) Create(ctx context.Context, createTodoRequest modelrequests.CreateTodoRequest) (httpCode int, response interface{}) {
+	ctx, span := helpers.StartSpan(ctx, "TodoService.Create")
+	defer func() { helpers.EndSpan(span, httpCode, response) }()
+
 	err := service.Validate.Struct(createTodoRequest)
 	if err != nil {
 		httpCode = http.StatusInternalServerError
@@ -44,7 +54,7 @@ func (service *TodoServiceImplementation) Create(ctx context.Context, createTodo
 		return
 	}
 
-	userId, ok := ctx.Value("userId").(int)
+	principal, ok := helpers.PrincipalFromContext(ctx)
 	if !ok {
 		httpCode = http.StatusInternalServerError
 		response = helpers.ToResponse("cannot find user id")
@@ -63,11 +73,28 @@ func (service *TodoServiceImplementation) Create(ctx context.Context, createTodo
 		if errCommitOrRollback != nil {
 			httpCode = http.StatusInternalServerError
 			response = helpers.ToResponse(errCommitOrRollback.Error())
+		} else if httpCode == http.StatusCreated {
+			service.MetricsHelper.CountTodoCreated()
 		}
 	}()
 
+	if service.Config.EmailVerification.Required {
+		var user modelentities.User
+		user, err = service.UserRepository.FindById(tx, ctx, principal.Id)
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+		if !user.EmailVerifiedAt.Valid {
+			httpCode = http.StatusForbidden
+			response = helpers.ToResponse("please verify your email before creating todos")
+			return
+		}
+	}
+
 	var todo modelentities.Todo
-	todo.UserId = pgtype.Int4{Valid: true, Int32: int32(userId)}
+	todo.UserId = pgtype.Int4{Valid: true, Int32: int32(principal.Id)}
 	todo.Title = pgtype.Text{Valid: true, String: createTodoRequest.Title}
 	todo.Description = pgtype.Text{Valid: true, String: createTodoRequest.Description}
 	lastInsertedId, err := service.TodoRepository.Create(tx, ctx, todo)
@@ -93,6 +120,9 @@ func (service *TodoServiceImplementation) Create(ctx context.Context, createTodo
 }
 
 func (service *TodoServiceImplementation) Update(ctx context.Context, id int, updateTodoRequest modelrequests.UpdateTodoRequest) (httpCode int, response interface{}) {
+	ctx, span := helpers.StartSpan(ctx, "TodoService.Update")
+	defer func() { helpers.EndSpan(span, httpCode, response) }()
+
 	err := service.Validate.Struct(updateTodoRequest)
 	if err != nil {
 		httpCode = http.StatusBadRequest
@@ -100,7 +130,7 @@ func (service *TodoServiceImplementation) Update(ctx context.Context, id int, up
 		return
 	}
 
-	userId, ok := ctx.Value("userId").(int)
+	principal, ok := helpers.PrincipalFromContext(ctx)
 	if !ok {
 		httpCode = http.StatusInternalServerError
 		response = helpers.ToResponse("cannot find user id")
@@ -122,7 +152,7 @@ func (service *TodoServiceImplementation) Update(ctx context.Context, id int, up
 		}
 	}()
 
-	todo, err := service.TodoRepository.FindByIdAndUserId(tx, ctx, id, userId)
+	todo, err := service.TodoRepository.FindByIdAndUserId(tx, ctx, id, principal.Id)
 	if err != nil && err != pgx.ErrNoRows {
 		httpCode = http.StatusInternalServerError
 		response = helpers.ToResponse(err.Error())
@@ -158,6 +188,9 @@ func (service *TodoServiceImplementation) Update(ctx context.Context, id int, up
 }
 
 func (service *TodoServiceImplementation) Delete(ctx context.Context, id int) (httpCode int, response interface{}) {
+	ctx, span := helpers.StartSpan(ctx, "TodoService.Delete")
+	defer func() { helpers.EndSpan(span, httpCode, response) }()
+
 	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		httpCode = http.StatusInternalServerError
@@ -173,14 +206,14 @@ func (service *TodoServiceImplementation) Delete(ctx context.Context, id int) (h
 		}
 	}()
 
-	userId, ok := ctx.Value("userId").(int)
+	principal, ok := helpers.PrincipalFromContext(ctx)
 	if !ok {
 		httpCode = http.StatusInternalServerError
 		response = helpers.ToResponse("cannot find user id")
 		return
 	}
 
-	_, err = service.TodoRepository.FindByIdAndUserId(tx, ctx, id, userId)
+	_, err = service.TodoRepository.FindByIdAndUserId(tx, ctx, id, principal.Id)
 	if err != nil && err != pgx.ErrNoRows {
 		httpCode = http.StatusInternalServerError
 		response = helpers.ToResponse(err.Error())
@@ -209,14 +242,17 @@ func (service *TodoServiceImplementation) Delete(ctx context.Context, id int) (h
 }
 
 func (service *TodoServiceImplementation) FindWithPagination(ctx context.Context, page int, limit int) (httpCode int, response interface{}) {
-	userId, ok := ctx.Value("userId").(int)
+	ctx, span := helpers.StartSpan(ctx, "TodoService.FindWithPagination")
+	defer func() { helpers.EndSpan(span, httpCode, response) }()
+
+	principal, ok := helpers.PrincipalFromContext(ctx)
 	if !ok {
 		httpCode = http.StatusInternalServerError
 		response = helpers.ToResponse("cannot find user id")
 		return
 	}
 	offset := (page - 1) * limit
-	todos, err := service.TodoRepository.FindByPagination(service.PostgresUtil.GetPool(), ctx, userId, offset, limit)
+	todos, err := service.TodoRepository.FindByPagination(service.PostgresUtil.GetPool(), ctx, principal.Id, offset, limit)
 	if err != nil {
 		httpCode = http.StatusInternalServerError
 		response = helpers.ToResponse(err.Error())
@@ -0,0 +1,547 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	modelrequests "todo-list-api/models/requests"
+	modelresponses "todo-list-api/models/responses"
+	"todo-list-api/repositories"
+	"todo-list-api/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type TwoFactorService interface {
+	Enroll(ctx context.Context) (httpCode int, response interface{})
+	Confirm(ctx context.Context, confirmTwoFactorRequest modelrequests.ConfirmTwoFactorRequest) (httpCode int, response interface{})
+	Disable(ctx context.Context, disableTwoFactorRequest modelrequests.DisableTwoFactorRequest) (httpCode int, response interface{})
+	Login(ctx context.Context, loginTwoFactorRequest modelrequests.LoginTwoFactorRequest) (httpCode int, accessToken string, refreshToken string, response interface{})
+}
+
+type TwoFactorServiceImplementation struct {
+	PostgresUtil            utils.PostgresUtil
+	Validate                *validator.Validate
+	UserRepository          repositories.UserRepository
+	RecoveryCodeRepository  repositories.RecoveryCodeRepository
+	LoginAttemptRepository  repositories.LoginAttemptRepository
+	MfaChallengeRepository  repositories.MfaChallengeRepository
+	PasswordHasher          helpers.PasswordHasher
+	JwtHelper               helpers.JwtHelper
+	TotpHelper              helpers.TotpHelper
+	SecurityEventRepository repositories.SecurityEventRepository
+	MetricsHelper           helpers.MetricsHelper
+	Config                  config.Config
+}
+
+func NewTwoFactorService(postgresUtil utils.PostgresUtil, validate *validator.Validate, userRepository repositories.UserRepository, recoveryCodeRepository repositories.RecoveryCodeRepository, loginAttemptRepository repositories.LoginAttemptRepository, mfaChallengeRepository repositories.MfaChallengeRepository, passwordHasher helpers.PasswordHasher, jwtHelper helpers.JwtHelper, totpHelper helpers.TotpHelper, securityEventRepository repositories.SecurityEventRepository, metricsHelper helpers.MetricsHelper, config config.Config) TwoFactorService {
+	return &TwoFactorServiceImplementation{
+		PostgresUtil:            postgresUtil,
+		Validate:                validate,
+		UserRepository:          userRepository,
+		RecoveryCodeRepository:  recoveryCodeRepository,
+		LoginAttemptRepository:  loginAttemptRepository,
+		MfaChallengeRepository:  mfaChallengeRepository,
+		PasswordHasher:          passwordHasher,
+		JwtHelper:               jwtHelper,
+		TotpHelper:              totpHelper,
+		SecurityEventRepository: securityEventRepository,
+		MetricsHelper:           metricsHelper,
+		Config:                  config,
+	}
+}
+
+func (service *TwoFactorServiceImplementation) Enroll(ctx context.Context) (httpCode int, response interface{}) {
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	user, err := service.UserRepository.FindById(tx, ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if user.TotpEnabledAt.Valid {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("two factor authentication already enabled")
+		return
+	}
+
+	secret, err := service.TotpHelper.GenerateSecret()
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	rowsAffected, err := service.UserRepository.UpdateTotpSecret(tx, ctx, secret, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if rowsAffected != 1 {
+		httpCode = http.StatusInternalServerError
+		err = errors.New("rows affected not one")
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	var enrollTwoFactorResponse modelresponses.EnrollTwoFactorResponse
+	enrollTwoFactorResponse.Secret = secret
+	enrollTwoFactorResponse.OtpauthUri = service.TotpHelper.GenerateUri(secret, user.Email.String)
+
+	httpCode = http.StatusOK
+	response = enrollTwoFactorResponse
+	return
+}
+
+func (service *TwoFactorServiceImplementation) Confirm(ctx context.Context, confirmTwoFactorRequest modelrequests.ConfirmTwoFactorRequest) (httpCode int, response interface{}) {
+	err := service.Validate.Struct(confirmTwoFactorRequest)
+	if err != nil {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	user, err := service.UserRepository.FindById(tx, ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if user.TotpEnabledAt.Valid {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("two factor authentication already enabled")
+		return
+	}
+	if !user.TotpSecret.Valid {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("two factor authentication enrollment not started")
+		return
+	}
+
+	step, ok := service.TotpHelper.Validate(user.TotpSecret.String, confirmTwoFactorRequest.Code, time.Now())
+	if !ok {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("wrong code")
+		return
+	}
+	_, err = service.UserRepository.UpdateTotpLastUsedStep(tx, ctx, step, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	rowsAffected, err := service.UserRepository.EnableTotp(tx, ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if rowsAffected != 1 {
+		httpCode = http.StatusInternalServerError
+		err = errors.New("rows affected not one")
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	recoveryCodes, err := service.TotpHelper.GenerateRecoveryCodes()
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	_, err = service.RecoveryCodeRepository.DeleteByUserId(tx, ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	for _, recoveryCode := range recoveryCodes {
+		var recoveryCodeEntity modelentities.RecoveryCode
+		recoveryCodeEntity.UserId = pgtype.Int4{Valid: true, Int32: int32(principal.Id)}
+		recoveryCodeEntity.CodeHash = pgtype.Text{Valid: true, String: helpers.HashToken(recoveryCode)}
+		_, err = service.RecoveryCodeRepository.Create(tx, ctx, recoveryCodeEntity)
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+	}
+	err = recordSecurityEvent(tx, ctx, service.SecurityEventRepository, principal.Id, principal.Email, helpers.SecurityEventTwoFactorEnabled, "")
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	var confirmTwoFactorResponse modelresponses.ConfirmTwoFactorResponse
+	confirmTwoFactorResponse.Message = "two factor authentication enabled, store the recovery codes somewhere safe, they are only shown once"
+	confirmTwoFactorResponse.RecoveryCodes = recoveryCodes
+
+	httpCode = http.StatusOK
+	response = confirmTwoFactorResponse
+	return
+}
+
+func (service *TwoFactorServiceImplementation) Disable(ctx context.Context, disableTwoFactorRequest modelrequests.DisableTwoFactorRequest) (httpCode int, response interface{}) {
+	err := service.Validate.Struct(disableTwoFactorRequest)
+	if err != nil {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	user, err := service.UserRepository.FindById(tx, ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	_, err = service.PasswordHasher.Verify(user.Password.String, disableTwoFactorRequest.Password)
+	if err != nil && err != helpers.ErrPasswordMismatch {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == helpers.ErrPasswordMismatch {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("wrong password")
+		return
+	}
+	if !user.TotpSecret.Valid {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("two factor authentication not enabled")
+		return
+	}
+
+	_, err = service.UserRepository.DisableTotp(tx, ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	_, err = service.RecoveryCodeRepository.DeleteByUserId(tx, ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	err = recordSecurityEvent(tx, ctx, service.SecurityEventRepository, principal.Id, principal.Email, helpers.SecurityEventTwoFactorDisabled, "")
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode = http.StatusOK
+	response = helpers.ToResponse("two factor authentication disabled")
+	return
+}
+
+func (service *TwoFactorServiceImplementation) Login(ctx context.Context, loginTwoFactorRequest modelrequests.LoginTwoFactorRequest) (httpCode int, accessToken string, refreshToken string, response interface{}) {
+	err := service.Validate.Struct(loginTwoFactorRequest)
+	if err != nil {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	claims, err := service.JwtHelper.ParseMfaPendingToken(loginTwoFactorRequest.MfaToken)
+	if err != nil {
+		httpCode = http.StatusUnauthorized
+		response = helpers.ToResponse("invalid or expired mfa token")
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			accessToken = ""
+			refreshToken = ""
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	user, err := service.UserRepository.FindById(tx, ctx, claims.Id)
+	if err != nil && err != pgx.ErrNoRows {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == pgx.ErrNoRows {
+		httpCode = http.StatusUnauthorized
+		response = helpers.ToResponse("invalid or expired mfa token")
+		return
+	}
+	if !user.TotpEnabledAt.Valid {
+		httpCode = http.StatusUnauthorized
+		response = helpers.ToResponse("invalid or expired mfa token")
+		return
+	}
+	if user.DisabledAt.Valid {
+		err = recordLoginEvent(tx, ctx, service.SecurityEventRepository, service.MetricsHelper, int(user.Id.Int32), user.Email.String, helpers.SecurityEventLoginFailed, "disabled")
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+		httpCode = http.StatusForbidden
+		response = helpers.ToResponse("account is disabled")
+		return
+	}
+
+	throttle := service.Config.Login
+	clientInfo, _ := helpers.ClientInfoFromContext(ctx)
+	attemptKeys := loginAttemptKeys(user.Email.String, clientInfo, throttle)
+	lockedAttempt, locked, err := findLockedAttempt(tx, ctx, service.LoginAttemptRepository, attemptKeys)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if locked {
+		err = recordLoginEvent(tx, ctx, service.SecurityEventRepository, service.MetricsHelper, int(user.Id.Int32), user.Email.String, helpers.SecurityEventLoginFailed, "locked")
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+		httpCode = http.StatusTooManyRequests
+		response = lockedResponse(lockedAttempt)
+		return
+	}
+
+	// a token is good for one login and a few guesses, then the password has to be given again
+	mfaChallenge, err := service.MfaChallengeRepository.FindOrCreate(tx, ctx, claims.ID, claims.Id, claims.ExpiresAt.Time)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if mfaChallenge.UsedAt.Valid || int(mfaChallenge.FailedCount.Int32) >= service.Config.Jwt.MfaMaxAttempts {
+		httpCode = http.StatusUnauthorized
+		response = helpers.ToResponse("invalid or expired mfa token")
+		return
+	}
+
+	loginMethod := "recovery_code"
+	if loginTwoFactorRequest.Code != "" {
+		loginMethod = "two_factor"
+		step, ok := service.TotpHelper.Validate(user.TotpSecret.String, loginTwoFactorRequest.Code, time.Now())
+		if !ok {
+			err = service.recordCodeFailure(tx, ctx, claims.ID, attemptKeys, throttle, clientInfo, user, "wrong_code")
+			if err != nil {
+				httpCode = http.StatusInternalServerError
+				response = helpers.ToResponse(err.Error())
+				return
+			}
+			httpCode = http.StatusBadRequest
+			response = helpers.ToResponse("wrong code")
+			return
+		}
+		var rowsAffected int64
+		rowsAffected, err = service.UserRepository.UpdateTotpLastUsedStep(tx, ctx, step, claims.Id)
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+		if rowsAffected != 1 {
+			err = service.recordCodeFailure(tx, ctx, claims.ID, attemptKeys, throttle, clientInfo, user, "reused_code")
+			if err != nil {
+				httpCode = http.StatusInternalServerError
+				response = helpers.ToResponse(err.Error())
+				return
+			}
+			httpCode = http.StatusBadRequest
+			response = helpers.ToResponse("code already used, wait for the next one")
+			return
+		}
+	} else {
+		codeHash := helpers.HashToken(strings.ToLower(strings.TrimSpace(loginTwoFactorRequest.RecoveryCode)))
+		var rowsAffected int64
+		rowsAffected, err = service.RecoveryCodeRepository.UpdateUsedAtByUserIdAndCodeHash(tx, ctx, claims.Id, codeHash)
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+		if rowsAffected != 1 {
+			err = service.recordCodeFailure(tx, ctx, claims.ID, attemptKeys, throttle, clientInfo, user, "wrong_recovery_code")
+			if err != nil {
+				httpCode = http.StatusInternalServerError
+				response = helpers.ToResponse(err.Error())
+				return
+			}
+			httpCode = http.StatusBadRequest
+			response = helpers.ToResponse("wrong recovery code")
+			return
+		}
+	}
+
+	rowsAffected, err := service.MfaChallengeRepository.UpdateUsedAt(tx, ctx, claims.ID)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if rowsAffected != 1 {
+		httpCode = http.StatusInternalServerError
+		err = errors.New("rows affected not one")
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	_, err = service.MfaChallengeRepository.DeleteExpiredByUserId(tx, ctx, int(user.Id.Int32))
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	_, err = service.LoginAttemptRepository.DeleteByAttemptKey(tx, ctx, attemptKeys[0].attemptKey)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	if user.DeletionScheduledAt.Valid {
+		_, err = service.UserRepository.CancelDeletion(tx, ctx, int(user.Id.Int32))
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+	}
+
+	accessToken, err = service.JwtHelper.GenerateAccessToken(int(user.Id.Int32), user.Name.String, user.Email.String, service.Config.Jwt.AccessTokenTime)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	refreshToken, err = service.JwtHelper.GenerateRefreshToken(int(user.Id.Int32), service.Config.Jwt.RefreshTokenTime)
+	if err != nil {
+		accessToken = ""
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	rowsAffected, err = service.UserRepository.UpdateRefreshToken(tx, ctx, refreshToken, int(user.Id.Int32))
+	if err != nil {
+		accessToken = ""
+		refreshToken = ""
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if rowsAffected != 1 {
+		accessToken = ""
+		refreshToken = ""
+		httpCode = http.StatusInternalServerError
+		err = errors.New("rows affected not one")
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	err = recordLoginEvent(tx, ctx, service.SecurityEventRepository, service.MetricsHelper, int(user.Id.Int32), user.Email.String, helpers.SecurityEventLoginSucceeded, loginMethod)
+	if err != nil {
+		accessToken = ""
+		refreshToken = ""
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode = http.StatusOK
+	response = helpers.ToResponse("successfully login")
+	return
+}
+
+// recordCodeFailure counts a wrong code against the mfa token and against the lockout of the email and the ip, like
+// a wrong password.
+func (service *TwoFactorServiceImplementation) recordCodeFailure(tx pgx.Tx, ctx context.Context, jti string, attemptKeys []loginAttemptKey, throttle config.LoginConfig, clientInfo helpers.ClientInfo, user modelentities.User, reason string) (err error) {
+	_, err = service.MfaChallengeRepository.IncrementFailedCount(tx, ctx, jti)
+	if err != nil {
+		return
+	}
+	err = recordLoginFailure(tx, ctx, service.LoginAttemptRepository, service.SecurityEventRepository, service.MetricsHelper, attemptKeys, throttle, clientInfo, int(user.Id.Int32), user.Email.String, reason)
+	return
+}
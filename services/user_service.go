@@ -3,46 +3,72 @@ package services
 import (
 	"context"
 	"errors"
+	"math"
 	"net/http"
-	"os"
 	"strconv"
+	"strings"
+	"time"
+	"todo-list-api/config"
 	"todo-list-api/helpers"
 	modelentities "todo-list-api/models/entities"
 	modelrequests "todo-list-api/models/requests"
+	modelresponses "todo-list-api/models/responses"
 	"todo-list-api/repositories"
 	"todo-list-api/utils"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService interface {
 	Register(ctx context.Context, registerRequest modelrequests.RegisterRequest) (httpCode int, accessToken string, refreshToken string, response interface{})
 	Login(ctx context.Context, loginRequest modelrequests.LoginRequest) (httpCode int, accessToken string, refreshToken string, response interface{})
 	RefreshToken(ctx context.Context, refreshToken string) (httpCode int, accessToken string, response interface{})
+	VerifyEmail(ctx context.Context, emailVerificationToken string) (httpCode int, response interface{})
+	ResendVerificationEmail(ctx context.Context) (httpCode int, response interface{})
+	Logout(ctx context.Context) (httpCode int, response interface{})
 }
 
 type UserServiceImplementation struct {
-	PostgresUtil   utils.PostgresUtil
-	Validate       *validator.Validate
-	UserRepository repositories.UserRepository
-	BcryptHelper   helpers.BcryptHelper
-	JwtHelper      helpers.JwtHelper
+	PostgresUtil            utils.PostgresUtil
+	Validate                *validator.Validate
+	UserRepository          repositories.UserRepository
+	PasswordHasher          helpers.PasswordHasher
+	JwtHelper               helpers.JwtHelper
+	Mailer                  helpers.Mailer
+	LoginAttemptRepository  repositories.LoginAttemptRepository
+	PasswordPolicyHelper    helpers.PasswordPolicyHelper
+	SecurityEventRepository repositories.SecurityEventRepository
+	MetricsHelper           helpers.MetricsHelper
+	Config                  config.Config
 }
 
-func NewUserService(postgresUtil utils.PostgresUtil, validate *validator.Validate, userRepository repositories.UserRepository, bcryptHelper helpers.BcryptHelper, jwtHelper helpers.JwtHelper) UserService {
+type loginAttemptKey struct {
+	attemptKey  string
+	maxAttempts int
+}
+
+func NewUserService(postgresUtil utils.PostgresUtil, validate *validator.Validate, userRepository repositories.UserRepository, passwordHasher helpers.PasswordHasher, jwtHelper helpers.JwtHelper, mailer helpers.Mailer, loginAttemptRepository repositories.LoginAttemptRepository, passwordPolicyHelper helpers.PasswordPolicyHelper, securityEventRepository repositories.SecurityEventRepository, metricsHelper helpers.MetricsHelper, config config.Config) UserService {
 	return &UserServiceImplementation{
-		PostgresUtil:   postgresUtil,
-		Validate:       validate,
-		UserRepository: userRepository,
-		BcryptHelper:   bcryptHelper,
-		JwtHelper:      jwtHelper,
+		PostgresUtil:            postgresUtil,
+		Validate:                validate,
+		UserRepository:          userRepository,
+		PasswordHasher:          passwordHasher,
+		JwtHelper:               jwtHelper,
+		Mailer:                  mailer,
+		LoginAttemptRepository:  loginAttemptRepository,
+		PasswordPolicyHelper:    passwordPolicyHelper,
+		SecurityEventRepository: securityEventRepository,
+		MetricsHelper:           metricsHelper,
+		Config:                  config,
 	}
 }
 
 func (service *UserServiceImplementation) Register(ctx context.Context, registerRequest modelrequests.RegisterRequest) (httpCode int, accessToken string, refreshToken string, response interface{}) {
+	ctx, span := helpers.StartSpan(ctx, "UserService.Register")
+	defer func() { helpers.EndSpan(span, httpCode, response) }()
+
 	var err error
 	err = service.Validate.Struct(registerRequest)
 	if err != nil {
@@ -51,6 +77,21 @@ func (service *UserServiceImplementation) Register(ctx context.Context, register
 		return
 	}
 
+	violations, err := service.PasswordPolicyHelper.Check(registerRequest.Password, registerRequest.Name, registerRequest.Email)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if len(violations) > 0 {
+		httpCode = http.StatusBadRequest
+		response = modelresponses.PasswordPolicyResponse{
+			Message:    "password does not meet the password policy",
+			Violations: violations,
+		}
+		return
+	}
+
 	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		httpCode = http.StatusInternalServerError
@@ -69,13 +110,13 @@ func (service *UserServiceImplementation) Register(ctx context.Context, register
 	var user modelentities.User
 	user.Name = pgtype.Text{Valid: true, String: registerRequest.Name}
 	user.Email = pgtype.Text{Valid: true, String: registerRequest.Email}
-	passwordByte, err := service.BcryptHelper.GenerateFromPassword([]byte(registerRequest.Password), bcrypt.DefaultCost)
+	passwordHash, err := service.PasswordHasher.Hash(registerRequest.Password)
 	if err != nil {
 		httpCode = http.StatusInternalServerError
 		response = helpers.ToResponse(err.Error())
 		return
 	}
-	user.Password = pgtype.Text{Valid: true, String: string(passwordByte)}
+	user.Password = pgtype.Text{Valid: true, String: passwordHash}
 	lastInsertedId, err := service.UserRepository.Create(tx, ctx, user)
 	if err != nil {
 		httpCode = http.StatusInternalServerError
@@ -83,26 +124,20 @@ func (service *UserServiceImplementation) Register(ctx context.Context, register
 		return
 	}
 	user.Id = pgtype.Int4{Valid: true, Int32: int32(lastInsertedId)}
-
-	jwtAccessTokenTimeEnv := os.Getenv("JWT_ACCESS_TOKEN_TIME")
-	jwtAccessTokenTime, err := strconv.Atoi(jwtAccessTokenTimeEnv)
-	if err != nil {
-		return
-	}
-	accessToken, err = service.JwtHelper.GenerateAccessToken(int(user.Id.Int32), user.Name.String, user.Email.String, jwtAccessTokenTime, os.Getenv("JWT_SECRET"))
+	err = recordSecurityEvent(tx, ctx, service.SecurityEventRepository, int(user.Id.Int32), user.Email.String, helpers.SecurityEventRegistered, "")
 	if err != nil {
 		httpCode = http.StatusInternalServerError
 		response = helpers.ToResponse(err.Error())
 		return
 	}
-	jwtRefreshTokenTimeEnv := os.Getenv("JWT_REFRESH_TOKEN_TIME")
-	jwtRefreshTokenTime, err := strconv.Atoi(jwtRefreshTokenTimeEnv)
+
+	accessToken, err = service.JwtHelper.GenerateAccessToken(int(user.Id.Int32), user.Name.String, user.Email.String, service.Config.Jwt.AccessTokenTime)
 	if err != nil {
 		httpCode = http.StatusInternalServerError
 		response = helpers.ToResponse(err.Error())
 		return
 	}
-	refreshToken, err = service.JwtHelper.GenerateRefreshToken(int(user.Id.Int32), jwtRefreshTokenTime, os.Getenv("JWT_SECRET"))
+	refreshToken, err = service.JwtHelper.GenerateRefreshToken(int(user.Id.Int32), service.Config.Jwt.RefreshTokenTime)
 	if err != nil {
 		httpCode = http.StatusInternalServerError
 		accessToken = ""
@@ -127,12 +162,32 @@ func (service *UserServiceImplementation) Register(ctx context.Context, register
 		return
 	}
 
+	_, err = service.UserRepository.UpdateEmailVerificationSentAt(tx, ctx, int(user.Id.Int32), 0)
+	if err != nil {
+		accessToken = ""
+		refreshToken = ""
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	err = sendVerificationEmail(service.JwtHelper, service.Mailer, service.Config.EmailVerification, user)
+	if err != nil {
+		accessToken = ""
+		refreshToken = ""
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
 	httpCode = http.StatusCreated
 	response = helpers.ToResponse("successfully registered")
 	return
 }
 
 func (service *UserServiceImplementation) Login(ctx context.Context, loginRequest modelrequests.LoginRequest) (httpCode int, accessToken string, refreshToken string, response interface{}) {
+	ctx, span := helpers.StartSpan(ctx, "UserService.Login")
+	defer func() { helpers.EndSpan(span, httpCode, response) }()
+
 	err := service.Validate.Struct(loginRequest)
 	if err != nil {
 		httpCode = http.StatusInternalServerError
@@ -140,6 +195,7 @@ func (service *UserServiceImplementation) Login(ctx context.Context, loginReques
 		return
 	}
 
+	throttle := service.Config.Login
 	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		httpCode = http.StatusInternalServerError
@@ -155,46 +211,136 @@ func (service *UserServiceImplementation) Login(ctx context.Context, loginReques
 		}
 	}()
 
+	clientInfo, _ := helpers.ClientInfoFromContext(ctx)
+	attemptKeys := loginAttemptKeys(loginRequest.Email, clientInfo, throttle)
+	lockedAttempt, locked, err := findLockedAttempt(tx, ctx, service.LoginAttemptRepository, attemptKeys)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if locked {
+		err = recordLoginEvent(tx, ctx, service.SecurityEventRepository, service.MetricsHelper, 0, loginRequest.Email, helpers.SecurityEventLoginFailed, "locked")
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+		httpCode = http.StatusTooManyRequests
+		response = lockedResponse(lockedAttempt)
+		return
+	}
+
 	user, err := service.UserRepository.FindByEmail(tx, ctx, loginRequest.Email)
 	if err != nil && err != pgx.ErrNoRows {
 		httpCode = http.StatusInternalServerError
 		response = helpers.ToResponse(err.Error())
 		return
 	} else if err != nil && err == pgx.ErrNoRows {
+		// verifying against a dummy hash makes an unknown email take as long as a wrong password
+		_, _ = service.PasswordHasher.Verify(service.PasswordHasher.DummyHash(), loginRequest.Password)
+		err = recordLoginFailure(tx, ctx, service.LoginAttemptRepository, service.SecurityEventRepository, service.MetricsHelper, attemptKeys, throttle, clientInfo, 0, loginRequest.Email, "unknown_email")
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
 		httpCode = http.StatusBadRequest
 		response = helpers.ToResponse("wrong email or password")
 		return
 	}
 
-	err = service.BcryptHelper.CompareHashAndPassword([]byte(user.Password.String), []byte(loginRequest.Password))
-	if err != nil {
+	needsRehash, err := service.PasswordHasher.Verify(user.Password.String, loginRequest.Password)
+	if err != nil && err != helpers.ErrPasswordMismatch {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == helpers.ErrPasswordMismatch {
+		err = recordLoginFailure(tx, ctx, service.LoginAttemptRepository, service.SecurityEventRepository, service.MetricsHelper, attemptKeys, throttle, clientInfo, int(user.Id.Int32), loginRequest.Email, "wrong_password")
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
 		httpCode = http.StatusBadRequest
 		response = helpers.ToResponse("wrong email or password")
 		return
 	}
 
-	jwtAccessTokenTimeEnv := os.Getenv("JWT_ACCESS_TOKEN_TIME")
-	jwtAccessTokenTime, err := strconv.Atoi(jwtAccessTokenTimeEnv)
-	if err != nil {
-		httpCode = http.StatusInternalServerError
-		response = helpers.ToResponse(err.Error())
+	// checked after the password so the status of an account is only told to someone who knows its password
+	if user.DisabledAt.Valid {
+		err = recordLoginEvent(tx, ctx, service.SecurityEventRepository, service.MetricsHelper, int(user.Id.Int32), loginRequest.Email, helpers.SecurityEventLoginFailed, "disabled")
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+		httpCode = http.StatusForbidden
+		response = helpers.ToResponse("account is disabled")
+		return
+	}
+
+	// the password is only known here, so this is the moment to move the stored hash to the current algorithm and parameters
+	if needsRehash {
+		var passwordHash string
+		passwordHash, err = service.PasswordHasher.Hash(loginRequest.Password)
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+		_, err = service.UserRepository.UpdatePassword(tx, ctx, passwordHash, int(user.Id.Int32))
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+	}
+
+	if user.TotpEnabledAt.Valid {
+		var mfaPendingToken string
+		mfaPendingToken, err = service.JwtHelper.GenerateMfaPendingToken(int(user.Id.Int32), service.Config.Jwt.MfaPendingTokenTime)
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+		httpCode = http.StatusOK
+		response = modelresponses.MfaRequiredResponse{
+			Message:     "two factor authentication code required",
+			MfaRequired: true,
+			MfaToken:    mfaPendingToken,
+		}
 		return
 	}
-	accessToken, err = service.JwtHelper.GenerateAccessToken(int(user.Id.Int32), user.Name.String, user.Email.String, jwtAccessTokenTime, os.Getenv("JWT_SECRET"))
+
+	// only the account counter is cleared, one valid login must not reset the counter of an ip guessing other accounts.
+	// With two factor it is cleared by the code, else the password alone would reset the guesses at the code
+	_, err = service.LoginAttemptRepository.DeleteByAttemptKey(tx, ctx, attemptKeys[0].attemptKey)
 	if err != nil {
 		httpCode = http.StatusInternalServerError
 		response = helpers.ToResponse(err.Error())
 		return
 	}
 
-	jwtRefreshTokenTimeEnv := os.Getenv("JWT_REFRESH_TOKEN_TIME")
-	jwtRefreshTokenTime, err := strconv.Atoi(jwtRefreshTokenTimeEnv)
+	// logging in during the grace period keeps the account
+	if user.DeletionScheduledAt.Valid {
+		_, err = service.UserRepository.CancelDeletion(tx, ctx, int(user.Id.Int32))
+		if err != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(err.Error())
+			return
+		}
+	}
+
+	accessToken, err = service.JwtHelper.GenerateAccessToken(int(user.Id.Int32), user.Name.String, user.Email.String, service.Config.Jwt.AccessTokenTime)
 	if err != nil {
 		httpCode = http.StatusInternalServerError
 		response = helpers.ToResponse(err.Error())
 		return
 	}
-	refreshToken, err = service.JwtHelper.GenerateRefreshToken(int(user.Id.Int32), jwtRefreshTokenTime, os.Getenv("JWT_SECRET"))
+
+	refreshToken, err = service.JwtHelper.GenerateRefreshToken(int(user.Id.Int32), service.Config.Jwt.RefreshTokenTime)
 	if err != nil {
 		accessToken = ""
 		httpCode = http.StatusInternalServerError
@@ -218,13 +364,99 @@ func (service *UserServiceImplementation) Login(ctx context.Context, loginReques
 		response = helpers.ToResponse(err.Error())
 		return
 	}
+	err = recordLoginEvent(tx, ctx, service.SecurityEventRepository, service.MetricsHelper, int(user.Id.Int32), loginRequest.Email, helpers.SecurityEventLoginSucceeded, "password")
+	if err != nil {
+		accessToken = ""
+		refreshToken = ""
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
 
 	httpCode = http.StatusOK
 	response = helpers.ToResponse("successfully login")
 	return
 }
 
+// loginAttemptKeys are the counters a login attempt for email is checked and counted against, the email and the ip
+// of the client. The password and the second factor share them, a guessed code counts like a guessed password.
+func loginAttemptKeys(email string, clientInfo helpers.ClientInfo, throttle config.LoginConfig) []loginAttemptKey {
+	attemptKeys := []loginAttemptKey{{attemptKey: "email:" + strings.ToLower(email), maxAttempts: throttle.MaxAttempts}}
+	if clientInfo.IpAddress != "" {
+		attemptKeys = append(attemptKeys, loginAttemptKey{attemptKey: "ip:" + clientInfo.IpAddress, maxAttempts: throttle.MaxAttemptsPerIp})
+	}
+	return attemptKeys
+}
+
+// findLockedAttempt returns the first of attemptKeys that is locked right now.
+func findLockedAttempt(tx pgx.Tx, ctx context.Context, loginAttemptRepository repositories.LoginAttemptRepository, attemptKeys []loginAttemptKey) (lockedAttempt modelentities.LoginAttempt, locked bool, err error) {
+	for _, attemptKey := range attemptKeys {
+		var loginAttempt modelentities.LoginAttempt
+		loginAttempt, err = loginAttemptRepository.FindByAttemptKey(tx, ctx, attemptKey.attemptKey)
+		if err != nil && err != pgx.ErrNoRows {
+			return
+		} else if err != nil && err == pgx.ErrNoRows {
+			err = nil
+			continue
+		}
+		if loginAttempt.LockedUntil.Valid && loginAttempt.LockedUntil.Time.After(time.Now()) {
+			lockedAttempt = loginAttempt
+			locked = true
+			return
+		}
+	}
+	return
+}
+
+func lockedResponse(lockedAttempt modelentities.LoginAttempt) modelresponses.RetryAfterResponse {
+	return modelresponses.RetryAfterResponse{
+		Message:    "too many failed login attempts, try again later",
+		RetryAfter: int(math.Ceil(time.Until(lockedAttempt.LockedUntil.Time).Seconds())),
+	}
+}
+
+// recordLoginFailure counts the failure against every key and locks the keys that went over their limit.
+// userId is 0 when the email belongs to no account.
+func recordLoginFailure(tx pgx.Tx, ctx context.Context, loginAttemptRepository repositories.LoginAttemptRepository, securityEventRepository repositories.SecurityEventRepository, metricsHelper helpers.MetricsHelper, attemptKeys []loginAttemptKey, throttle config.LoginConfig, clientInfo helpers.ClientInfo, userId int, email string, reason string) (err error) {
+	err = recordLoginEvent(tx, ctx, securityEventRepository, metricsHelper, userId, email, helpers.SecurityEventLoginFailed, reason)
+	if err != nil {
+		return
+	}
+	for _, attemptKey := range attemptKeys {
+		var failedCount int
+		failedCount, err = loginAttemptRepository.IncrementFailedCount(tx, ctx, attemptKey.attemptKey, throttle.AttemptWindow)
+		if err != nil {
+			return
+		}
+		lockSeconds := helpers.LockoutDuration(failedCount, attemptKey.maxAttempts, throttle.LockoutTime, throttle.LockoutMaxTime)
+		if lockSeconds == 0 {
+			continue
+		}
+		_, err = loginAttemptRepository.UpdateLockedUntil(tx, ctx, attemptKey.attemptKey, lockSeconds)
+		if err != nil {
+			return
+		}
+		var loginLockoutEvent modelentities.LoginLockoutEvent
+		loginLockoutEvent.AttemptKey = pgtype.Text{Valid: true, String: attemptKey.attemptKey}
+		loginLockoutEvent.IpAddress = pgtype.Text{Valid: clientInfo.IpAddress != "", String: clientInfo.IpAddress}
+		loginLockoutEvent.FailedCount = pgtype.Int4{Valid: true, Int32: int32(failedCount)}
+		loginLockoutEvent.LockedUntil = pgtype.Timestamptz{Valid: true, Time: time.Now().Add(time.Duration(lockSeconds) * time.Second)}
+		_, err = loginAttemptRepository.CreateLockoutEvent(tx, ctx, loginLockoutEvent)
+		if err != nil {
+			return
+		}
+		err = recordSecurityEvent(tx, ctx, securityEventRepository, userId, email, helpers.SecurityEventAccountLocked, attemptKey.attemptKey)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
 func (service *UserServiceImplementation) RefreshToken(ctx context.Context, refreshToken string) (httpCode int, accessToken string, response interface{}) {
+	ctx, span := helpers.StartSpan(ctx, "UserService.RefreshToken")
+	defer func() { helpers.EndSpan(span, httpCode, response) }()
+
 	user, err := service.UserRepository.FindByRefreshToken(service.PostgresUtil.GetPool(), ctx, refreshToken)
 	if err != nil && err != pgx.ErrNoRows {
 		httpCode = http.StatusInternalServerError
@@ -236,15 +468,33 @@ func (service *UserServiceImplementation) RefreshToken(ctx context.Context, refr
 		return
 	}
 
-	jwtAccessTokenTimeEnv := os.Getenv("JWT_ACCESS_TOKEN_TIME")
-	jwtAccessTokenTime, err := strconv.Atoi(jwtAccessTokenTimeEnv)
+	accessToken, err = service.JwtHelper.GenerateAccessToken(int(user.Id.Int32), user.Name.String, user.Email.String, service.Config.Jwt.AccessTokenTime)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
+		accessToken = ""
 		httpCode = http.StatusInternalServerError
 		response = helpers.ToResponse(err.Error())
 		return
 	}
-	accessToken, err = service.JwtHelper.GenerateAccessToken(int(user.Id.Int32), user.Name.String, user.Email.String, jwtAccessTokenTime, os.Getenv("JWT_SECRET"))
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			accessToken = ""
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	err = recordSecurityEvent(tx, ctx, service.SecurityEventRepository, int(user.Id.Int32), user.Email.String, helpers.SecurityEventTokenRefreshed, "")
 	if err != nil {
+		accessToken = ""
 		httpCode = http.StatusInternalServerError
 		response = helpers.ToResponse(err.Error())
 		return
@@ -253,3 +503,188 @@ func (service *UserServiceImplementation) RefreshToken(ctx context.Context, refr
 	response = helpers.ToResponse("successfully refresh token")
 	return
 }
+
+func sendVerificationEmail(jwtHelper helpers.JwtHelper, mailer helpers.Mailer, emailVerificationConfig config.EmailVerificationConfig, user modelentities.User) (err error) {
+	emailVerificationToken, err := jwtHelper.GenerateEmailVerificationToken(int(user.Id.Int32), user.Email.String, emailVerificationConfig.TokenTime)
+	if err != nil {
+		return
+	}
+	body := "Hi " + user.Name.String + ",\r\n\r\n" +
+		"please verify your email by opening the link below, it expires in " + strconv.Itoa(emailVerificationConfig.TokenTime) + " minutes.\r\n\r\n" +
+		emailVerificationConfig.Url + emailVerificationToken
+	return mailer.Send(user.Email.String, "Verify your email", body)
+}
+
+func (service *UserServiceImplementation) VerifyEmail(ctx context.Context, emailVerificationToken string) (httpCode int, response interface{}) {
+	ctx, span := helpers.StartSpan(ctx, "UserService.VerifyEmail")
+	defer func() { helpers.EndSpan(span, httpCode, response) }()
+
+	claims, err := service.JwtHelper.ParseEmailVerificationToken(emailVerificationToken)
+	if err != nil {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("invalid or expired token")
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	user, err := service.UserRepository.FindById(tx, ctx, claims.Id)
+	if err != nil && err != pgx.ErrNoRows {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	} else if err != nil && err == pgx.ErrNoRows {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("invalid or expired token")
+		return
+	}
+	// a link sent to an address the account no longer uses must not verify the current one
+	if user.Email.String != claims.Email {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("invalid or expired token")
+		return
+	}
+	if user.EmailVerifiedAt.Valid {
+		httpCode = http.StatusOK
+		response = helpers.ToResponse("email already verified")
+		return
+	}
+
+	_, err = service.UserRepository.UpdateEmailVerifiedAt(tx, ctx, claims.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode = http.StatusOK
+	response = helpers.ToResponse("successfully verified email")
+	return
+}
+
+func (service *UserServiceImplementation) ResendVerificationEmail(ctx context.Context) (httpCode int, response interface{}) {
+	ctx, span := helpers.StartSpan(ctx, "UserService.ResendVerificationEmail")
+	defer func() { helpers.EndSpan(span, httpCode, response) }()
+
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	user, err := service.UserRepository.FindById(tx, ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if user.EmailVerifiedAt.Valid {
+		httpCode = http.StatusBadRequest
+		response = helpers.ToResponse("email already verified")
+		return
+	}
+
+	rowsAffected, err := service.UserRepository.UpdateEmailVerificationSentAt(tx, ctx, principal.Id, service.Config.EmailVerification.ResendInterval)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	if rowsAffected != 1 {
+		retryAfter := service.Config.EmailVerification.ResendInterval
+		if user.EmailVerificationSentAt.Valid {
+			retryAfter = int(time.Until(user.EmailVerificationSentAt.Time.Add(time.Duration(service.Config.EmailVerification.ResendInterval)*time.Second)).Seconds()) + 1
+		}
+		httpCode = http.StatusTooManyRequests
+		response = modelresponses.RetryAfterResponse{
+			Message:    "verification email was sent recently, please wait before asking for another one",
+			RetryAfter: retryAfter,
+		}
+		return
+	}
+
+	err = sendVerificationEmail(service.JwtHelper, service.Mailer, service.Config.EmailVerification, user)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode = http.StatusOK
+	response = helpers.ToResponse("verification email has been sent")
+	return
+}
+
+// Logout revokes every session of the user, the refresh token included, the controller then expires the cookies.
+func (service *UserServiceImplementation) Logout(ctx context.Context) (httpCode int, response interface{}) {
+	ctx, span := helpers.StartSpan(ctx, "UserService.Logout")
+	defer func() { helpers.EndSpan(span, httpCode, response) }()
+
+	principal, ok := helpers.PrincipalFromContext(ctx)
+	if !ok {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse("cannot find user id")
+		return
+	}
+
+	tx, err := service.PostgresUtil.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	defer func() {
+		errCommitOrRollback := service.PostgresUtil.CommitOrRollback(tx, ctx, err)
+		if errCommitOrRollback != nil {
+			httpCode = http.StatusInternalServerError
+			response = helpers.ToResponse(errCommitOrRollback.Error())
+		}
+	}()
+
+	_, err = service.UserRepository.RevokeSessions(tx, ctx, principal.Id)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+	err = recordSecurityEvent(tx, ctx, service.SecurityEventRepository, principal.Id, principal.Email, helpers.SecurityEventLoggedOut, "")
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		response = helpers.ToResponse(err.Error())
+		return
+	}
+
+	httpCode = http.StatusOK
+	response = helpers.ToResponse("successfully logged out")
+	return
+}
@@ -0,0 +1,212 @@
+package config_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"todo-list-api/config"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigTestSuite struct {
+	suite.Suite
+	requiredArgs []string
+	directory    string
+}
+
+func TestConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(ConfigTestSuite))
+}
+
+func (sut *ConfigTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.requiredArgs = []string{"-postgres-username=postgres", "-postgres-database=todo_list", "-jwt-secret=secret"}
+}
+
+func (sut *ConfigTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.directory = sut.T().TempDir()
+}
+
+func (sut *ConfigTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *ConfigTestSuite) writeFile(name string, content string) string {
+	path := filepath.Join(sut.directory, name)
+	sut.Require().NoError(os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func (sut *ConfigTestSuite) Test01Defaults() {
+	sut.T().Log("Test01Defaults")
+	cfg, err := config.Load(sut.requiredArgs)
+	sut.Require().NoError(err)
+	sut.Equal(cfg.Server.Host, ":8080")
+	sut.Equal(cfg.Jwt.AccessTokenTime, 15)
+	sut.Equal(cfg.Jwt.Secret, "secret")
+	sut.Equal(cfg.RateLimit.Store, "memory")
+}
+
+func (sut *ConfigTestSuite) Test02RequiredSettings() {
+	sut.T().Log("Test02RequiredSettings")
+	_, err := config.Load(nil)
+	sut.Require().Error(err)
+	sut.Contains(err.Error(), "POSTGRES_USERNAME is required")
+	sut.Contains(err.Error(), "POSTGRES_DATABASE is required")
+	sut.Contains(err.Error(), "JWT_SECRET or JWT_SIGNING_KEYS is required")
+}
+
+func (sut *ConfigTestSuite) Test03FileEnvAndFlagPrecedence() {
+	sut.T().Log("Test03FileEnvAndFlagPrecedence")
+	path := sut.writeFile("config.yaml", "jwt:\n  accessTokenTime: 30\n  refreshTokenTime: 7\n  mfaPendingTokenTime: 3\nserver:\n  trustProxy: true\n")
+	sut.T().Setenv("JWT_REFRESH_TOKEN_TIME", "2")
+	sut.T().Setenv("MFA_PENDING_TOKEN_TIME", "2")
+	cfg, err := config.Load(append(sut.requiredArgs, "-config", path, "-mfa-pending-token-time", "1"))
+	sut.Require().NoError(err)
+	sut.Equal(cfg.Jwt.AccessTokenTime, 30)
+	sut.Equal(cfg.Jwt.RefreshTokenTime, 2)
+	sut.Equal(cfg.Jwt.MfaPendingTokenTime, 1)
+	sut.True(cfg.Server.TrustProxy)
+}
+
+func (sut *ConfigTestSuite) Test04ConfigFileFromEnv() {
+	sut.T().Log("Test04ConfigFileFromEnv")
+	sut.T().Setenv("CONFIG_FILE", sut.writeFile("config.yml", "cookie:\n  secure: true\n"))
+	cfg, err := config.Load(sut.requiredArgs)
+	sut.Require().NoError(err)
+	sut.True(cfg.Cookie.Secure)
+}
+
+func (sut *ConfigTestSuite) Test05UnknownFileSetting() {
+	sut.T().Log("Test05UnknownFileSetting")
+	path := sut.writeFile("config.yaml", "jwt:\n  acessTokenTime: 30\n")
+	_, err := config.Load(append(sut.requiredArgs, "-config", path))
+	sut.Require().Error(err)
+	sut.Contains(err.Error(), "acessTokenTime")
+}
+
+func (sut *ConfigTestSuite) Test06SecretFromFile() {
+	sut.T().Log("Test06SecretFromFile")
+	sut.T().Setenv("POSTGRES_PASSWORD", "from-env")
+	sut.T().Setenv("POSTGRES_PASSWORD_FILE", sut.writeFile("postgres_password", "from-file\n"))
+	cfg, err := config.Load(sut.requiredArgs)
+	sut.Require().NoError(err)
+	sut.Equal(cfg.Postgres.Password, "from-file")
+
+	sut.T().Setenv("POSTGRES_PASSWORD_FILE", filepath.Join(sut.directory, "missing"))
+	_, err = config.Load(sut.requiredArgs)
+	sut.Require().Error(err)
+	sut.Contains(err.Error(), "POSTGRES_PASSWORD_FILE")
+}
+
+func (sut *ConfigTestSuite) Test07InvalidValues() {
+	sut.T().Log("Test07InvalidValues")
+	sut.T().Setenv("JWT_ACCESS_TOKEN_TIME", "fifteen")
+	sut.T().Setenv("TRUST_PROXY", "maybe")
+	_, err := config.Load(sut.requiredArgs)
+	sut.Require().Error(err)
+	sut.Contains(err.Error(), `JWT_ACCESS_TOKEN_TIME: "fifteen" is not a number`)
+	sut.Contains(err.Error(), `TRUST_PROXY: "maybe" is not true or false`)
+}
+
+func (sut *ConfigTestSuite) Test08ValidateCollectsEveryProblem() {
+	sut.T().Log("Test08ValidateCollectsEveryProblem")
+	_, err := config.Load(append(sut.requiredArgs, "-jwt-access-token-time=0", "-mailer=pigeon", "-password-required-classes=lowercase,emoji", "-log-level=loud"))
+	sut.Require().Error(err)
+	sut.Contains(err.Error(), "JWT_ACCESS_TOKEN_TIME must be at least 1, got 0")
+	sut.Contains(err.Error(), `MAILER must be one of file, smtp, got "pigeon"`)
+	sut.Contains(err.Error(), `PASSWORD_REQUIRED_CLASSES must be one of lowercase, uppercase, digit, symbol, none, got "emoji"`)
+	sut.Contains(err.Error(), `LOG_LEVEL must be one of debug, info, warn, error, got "loud"`)
+}
+
+func (sut *ConfigTestSuite) Test09OidcProvidersFromEnv() {
+	sut.T().Log("Test09OidcProvidersFromEnv")
+	sut.T().Setenv("OIDC_PROVIDERS", "Google, github")
+	sut.T().Setenv("OIDC_GOOGLE_ISSUER", "https://accounts.google.com")
+	sut.T().Setenv("OIDC_GOOGLE_CLIENT_ID", "google-client")
+	sut.T().Setenv("OIDC_GOOGLE_CLIENT_SECRET_FILE", sut.writeFile("google_secret", "google-secret\n"))
+	_, err := config.Load(sut.requiredArgs)
+	sut.Require().Error(err)
+	sut.Contains(err.Error(), "OIDC_GITHUB_ISSUER is required")
+
+	sut.T().Setenv("OIDC_PROVIDERS", "google")
+	cfg, err := config.Load(sut.requiredArgs)
+	sut.Require().NoError(err)
+	sut.Equal(cfg.Oidc.Providers, map[string]config.OidcProviderConfig{
+		"google": {Issuer: "https://accounts.google.com", ClientId: "google-client", ClientSecret: "google-secret"},
+	})
+}
+
+func (sut *ConfigTestSuite) Test10UnexpectedArgument() {
+	sut.T().Log("Test10UnexpectedArgument")
+	_, err := config.Load(append(sut.requiredArgs, "serve"))
+	sut.Require().Error(err)
+	sut.Equal(err.Error(), `unexpected argument "serve"`)
+}
+
+func (sut *ConfigTestSuite) Test11CommandFlags() {
+	sut.T().Log("Test11CommandFlags")
+	var email string
+	cfg, err := config.Load(append(sut.requiredArgs, "-email=john@doe.com", "-jwt-access-token-time=30"), func(flagSet *flag.FlagSet) {
+		flagSet.StringVar(&email, "email", "", "email of the account")
+	})
+	sut.Require().NoError(err)
+	sut.Equal(email, "john@doe.com")
+	sut.Equal(cfg.Jwt.AccessTokenTime, 30)
+}
+
+func (sut *ConfigTestSuite) Test12Tracing() {
+	sut.T().Log("Test12Tracing")
+	_, err := config.Load(append(sut.requiredArgs, "-tracing-exporter=file", "-tracing-sample-percent=101"))
+	sut.Require().Error(err)
+	sut.Contains(err.Error(), "TRACING_FILE_PATH is required")
+	sut.Contains(err.Error(), "TRACING_SAMPLE_PERCENT must be at most 100")
+
+	sut.T().Setenv("TRACING_EXPORTER", "otlp")
+	sut.T().Setenv("TRACING_OTLP_ENDPOINT", "http://collector:4318")
+	cfg, err := config.Load(sut.requiredArgs)
+	sut.Require().NoError(err)
+	sut.Equal(cfg.Tracing, config.TracingConfig{Exporter: "otlp", ServiceName: "todo-list-api", OtlpEndpoint: "http://collector:4318", SamplePercent: 100})
+}
+
+func (sut *ConfigTestSuite) Test13UnsupportedFile() {
+	sut.T().Log("Test13UnsupportedFile")
+	path := sut.writeFile("config.json", `{"jwt": {"accessTokenTime": 30}}`)
+	_, err := config.Load(append(sut.requiredArgs, "-config", path))
+	sut.Require().Error(err)
+	sut.Contains(err.Error(), "only .yaml, .yml and .toml files are supported")
+}
+
+func (sut *ConfigTestSuite) Test14TomlFile() {
+	sut.T().Log("Test14TomlFile")
+	path := sut.writeFile("config.toml", "[server]\ntrustProxy = true\n\n[jwt]\naccessTokenTime = 30\n\n[oidc.providers.google]\nissuer = \"https://accounts.google.com\"\nclientId = \"id\"\nclientSecret = \"secret\"\n")
+	sut.T().Setenv("JWT_ACCESS_TOKEN_TIME", "45")
+	cfg, err := config.Load(append(sut.requiredArgs, "-config", path))
+	sut.Require().NoError(err)
+	sut.True(cfg.Server.TrustProxy)
+	sut.Equal(cfg.Jwt.AccessTokenTime, 45)
+	sut.Equal(cfg.Oidc.Providers["google"], config.OidcProviderConfig{Issuer: "https://accounts.google.com", ClientId: "id", ClientSecret: "secret"})
+}
+
+func (sut *ConfigTestSuite) Test15TomlFileUnknownKey() {
+	sut.T().Log("Test15TomlFileUnknownKey")
+	path := sut.writeFile("config.toml", "[jwt]\naccesTokenTime = 30\n")
+	_, err := config.Load(append(sut.requiredArgs, "-config", path))
+	sut.Require().Error(err)
+	sut.Contains(err.Error(), "unknown keys jwt.accesTokenTime")
+}
+
+func (sut *ConfigTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *ConfigTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *ConfigTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
@@ -0,0 +1,132 @@
+package helpers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-list-api/helpers"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConcurrencyLimiterHelperTestSuite struct {
+	suite.Suite
+	ctx context.Context
+}
+
+func TestConcurrencyLimiterHelperTestSuite(t *testing.T) {
+	suite.Run(t, new(ConcurrencyLimiterHelperTestSuite))
+}
+
+func (sut *ConcurrencyLimiterHelperTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.ctx = context.Background()
+}
+
+func (sut *ConcurrencyLimiterHelperTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+}
+
+func (sut *ConcurrencyLimiterHelperTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+// acquireAsync starts waiting for a slot and returns once the request is in the queue.
+func (sut *ConcurrencyLimiterHelperTestSuite) acquireAsync(concurrencyLimiter helpers.ConcurrencyLimiter, priority int) chan bool {
+	acquired := make(chan bool, 1)
+	go func() {
+		_, ok := concurrencyLimiter.Acquire(sut.ctx, priority)
+		acquired <- ok
+	}()
+	time.Sleep(20 * time.Millisecond)
+	return acquired
+}
+
+func (sut *ConcurrencyLimiterHelperTestSuite) Test01AcquireUpToLimit() {
+	sut.T().Log("Test01AcquireUpToLimit")
+	concurrencyLimiter := helpers.NewConcurrencyLimiter(2, 0, time.Second)
+	_, ok := concurrencyLimiter.Acquire(sut.ctx, helpers.PriorityNormal)
+	sut.True(ok)
+	release, ok := concurrencyLimiter.Acquire(sut.ctx, helpers.PriorityNormal)
+	sut.True(ok)
+	_, ok = concurrencyLimiter.Acquire(sut.ctx, helpers.PriorityHigh)
+	sut.False(ok)
+	release()
+	_, ok = concurrencyLimiter.Acquire(sut.ctx, helpers.PriorityNormal)
+	sut.True(ok)
+}
+
+func (sut *ConcurrencyLimiterHelperTestSuite) Test02QueueWaitTimesOut() {
+	sut.T().Log("Test02QueueWaitTimesOut")
+	concurrencyLimiter := helpers.NewConcurrencyLimiter(1, 1, 30*time.Millisecond)
+	_, ok := concurrencyLimiter.Acquire(sut.ctx, helpers.PriorityNormal)
+	sut.True(ok)
+	start := time.Now()
+	_, ok = concurrencyLimiter.Acquire(sut.ctx, helpers.PriorityNormal)
+	sut.False(ok)
+	sut.GreaterOrEqual(time.Since(start), 30*time.Millisecond)
+}
+
+func (sut *ConcurrencyLimiterHelperTestSuite) Test03ReleaseHandsSlotToWaiter() {
+	sut.T().Log("Test03ReleaseHandsSlotToWaiter")
+	concurrencyLimiter := helpers.NewConcurrencyLimiter(1, 1, time.Second)
+	release, ok := concurrencyLimiter.Acquire(sut.ctx, helpers.PriorityNormal)
+	sut.True(ok)
+	acquired := sut.acquireAsync(concurrencyLimiter, helpers.PriorityNormal)
+	release()
+	sut.True(<-acquired)
+	_, ok = concurrencyLimiter.Acquire(sut.ctx, helpers.PriorityHigh)
+	sut.False(ok)
+}
+
+func (sut *ConcurrencyLimiterHelperTestSuite) Test04HigherPriorityFirst() {
+	sut.T().Log("Test04HigherPriorityFirst")
+	concurrencyLimiter := helpers.NewConcurrencyLimiter(1, 2, time.Second)
+	release, ok := concurrencyLimiter.Acquire(sut.ctx, helpers.PriorityNormal)
+	sut.True(ok)
+	lowAcquired := sut.acquireAsync(concurrencyLimiter, helpers.PriorityLow)
+	highAcquired := sut.acquireAsync(concurrencyLimiter, helpers.PriorityHigh)
+	release()
+	sut.True(<-highAcquired)
+	sut.Len(lowAcquired, 0)
+}
+
+func (sut *ConcurrencyLimiterHelperTestSuite) Test05FullQueueEvictsLowerPriority() {
+	sut.T().Log("Test05FullQueueEvictsLowerPriority")
+	concurrencyLimiter := helpers.NewConcurrencyLimiter(1, 1, time.Second)
+	release, ok := concurrencyLimiter.Acquire(sut.ctx, helpers.PriorityNormal)
+	sut.True(ok)
+	lowAcquired := sut.acquireAsync(concurrencyLimiter, helpers.PriorityLow)
+	highAcquired := sut.acquireAsync(concurrencyLimiter, helpers.PriorityHigh)
+	sut.False(<-lowAcquired)
+	_, ok = concurrencyLimiter.Acquire(sut.ctx, helpers.PriorityNormal)
+	sut.False(ok)
+	release()
+	sut.True(<-highAcquired)
+}
+
+func (sut *ConcurrencyLimiterHelperTestSuite) Test06CanceledContextLeavesQueue() {
+	sut.T().Log("Test06CanceledContextLeavesQueue")
+	concurrencyLimiter := helpers.NewConcurrencyLimiter(1, 1, time.Second)
+	release, ok := concurrencyLimiter.Acquire(sut.ctx, helpers.PriorityNormal)
+	sut.True(ok)
+	ctx, cancel := context.WithCancel(sut.ctx)
+	cancel()
+	_, ok = concurrencyLimiter.Acquire(ctx, helpers.PriorityNormal)
+	sut.False(ok)
+	release()
+	_, ok = concurrencyLimiter.Acquire(sut.ctx, helpers.PriorityNormal)
+	sut.True(ok)
+}
+
+func (sut *ConcurrencyLimiterHelperTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *ConcurrencyLimiterHelperTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *ConcurrencyLimiterHelperTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
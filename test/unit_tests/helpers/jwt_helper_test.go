@@ -0,0 +1,200 @@
+package helpers_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+	"todo-list-api/helpers"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/suite"
+)
+
+type JwtHelperTestSuite struct {
+	suite.Suite
+	oldKey    helpers.JwtKey
+	activeKey helpers.JwtKey
+}
+
+func TestJwtHelperTestSuite(t *testing.T) {
+	suite.Run(t, new(JwtHelperTestSuite))
+}
+
+func (sut *JwtHelperTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	sut.Require().NoError(err)
+	sut.oldKey = helpers.JwtKey{
+		Id:            "old",
+		SigningMethod: jwt.SigningMethodRS256,
+		SigningKey:    rsaKey,
+		VerifyingKey:  &rsaKey.PublicKey,
+	}
+	edPublicKey, edPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	sut.Require().NoError(err)
+	sut.activeKey = helpers.JwtKey{
+		Id:            "active",
+		SigningMethod: jwt.SigningMethodEdDSA,
+		SigningKey:    edPrivateKey,
+		VerifyingKey:  edPublicKey,
+	}
+}
+
+func (sut *JwtHelperTestSuite) Test01ActiveKeyNotFound() {
+	sut.T().Log("Test01ActiveKeyNotFound")
+	_, err := helpers.NewJwtHelperWithKeys([]helpers.JwtKey{sut.oldKey}, "active", "todo-list-api", "todo-list-api")
+	sut.NotNil(err)
+}
+
+func (sut *JwtHelperTestSuite) Test02DuplicateKeyId() {
+	sut.T().Log("Test02DuplicateKeyId")
+	_, err := helpers.NewJwtHelperWithKeys([]helpers.JwtKey{sut.oldKey, sut.oldKey}, "old", "todo-list-api", "todo-list-api")
+	sut.NotNil(err)
+}
+
+func (sut *JwtHelperTestSuite) Test03TokenSignedBeforeRotationStillVerifies() {
+	sut.T().Log("Test03TokenSignedBeforeRotationStillVerifies")
+	oldJwtHelper, err := helpers.NewJwtHelperWithKeys([]helpers.JwtKey{sut.oldKey}, "old", "todo-list-api", "todo-list-api")
+	sut.Require().NoError(err)
+	accessToken, err := oldJwtHelper.GenerateAccessToken(1, "John Doe", "john@doe.com", 15)
+	sut.Require().NoError(err)
+
+	rotatedJwtHelper, err := helpers.NewJwtHelperWithKeys([]helpers.JwtKey{sut.oldKey, sut.activeKey}, "active", "todo-list-api", "todo-list-api")
+	sut.Require().NoError(err)
+	claims, err := rotatedJwtHelper.ParseAccessToken(accessToken)
+	sut.Nil(err)
+	sut.Equal(claims.Id, 1)
+
+	accessToken, err = rotatedJwtHelper.GenerateAccessToken(1, "John Doe", "john@doe.com", 15)
+	sut.Require().NoError(err)
+	token, _, err := jwt.NewParser().ParseUnverified(accessToken, &helpers.AccessTokenCustomClaims{})
+	sut.Nil(err)
+	sut.Equal(token.Header["kid"], "active")
+	sut.Equal(token.Method.Alg(), "EdDSA")
+	claims, err = rotatedJwtHelper.ParseAccessToken(accessToken)
+	sut.Nil(err)
+	sut.Equal(claims.Subject, "1")
+	sut.Equal(claims.Issuer, "todo-list-api")
+	sut.NotEqual(claims.ID, "")
+}
+
+func (sut *JwtHelperTestSuite) Test04UnknownKeyId() {
+	sut.T().Log("Test04UnknownKeyId")
+	oldJwtHelper, err := helpers.NewJwtHelperWithKeys([]helpers.JwtKey{sut.oldKey}, "old", "todo-list-api", "todo-list-api")
+	sut.Require().NoError(err)
+	accessToken, err := oldJwtHelper.GenerateAccessToken(1, "John Doe", "john@doe.com", 15)
+	sut.Require().NoError(err)
+
+	activeJwtHelper, err := helpers.NewJwtHelperWithKeys([]helpers.JwtKey{sut.activeKey}, "active", "todo-list-api", "todo-list-api")
+	sut.Require().NoError(err)
+	_, err = activeJwtHelper.ParseAccessToken(accessToken)
+	sut.NotNil(err)
+}
+
+func (sut *JwtHelperTestSuite) Test05JwksOnlyPublishesAsymmetricKeys() {
+	sut.T().Log("Test05JwksOnlyPublishesAsymmetricKeys")
+	secretKey := helpers.JwtKey{
+		Id:            "secret",
+		SigningMethod: jwt.SigningMethodHS256,
+		SigningKey:    []byte("secret"),
+		VerifyingKey:  []byte("secret"),
+	}
+	jwtHelper, err := helpers.NewJwtHelperWithKeys([]helpers.JwtKey{secretKey, sut.oldKey, sut.activeKey}, "active", "todo-list-api", "todo-list-api")
+	sut.Require().NoError(err)
+	jwks := jwtHelper.GetJwks()
+	sut.Equal(len(jwks.Keys), 2)
+	sut.Equal(jwks.Keys[0].Kid, "active")
+	sut.Equal(jwks.Keys[0].Kty, "OKP")
+	sut.Equal(jwks.Keys[0].Alg, "EdDSA")
+	sut.NotEqual(jwks.Keys[0].X, "")
+	sut.Equal(jwks.Keys[1].Kid, "old")
+	sut.Equal(jwks.Keys[1].Kty, "RSA")
+	sut.Equal(jwks.Keys[1].E, "AQAB")
+}
+
+func (sut *JwtHelperTestSuite) Test06RefreshTokenIsNotAnAccessToken() {
+	sut.T().Log("Test06RefreshTokenIsNotAnAccessToken")
+	jwtHelper, err := helpers.NewJwtHelperWithKeys([]helpers.JwtKey{sut.activeKey}, "active", "todo-list-api", "todo-list-api")
+	sut.Require().NoError(err)
+	refreshToken, err := jwtHelper.GenerateRefreshToken(1, 1)
+	sut.Require().NoError(err)
+	_, err = jwtHelper.ParseAccessToken(refreshToken)
+	sut.NotNil(err)
+}
+
+func (sut *JwtHelperTestSuite) Test07WrongAudience() {
+	sut.T().Log("Test07WrongAudience")
+	otherJwtHelper, err := helpers.NewJwtHelperWithKeys([]helpers.JwtKey{sut.activeKey}, "active", "todo-list-api", "other-api")
+	sut.Require().NoError(err)
+	accessToken, err := otherJwtHelper.GenerateAccessToken(1, "John Doe", "john@doe.com", 15)
+	sut.Require().NoError(err)
+	jwtHelper, err := helpers.NewJwtHelperWithKeys([]helpers.JwtKey{sut.activeKey}, "active", "todo-list-api", "todo-list-api")
+	sut.Require().NoError(err)
+	_, err = jwtHelper.ParseAccessToken(accessToken)
+	sut.NotNil(err)
+}
+
+func (sut *JwtHelperTestSuite) Test08UnexpectedSigningMethod() {
+	sut.T().Log("Test08UnexpectedSigningMethod")
+	jwtHelper, err := helpers.NewJwtHelperWithKeys([]helpers.JwtKey{sut.oldKey}, "old", "todo-list-api", "todo-list-api")
+	sut.Require().NoError(err)
+	claims := helpers.AccessTokenCustomClaims{
+		Id:        1,
+		TokenType: helpers.AccessTokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "todo-list-api",
+			Subject:   "1",
+			Audience:  jwt.ClaimStrings{"todo-list-api"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = "old"
+	accessToken, err := token.SignedString([]byte("guessed"))
+	sut.Require().NoError(err)
+	_, err = jwtHelper.ParseAccessToken(accessToken)
+	sut.NotNil(err)
+}
+
+func (sut *JwtHelperTestSuite) Test09OidcStateToken() {
+	sut.T().Log("Test09OidcStateToken")
+	jwtHelper, err := helpers.NewJwtHelperWithKeys([]helpers.JwtKey{sut.activeKey}, "active", "todo-list-api", "todo-list-api")
+	sut.Require().NoError(err)
+	oidcStateToken, err := jwtHelper.GenerateOidcStateToken("google", "state", "nonce", "codeVerifier", 10)
+	sut.Require().NoError(err)
+	claims, err := jwtHelper.ParseOidcStateToken(oidcStateToken)
+	sut.Require().NoError(err)
+	sut.Equal(claims.Provider, "google")
+	sut.Equal(claims.State, "state")
+	sut.Equal(claims.Nonce, "nonce")
+	sut.Equal(claims.CodeVerifier, "codeVerifier")
+	_, err = jwtHelper.ParseAccessToken(oidcStateToken)
+	sut.NotNil(err)
+	mfaPendingToken, err := jwtHelper.GenerateMfaPendingToken(1, 5)
+	sut.Require().NoError(err)
+	_, err = jwtHelper.ParseOidcStateToken(mfaPendingToken)
+	sut.NotNil(err)
+}
+
+func (sut *JwtHelperTestSuite) Test10OauthAccessToken() {
+	sut.T().Log("Test10OauthAccessToken")
+	jwtHelper, err := helpers.NewJwtHelperWithKeys([]helpers.JwtKey{sut.activeKey}, "active", "todo-list-api", "todo-list-api")
+	sut.Require().NoError(err)
+	oauthAccessToken, err := jwtHelper.GenerateOauthAccessToken(1, "clientId", "todos:read", 7, 15)
+	sut.Require().NoError(err)
+	claims, err := jwtHelper.ParseOauthAccessToken(oauthAccessToken)
+	sut.Require().NoError(err)
+	sut.Equal(claims.Id, 1)
+	sut.Equal(claims.ClientId, "clientId")
+	sut.Equal(claims.Scope, "todos:read")
+	sut.Equal(claims.GrantId, 7)
+	// a third party token must not pass for a session token and the other way around
+	_, err = jwtHelper.ParseAccessToken(oauthAccessToken)
+	sut.NotNil(err)
+	accessToken, err := jwtHelper.GenerateAccessToken(1, "John Doe", "john@doe.com", 15)
+	sut.Require().NoError(err)
+	_, err = jwtHelper.ParseOauthAccessToken(accessToken)
+	sut.NotNil(err)
+}
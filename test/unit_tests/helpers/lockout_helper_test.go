@@ -0,0 +1,48 @@
+package helpers_test
+
+import (
+	"testing"
+	"todo-list-api/helpers"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LockoutHelperTestSuite struct {
+	suite.Suite
+}
+
+func TestLockoutHelperTestSuite(t *testing.T) {
+	suite.Run(t, new(LockoutHelperTestSuite))
+}
+
+func (sut *LockoutHelperTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+}
+
+func (sut *LockoutHelperTestSuite) Test01LockoutDurationBelowLimit() {
+	sut.T().Log("Test01LockoutDurationBelowLimit")
+	sut.Equal(helpers.LockoutDuration(0, 5, 60, 3600), 0)
+	sut.Equal(helpers.LockoutDuration(4, 5, 60, 3600), 0)
+}
+
+func (sut *LockoutHelperTestSuite) Test02LockoutDurationDoubles() {
+	sut.T().Log("Test02LockoutDurationDoubles")
+	sut.Equal(helpers.LockoutDuration(5, 5, 60, 3600), 60)
+	sut.Equal(helpers.LockoutDuration(6, 5, 60, 3600), 120)
+	sut.Equal(helpers.LockoutDuration(8, 5, 60, 3600), 480)
+}
+
+func (sut *LockoutHelperTestSuite) Test03LockoutDurationCapped() {
+	sut.T().Log("Test03LockoutDurationCapped")
+	sut.Equal(helpers.LockoutDuration(11, 5, 60, 3600), 3600)
+	sut.Equal(helpers.LockoutDuration(1000, 5, 60, 3600), 3600)
+}
+
+func (sut *LockoutHelperTestSuite) Test04LockoutDurationDisabled() {
+	sut.T().Log("Test04LockoutDurationDisabled")
+	sut.Equal(helpers.LockoutDuration(100, 0, 60, 3600), 0)
+}
+
+func (sut *LockoutHelperTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
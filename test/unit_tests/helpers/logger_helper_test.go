@@ -0,0 +1,89 @@
+package helpers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LoggerHelperTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	output *bytes.Buffer
+}
+
+func TestLoggerHelperTestSuite(t *testing.T) {
+	suite.Run(t, new(LoggerHelperTestSuite))
+}
+
+func (sut *LoggerHelperTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.ctx = context.Background()
+}
+
+func (sut *LoggerHelperTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.output = new(bytes.Buffer)
+}
+
+func (sut *LoggerHelperTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *LoggerHelperTestSuite) Test01WritesJson() {
+	sut.T().Log("Test01WritesJson")
+	logger := helpers.NewLogger(config.LogConfig{Level: "info"}, sut.output)
+	logger.Info("request", slog.Int("status", 200), slog.String("route", "/todos"))
+	var line map[string]interface{}
+	sut.Require().NoError(json.Unmarshal(sut.output.Bytes(), &line))
+	sut.Equal(line["level"], "INFO")
+	sut.Equal(line["msg"], "request")
+	sut.Equal(line["status"], float64(200))
+	sut.Equal(line["route"], "/todos")
+}
+
+func (sut *LoggerHelperTestSuite) Test02Level() {
+	sut.T().Log("Test02Level")
+	logger := helpers.NewLogger(config.LogConfig{Level: "warn"}, sut.output)
+	logger.Info("hidden")
+	sut.Empty(sut.output.String())
+	logger.Warn("shown")
+	sut.Contains(sut.output.String(), `"msg":"shown"`)
+}
+
+func (sut *LoggerHelperTestSuite) Test03RedactsSecrets() {
+	sut.T().Log("Test03RedactsSecrets")
+	logger := helpers.NewLogger(config.LogConfig{Level: "info"}, sut.output)
+	logger.Info("login", slog.String("password", "Secret-1234"), slog.String("refresh_token", "abc"), slog.String("Cookie", "access_token=abc"), slog.Group("request", slog.String("authorization", "Bearer abc")), slog.String("email", "john@doe.com"))
+	sut.NotContains(sut.output.String(), "Secret-1234")
+	sut.NotContains(sut.output.String(), "abc")
+	sut.Contains(sut.output.String(), `"password":"[REDACTED]"`)
+	sut.Contains(sut.output.String(), `"request":{"authorization":"[REDACTED]"}`)
+	sut.Contains(sut.output.String(), `"email":"john@doe.com"`)
+}
+
+func (sut *LoggerHelperTestSuite) Test04LoggerFromContext() {
+	sut.T().Log("Test04LoggerFromContext")
+	sut.Equal(helpers.LoggerFromContext(sut.ctx), slog.Default())
+	logger := helpers.NewLogger(config.LogConfig{Level: "info"}, sut.output).With(slog.String("request_id", "42"))
+	helpers.LoggerFromContext(helpers.ContextWithLogger(sut.ctx, logger)).Info("query")
+	sut.Contains(sut.output.String(), `"request_id":"42"`)
+}
+
+func (sut *LoggerHelperTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *LoggerHelperTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *LoggerHelperTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
@@ -0,0 +1,71 @@
+package helpers_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	mockhelpers "todo-list-api/test/unit_tests/helpers/mocks"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MailQueueHelperTestSuite struct {
+	suite.Suite
+	ctx        context.Context
+	output     *bytes.Buffer
+	mailerMock *mockhelpers.MailerMock
+	mailQueue  helpers.MailQueue
+}
+
+func TestMailQueueHelperTestSuite(t *testing.T) {
+	suite.Run(t, new(MailQueueHelperTestSuite))
+}
+
+func (sut *MailQueueHelperTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+}
+
+func (sut *MailQueueHelperTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.output = new(bytes.Buffer)
+	sut.ctx = helpers.ContextWithLogger(context.Background(), helpers.NewLogger(config.LogConfig{Level: "info"}, sut.output))
+	sut.mailerMock = new(mockhelpers.MailerMock)
+	sut.mailQueue = helpers.NewMailQueue(sut.mailerMock)
+}
+
+func (sut *MailQueueHelperTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *MailQueueHelperTestSuite) Test01Sends() {
+	sut.T().Log("Test01Sends")
+	sut.mailerMock.Mock.On("Send", "jane@doe.com", "subject", "body").Return(nil)
+	sut.mailQueue.Send(sut.ctx, "jane@doe.com", "subject", "body")
+	sut.mailQueue.Wait()
+	sut.mailerMock.Mock.AssertCalled(sut.T(), "Send", "jane@doe.com", "subject", "body")
+	sut.Empty(sut.output.String())
+}
+
+func (sut *MailQueueHelperTestSuite) Test02LogsSendError() {
+	sut.T().Log("Test02LogsSendError")
+	sut.mailerMock.Mock.On("Send", "jane@doe.com", "subject", "body").Return(errors.New("connection refused"))
+	sut.mailQueue.Send(sut.ctx, "jane@doe.com", "subject", "body")
+	sut.mailQueue.Wait()
+	sut.Contains(sut.output.String(), `"msg":"mail: cannot send"`)
+	sut.Contains(sut.output.String(), "connection refused")
+}
+
+func (sut *MailQueueHelperTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *MailQueueHelperTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *MailQueueHelperTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
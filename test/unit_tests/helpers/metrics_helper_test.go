@@ -0,0 +1,110 @@
+package helpers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-list-api/helpers"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/suite"
+)
+
+type MetricsHelperTestSuite struct {
+	suite.Suite
+	metricsHelper helpers.MetricsHelper
+}
+
+func TestMetricsHelperTestSuite(t *testing.T) {
+	suite.Run(t, new(MetricsHelperTestSuite))
+}
+
+func (sut *MetricsHelperTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+}
+
+func (sut *MetricsHelperTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.metricsHelper = helpers.NewMetricsHelper()
+}
+
+func (sut *MetricsHelperTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *MetricsHelperTestSuite) scrape() *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	sut.metricsHelper.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return recorder
+}
+
+func (sut *MetricsHelperTestSuite) Test01PrometheusTextFormat() {
+	sut.T().Log("Test01PrometheusTextFormat")
+	sut.metricsHelper.ObserveRequest(http.MethodPost, "/todos", http.StatusCreated, 30*time.Millisecond)
+	recorder := sut.scrape()
+	sut.Equal(recorder.Code, http.StatusOK)
+	sut.Contains(recorder.Header().Get("Content-Type"), "text/plain")
+	sut.Contains(recorder.Body.String(), `http_requests_total{method="POST",route="/todos",status="201"} 1`)
+	sut.Contains(recorder.Body.String(), `http_request_duration_seconds_bucket{method="POST",route="/todos",status="201",le="0.05"} 1`)
+	sut.Contains(recorder.Body.String(), "go_goroutines")
+}
+
+func (sut *MetricsHelperTestSuite) Test02Counters() {
+	sut.T().Log("Test02Counters")
+	sut.metricsHelper.CountRateLimitRejection("concurrency", "")
+	sut.metricsHelper.CountLogin(helpers.LoginFailed, "wrong_code")
+	sut.metricsHelper.CountLogin(helpers.LoginFailed, "wrong_code")
+	sut.metricsHelper.CountTodoCreated()
+	body := sut.scrape().Body.String()
+	sut.Contains(body, `rate_limit_rejections_total{limiter="concurrency",policy=""} 1`)
+	sut.Contains(body, `logins_total{reason="wrong_code",result="failed"} 2`)
+	sut.Contains(body, "todos_created_total 1")
+}
+
+func (sut *MetricsHelperTestSuite) Test03PostgresPool() {
+	sut.T().Log("Test03PostgresPool")
+	// the pool only connects when a connection is acquired
+	pool, err := pgxpool.New(context.Background(), "postgres://localhost:5432/todo?pool_max_conns=7")
+	sut.Require().NoError(err)
+	defer pool.Close()
+	sut.metricsHelper.CollectPostgresPool(pool.Stat)
+	body := sut.scrape().Body.String()
+	sut.Contains(body, "pgxpool_acquired_connections 0")
+	sut.Contains(body, "pgxpool_max_connections 7")
+	sut.Contains(body, "pgxpool_acquires_total 0")
+}
+
+func (sut *MetricsHelperTestSuite) Test04Todos() {
+	sut.T().Log("Test04Todos")
+	sut.metricsHelper.CollectTodos(func(ctx context.Context) (int, error) {
+		return 3, nil
+	})
+	body := sut.scrape().Body.String()
+	sut.Contains(body, "\ntodos 3\n")
+}
+
+func (sut *MetricsHelperTestSuite) Test05TodosCountError() {
+	sut.T().Log("Test05TodosCountError")
+	sut.metricsHelper.CollectTodos(func(ctx context.Context) (int, error) {
+		return 0, errors.New("connection refused")
+	})
+	recorder := sut.scrape()
+	sut.Equal(recorder.Code, http.StatusOK)
+	sut.NotContains(recorder.Body.String(), "\ntodos ")
+	sut.Contains(recorder.Body.String(), "todos_created_total 0")
+}
+
+func (sut *MetricsHelperTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *MetricsHelperTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *MetricsHelperTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
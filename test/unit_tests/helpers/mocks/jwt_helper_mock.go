@@ -1,17 +1,72 @@
 package mockhelpers
 
-import "github.com/stretchr/testify/mock"
+import (
+	"todo-list-api/helpers"
+	modelresponses "todo-list-api/models/responses"
+
+	"github.com/stretchr/testify/mock"
+)
 
 type JwtHelperMock struct {
 	Mock mock.Mock
 }
 
-func (helper *JwtHelperMock) GenerateAccessToken(id int, name string, email string, jwtAccessTokenTime int, secret string) (accessToken string, err error) {
-	arguments := helper.Mock.Called(id, name, email, jwtAccessTokenTime, secret)
+func (helper *JwtHelperMock) GenerateAccessToken(id int, name string, email string, jwtAccessTokenTime int) (accessToken string, err error) {
+	arguments := helper.Mock.Called(id, name, email, jwtAccessTokenTime)
+	return arguments.Get(0).(string), arguments.Error(1)
+}
+
+func (helper *JwtHelperMock) GenerateRefreshToken(id int, jwtRefreshTokenTime int) (refreshToken string, err error) {
+	arguments := helper.Mock.Called(id, jwtRefreshTokenTime)
 	return arguments.Get(0).(string), arguments.Error(1)
 }
 
-func (helper *JwtHelperMock) GenerateRefreshToken(id int, jwtRefreshTokenTime int, secret string) (refreshToken string, err error) {
-	arguments := helper.Mock.Called(id, jwtRefreshTokenTime, secret)
+func (helper *JwtHelperMock) ParseAccessToken(accessToken string) (claims *helpers.AccessTokenCustomClaims, err error) {
+	arguments := helper.Mock.Called(accessToken)
+	return arguments.Get(0).(*helpers.AccessTokenCustomClaims), arguments.Error(1)
+}
+
+func (helper *JwtHelperMock) GetJwks() modelresponses.JwksResponse {
+	arguments := helper.Mock.Called()
+	return arguments.Get(0).(modelresponses.JwksResponse)
+}
+
+func (helper *JwtHelperMock) GenerateEmailVerificationToken(id int, email string, emailVerificationTokenTime int) (emailVerificationToken string, err error) {
+	arguments := helper.Mock.Called(id, email, emailVerificationTokenTime)
+	return arguments.Get(0).(string), arguments.Error(1)
+}
+
+func (helper *JwtHelperMock) ParseEmailVerificationToken(emailVerificationToken string) (claims *helpers.EmailVerificationTokenCustomClaims, err error) {
+	arguments := helper.Mock.Called(emailVerificationToken)
+	return arguments.Get(0).(*helpers.EmailVerificationTokenCustomClaims), arguments.Error(1)
+}
+
+func (helper *JwtHelperMock) GenerateMfaPendingToken(id int, mfaPendingTokenTime int) (mfaPendingToken string, err error) {
+	arguments := helper.Mock.Called(id, mfaPendingTokenTime)
 	return arguments.Get(0).(string), arguments.Error(1)
 }
+
+func (helper *JwtHelperMock) ParseMfaPendingToken(mfaPendingToken string) (claims *helpers.MfaPendingTokenCustomClaims, err error) {
+	arguments := helper.Mock.Called(mfaPendingToken)
+	return arguments.Get(0).(*helpers.MfaPendingTokenCustomClaims), arguments.Error(1)
+}
+
+func (helper *JwtHelperMock) GenerateOidcStateToken(provider string, state string, nonce string, codeVerifier string, oidcStateTokenTime int) (oidcStateToken string, err error) {
+	arguments := helper.Mock.Called(provider, state, nonce, codeVerifier, oidcStateTokenTime)
+	return arguments.Get(0).(string), arguments.Error(1)
+}
+
+func (helper *JwtHelperMock) ParseOidcStateToken(oidcStateToken string) (claims *helpers.OidcStateTokenCustomClaims, err error) {
+	arguments := helper.Mock.Called(oidcStateToken)
+	return arguments.Get(0).(*helpers.OidcStateTokenCustomClaims), arguments.Error(1)
+}
+
+func (helper *JwtHelperMock) GenerateOauthAccessToken(id int, clientId string, scope string, grantId int, oauthAccessTokenTime int) (oauthAccessToken string, err error) {
+	arguments := helper.Mock.Called(id, clientId, scope, grantId, oauthAccessTokenTime)
+	return arguments.Get(0).(string), arguments.Error(1)
+}
+
+func (helper *JwtHelperMock) ParseOauthAccessToken(oauthAccessToken string) (claims *helpers.OauthAccessTokenCustomClaims, err error) {
+	arguments := helper.Mock.Called(oauthAccessToken)
+	return arguments.Get(0).(*helpers.OauthAccessTokenCustomClaims), arguments.Error(1)
+}
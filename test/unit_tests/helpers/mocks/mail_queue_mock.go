@@ -0,0 +1,19 @@
+package mockhelpers
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MailQueueMock struct {
+	Mock mock.Mock
+}
+
+func (queue *MailQueueMock) Send(ctx context.Context, to string, subject string, body string) {
+	queue.Mock.Called(ctx, to, subject, body)
+}
+
+func (queue *MailQueueMock) Wait() {
+	queue.Mock.Called()
+}
@@ -0,0 +1,12 @@
+package mockhelpers
+
+import "github.com/stretchr/testify/mock"
+
+type MailerMock struct {
+	Mock mock.Mock
+}
+
+func (mailer *MailerMock) Send(to string, subject string, body string) error {
+	arguments := mailer.Mock.Called(to, subject, body)
+	return arguments.Error(0)
+}
@@ -0,0 +1,22 @@
+package mockhelpers
+
+import (
+	"context"
+	"todo-list-api/helpers"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type OidcHelperMock struct {
+	Mock mock.Mock
+}
+
+func (helper *OidcHelperMock) AuthCodeUrl(ctx context.Context, provider string, state string, nonce string, codeVerifier string) (authCodeUrl string, err error) {
+	arguments := helper.Mock.Called(ctx, provider, state, nonce, codeVerifier)
+	return arguments.Get(0).(string), arguments.Error(1)
+}
+
+func (helper *OidcHelperMock) Exchange(ctx context.Context, provider string, code string, codeVerifier string, nonce string) (identity helpers.OidcIdentity, err error) {
+	arguments := helper.Mock.Called(ctx, provider, code, codeVerifier, nonce)
+	return arguments.Get(0).(helpers.OidcIdentity), arguments.Error(1)
+}
@@ -0,0 +1,24 @@
+package mockhelpers
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+type PasswordHasherMock struct {
+	Mock mock.Mock
+}
+
+func (helper *PasswordHasherMock) Hash(password string) (passwordHash string, err error) {
+	arguments := helper.Mock.Called(password)
+	return arguments.Get(0).(string), arguments.Error(1)
+}
+
+func (helper *PasswordHasherMock) Verify(passwordHash string, password string) (needsRehash bool, err error) {
+	arguments := helper.Mock.Called(passwordHash, password)
+	return arguments.Get(0).(bool), arguments.Error(1)
+}
+
+func (helper *PasswordHasherMock) DummyHash() string {
+	arguments := helper.Mock.Called()
+	return arguments.Get(0).(string)
+}
@@ -0,0 +1,16 @@
+package mockhelpers
+
+import (
+	modelresponses "todo-list-api/models/responses"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type PasswordPolicyHelperMock struct {
+	Mock mock.Mock
+}
+
+func (helper *PasswordPolicyHelperMock) Check(password string, name string, email string) (violations []modelresponses.PasswordPolicyViolation, err error) {
+	arguments := helper.Mock.Called(password, name, email)
+	return arguments.Get(0).([]modelresponses.PasswordPolicyViolation), arguments.Error(1)
+}
@@ -0,0 +1,18 @@
+package mockhelpers
+
+import (
+	"context"
+	"time"
+	"todo-list-api/helpers"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type RateLimitStoreMock struct {
+	Mock mock.Mock
+}
+
+func (store *RateLimitStoreMock) Take(ctx context.Context, key string, policy helpers.RateLimitPolicy, now time.Time) (result helpers.RateLimitResult, err error) {
+	arguments := store.Mock.Called(ctx, key, policy, now)
+	return arguments.Get(0).(helpers.RateLimitResult), arguments.Error(1)
+}
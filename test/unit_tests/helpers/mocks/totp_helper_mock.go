@@ -0,0 +1,31 @@
+package mockhelpers
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type TotpHelperMock struct {
+	Mock mock.Mock
+}
+
+func (helper *TotpHelperMock) GenerateSecret() (secret string, err error) {
+	arguments := helper.Mock.Called()
+	return arguments.Get(0).(string), arguments.Error(1)
+}
+
+func (helper *TotpHelperMock) GenerateUri(secret string, accountName string) string {
+	arguments := helper.Mock.Called(secret, accountName)
+	return arguments.Get(0).(string)
+}
+
+func (helper *TotpHelperMock) Validate(secret string, code string, now time.Time) (step int64, ok bool) {
+	arguments := helper.Mock.Called(secret, code, now)
+	return arguments.Get(0).(int64), arguments.Bool(1)
+}
+
+func (helper *TotpHelperMock) GenerateRecoveryCodes() (recoveryCodes []string, err error) {
+	arguments := helper.Mock.Called()
+	return arguments.Get(0).([]string), arguments.Error(1)
+}
@@ -0,0 +1,62 @@
+package helpers_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"todo-list-api/helpers"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type OauthHelperTestSuite struct {
+	suite.Suite
+	codeVerifier  string
+	codeChallenge string
+}
+
+func TestOauthHelperTestSuite(t *testing.T) {
+	suite.Run(t, new(OauthHelperTestSuite))
+}
+
+func (sut *OauthHelperTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	// the example of rfc 7636 appendix b
+	sut.codeVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	sut.codeChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+}
+
+func (sut *OauthHelperTestSuite) Test01ParseOauthScopes() {
+	sut.T().Log("Test01ParseOauthScopes")
+	sut.Equal(helpers.ParseOauthScopes("todos:write  todos:read todos:write"), []string{"todos:read", "todos:write"})
+	sut.Nil(helpers.ParseOauthScopes(" "))
+}
+
+func (sut *OauthHelperTestSuite) Test02ContainsOauthScopes() {
+	sut.T().Log("Test02ContainsOauthScopes")
+	allowed := []string{"profile:read", "todos:read"}
+	sut.True(helpers.ContainsOauthScopes(allowed, []string{"todos:read"}))
+	sut.True(helpers.ContainsOauthScopes(allowed, nil))
+	sut.False(helpers.ContainsOauthScopes(allowed, []string{"todos:read", "todos:write"}))
+}
+
+func (sut *OauthHelperTestSuite) Test03VerifyPkce() {
+	sut.T().Log("Test03VerifyPkce")
+	sut.True(helpers.VerifyPkce(sut.codeVerifier, sut.codeChallenge))
+	sut.False(helpers.VerifyPkce(sut.codeVerifier+"x", sut.codeChallenge))
+	// the plain method would send the verifier itself as the challenge
+	sut.False(helpers.VerifyPkce(sut.codeVerifier, sut.codeVerifier))
+}
+
+func (sut *OauthHelperTestSuite) Test04VerifyPkceVerifierLength() {
+	sut.T().Log("Test04VerifyPkceVerifierLength")
+	for _, codeVerifier := range []string{strings.Repeat("a", 42), strings.Repeat("a", 129)} {
+		codeVerifierHash := sha256.Sum256([]byte(codeVerifier))
+		sut.False(helpers.VerifyPkce(codeVerifier, base64.RawURLEncoding.EncodeToString(codeVerifierHash[:])))
+	}
+}
+
+func (sut *OauthHelperTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
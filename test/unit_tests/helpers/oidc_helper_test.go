@@ -0,0 +1,262 @@
+package helpers_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+	"todo-list-api/helpers"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeOidcServer is just enough of an openid provider for the helper: discovery, jwks and a token endpoint that
+// checks the pkce verifier against the challenge of the authorization request.
+type fakeOidcServer struct {
+	server        *httptest.Server
+	signingKey    *rsa.PrivateKey
+	publicKey     *rsa.PublicKey
+	mutex         sync.Mutex
+	code          string
+	codeChallenge string
+	idTokenClaims jwt.MapClaims
+}
+
+func newFakeOidcServer(signingKey *rsa.PrivateKey) *fakeOidcServer {
+	fakeServer := &fakeOidcServer{signingKey: signingKey, publicKey: &signingKey.PublicKey}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", fakeServer.discovery)
+	mux.HandleFunc("/jwks", fakeServer.jwks)
+	mux.HandleFunc("/token", fakeServer.token)
+	fakeServer.server = httptest.NewServer(mux)
+	return fakeServer
+}
+
+func (fakeServer *fakeOidcServer) discovery(w http.ResponseWriter, r *http.Request) {
+	issuer := fakeServer.server.URL
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/authorize",
+		"token_endpoint":                        issuer + "/token",
+		"jwks_uri":                              issuer + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (fakeServer *fakeOidcServer) jwks(w http.ResponseWriter, r *http.Request) {
+	publicKey := fakeServer.publicKey
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "fake",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}},
+	})
+}
+
+func (fakeServer *fakeOidcServer) token(w http.ResponseWriter, r *http.Request) {
+	fakeServer.mutex.Lock()
+	defer fakeServer.mutex.Unlock()
+	codeVerifierHash := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("code") != fakeServer.code ||
+		base64.RawURLEncoding.EncodeToString(codeVerifierHash[:]) != fakeServer.codeChallenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, fakeServer.idTokenClaims)
+	token.Header["kid"] = "fake"
+	idToken, err := token.SignedString(fakeServer.signingKey)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "providerAccessToken",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+type OidcHelperTestSuite struct {
+	suite.Suite
+	ctx          context.Context
+	signingKey   *rsa.PrivateKey
+	otherKey     *rsa.PrivateKey
+	fakeServer   *fakeOidcServer
+	oidcHelper   helpers.OidcHelper
+	codeVerifier string
+}
+
+func TestOidcHelperTestSuite(t *testing.T) {
+	suite.Run(t, new(OidcHelperTestSuite))
+}
+
+func (sut *OidcHelperTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.ctx = context.Background()
+	var err error
+	sut.signingKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	sut.Require().NoError(err)
+	sut.otherKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	sut.Require().NoError(err)
+	sut.codeVerifier, _, err = helpers.GenerateRandomToken()
+	sut.Require().NoError(err)
+}
+
+func (sut *OidcHelperTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.fakeServer = newFakeOidcServer(sut.signingKey)
+	sut.oidcHelper = helpers.NewOidcHelperWithProviders([]helpers.OidcProvider{{
+		Name:         "fake",
+		Issuer:       sut.fakeServer.server.URL,
+		ClientId:     "todo-list-api",
+		ClientSecret: "secret",
+		RedirectUrl:  "http://localhost:8080/oidc/fake/callback",
+	}}, sut.fakeServer.server.Client())
+	sut.fakeServer.idTokenClaims = jwt.MapClaims{
+		"iss":            sut.fakeServer.server.URL,
+		"aud":            "todo-list-api",
+		"sub":            "subject-1",
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Minute).Unix(),
+		"nonce":          "nonce",
+		"email":          "John@Doe.com",
+		"email_verified": true,
+		"name":           "John Doe",
+	}
+}
+
+func (sut *OidcHelperTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+// authorize plays the part of the browser going through the provider's login page.
+func (sut *OidcHelperTestSuite) authorize() {
+	authCodeUrl, err := sut.oidcHelper.AuthCodeUrl(sut.ctx, "fake", "state", "nonce", sut.codeVerifier)
+	sut.Require().NoError(err)
+	parsedUrl, err := url.Parse(authCodeUrl)
+	sut.Require().NoError(err)
+	sut.fakeServer.code = "code"
+	sut.fakeServer.codeChallenge = parsedUrl.Query().Get("code_challenge")
+}
+
+func (sut *OidcHelperTestSuite) Test01AuthCodeUrl() {
+	sut.T().Log("Test01AuthCodeUrl")
+	authCodeUrl, err := sut.oidcHelper.AuthCodeUrl(sut.ctx, "fake", "state", "nonce", sut.codeVerifier)
+	sut.Require().NoError(err)
+	parsedUrl, err := url.Parse(authCodeUrl)
+	sut.Require().NoError(err)
+	sut.Equal(parsedUrl.Scheme+"://"+parsedUrl.Host+parsedUrl.Path, sut.fakeServer.server.URL+"/authorize")
+	query := parsedUrl.Query()
+	codeVerifierHash := sha256.Sum256([]byte(sut.codeVerifier))
+	sut.Equal(query.Get("code_challenge"), base64.RawURLEncoding.EncodeToString(codeVerifierHash[:]))
+	sut.Equal(query.Get("code_challenge_method"), "S256")
+	sut.Equal(query.Get("state"), "state")
+	sut.Equal(query.Get("nonce"), "nonce")
+	sut.Equal(query.Get("client_id"), "todo-list-api")
+	sut.Equal(query.Get("redirect_uri"), "http://localhost:8080/oidc/fake/callback")
+	sut.Equal(query.Get("response_type"), "code")
+	sut.Equal(query.Get("scope"), "openid email profile")
+}
+
+func (sut *OidcHelperTestSuite) Test02UnknownProvider() {
+	sut.T().Log("Test02UnknownProvider")
+	_, err := sut.oidcHelper.AuthCodeUrl(sut.ctx, "other", "state", "nonce", sut.codeVerifier)
+	sut.Equal(err, helpers.ErrUnknownOidcProvider)
+	_, err = sut.oidcHelper.Exchange(sut.ctx, "other", "code", sut.codeVerifier, "nonce")
+	sut.Equal(err, helpers.ErrUnknownOidcProvider)
+}
+
+func (sut *OidcHelperTestSuite) Test03ExchangeSuccess() {
+	sut.T().Log("Test03ExchangeSuccess")
+	sut.authorize()
+	identity, err := sut.oidcHelper.Exchange(sut.ctx, "fake", "code", sut.codeVerifier, "nonce")
+	sut.Require().NoError(err)
+	sut.Equal(identity, helpers.OidcIdentity{
+		Subject:       "subject-1",
+		Email:         "john@doe.com",
+		EmailVerified: true,
+		Name:          "John Doe",
+	})
+}
+
+func (sut *OidcHelperTestSuite) Test04ExchangeWrongCodeVerifier() {
+	sut.T().Log("Test04ExchangeWrongCodeVerifier")
+	sut.authorize()
+	otherCodeVerifier, _, err := helpers.GenerateRandomToken()
+	sut.Require().NoError(err)
+	_, err = sut.oidcHelper.Exchange(sut.ctx, "fake", "code", otherCodeVerifier, "nonce")
+	sut.NotNil(err)
+}
+
+func (sut *OidcHelperTestSuite) Test05ExchangeWrongNonce() {
+	sut.T().Log("Test05ExchangeWrongNonce")
+	sut.authorize()
+	_, err := sut.oidcHelper.Exchange(sut.ctx, "fake", "code", sut.codeVerifier, "otherNonce")
+	sut.NotNil(err)
+}
+
+func (sut *OidcHelperTestSuite) Test06ExchangeWrongAudience() {
+	sut.T().Log("Test06ExchangeWrongAudience")
+	sut.fakeServer.idTokenClaims["aud"] = "other-client"
+	sut.authorize()
+	_, err := sut.oidcHelper.Exchange(sut.ctx, "fake", "code", sut.codeVerifier, "nonce")
+	sut.NotNil(err)
+}
+
+func (sut *OidcHelperTestSuite) Test07ExchangeExpiredIdToken() {
+	sut.T().Log("Test07ExchangeExpiredIdToken")
+	sut.fakeServer.idTokenClaims["exp"] = time.Now().Add(-time.Minute).Unix()
+	sut.authorize()
+	_, err := sut.oidcHelper.Exchange(sut.ctx, "fake", "code", sut.codeVerifier, "nonce")
+	sut.NotNil(err)
+}
+
+func (sut *OidcHelperTestSuite) Test08ExchangeIdTokenSignedByAnotherKey() {
+	sut.T().Log("Test08ExchangeIdTokenSignedByAnotherKey")
+	sut.authorize()
+	sut.fakeServer.signingKey = sut.otherKey
+	_, err := sut.oidcHelper.Exchange(sut.ctx, "fake", "code", sut.codeVerifier, "nonce")
+	sut.NotNil(err)
+}
+
+func (sut *OidcHelperTestSuite) Test09ExchangeEmailVerifiedAsString() {
+	sut.T().Log("Test09ExchangeEmailVerifiedAsString")
+	sut.fakeServer.idTokenClaims["email_verified"] = "false"
+	sut.authorize()
+	identity, err := sut.oidcHelper.Exchange(sut.ctx, "fake", "code", sut.codeVerifier, "nonce")
+	sut.Require().NoError(err)
+	sut.False(identity.EmailVerified)
+}
+
+func (sut *OidcHelperTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *OidcHelperTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+	sut.fakeServer.server.Close()
+}
+
+func (sut *OidcHelperTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
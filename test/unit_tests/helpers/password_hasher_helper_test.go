@@ -0,0 +1,125 @@
+package helpers_test
+
+import (
+	"strings"
+	"testing"
+	"todo-list-api/helpers"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type PasswordHasherTestSuite struct {
+	suite.Suite
+	options helpers.PasswordHasherOptions
+}
+
+func TestPasswordHasherTestSuite(t *testing.T) {
+	suite.Run(t, new(PasswordHasherTestSuite))
+}
+
+func (sut *PasswordHasherTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	// small parameters keep the tests fast
+	sut.options = helpers.DefaultPasswordHasherOptions()
+	sut.options.Argon2Memory = 1024
+	sut.options.Argon2Iterations = 1
+	sut.options.BcryptCost = bcrypt.MinCost
+}
+
+func (sut *PasswordHasherTestSuite) Test01Argon2idHashAndVerify() {
+	sut.T().Log("Test01Argon2idHashAndVerify")
+	passwordHasher, err := helpers.NewPasswordHasherWithOptions(sut.options)
+	sut.Nil(err)
+	passwordHash, err := passwordHasher.Hash("Correct1Horse")
+	sut.Nil(err)
+	sut.True(strings.HasPrefix(passwordHash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+	sut.Len(strings.Split(passwordHash, "$"), 6)
+
+	needsRehash, err := passwordHasher.Verify(passwordHash, "Correct1Horse")
+	sut.Nil(err)
+	sut.False(needsRehash)
+
+	_, err = passwordHasher.Verify(passwordHash, "Wrong1Horse")
+	sut.Equal(err, helpers.ErrPasswordMismatch)
+}
+
+func (sut *PasswordHasherTestSuite) Test02Argon2idWeakerParametersNeedRehash() {
+	sut.T().Log("Test02Argon2idWeakerParametersNeedRehash")
+	passwordHasher, err := helpers.NewPasswordHasherWithOptions(sut.options)
+	sut.Nil(err)
+	passwordHash, err := passwordHasher.Hash("Correct1Horse")
+	sut.Nil(err)
+
+	sut.options.Argon2Iterations = 2
+	strongerPasswordHasher, err := helpers.NewPasswordHasherWithOptions(sut.options)
+	sut.Nil(err)
+	needsRehash, err := strongerPasswordHasher.Verify(passwordHash, "Correct1Horse")
+	sut.Nil(err)
+	sut.True(needsRehash)
+}
+
+func (sut *PasswordHasherTestSuite) Test03BcryptHashNeedsRehashToArgon2id() {
+	sut.T().Log("Test03BcryptHashNeedsRehashToArgon2id")
+	passwordByte, err := bcrypt.GenerateFromPassword([]byte("Correct1Horse"), bcrypt.MinCost)
+	sut.Nil(err)
+	passwordHasher, err := helpers.NewPasswordHasherWithOptions(sut.options)
+	sut.Nil(err)
+
+	needsRehash, err := passwordHasher.Verify(string(passwordByte), "Correct1Horse")
+	sut.Nil(err)
+	sut.True(needsRehash)
+
+	_, err = passwordHasher.Verify(string(passwordByte), "Wrong1Horse")
+	sut.Equal(err, helpers.ErrPasswordMismatch)
+}
+
+func (sut *PasswordHasherTestSuite) Test04BcryptCostNeedsRehash() {
+	sut.T().Log("Test04BcryptCostNeedsRehash")
+	sut.options.Algorithm = helpers.BcryptAlgorithm
+	passwordHasher, err := helpers.NewPasswordHasherWithOptions(sut.options)
+	sut.Nil(err)
+	passwordHash, err := passwordHasher.Hash("Correct1Horse")
+	sut.Nil(err)
+	needsRehash, err := passwordHasher.Verify(passwordHash, "Correct1Horse")
+	sut.Nil(err)
+	sut.False(needsRehash)
+
+	sut.options.BcryptCost = bcrypt.MinCost + 1
+	strongerPasswordHasher, err := helpers.NewPasswordHasherWithOptions(sut.options)
+	sut.Nil(err)
+	needsRehash, err = strongerPasswordHasher.Verify(passwordHash, "Correct1Horse")
+	sut.Nil(err)
+	sut.True(needsRehash)
+}
+
+func (sut *PasswordHasherTestSuite) Test05VerifyUnknownFormat() {
+	sut.T().Log("Test05VerifyUnknownFormat")
+	passwordHasher, err := helpers.NewPasswordHasherWithOptions(sut.options)
+	sut.Nil(err)
+	_, err = passwordHasher.Verify("plaintext", "plaintext")
+	sut.NotNil(err)
+	sut.NotEqual(err, helpers.ErrPasswordMismatch)
+	_, err = passwordHasher.Verify("$argon2id$v=19$m=1024,t=1$salt$key", "Correct1Horse")
+	sut.NotNil(err)
+}
+
+func (sut *PasswordHasherTestSuite) Test06DummyHashUsesCurrentParameters() {
+	sut.T().Log("Test06DummyHashUsesCurrentParameters")
+	passwordHasher, err := helpers.NewPasswordHasherWithOptions(sut.options)
+	sut.Nil(err)
+	sut.True(strings.HasPrefix(passwordHasher.DummyHash(), "$argon2id$v=19$m=1024,t=1,p=1$"))
+	_, err = passwordHasher.Verify(passwordHasher.DummyHash(), "Correct1Horse")
+	sut.Equal(err, helpers.ErrPasswordMismatch)
+}
+
+func (sut *PasswordHasherTestSuite) Test07UnknownAlgorithm() {
+	sut.T().Log("Test07UnknownAlgorithm")
+	sut.options.Algorithm = "md5"
+	_, err := helpers.NewPasswordHasherWithOptions(sut.options)
+	sut.NotNil(err)
+}
+
+func (sut *PasswordHasherTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
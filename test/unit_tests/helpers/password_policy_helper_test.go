@@ -0,0 +1,123 @@
+package helpers_test
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"todo-list-api/helpers"
+	modelresponses "todo-list-api/models/responses"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PasswordPolicyHelperTestSuite struct {
+	suite.Suite
+	policy helpers.PasswordPolicy
+}
+
+func TestPasswordPolicyHelperTestSuite(t *testing.T) {
+	suite.Run(t, new(PasswordPolicyHelperTestSuite))
+}
+
+func (sut *PasswordPolicyHelperTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.policy = helpers.PasswordPolicy{
+		MinLength:        8,
+		MaxLength:        72,
+		RequireLowercase: true,
+		RequireUppercase: true,
+		RequireDigit:     true,
+	}
+}
+
+func violationCodes(violations []modelresponses.PasswordPolicyViolation) (codes []string) {
+	for _, violation := range violations {
+		codes = append(codes, violation.Code)
+	}
+	return
+}
+
+func (sut *PasswordPolicyHelperTestSuite) Test01CheckValidPassword() {
+	sut.T().Log("Test01CheckValidPassword")
+	passwordPolicyHelper, err := helpers.NewPasswordPolicyHelperWithPolicy(sut.policy)
+	sut.Nil(err)
+	violations, err := passwordPolicyHelper.Check("Correct1Horse", "John Doe", "john@doe.com")
+	sut.Nil(err)
+	sut.Empty(violations)
+}
+
+func (sut *PasswordPolicyHelperTestSuite) Test02CheckLengthAndClasses() {
+	sut.T().Log("Test02CheckLengthAndClasses")
+	sut.policy.RequireSymbol = true
+	passwordPolicyHelper, err := helpers.NewPasswordPolicyHelperWithPolicy(sut.policy)
+	sut.Nil(err)
+	violations, err := passwordPolicyHelper.Check("abc", "John Doe", "john@doe.com")
+	sut.Nil(err)
+	sut.Equal(violationCodes(violations), []string{"too_short", "missing_uppercase", "missing_digit", "missing_symbol"})
+
+	violations, err = passwordPolicyHelper.Check("Aa1!"+strings.Repeat("x", 69), "John Doe", "john@doe.com")
+	sut.Nil(err)
+	sut.Equal(violationCodes(violations), []string{"too_long"})
+}
+
+func (sut *PasswordPolicyHelperTestSuite) Test03CheckMaxLengthIsCappedForBcrypt() {
+	sut.T().Log("Test03CheckMaxLengthIsCappedForBcrypt")
+	sut.policy.MaxLength = 200
+	passwordPolicyHelper, err := helpers.NewPasswordPolicyHelperWithPolicy(sut.policy)
+	sut.Nil(err)
+	violations, err := passwordPolicyHelper.Check("Aa1"+strings.Repeat("x", 70), "John Doe", "john@doe.com")
+	sut.Nil(err)
+	sut.Equal(violationCodes(violations), []string{"too_long"})
+}
+
+func (sut *PasswordPolicyHelperTestSuite) Test04CheckContainsEmailOrName() {
+	sut.T().Log("Test04CheckContainsEmailOrName")
+	passwordPolicyHelper, err := helpers.NewPasswordPolicyHelperWithPolicy(sut.policy)
+	sut.Nil(err)
+	violations, err := passwordPolicyHelper.Check("MyJohn2024", "Johnny Doe", "jdoe@doe.com")
+	sut.Nil(err)
+	sut.Empty(violations)
+
+	violations, err = passwordPolicyHelper.Check("Secret1JOHN", "John Doe", "someone@doe.com")
+	sut.Nil(err)
+	sut.Equal(violationCodes(violations), []string{"contains_name"})
+
+	violations, err = passwordPolicyHelper.Check("Xjdoe1Secret", "Someone", "jdoe@doe.com")
+	sut.Nil(err)
+	sut.Equal(violationCodes(violations), []string{"contains_email"})
+}
+
+func (sut *PasswordPolicyHelperTestSuite) Test05CheckBreachedPassword() {
+	sut.T().Log("Test05CheckBreachedPassword")
+	breachedPasswordsPath := sut.T().TempDir()
+	passwordHash := sha1.Sum([]byte("Password1"))
+	hexHash := strings.ToUpper(hex.EncodeToString(passwordHash[:]))
+	rangeFile := "0018A45C4D1DEF81644B54AB7F969B88D65:3\r\n" + hexHash[5:] + ":11850\r\n"
+	err := os.WriteFile(filepath.Join(breachedPasswordsPath, hexHash[:5]+".txt"), []byte(rangeFile), 0600)
+	sut.Nil(err)
+
+	sut.policy.BreachedPasswordsPath = breachedPasswordsPath
+	passwordPolicyHelper, err := helpers.NewPasswordPolicyHelperWithPolicy(sut.policy)
+	sut.Nil(err)
+	violations, err := passwordPolicyHelper.Check("Password1", "John Doe", "john@doe.com")
+	sut.Nil(err)
+	sut.Equal(violationCodes(violations), []string{"breached"})
+
+	violations, err = passwordPolicyHelper.Check("Correct1Horse", "John Doe", "john@doe.com")
+	sut.Nil(err)
+	sut.Empty(violations)
+}
+
+func (sut *PasswordPolicyHelperTestSuite) Test06NewPasswordPolicyHelperMissingBreachedPasswordsPath() {
+	sut.T().Log("Test06NewPasswordPolicyHelperMissingBreachedPasswordsPath")
+	sut.policy.BreachedPasswordsPath = filepath.Join(sut.T().TempDir(), "missing")
+	_, err := helpers.NewPasswordPolicyHelperWithPolicy(sut.policy)
+	sut.NotNil(err)
+}
+
+func (sut *PasswordPolicyHelperTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
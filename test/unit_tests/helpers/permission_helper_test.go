@@ -0,0 +1,42 @@
+package helpers_test
+
+import (
+	"testing"
+	"todo-list-api/helpers"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PermissionHelperTestSuite struct {
+	suite.Suite
+}
+
+func TestPermissionHelperTestSuite(t *testing.T) {
+	suite.Run(t, new(PermissionHelperTestSuite))
+}
+
+func (sut *PermissionHelperTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+}
+
+func (sut *PermissionHelperTestSuite) Test01HasPermissionAdmin() {
+	sut.T().Log("Test01HasPermissionAdmin")
+	sut.True(helpers.HasPermission(helpers.RoleAdmin, helpers.PermissionManageUsers))
+	sut.True(helpers.HasPermission(helpers.RoleAdmin, helpers.PermissionViewStatistics))
+}
+
+func (sut *PermissionHelperTestSuite) Test02HasPermissionUser() {
+	sut.T().Log("Test02HasPermissionUser")
+	sut.False(helpers.HasPermission(helpers.RoleUser, helpers.PermissionManageUsers))
+	sut.False(helpers.HasPermission(helpers.RoleUser, helpers.PermissionViewStatistics))
+}
+
+func (sut *PermissionHelperTestSuite) Test03HasPermissionUnknownRole() {
+	sut.T().Log("Test03HasPermissionUnknownRole")
+	sut.False(helpers.HasPermission("", helpers.PermissionManageUsers))
+	sut.False(helpers.HasPermission("root", helpers.PermissionManageUsers))
+}
+
+func (sut *PermissionHelperTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
@@ -0,0 +1,130 @@
+package helpers_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+	"todo-list-api/helpers"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RateLimiterHelperTestSuite struct {
+	suite.Suite
+	ctx            context.Context
+	now            time.Time
+	policy         helpers.RateLimitPolicy
+	rateLimitStore helpers.RateLimitStore
+}
+
+func TestRateLimiterHelperTestSuite(t *testing.T) {
+	suite.Run(t, new(RateLimiterHelperTestSuite))
+}
+
+func (sut *RateLimiterHelperTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.ctx = context.Background()
+	sut.now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sut.policy = helpers.RateLimitPolicy{Limit: 3, Window: 3 * time.Second}
+}
+
+func (sut *RateLimiterHelperTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.rateLimitStore = helpers.NewMemoryRateLimitStore()
+}
+
+func (sut *RateLimiterHelperTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *RateLimiterHelperTestSuite) take(key string, policy helpers.RateLimitPolicy, now time.Time) helpers.RateLimitResult {
+	result, err := sut.rateLimitStore.Take(sut.ctx, key, policy, now)
+	sut.Require().NoError(err)
+	return result
+}
+
+func (sut *RateLimiterHelperTestSuite) Test01BurstUpToLimit() {
+	sut.T().Log("Test01BurstUpToLimit")
+	for i := 2; i >= 0; i-- {
+		result := sut.take("key", sut.policy, sut.now)
+		sut.True(result.Allowed)
+		sut.Equal(result.Limit, 3)
+		sut.Equal(result.Remaining, i)
+	}
+	result := sut.take("key", sut.policy, sut.now)
+	sut.False(result.Allowed)
+	sut.Equal(result.Remaining, 0)
+	sut.Equal(result.RetryAfter, time.Second)
+	sut.Equal(result.ResetAfter, 3*time.Second)
+}
+
+func (sut *RateLimiterHelperTestSuite) Test02RefillsOverWindow() {
+	sut.T().Log("Test02RefillsOverWindow")
+	for i := 0; i < 3; i++ {
+		sut.take("key", sut.policy, sut.now)
+	}
+	sut.False(sut.take("key", sut.policy, sut.now.Add(500*time.Millisecond)).Allowed)
+	result := sut.take("key", sut.policy, sut.now.Add(time.Second))
+	sut.True(result.Allowed)
+	sut.Equal(result.Remaining, 0)
+	result = sut.take("key", sut.policy, sut.now.Add(time.Hour))
+	sut.True(result.Allowed)
+	sut.Equal(result.Remaining, 2)
+}
+
+func (sut *RateLimiterHelperTestSuite) Test03KeysAreSeparate() {
+	sut.T().Log("Test03KeysAreSeparate")
+	for i := 0; i < 3; i++ {
+		sut.take("user:1", sut.policy, sut.now)
+	}
+	sut.False(sut.take("user:1", sut.policy, sut.now).Allowed)
+	sut.True(sut.take("user:2", sut.policy, sut.now).Allowed)
+}
+
+func (sut *RateLimiterHelperTestSuite) Test04ConcurrentTakesNeverExceedLimit() {
+	sut.T().Log("Test04ConcurrentTakesNeverExceedLimit")
+	policy := helpers.RateLimitPolicy{Limit: 50, Window: time.Hour}
+	var waitGroup sync.WaitGroup
+	var mutex sync.Mutex
+	allowed := 0
+	for i := 0; i < 200; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			result, err := sut.rateLimitStore.Take(sut.ctx, "key", policy, sut.now)
+			if err == nil && result.Allowed {
+				mutex.Lock()
+				allowed++
+				mutex.Unlock()
+			}
+		}()
+	}
+	waitGroup.Wait()
+	sut.Equal(allowed, 50)
+}
+
+func (sut *RateLimiterHelperTestSuite) Test05NewRateLimitPolicy() {
+	sut.T().Log("Test05NewRateLimitPolicy")
+	sut.Equal(helpers.NewRateLimitPolicy(10, 60), helpers.RateLimitPolicy{Limit: 10, Window: time.Minute})
+}
+
+func (sut *RateLimiterHelperTestSuite) Test06NewRateLimitResult() {
+	sut.T().Log("Test06NewRateLimitResult")
+	policy := helpers.RateLimitPolicy{Limit: 10, Window: 10 * time.Second}
+	sut.Equal(helpers.NewRateLimitResult(4.5, true, policy), helpers.RateLimitResult{
+		Allowed:    true,
+		Limit:      10,
+		Remaining:  4,
+		ResetAfter: 5500 * time.Millisecond,
+	})
+	sut.Equal(helpers.NewRateLimitResult(0.25, false, policy), helpers.RateLimitResult{
+		Limit:      10,
+		ResetAfter: 9750 * time.Millisecond,
+		RetryAfter: 750 * time.Millisecond,
+	})
+}
+
+func (sut *RateLimiterHelperTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
@@ -0,0 +1,80 @@
+package helpers_test
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TotpHelperTestSuite struct {
+	suite.Suite
+	totpHelper helpers.TotpHelper
+	secret     string
+}
+
+func TestTotpHelperTestSuite(t *testing.T) {
+	suite.Run(t, new(TotpHelperTestSuite))
+}
+
+func (sut *TotpHelperTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.totpHelper = helpers.NewTotpHelper(config.TotpConfig{Issuer: "todo-list-api"})
+	// the RFC 6238 test key
+	sut.secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+}
+
+func (sut *TotpHelperTestSuite) Test01GenerateTotpCodeRfc6238Vectors() {
+	sut.T().Log("Test01GenerateTotpCodeRfc6238Vectors")
+	key := []byte("12345678901234567890")
+	sut.Equal(helpers.GenerateTotpCode(key, 59/30), "287082")
+	sut.Equal(helpers.GenerateTotpCode(key, 1111111109/30), "081804")
+	sut.Equal(helpers.GenerateTotpCode(key, 1234567890/30), "005924")
+	sut.Equal(helpers.GenerateTotpCode(key, 2000000000/30), "279037")
+}
+
+func (sut *TotpHelperTestSuite) Test02ValidateAllowsOneStepOfDrift() {
+	sut.T().Log("Test02ValidateAllowsOneStepOfDrift")
+	now := time.Unix(1111111109, 0)
+	step, ok := sut.totpHelper.Validate(sut.secret, "081804", now)
+	sut.True(ok)
+	sut.Equal(step, int64(1111111109/30))
+	_, ok = sut.totpHelper.Validate(sut.secret, "081804", now.Add(30*time.Second))
+	sut.True(ok)
+	_, ok = sut.totpHelper.Validate(sut.secret, "081804", now.Add(90*time.Second))
+	sut.False(ok)
+	_, ok = sut.totpHelper.Validate(sut.secret, "000000", now)
+	sut.False(ok)
+}
+
+func (sut *TotpHelperTestSuite) Test03GenerateUri() {
+	sut.T().Log("Test03GenerateUri")
+	secret, err := sut.totpHelper.GenerateSecret()
+	sut.Nil(err)
+	uri, err := url.Parse(sut.totpHelper.GenerateUri(secret, "john@doe.com"))
+	sut.Nil(err)
+	sut.Equal(uri.Scheme, "otpauth")
+	sut.Equal(uri.Host, "totp")
+	sut.Equal(uri.Path, "/todo-list-api:john@doe.com")
+	sut.Equal(uri.Query().Get("secret"), secret)
+	sut.Equal(uri.Query().Get("issuer"), "todo-list-api")
+}
+
+func (sut *TotpHelperTestSuite) Test04GenerateRecoveryCodes() {
+	sut.T().Log("Test04GenerateRecoveryCodes")
+	recoveryCodes, err := sut.totpHelper.GenerateRecoveryCodes()
+	sut.Nil(err)
+	sut.Equal(len(recoveryCodes), 10)
+	seen := map[string]bool{}
+	for _, recoveryCode := range recoveryCodes {
+		sut.Len(recoveryCode, 17)
+		sut.Equal(strings.ToLower(recoveryCode), recoveryCode)
+		sut.False(seen[recoveryCode])
+		seen[recoveryCode] = true
+	}
+}
@@ -0,0 +1,107 @@
+package helpers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-list-api/helpers"
+
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type TracingHelperTestSuite struct {
+	suite.Suite
+	ctx          context.Context
+	spanRecorder *tracetest.SpanRecorder
+}
+
+func TestTracingHelperTestSuite(t *testing.T) {
+	suite.Run(t, new(TracingHelperTestSuite))
+}
+
+func (sut *TracingHelperTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.ctx = context.Background()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
+
+func (sut *TracingHelperTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.spanRecorder = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sut.spanRecorder)))
+}
+
+func (sut *TracingHelperTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *TracingHelperTestSuite) Test01EndSpanFailsOnServerError() {
+	sut.T().Log("Test01EndSpanFailsOnServerError")
+	_, span := helpers.StartSpan(sut.ctx, "TodoService.Create")
+	helpers.EndSpan(span, http.StatusInternalServerError, helpers.ToResponse("connection refused"))
+	_, span = helpers.StartSpan(sut.ctx, "TodoService.Update")
+	helpers.EndSpan(span, http.StatusNotFound, helpers.ToResponse("cannot find todo"))
+	spans := sut.spanRecorder.Ended()
+	sut.Require().Len(spans, 2)
+	sut.Equal(spans[0].Status(), sdktrace.Status{Code: codes.Error, Description: "connection refused"})
+	sut.Contains(spans[0].Attributes(), attribute.Int("http_code", http.StatusInternalServerError))
+	sut.Equal(spans[1].Status().Code, codes.Unset)
+}
+
+func (sut *TracingHelperTestSuite) Test02StartSpanLeavesUnrecordedSpansOutOfTheContext() {
+	sut.T().Log("Test02StartSpanLeavesUnrecordedSpansOutOfTheContext")
+	otel.SetTracerProvider(noop.NewTracerProvider())
+	ctx, span := helpers.StartSpan(sut.ctx, "TodoService.Create")
+	sut.False(span.IsRecording())
+	sut.Equal(ctx, sut.ctx)
+}
+
+func (sut *TracingHelperTestSuite) Test03TransportPassesTheTraceOn() {
+	sut.T().Log("Test03TransportPassesTheTraceOn")
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx, parent := helpers.StartSpan(sut.ctx, "OidcService.Callback")
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/token?code=secret", nil)
+	sut.Require().NoError(err)
+	response, err := (&http.Client{Transport: helpers.TracingTransport{}}).Do(request)
+	sut.Require().NoError(err)
+	response.Body.Close()
+	parent.End()
+
+	spans := sut.spanRecorder.Ended()
+	sut.Require().Len(spans, 2)
+	clientSpan := spans[0]
+	sut.Equal(clientSpan.SpanKind(), trace.SpanKindClient)
+	sut.Equal(clientSpan.Parent().SpanID(), parent.SpanContext().SpanID())
+	sut.Contains(clientSpan.Attributes(), attribute.String("url.full", server.URL+"/token"))
+	sut.Contains(clientSpan.Attributes(), attribute.Int("http.response.status_code", http.StatusOK))
+	sut.Equal(traceparent, "00-"+clientSpan.SpanContext().TraceID().String()+"-"+clientSpan.SpanContext().SpanID().String()+"-01")
+}
+
+func (sut *TracingHelperTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *TracingHelperTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+	otel.SetTracerProvider(noop.NewTracerProvider())
+}
+
+func (sut *TracingHelperTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+}
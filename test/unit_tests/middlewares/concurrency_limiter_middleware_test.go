@@ -0,0 +1,103 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-list-api/helpers"
+	"todo-list-api/middlewares"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConcurrencyLimiterMiddlewareTestSuite struct {
+	suite.Suite
+	e       *echo.Echo
+	started chan struct{}
+	finish  chan struct{}
+}
+
+func TestConcurrencyLimiterMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(ConcurrencyLimiterMiddlewareTestSuite))
+}
+
+func (sut *ConcurrencyLimiterMiddlewareTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+}
+
+func (sut *ConcurrencyLimiterMiddlewareTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.started = make(chan struct{}, 1)
+	sut.finish = make(chan struct{})
+	sut.e = echo.New()
+	routePriorities := map[string]int{"POST /login": helpers.PriorityHigh, "GET /todos": helpers.PriorityLow}
+	sut.e.Use(middlewares.LimitConcurrency(helpers.NewConcurrencyLimiter(1, 1, time.Second), helpers.NewMetricsHelper(), routePriorities, 2*time.Second))
+	sut.e.GET("/slow", func(c echo.Context) error {
+		sut.started <- struct{}{}
+		<-sut.finish
+		return c.NoContent(http.StatusNoContent)
+	})
+	handler := func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}
+	sut.e.GET("/todos", handler)
+	sut.e.POST("/login", handler)
+}
+
+func (sut *ConcurrencyLimiterMiddlewareTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *ConcurrencyLimiterMiddlewareTestSuite) serve(method string, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	sut.e.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+	return recorder
+}
+
+// serveAsync sends the request and returns once it is handled or waiting in the queue.
+func (sut *ConcurrencyLimiterMiddlewareTestSuite) serveAsync(method string, path string) chan *httptest.ResponseRecorder {
+	served := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		served <- sut.serve(method, path)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	return served
+}
+
+func (sut *ConcurrencyLimiterMiddlewareTestSuite) Test01PassesWhenIdle() {
+	sut.T().Log("Test01PassesWhenIdle")
+	recorder := sut.serve(http.MethodGet, "/todos")
+	sut.Equal(recorder.Code, http.StatusNoContent)
+	sut.Equal(recorder.Header().Get("Retry-After"), "")
+}
+
+func (sut *ConcurrencyLimiterMiddlewareTestSuite) Test02AuthRoutePushesOutListRoute() {
+	sut.T().Log("Test02AuthRoutePushesOutListRoute")
+	slowServed := sut.serveAsync(http.MethodGet, "/slow")
+	<-sut.started
+	todosServed := sut.serveAsync(http.MethodGet, "/todos")
+	loginServed := sut.serveAsync(http.MethodPost, "/login")
+
+	recorder := <-todosServed
+	sut.Equal(recorder.Code, http.StatusServiceUnavailable)
+	sut.Equal(recorder.Header().Get("Retry-After"), "2")
+	sut.JSONEq(`{"message":"server is busy, try again later","retryAfter":2}`, recorder.Body.String())
+
+	close(sut.finish)
+	sut.Equal((<-slowServed).Code, http.StatusNoContent)
+	sut.Equal((<-loginServed).Code, http.StatusNoContent)
+}
+
+func (sut *ConcurrencyLimiterMiddlewareTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *ConcurrencyLimiterMiddlewareTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *ConcurrencyLimiterMiddlewareTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
@@ -0,0 +1,121 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	"todo-list-api/middlewares"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+type CsrfMiddlewareTestSuite struct {
+	suite.Suite
+	e       *echo.Echo
+	handler echo.HandlerFunc
+}
+
+func TestCsrfMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(CsrfMiddlewareTestSuite))
+}
+
+func (sut *CsrfMiddlewareTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.e = echo.New()
+	sut.handler = middlewares.CsrfProtect(config.CookieConfig{Secure: true})(func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+}
+
+func (sut *CsrfMiddlewareTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *CsrfMiddlewareTestSuite) serve(request *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	err := sut.handler(sut.e.NewContext(request, recorder))
+	sut.Require().NoError(err)
+	return recorder
+}
+
+func (sut *CsrfMiddlewareTestSuite) Test01SafeRequestGetsCsrfCookie() {
+	sut.T().Log("Test01SafeRequestGetsCsrfCookie")
+	recorder := sut.serve(httptest.NewRequest(http.MethodGet, "/todos", nil))
+	sut.Equal(recorder.Code, http.StatusNoContent)
+	cookies := recorder.Result().Cookies()
+	sut.Require().Equal(len(cookies), 1)
+	sut.Equal(cookies[0].Name, helpers.CsrfTokenCookieName)
+	sut.NotEqual(cookies[0].Value, "")
+	sut.False(cookies[0].HttpOnly)
+	sut.True(cookies[0].Secure)
+	sut.Equal(cookies[0].SameSite, http.SameSiteLaxMode)
+}
+
+func (sut *CsrfMiddlewareTestSuite) Test02UnsafeRequestWithoutHeader() {
+	sut.T().Log("Test02UnsafeRequestWithoutHeader")
+	request := httptest.NewRequest(http.MethodDelete, "/todos/1", nil)
+	request.AddCookie(&http.Cookie{Name: helpers.AccessTokenCookieName, Value: "accessToken"})
+	request.AddCookie(&http.Cookie{Name: helpers.CsrfTokenCookieName, Value: "csrfToken"})
+	recorder := sut.serve(request)
+	sut.Equal(recorder.Code, http.StatusForbidden)
+}
+
+func (sut *CsrfMiddlewareTestSuite) Test03UnsafeRequestWithWrongHeader() {
+	sut.T().Log("Test03UnsafeRequestWithWrongHeader")
+	request := httptest.NewRequest(http.MethodDelete, "/todos/1", nil)
+	request.AddCookie(&http.Cookie{Name: helpers.AccessTokenCookieName, Value: "accessToken"})
+	request.AddCookie(&http.Cookie{Name: helpers.CsrfTokenCookieName, Value: "csrfToken"})
+	request.Header.Set(helpers.CsrfTokenHeaderName, "otherCsrfToken")
+	recorder := sut.serve(request)
+	sut.Equal(recorder.Code, http.StatusForbidden)
+}
+
+func (sut *CsrfMiddlewareTestSuite) Test04UnsafeRequestWithoutCsrfCookie() {
+	sut.T().Log("Test04UnsafeRequestWithoutCsrfCookie")
+	request := httptest.NewRequest(http.MethodDelete, "/todos/1", nil)
+	request.AddCookie(&http.Cookie{Name: helpers.AccessTokenCookieName, Value: "accessToken"})
+	request.Header.Set(helpers.CsrfTokenHeaderName, "csrfToken")
+	recorder := sut.serve(request)
+	sut.Equal(recorder.Code, http.StatusForbidden)
+	// the response carries a fresh token so the frontend can retry
+	cookies := recorder.Result().Cookies()
+	sut.Require().Equal(len(cookies), 1)
+	sut.NotEqual(cookies[0].Value, "csrfToken")
+}
+
+func (sut *CsrfMiddlewareTestSuite) Test05UnsafeRequestWithMatchingHeader() {
+	sut.T().Log("Test05UnsafeRequestWithMatchingHeader")
+	request := httptest.NewRequest(http.MethodDelete, "/todos/1", nil)
+	request.AddCookie(&http.Cookie{Name: helpers.AccessTokenCookieName, Value: "accessToken"})
+	request.AddCookie(&http.Cookie{Name: helpers.CsrfTokenCookieName, Value: "csrfToken"})
+	request.Header.Set(helpers.CsrfTokenHeaderName, "csrfToken")
+	recorder := sut.serve(request)
+	sut.Equal(recorder.Code, http.StatusNoContent)
+	sut.Equal(len(recorder.Result().Cookies()), 0)
+}
+
+func (sut *CsrfMiddlewareTestSuite) Test06BearerRequestIsNotChecked() {
+	sut.T().Log("Test06BearerRequestIsNotChecked")
+	request := httptest.NewRequest(http.MethodDelete, "/todos/1", nil)
+	request.AddCookie(&http.Cookie{Name: helpers.AccessTokenCookieName, Value: "accessToken"})
+	request.Header.Set(echo.HeaderAuthorization, "Bearer oauthAccessToken")
+	recorder := sut.serve(request)
+	sut.Equal(recorder.Code, http.StatusNoContent)
+}
+
+func (sut *CsrfMiddlewareTestSuite) Test07RequestWithoutSessionCookieIsNotChecked() {
+	sut.T().Log("Test07RequestWithoutSessionCookieIsNotChecked")
+	recorder := sut.serve(httptest.NewRequest(http.MethodPost, "/oauth/token", nil))
+	sut.Equal(recorder.Code, http.StatusNoContent)
+}
+
+func (sut *CsrfMiddlewareTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *CsrfMiddlewareTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
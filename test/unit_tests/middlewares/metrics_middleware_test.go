@@ -0,0 +1,92 @@
+package middlewares_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-list-api/helpers"
+	"todo-list-api/middlewares"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+// scrapeMetrics returns what GET /metrics would answer.
+func scrapeMetrics(metricsHelper helpers.MetricsHelper) string {
+	recorder := httptest.NewRecorder()
+	metricsHelper.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return recorder.Body.String()
+}
+
+type MetricsMiddlewareTestSuite struct {
+	suite.Suite
+	e             *echo.Echo
+	metricsHelper helpers.MetricsHelper
+}
+
+func TestMetricsMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(MetricsMiddlewareTestSuite))
+}
+
+func (sut *MetricsMiddlewareTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+}
+
+func (sut *MetricsMiddlewareTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.metricsHelper = helpers.NewMetricsHelper()
+	sut.e = echo.New()
+	sut.e.Use(middlewares.CollectMetrics(sut.metricsHelper))
+	sut.e.GET("/todos/:id", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, helpers.ToResponse("ok"))
+	})
+	sut.e.DELETE("/todos/:id", func(c echo.Context) error {
+		return errors.New("connection refused")
+	})
+}
+
+func (sut *MetricsMiddlewareTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *MetricsMiddlewareTestSuite) serve(method string, path string) {
+	sut.e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, path, nil))
+}
+
+func (sut *MetricsMiddlewareTestSuite) Test01CountsByRoute() {
+	sut.T().Log("Test01CountsByRoute")
+	sut.serve(http.MethodGet, "/todos/1")
+	sut.serve(http.MethodGet, "/todos/2")
+	metrics := scrapeMetrics(sut.metricsHelper)
+	sut.Contains(metrics, `http_requests_total{method="GET",route="/todos/:id",status="200"} 2`)
+	sut.Contains(metrics, `http_request_duration_seconds_count{method="GET",route="/todos/:id",status="200"} 2`)
+	sut.NotContains(metrics, "/todos/1")
+}
+
+func (sut *MetricsMiddlewareTestSuite) Test02CountsHandlerErrorWithItsStatus() {
+	sut.T().Log("Test02CountsHandlerErrorWithItsStatus")
+	sut.serve(http.MethodDelete, "/todos/1")
+	sut.Contains(scrapeMetrics(sut.metricsHelper), `http_requests_total{method="DELETE",route="/todos/:id",status="500"} 1`)
+}
+
+func (sut *MetricsMiddlewareTestSuite) Test03UnmatchedPathsShareOneRoute() {
+	sut.T().Log("Test03UnmatchedPathsShareOneRoute")
+	sut.serve(http.MethodGet, "/wp-admin")
+	sut.serve(http.MethodGet, "/.env")
+	metrics := scrapeMetrics(sut.metricsHelper)
+	sut.Contains(metrics, `http_requests_total{method="GET",route="unmatched",status="404"} 2`)
+	sut.NotContains(metrics, "wp-admin")
+}
+
+func (sut *MetricsMiddlewareTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *MetricsMiddlewareTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *MetricsMiddlewareTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
@@ -0,0 +1,149 @@
+package middlewares_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	"todo-list-api/middlewares"
+	mockhelpers "todo-list-api/test/unit_tests/helpers/mocks"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type RateLimiterMiddlewareTestSuite struct {
+	suite.Suite
+	e             *echo.Echo
+	jwtHelperMock *mockhelpers.JwtHelperMock
+	metricsHelper helpers.MetricsHelper
+}
+
+func TestRateLimiterMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(RateLimiterMiddlewareTestSuite))
+}
+
+func (sut *RateLimiterMiddlewareTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+}
+
+func (sut *RateLimiterMiddlewareTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.jwtHelperMock = new(mockhelpers.JwtHelperMock)
+	sut.metricsHelper = helpers.NewMetricsHelper()
+	sut.e = echo.New()
+	sut.e.IPExtractor = echo.ExtractIPDirect()
+	defaultPolicy := helpers.RateLimitPolicy{Limit: 2, Window: time.Minute}
+	routePolicies := map[string]helpers.RateLimitPolicy{"POST /login": {Limit: 1, Window: time.Minute}}
+	sut.e.Use(middlewares.RateLimit(helpers.NewMemoryRateLimitStore(), sut.jwtHelperMock, sut.metricsHelper, defaultPolicy, routePolicies))
+	handler := func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}
+	sut.e.GET("/todos", handler)
+	sut.e.POST("/login", handler)
+}
+
+func (sut *RateLimiterMiddlewareTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *RateLimiterMiddlewareTestSuite) serve(method string, path string, remoteAddr string, accessToken string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, nil)
+	request.RemoteAddr = remoteAddr
+	if accessToken != "" {
+		request.AddCookie(&http.Cookie{Name: helpers.AccessTokenCookieName, Value: accessToken})
+	}
+	recorder := httptest.NewRecorder()
+	sut.e.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (sut *RateLimiterMiddlewareTestSuite) Test01SetsHeaders() {
+	sut.T().Log("Test01SetsHeaders")
+	recorder := sut.serve(http.MethodGet, "/todos", "10.0.0.1:1234", "")
+	sut.Equal(recorder.Code, http.StatusNoContent)
+	sut.Equal(recorder.Header().Get("X-RateLimit-Limit"), "2")
+	sut.Equal(recorder.Header().Get("X-RateLimit-Remaining"), "1")
+	sut.Equal(recorder.Header().Get("X-RateLimit-Reset"), "30")
+	sut.Equal(recorder.Header().Get("Retry-After"), "")
+}
+
+func (sut *RateLimiterMiddlewareTestSuite) Test02LimitedByIp() {
+	sut.T().Log("Test02LimitedByIp")
+	sut.serve(http.MethodGet, "/todos", "10.0.0.1:1234", "")
+	sut.serve(http.MethodGet, "/todos", "10.0.0.1:1234", "")
+	recorder := sut.serve(http.MethodGet, "/todos", "10.0.0.1:1234", "")
+	sut.Equal(recorder.Code, http.StatusTooManyRequests)
+	sut.Equal(recorder.Header().Get("Retry-After"), "30")
+	sut.JSONEq(`{"message":"too many requests","retryAfter":30}`, recorder.Body.String())
+	sut.Equal(sut.serve(http.MethodGet, "/todos", "10.0.0.2:1234", "").Code, http.StatusNoContent)
+}
+
+func (sut *RateLimiterMiddlewareTestSuite) Test03LimitedByUserAcrossAddresses() {
+	sut.T().Log("Test03LimitedByUserAcrossAddresses")
+	sut.jwtHelperMock.Mock.On("ParseAccessToken", "accessToken").Return(&helpers.AccessTokenCustomClaims{Id: 1}, nil)
+	sut.serve(http.MethodGet, "/todos", "10.0.0.1:1234", "accessToken")
+	sut.serve(http.MethodGet, "/todos", "10.0.0.2:1234", "accessToken")
+	sut.Equal(sut.serve(http.MethodGet, "/todos", "10.0.0.3:1234", "accessToken").Code, http.StatusTooManyRequests)
+	sut.Equal(sut.serve(http.MethodGet, "/todos", "10.0.0.3:1234", "").Code, http.StatusNoContent)
+}
+
+func (sut *RateLimiterMiddlewareTestSuite) Test04InvalidTokenFallsBackToIp() {
+	sut.T().Log("Test04InvalidTokenFallsBackToIp")
+	var claims *helpers.AccessTokenCustomClaims
+	sut.jwtHelperMock.Mock.On("ParseAccessToken", "invalid").Return(claims, errors.New("invalid token"))
+	sut.serve(http.MethodGet, "/todos", "10.0.0.1:1234", "invalid")
+	sut.serve(http.MethodGet, "/todos", "10.0.0.1:1234", "")
+	sut.Equal(sut.serve(http.MethodGet, "/todos", "10.0.0.1:1234", "invalid").Code, http.StatusTooManyRequests)
+}
+
+func (sut *RateLimiterMiddlewareTestSuite) Test05RoutePolicyHasItsOwnBucket() {
+	sut.T().Log("Test05RoutePolicyHasItsOwnBucket")
+	recorder := sut.serve(http.MethodPost, "/login", "10.0.0.1:1234", "")
+	sut.Equal(recorder.Code, http.StatusNoContent)
+	sut.Equal(recorder.Header().Get("X-RateLimit-Limit"), "1")
+	recorder = sut.serve(http.MethodPost, "/login", "10.0.0.1:1234", "")
+	sut.Equal(recorder.Code, http.StatusTooManyRequests)
+	sut.Equal(recorder.Header().Get("Retry-After"), "60")
+	sut.Equal(sut.serve(http.MethodGet, "/todos", "10.0.0.1:1234", "").Code, http.StatusNoContent)
+}
+
+func (sut *RateLimiterMiddlewareTestSuite) Test06StoreErrorLetsRequestThrough() {
+	sut.T().Log("Test06StoreErrorLetsRequestThrough")
+	rateLimitStoreMock := new(mockhelpers.RateLimitStoreMock)
+	rateLimitStoreMock.Mock.On("Take", mock.Anything, "default|ip:10.0.0.1", mock.Anything, mock.Anything).Return(helpers.RateLimitResult{}, errors.New("connection refused"))
+	handler := middlewares.RateLimit(rateLimitStoreMock, sut.jwtHelperMock, sut.metricsHelper, helpers.RateLimitPolicy{Limit: 1, Window: time.Minute}, nil)(func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+	output := new(bytes.Buffer)
+	request := httptest.NewRequest(http.MethodGet, "/todos", nil)
+	request = request.WithContext(helpers.ContextWithLogger(request.Context(), helpers.NewLogger(config.LogConfig{Level: "info"}, output)))
+	request.RemoteAddr = "10.0.0.1:1234"
+	recorder := httptest.NewRecorder()
+	err := handler(sut.e.NewContext(request, recorder))
+	sut.NoError(err)
+	sut.Equal(recorder.Code, http.StatusNoContent)
+	sut.Equal(recorder.Header().Get("X-RateLimit-Limit"), "")
+	sut.Contains(output.String(), `"msg":"rate limit","error":"connection refused"`)
+}
+
+func (sut *RateLimiterMiddlewareTestSuite) Test07RejectionIsCounted() {
+	sut.T().Log("Test07RejectionIsCounted")
+	sut.serve(http.MethodPost, "/login", "10.0.0.1:1234", "")
+	sut.serve(http.MethodPost, "/login", "10.0.0.1:1234", "")
+	sut.serve(http.MethodPost, "/login", "10.0.0.1:1234", "")
+	sut.Contains(scrapeMetrics(sut.metricsHelper), `rate_limit_rejections_total{limiter="rate",policy="POST /login"} 2`)
+}
+
+func (sut *RateLimiterMiddlewareTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *RateLimiterMiddlewareTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
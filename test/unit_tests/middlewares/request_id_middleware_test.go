@@ -0,0 +1,110 @@
+package middlewares_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-list-api/helpers"
+	"todo-list-api/middlewares"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+type RequestIdMiddlewareTestSuite struct {
+	suite.Suite
+	e *echo.Echo
+}
+
+func TestRequestIdMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(RequestIdMiddlewareTestSuite))
+}
+
+func (sut *RequestIdMiddlewareTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+}
+
+func (sut *RequestIdMiddlewareTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.e = echo.New()
+	sut.e.JSONSerializer = middlewares.RequestIdJsonSerializer{}
+	sut.e.Use(middlewares.SetRequestId)
+	sut.e.GET("/request-id", func(c echo.Context) error {
+		requestId, _ := helpers.RequestIdFromContext(c.Request().Context())
+		return c.JSON(http.StatusOK, helpers.ToResponse(requestId))
+	})
+	sut.e.GET("/error", func(c echo.Context) error {
+		return c.JSON(http.StatusBadRequest, helpers.ToResponse("title is required"))
+	})
+	sut.e.GET("/list-error", func(c echo.Context) error {
+		return c.JSON(http.StatusBadRequest, []string{"title is required"})
+	})
+}
+
+func (sut *RequestIdMiddlewareTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *RequestIdMiddlewareTestSuite) serve(path string, requestId string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	if requestId != "" {
+		request.Header.Set(echo.HeaderXRequestID, requestId)
+	}
+	recorder := httptest.NewRecorder()
+	sut.e.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (sut *RequestIdMiddlewareTestSuite) Test01KeepsTheRequestIdOfTheCaller() {
+	sut.T().Log("Test01KeepsTheRequestIdOfTheCaller")
+	recorder := sut.serve("/request-id", "proxy-1234")
+	sut.Equal(recorder.Code, http.StatusOK)
+	sut.Equal(recorder.Header().Get(echo.HeaderXRequestID), "proxy-1234")
+	sut.JSONEq(recorder.Body.String(), `{"message":"proxy-1234"}`)
+}
+
+func (sut *RequestIdMiddlewareTestSuite) Test02GeneratesARequestId() {
+	sut.T().Log("Test02GeneratesARequestId")
+	recorder := sut.serve("/request-id", "")
+	requestId := recorder.Header().Get(echo.HeaderXRequestID)
+	sut.Len(requestId, 32)
+	sut.JSONEq(recorder.Body.String(), `{"message":"`+requestId+`"}`)
+}
+
+func (sut *RequestIdMiddlewareTestSuite) Test03ReplacesAnInvalidRequestId() {
+	sut.T().Log("Test03ReplacesAnInvalidRequestId")
+	recorder := sut.serve("/request-id", "x'; DROP TABLE users; --")
+	sut.Len(recorder.Header().Get(echo.HeaderXRequestID), 32)
+}
+
+func (sut *RequestIdMiddlewareTestSuite) Test04AddsTheRequestIdToErrorBodies() {
+	sut.T().Log("Test04AddsTheRequestIdToErrorBodies")
+	recorder := sut.serve("/error", "request-1")
+	sut.Equal(recorder.Code, http.StatusBadRequest)
+	sut.JSONEq(recorder.Body.String(), `{"message":"title is required","request_id":"request-1"}`)
+
+	recorder = sut.serve("/missing", "request-2")
+	sut.Equal(recorder.Code, http.StatusNotFound)
+	var body map[string]string
+	sut.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &body))
+	sut.Equal(body["request_id"], "request-2")
+}
+
+func (sut *RequestIdMiddlewareTestSuite) Test05LeavesOtherErrorBodiesAlone() {
+	sut.T().Log("Test05LeavesOtherErrorBodiesAlone")
+	recorder := sut.serve("/list-error", "request-1")
+	sut.JSONEq(recorder.Body.String(), `["title is required"]`)
+}
+
+func (sut *RequestIdMiddlewareTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *RequestIdMiddlewareTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *RequestIdMiddlewareTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
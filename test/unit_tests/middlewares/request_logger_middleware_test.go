@@ -0,0 +1,112 @@
+package middlewares_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	"todo-list-api/middlewares"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+type RequestLoggerMiddlewareTestSuite struct {
+	suite.Suite
+	e      *echo.Echo
+	output *bytes.Buffer
+}
+
+func TestRequestLoggerMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(RequestLoggerMiddlewareTestSuite))
+}
+
+func (sut *RequestLoggerMiddlewareTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+}
+
+func (sut *RequestLoggerMiddlewareTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.output = new(bytes.Buffer)
+	sut.e = echo.New()
+	sut.e.Use(middlewares.SetRequestId)
+	sut.e.Use(middlewares.LogRequests(helpers.NewLogger(config.LogConfig{Level: "debug"}, sut.output)))
+	sut.e.GET("/todos/:id", func(c echo.Context) error {
+		ctx := helpers.ContextWithPrincipal(c.Request().Context(), helpers.Principal{Id: 7})
+		c.SetRequest(c.Request().WithContext(ctx))
+		helpers.LoggerFromContext(ctx).Debug("query")
+		return c.JSON(http.StatusOK, helpers.ToResponse("ok"))
+	})
+	sut.e.GET("/broken", func(c echo.Context) error {
+		return c.JSON(http.StatusInternalServerError, helpers.ToResponse("connection refused"))
+	})
+}
+
+func (sut *RequestLoggerMiddlewareTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *RequestLoggerMiddlewareTestSuite) serve(path string) (lines []map[string]interface{}) {
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	request.Header.Set(echo.HeaderXRequestID, "request-1")
+	sut.e.ServeHTTP(httptest.NewRecorder(), request)
+	decoder := json.NewDecoder(sut.output)
+	for decoder.More() {
+		var line map[string]interface{}
+		sut.Require().NoError(decoder.Decode(&line))
+		lines = append(lines, line)
+	}
+	return
+}
+
+func (sut *RequestLoggerMiddlewareTestSuite) Test01LogsTheRequest() {
+	sut.T().Log("Test01LogsTheRequest")
+	lines := sut.serve("/todos/1?token=abc")
+	sut.Require().Len(lines, 2)
+	sut.Equal(lines[0]["msg"], "query")
+	sut.Equal(lines[0]["request_id"], "request-1")
+	sut.Equal(lines[1]["msg"], "request")
+	sut.Equal(lines[1]["level"], "INFO")
+	sut.Equal(lines[1]["request_id"], "request-1")
+	sut.Equal(lines[1]["method"], http.MethodGet)
+	sut.Equal(lines[1]["route"], "/todos/:id")
+	sut.Equal(lines[1]["path"], "/todos/1")
+	sut.Equal(lines[1]["status"], float64(http.StatusOK))
+	sut.Equal(lines[1]["user_id"], float64(7))
+	sut.Greater(lines[1]["bytes"], float64(0))
+	sut.Contains(lines[1], "latency_ms")
+	sut.NotContains(sut.output.String(), "abc")
+}
+
+func (sut *RequestLoggerMiddlewareTestSuite) Test02LogsTheCauseOfServerErrors() {
+	sut.T().Log("Test02LogsTheCauseOfServerErrors")
+	lines := sut.serve("/broken")
+	sut.Require().Len(lines, 1)
+	sut.Equal(lines[0]["level"], "ERROR")
+	sut.Equal(lines[0]["status"], float64(http.StatusInternalServerError))
+	sut.Contains(lines[0]["error"], "connection refused")
+	sut.NotContains(lines[0], "user_id")
+}
+
+func (sut *RequestLoggerMiddlewareTestSuite) Test03LogsUnknownRoutesAsWarnings() {
+	sut.T().Log("Test03LogsUnknownRoutesAsWarnings")
+	lines := sut.serve("/missing")
+	sut.Require().Len(lines, 1)
+	sut.Equal(lines[0]["level"], "WARN")
+	sut.Equal(lines[0]["status"], float64(http.StatusNotFound))
+}
+
+func (sut *RequestLoggerMiddlewareTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *RequestLoggerMiddlewareTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *RequestLoggerMiddlewareTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
@@ -0,0 +1,126 @@
+package middlewares_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-list-api/helpers"
+	"todo-list-api/middlewares"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type TracingMiddlewareTestSuite struct {
+	suite.Suite
+	e            *echo.Echo
+	spanRecorder *tracetest.SpanRecorder
+}
+
+func TestTracingMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(TracingMiddlewareTestSuite))
+}
+
+func (sut *TracingMiddlewareTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
+
+func (sut *TracingMiddlewareTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.spanRecorder = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sut.spanRecorder)))
+	sut.e = echo.New()
+	sut.e.Use(middlewares.SetRequestId)
+	sut.e.Use(middlewares.Trace)
+	sut.e.GET("/todos/:id", func(c echo.Context) error {
+		ctx, span := helpers.StartSpan(c.Request().Context(), "TodoService.FindById")
+		helpers.EndSpan(span, http.StatusOK, nil)
+		c.SetRequest(c.Request().WithContext(helpers.ContextWithPrincipal(ctx, helpers.Principal{Id: 7})))
+		return c.JSON(http.StatusOK, helpers.ToResponse("ok"))
+	})
+	sut.e.DELETE("/todos/:id", func(c echo.Context) error {
+		return errors.New("connection refused")
+	})
+}
+
+func (sut *TracingMiddlewareTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *TracingMiddlewareTestSuite) serve(method string, path string, traceparent string) {
+	request := httptest.NewRequest(method, path, nil)
+	request.Header.Set(echo.HeaderXRequestID, "request-1")
+	if traceparent != "" {
+		request.Header.Set("traceparent", traceparent)
+	}
+	sut.e.ServeHTTP(httptest.NewRecorder(), request)
+}
+
+func (sut *TracingMiddlewareTestSuite) Test01SpanPerRequest() {
+	sut.T().Log("Test01SpanPerRequest")
+	sut.serve(http.MethodGet, "/todos/1", "")
+	spans := sut.spanRecorder.Ended()
+	sut.Require().Len(spans, 2)
+	serviceSpan, serverSpan := spans[0], spans[1]
+	sut.Equal(serverSpan.Name(), "GET /todos/:id")
+	sut.Equal(serverSpan.SpanKind(), trace.SpanKindServer)
+	sut.Contains(serverSpan.Attributes(), attribute.String("http.route", "/todos/:id"))
+	sut.Contains(serverSpan.Attributes(), attribute.String("url.path", "/todos/1"))
+	sut.Contains(serverSpan.Attributes(), attribute.Int("http.response.status_code", http.StatusOK))
+	sut.Contains(serverSpan.Attributes(), attribute.String("request_id", "request-1"))
+	sut.Contains(serverSpan.Attributes(), attribute.Int("user_id", 7))
+	sut.False(serverSpan.Parent().IsValid())
+	sut.Equal(serviceSpan.Name(), "TodoService.FindById")
+	sut.Equal(serviceSpan.Parent().SpanID(), serverSpan.SpanContext().SpanID())
+}
+
+func (sut *TracingMiddlewareTestSuite) Test02ContinuesTheTraceOfTheCaller() {
+	sut.T().Log("Test02ContinuesTheTraceOfTheCaller")
+	sut.serve(http.MethodGet, "/todos/1", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	spans := sut.spanRecorder.Ended()
+	sut.Require().Len(spans, 2)
+	sut.Equal(spans[1].SpanContext().TraceID().String(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	sut.Equal(spans[1].Parent().SpanID().String(), "00f067aa0ba902b7")
+	sut.True(spans[1].Parent().IsRemote())
+}
+
+func (sut *TracingMiddlewareTestSuite) Test03ServerErrorFailsTheSpan() {
+	sut.T().Log("Test03ServerErrorFailsTheSpan")
+	sut.serve(http.MethodDelete, "/todos/1", "")
+	spans := sut.spanRecorder.Ended()
+	sut.Require().Len(spans, 1)
+	sut.Contains(spans[0].Attributes(), attribute.Int("http.response.status_code", http.StatusInternalServerError))
+	sut.Equal(spans[0].Status().Code, codes.Error)
+}
+
+func (sut *TracingMiddlewareTestSuite) Test04UnmatchedPathsAreNamedAfterTheMethod() {
+	sut.T().Log("Test04UnmatchedPathsAreNamedAfterTheMethod")
+	sut.serve(http.MethodGet, "/wp-admin", "")
+	spans := sut.spanRecorder.Ended()
+	sut.Require().Len(spans, 1)
+	sut.Equal(spans[0].Name(), http.MethodGet)
+}
+
+func (sut *TracingMiddlewareTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *TracingMiddlewareTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+	otel.SetTracerProvider(noop.NewTracerProvider())
+}
+
+func (sut *TracingMiddlewareTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+}
@@ -0,0 +1,39 @@
+package mockrepositories
+
+import (
+	"context"
+
+	modelentities "todo-list-api/models/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/mock"
+)
+
+type LoginAttemptRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (repository *LoginAttemptRepositoryMock) FindByAttemptKey(tx pgx.Tx, ctx context.Context, attemptKey string) (loginAttempt modelentities.LoginAttempt, err error) {
+	arguments := repository.Mock.Called(tx, ctx, attemptKey)
+	return arguments.Get(0).(modelentities.LoginAttempt), arguments.Error(1)
+}
+
+func (repository *LoginAttemptRepositoryMock) IncrementFailedCount(tx pgx.Tx, ctx context.Context, attemptKey string, attemptWindow int) (failedCount int, err error) {
+	arguments := repository.Mock.Called(tx, ctx, attemptKey, attemptWindow)
+	return arguments.Get(0).(int), arguments.Error(1)
+}
+
+func (repository *LoginAttemptRepositoryMock) UpdateLockedUntil(tx pgx.Tx, ctx context.Context, attemptKey string, lockSeconds int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, attemptKey, lockSeconds)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *LoginAttemptRepositoryMock) DeleteByAttemptKey(tx pgx.Tx, ctx context.Context, attemptKey string) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, attemptKey)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *LoginAttemptRepositoryMock) CreateLockoutEvent(tx pgx.Tx, ctx context.Context, loginLockoutEvent modelentities.LoginLockoutEvent) (lastInsertedId int, err error) {
+	arguments := repository.Mock.Called(tx, ctx, loginLockoutEvent)
+	return arguments.Get(0).(int), arguments.Error(1)
+}
@@ -0,0 +1,35 @@
+package mockrepositories
+
+import (
+	"context"
+	"time"
+
+	modelentities "todo-list-api/models/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/mock"
+)
+
+type MfaChallengeRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (repository *MfaChallengeRepositoryMock) FindOrCreate(tx pgx.Tx, ctx context.Context, jti string, userId int, expiresAt time.Time) (mfaChallenge modelentities.MfaChallenge, err error) {
+	arguments := repository.Mock.Called(tx, ctx, jti, userId, expiresAt)
+	return arguments.Get(0).(modelentities.MfaChallenge), arguments.Error(1)
+}
+
+func (repository *MfaChallengeRepositoryMock) IncrementFailedCount(tx pgx.Tx, ctx context.Context, jti string) (failedCount int, err error) {
+	arguments := repository.Mock.Called(tx, ctx, jti)
+	return arguments.Get(0).(int), arguments.Error(1)
+}
+
+func (repository *MfaChallengeRepositoryMock) UpdateUsedAt(tx pgx.Tx, ctx context.Context, jti string) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, jti)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *MfaChallengeRepositoryMock) DeleteExpiredByUserId(tx pgx.Tx, ctx context.Context, userId int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, userId)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
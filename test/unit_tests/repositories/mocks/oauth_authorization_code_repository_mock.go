@@ -0,0 +1,29 @@
+package mockrepositories
+
+import (
+	"context"
+
+	modelentities "todo-list-api/models/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/mock"
+)
+
+type OauthAuthorizationCodeRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (repository *OauthAuthorizationCodeRepositoryMock) Create(tx pgx.Tx, ctx context.Context, oauthAuthorizationCode modelentities.OauthAuthorizationCode) (lastInsertedId int, err error) {
+	arguments := repository.Mock.Called(tx, ctx, oauthAuthorizationCode)
+	return arguments.Get(0).(int), arguments.Error(1)
+}
+
+func (repository *OauthAuthorizationCodeRepositoryMock) FindByCodeHash(tx pgx.Tx, ctx context.Context, codeHash string) (oauthAuthorizationCode modelentities.OauthAuthorizationCode, err error) {
+	arguments := repository.Mock.Called(tx, ctx, codeHash)
+	return arguments.Get(0).(modelentities.OauthAuthorizationCode), arguments.Error(1)
+}
+
+func (repository *OauthAuthorizationCodeRepositoryMock) UpdateUsedAt(tx pgx.Tx, ctx context.Context, grantId int, id int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, grantId, id)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
@@ -0,0 +1,35 @@
+package mockrepositories
+
+import (
+	"context"
+
+	modelentities "todo-list-api/models/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/mock"
+)
+
+type OauthClientRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (repository *OauthClientRepositoryMock) Create(tx pgx.Tx, ctx context.Context, oauthClient modelentities.OauthClient) (lastInsertedId int, err error) {
+	arguments := repository.Mock.Called(tx, ctx, oauthClient)
+	return arguments.Get(0).(int), arguments.Error(1)
+}
+
+func (repository *OauthClientRepositoryMock) FindByClientId(tx pgx.Tx, ctx context.Context, clientId string) (oauthClient modelentities.OauthClient, err error) {
+	arguments := repository.Mock.Called(tx, ctx, clientId)
+	return arguments.Get(0).(modelentities.OauthClient), arguments.Error(1)
+}
+
+func (repository *OauthClientRepositoryMock) FindByUserId(pool *pgxpool.Pool, ctx context.Context, userId int) (oauthClients []modelentities.OauthClient, err error) {
+	arguments := repository.Mock.Called(pool, ctx, userId)
+	return arguments.Get(0).([]modelentities.OauthClient), arguments.Error(1)
+}
+
+func (repository *OauthClientRepositoryMock) DeleteByClientIdAndUserId(tx pgx.Tx, ctx context.Context, clientId string, userId int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, clientId, userId)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
@@ -0,0 +1,46 @@
+package mockrepositories
+
+import (
+	"context"
+
+	modelentities "todo-list-api/models/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/mock"
+)
+
+type OauthGrantRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (repository *OauthGrantRepositoryMock) Create(tx pgx.Tx, ctx context.Context, oauthGrant modelentities.OauthGrant) (lastInsertedId int, err error) {
+	arguments := repository.Mock.Called(tx, ctx, oauthGrant)
+	return arguments.Get(0).(int), arguments.Error(1)
+}
+
+func (repository *OauthGrantRepositoryMock) FindByRefreshTokenHash(tx pgx.Tx, ctx context.Context, refreshTokenHash string) (oauthGrant modelentities.OauthGrant, err error) {
+	arguments := repository.Mock.Called(tx, ctx, refreshTokenHash)
+	return arguments.Get(0).(modelentities.OauthGrant), arguments.Error(1)
+}
+
+func (repository *OauthGrantRepositoryMock) FindActiveById(pool *pgxpool.Pool, ctx context.Context, id int) (oauthGrant modelentities.OauthGrant, err error) {
+	arguments := repository.Mock.Called(pool, ctx, id)
+	return arguments.Get(0).(modelentities.OauthGrant), arguments.Error(1)
+}
+
+func (repository *OauthGrantRepositoryMock) UpdateRefreshToken(tx pgx.Tx, ctx context.Context, refreshTokenHash string, refreshTokenExpiresAt pgtype.Timestamptz, id int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, refreshTokenHash, refreshTokenExpiresAt, id)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *OauthGrantRepositoryMock) Revoke(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, id)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *OauthGrantRepositoryMock) RevokeByUserId(tx pgx.Tx, ctx context.Context, userId int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, userId)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
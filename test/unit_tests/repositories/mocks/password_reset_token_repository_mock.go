@@ -0,0 +1,29 @@
+package mockrepositories
+
+import (
+	"context"
+
+	modelentities "todo-list-api/models/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/mock"
+)
+
+type PasswordResetTokenRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (repository *PasswordResetTokenRepositoryMock) Create(tx pgx.Tx, ctx context.Context, passwordResetToken modelentities.PasswordResetToken) (lastInsertedId int, err error) {
+	arguments := repository.Mock.Called(tx, ctx, passwordResetToken)
+	return arguments.Get(0).(int), arguments.Error(1)
+}
+
+func (repository *PasswordResetTokenRepositoryMock) FindByTokenHash(tx pgx.Tx, ctx context.Context, tokenHash string) (passwordResetToken modelentities.PasswordResetToken, err error) {
+	arguments := repository.Mock.Called(tx, ctx, tokenHash)
+	return arguments.Get(0).(modelentities.PasswordResetToken), arguments.Error(1)
+}
+
+func (repository *PasswordResetTokenRepositoryMock) UpdateUsedAtByUserId(tx pgx.Tx, ctx context.Context, userId int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, userId)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
@@ -0,0 +1,23 @@
+package mockrepositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/mock"
+)
+
+type RateLimitRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (repository *RateLimitRepositoryMock) TakeToken(pool *pgxpool.Pool, ctx context.Context, bucketKey string, limit int, windowSeconds float64, now time.Time) (remainingTokens float64, allowed bool, err error) {
+	arguments := repository.Mock.Called(pool, ctx, bucketKey, limit, windowSeconds, now)
+	return arguments.Get(0).(float64), arguments.Bool(1), arguments.Error(2)
+}
+
+func (repository *RateLimitRepositoryMock) DeleteExpired(pool *pgxpool.Pool, ctx context.Context, now time.Time) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(pool, ctx, now)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
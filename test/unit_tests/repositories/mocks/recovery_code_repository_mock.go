@@ -0,0 +1,29 @@
+package mockrepositories
+
+import (
+	"context"
+
+	modelentities "todo-list-api/models/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/mock"
+)
+
+type RecoveryCodeRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (repository *RecoveryCodeRepositoryMock) Create(tx pgx.Tx, ctx context.Context, recoveryCode modelentities.RecoveryCode) (lastInsertedId int, err error) {
+	arguments := repository.Mock.Called(tx, ctx, recoveryCode)
+	return arguments.Get(0).(int), arguments.Error(1)
+}
+
+func (repository *RecoveryCodeRepositoryMock) DeleteByUserId(tx pgx.Tx, ctx context.Context, userId int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, userId)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *RecoveryCodeRepositoryMock) UpdateUsedAtByUserIdAndCodeHash(tx pgx.Tx, ctx context.Context, userId int, codeHash string) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, userId, codeHash)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
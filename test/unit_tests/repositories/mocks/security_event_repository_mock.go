@@ -0,0 +1,30 @@
+package mockrepositories
+
+import (
+	"context"
+
+	modelentities "todo-list-api/models/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/mock"
+)
+
+type SecurityEventRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (repository *SecurityEventRepositoryMock) Create(tx pgx.Tx, ctx context.Context, securityEvent modelentities.SecurityEvent) (lastInsertedId int, err error) {
+	arguments := repository.Mock.Called(tx, ctx, securityEvent)
+	return arguments.Get(0).(int), arguments.Error(1)
+}
+
+func (repository *SecurityEventRepositoryMock) FindByFilterWithPagination(pool *pgxpool.Pool, ctx context.Context, securityEventFilter modelentities.SecurityEventFilter, offset int, limit int) (securityEvents []modelentities.SecurityEvent, err error) {
+	arguments := repository.Mock.Called(pool, ctx, securityEventFilter, offset, limit)
+	return arguments.Get(0).([]modelentities.SecurityEvent), arguments.Error(1)
+}
+
+func (repository *SecurityEventRepositoryMock) CountByFilter(pool *pgxpool.Pool, ctx context.Context, securityEventFilter modelentities.SecurityEventFilter) (numberOfSecurityEvents int, err error) {
+	arguments := repository.Mock.Called(pool, ctx, securityEventFilter)
+	return arguments.Get(0).(int), arguments.Error(1)
+}
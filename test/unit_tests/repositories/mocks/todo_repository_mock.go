@@ -43,3 +43,18 @@ func (repository *TodoRepositoryMock) Count(pool *pgxpool.Pool, ctx context.Cont
 	arguments := repository.Mock.Called(pool, ctx)
 	return arguments.Get(0).(int), arguments.Error(1)
 }
+
+func (repository *TodoRepositoryMock) FindByUserId(tx pgx.Tx, ctx context.Context, userId int) (todos []modelentities.Todo, err error) {
+	arguments := repository.Mock.Called(tx, ctx, userId)
+	return arguments.Get(0).([]modelentities.Todo), arguments.Error(1)
+}
+
+func (repository *TodoRepositoryMock) CountByUserIds(pool *pgxpool.Pool, ctx context.Context, userIds []int) (numberOfTodos map[int]int, err error) {
+	arguments := repository.Mock.Called(pool, ctx, userIds)
+	return arguments.Get(0).(map[int]int), arguments.Error(1)
+}
+
+func (repository *TodoRepositoryMock) FindStatistics(pool *pgxpool.Pool, ctx context.Context) (todoStatistics modelentities.TodoStatistics, err error) {
+	arguments := repository.Mock.Called(pool, ctx)
+	return arguments.Get(0).(modelentities.TodoStatistics), arguments.Error(1)
+}
@@ -0,0 +1,29 @@
+package mockrepositories
+
+import (
+	"context"
+
+	modelentities "todo-list-api/models/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/mock"
+)
+
+type UserIdentityRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (repository *UserIdentityRepositoryMock) Create(tx pgx.Tx, ctx context.Context, userIdentity modelentities.UserIdentity) (lastInsertedId int, err error) {
+	arguments := repository.Mock.Called(tx, ctx, userIdentity)
+	return arguments.Get(0).(int), arguments.Error(1)
+}
+
+func (repository *UserIdentityRepositoryMock) FindByProviderAndSubject(tx pgx.Tx, ctx context.Context, provider string, subject string) (userIdentity modelentities.UserIdentity, err error) {
+	arguments := repository.Mock.Called(tx, ctx, provider, subject)
+	return arguments.Get(0).(modelentities.UserIdentity), arguments.Error(1)
+}
+
+func (repository *UserIdentityRepositoryMock) UpdateLastLoginAt(tx pgx.Tx, ctx context.Context, email string, id int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, email, id)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
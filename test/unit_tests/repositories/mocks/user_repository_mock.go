@@ -6,6 +6,7 @@ import (
 	modelentities "todo-list-api/models/entities"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/mock"
 )
@@ -33,3 +34,98 @@ func (repository *UserRepositoryMock) FindByRefreshToken(pool *pgxpool.Pool, ctx
 	arguments := repository.Mock.Called(pool, ctx, refreshToken)
 	return arguments.Get(0).(modelentities.User), arguments.Error(1)
 }
+
+func (repository *UserRepositoryMock) UpdatePassword(tx pgx.Tx, ctx context.Context, password string, id int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, password, id)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *UserRepositoryMock) FindById(tx pgx.Tx, ctx context.Context, id int) (user modelentities.User, err error) {
+	arguments := repository.Mock.Called(tx, ctx, id)
+	return arguments.Get(0).(modelentities.User), arguments.Error(1)
+}
+
+func (repository *UserRepositoryMock) UpdateEmailVerifiedAt(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, id)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *UserRepositoryMock) UpdateEmailVerificationSentAt(tx pgx.Tx, ctx context.Context, id int, resendInterval int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, id, resendInterval)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *UserRepositoryMock) UpdateTotpSecret(tx pgx.Tx, ctx context.Context, totpSecret string, id int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, totpSecret, id)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *UserRepositoryMock) EnableTotp(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, id)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *UserRepositoryMock) DisableTotp(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, id)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *UserRepositoryMock) UpdateTotpLastUsedStep(tx pgx.Tx, ctx context.Context, step int64, id int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, step, id)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *UserRepositoryMock) UpdateName(tx pgx.Tx, ctx context.Context, name string, id int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, name, id)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *UserRepositoryMock) UpdateEmail(tx pgx.Tx, ctx context.Context, email string, id int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, email, id)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *UserRepositoryMock) ScheduleDeletion(tx pgx.Tx, ctx context.Context, gracePeriod int, id int) (deletionScheduledAt pgtype.Timestamptz, err error) {
+	arguments := repository.Mock.Called(tx, ctx, gracePeriod, id)
+	return arguments.Get(0).(pgtype.Timestamptz), arguments.Error(1)
+}
+
+func (repository *UserRepositoryMock) CancelDeletion(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, id)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *UserRepositoryMock) DeleteScheduled(tx pgx.Tx, ctx context.Context) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *UserRepositoryMock) FindSessionById(pool *pgxpool.Pool, ctx context.Context, id int) (user modelentities.User, err error) {
+	arguments := repository.Mock.Called(pool, ctx, id)
+	return arguments.Get(0).(modelentities.User), arguments.Error(1)
+}
+
+func (repository *UserRepositoryMock) FindBySearchWithPagination(pool *pgxpool.Pool, ctx context.Context, search string, offset int, limit int) (users []modelentities.User, err error) {
+	arguments := repository.Mock.Called(pool, ctx, search, offset, limit)
+	return arguments.Get(0).([]modelentities.User), arguments.Error(1)
+}
+
+func (repository *UserRepositoryMock) CountBySearch(pool *pgxpool.Pool, ctx context.Context, search string) (numberOfUsers int, err error) {
+	arguments := repository.Mock.Called(pool, ctx, search)
+	return arguments.Get(0).(int), arguments.Error(1)
+}
+
+func (repository *UserRepositoryMock) Disable(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, id)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *UserRepositoryMock) Enable(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, id)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
+
+func (repository *UserRepositoryMock) RevokeSessions(tx pgx.Tx, ctx context.Context, id int) (rowsAffected int64, err error) {
+	arguments := repository.Mock.Called(tx, ctx, id)
+	return arguments.Get(0).(int64), arguments.Error(1)
+}
@@ -0,0 +1,219 @@
+package services_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	modelrequests "todo-list-api/models/requests"
+	modelresponses "todo-list-api/models/responses"
+	"todo-list-api/services"
+	mockhelpers "todo-list-api/test/unit_tests/helpers/mocks"
+	mockrepositories "todo-list-api/test/unit_tests/repositories/mocks"
+	mockutils "todo-list-api/test/unit_tests/utils/mocks"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AccountServiceTestSuite struct {
+	suite.Suite
+	config                      config.Config
+	ctx                         context.Context
+	options                     pgx.TxOptions
+	exportOptions               pgx.TxOptions
+	errInternalServer           error
+	user                        modelentities.User
+	deleteAccountRequest        modelrequests.DeleteAccountRequest
+	postgresUtilMock            *mockutils.PostgresUtilMock
+	validate                    *validator.Validate
+	userRepositoryMock          *mockrepositories.UserRepositoryMock
+	todoRepositoryMock          *mockrepositories.TodoRepositoryMock
+	oauthGrantRepositoryMock    *mockrepositories.OauthGrantRepositoryMock
+	securityEventRepositoryMock *mockrepositories.SecurityEventRepositoryMock
+	passwordHasherMock          *mockhelpers.PasswordHasherMock
+	pgxTxMock                   *mockutils.PgxTxMock
+	accountService              services.AccountService
+}
+
+func TestAccountTestSuite(t *testing.T) {
+	suite.Run(t, new(AccountServiceTestSuite))
+}
+
+func (sut *AccountServiceTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.ctx = helpers.ContextWithPrincipal(context.Background(), helpers.Principal{Id: 1, Name: "John Doe", Email: "john@doe.com", Role: helpers.RoleUser})
+	sut.exportOptions = pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+	sut.errInternalServer = errors.New("internal server error")
+	sut.config = config.Default()
+}
+
+func (sut *AccountServiceTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.user = modelentities.User{
+		Id:       pgtype.Int4{Valid: true, Int32: 1},
+		Name:     pgtype.Text{Valid: true, String: "John Doe"},
+		Email:    pgtype.Text{Valid: true, String: "john@doe.com"},
+		Password: pgtype.Text{Valid: true, String: "passwordHash"},
+	}
+	sut.deleteAccountRequest = modelrequests.DeleteAccountRequest{
+		Password: "password",
+	}
+	sut.postgresUtilMock = new(mockutils.PostgresUtilMock)
+	sut.validate = validator.New()
+	sut.userRepositoryMock = new(mockrepositories.UserRepositoryMock)
+	sut.todoRepositoryMock = new(mockrepositories.TodoRepositoryMock)
+	sut.oauthGrantRepositoryMock = new(mockrepositories.OauthGrantRepositoryMock)
+	sut.securityEventRepositoryMock = new(mockrepositories.SecurityEventRepositoryMock)
+	sut.securityEventRepositoryMock.Mock.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(1, nil)
+	sut.passwordHasherMock = new(mockhelpers.PasswordHasherMock)
+	sut.pgxTxMock = new(mockutils.PgxTxMock)
+	sut.accountService = services.NewAccountService(sut.postgresUtilMock, sut.validate, sut.userRepositoryMock, sut.todoRepositoryMock, sut.oauthGrantRepositoryMock, sut.securityEventRepositoryMock, sut.passwordHasherMock, sut.config)
+}
+
+func (sut *AccountServiceTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *AccountServiceTestSuite) Test01DeleteValidationError() {
+	sut.T().Log("Test01DeleteValidationError")
+	httpCode, response := sut.accountService.Delete(sut.ctx, modelrequests.DeleteAccountRequest{})
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *AccountServiceTestSuite) Test02DeleteWrongPassword() {
+	sut.T().Log("Test02DeleteWrongPassword")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", "passwordHash", "password").Return(false, helpers.ErrPasswordMismatch)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, helpers.ErrPasswordMismatch).Return(nil)
+	httpCode, response := sut.accountService.Delete(sut.ctx, sut.deleteAccountRequest)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(response, helpers.ToResponse("wrong password"))
+	sut.userRepositoryMock.Mock.AssertNotCalled(sut.T(), "ScheduleDeletion", sut.pgxTxMock, sut.ctx, 30, 1)
+}
+
+func (sut *AccountServiceTestSuite) Test03DeleteSuccess() {
+	sut.T().Log("Test03DeleteSuccess")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", "passwordHash", "password").Return(false, nil)
+	deletionScheduledAt := time.Now().Add(30 * 24 * time.Hour)
+	sut.userRepositoryMock.Mock.On("ScheduleDeletion", sut.pgxTxMock, sut.ctx, 30, 1).Return(pgtype.Timestamptz{Valid: true, Time: deletionScheduledAt}, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("RevokeSessions", sut.pgxTxMock, sut.ctx, 1).Return(rowsAffected, nil)
+	sut.oauthGrantRepositoryMock.Mock.On("RevokeByUserId", sut.pgxTxMock, sut.ctx, 1).Return(int64(2), nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, response := sut.accountService.Delete(sut.ctx, sut.deleteAccountRequest)
+	sut.Equal(httpCode, http.StatusAccepted)
+	sut.Equal(response.(modelresponses.DeleteAccountResponse).DeletionScheduledAt, deletionScheduledAt)
+	sut.userRepositoryMock.Mock.AssertCalled(sut.T(), "RevokeSessions", sut.pgxTxMock, sut.ctx, 1)
+	sut.oauthGrantRepositoryMock.Mock.AssertCalled(sut.T(), "RevokeByUserId", sut.pgxTxMock, sut.ctx, 1)
+	sut.securityEventRepositoryMock.Mock.AssertCalled(sut.T(), "Create", sut.pgxTxMock, sut.ctx, mock.MatchedBy(func(securityEvent modelentities.SecurityEvent) bool {
+		return securityEvent.EventType.String == helpers.SecurityEventDeletionScheduled && securityEvent.UserId.Int32 == 1 && securityEvent.Detail.String == "user:1"
+	}))
+}
+
+func (sut *AccountServiceTestSuite) Test04ExportFindByUserIdError() {
+	sut.T().Log("Test04ExportFindByUserIdError")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.exportOptions).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.todoRepositoryMock.Mock.On("FindByUserId", sut.pgxTxMock, sut.ctx, 1).Return([]modelentities.Todo{}, sut.errInternalServer)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer).Return(nil)
+	httpCode, archive, response := sut.accountService.Export(sut.ctx)
+	sut.Equal(httpCode, http.StatusInternalServerError)
+	sut.Nil(archive)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *AccountServiceTestSuite) Test05ExportSuccess() {
+	sut.T().Log("Test05ExportSuccess")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.exportOptions).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	todos := []modelentities.Todo{
+		{
+			Id:          pgtype.Int4{Valid: true, Int32: 1},
+			UserId:      pgtype.Int4{Valid: true, Int32: 1},
+			Title:       pgtype.Text{Valid: true, String: "Buy groceries"},
+			Description: pgtype.Text{Valid: true, String: "Buy milk, eggs, and bread"},
+		},
+	}
+	sut.todoRepositoryMock.Mock.On("FindByUserId", sut.pgxTxMock, sut.ctx, 1).Return(todos, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, archive, _ := sut.accountService.Export(sut.ctx)
+	sut.Equal(httpCode, http.StatusOK)
+
+	zipReader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	sut.Nil(err)
+	files := map[string][]byte{}
+	for _, file := range zipReader.File {
+		fileReader, err := file.Open()
+		sut.Nil(err)
+		content, err := io.ReadAll(fileReader)
+		sut.Nil(err)
+		files[file.Name] = content
+	}
+	sut.Len(files, 2)
+
+	var exportProfileResponse modelresponses.ExportProfileResponse
+	sut.Nil(json.Unmarshal(files["profile.json"], &exportProfileResponse))
+	sut.Equal(exportProfileResponse.Email, "john@doe.com")
+	sut.Nil(exportProfileResponse.EmailVerifiedAt)
+	var todoResponses []modelresponses.TodoResponse
+	sut.Nil(json.Unmarshal(files["todos.json"], &todoResponses))
+	sut.Equal(todoResponses, []modelresponses.TodoResponse{{Id: 1, Title: "Buy groceries", Description: "Buy milk, eggs, and bread"}})
+}
+
+func (sut *AccountServiceTestSuite) Test06PurgeDeleted() {
+	sut.T().Log("Test06PurgeDeleted")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	var rowsAffected int64
+	rowsAffected = 2
+	sut.userRepositoryMock.Mock.On("DeleteScheduled", sut.pgxTxMock, sut.ctx).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	deletedAccounts, err := sut.accountService.PurgeDeleted(sut.ctx)
+	sut.Nil(err)
+	sut.Equal(deletedAccounts, int64(2))
+}
+
+func (sut *AccountServiceTestSuite) Test07DeleteRevokeOauthGrantsErrorRollsBack() {
+	sut.T().Log("Test07DeleteRevokeOauthGrantsErrorRollsBack")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", "passwordHash", "password").Return(false, nil)
+	sut.userRepositoryMock.Mock.On("ScheduleDeletion", sut.pgxTxMock, sut.ctx, 30, 1).Return(pgtype.Timestamptz{Valid: true, Time: time.Now()}, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("RevokeSessions", sut.pgxTxMock, sut.ctx, 1).Return(rowsAffected, nil)
+	sut.oauthGrantRepositoryMock.Mock.On("RevokeByUserId", sut.pgxTxMock, sut.ctx, 1).Return(int64(0), sut.errInternalServer)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer).Return(nil)
+	httpCode, response := sut.accountService.Delete(sut.ctx, sut.deleteAccountRequest)
+	sut.Equal(httpCode, http.StatusInternalServerError)
+	sut.Equal(response, helpers.ToResponse(sut.errInternalServer.Error()))
+	sut.postgresUtilMock.Mock.AssertCalled(sut.T(), "CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer)
+}
+
+func (sut *AccountServiceTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *AccountServiceTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *AccountServiceTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
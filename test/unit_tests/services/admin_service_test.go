@@ -0,0 +1,233 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	modelresponses "todo-list-api/models/responses"
+	"todo-list-api/services"
+	mockrepositories "todo-list-api/test/unit_tests/repositories/mocks"
+	mockutils "todo-list-api/test/unit_tests/utils/mocks"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AdminServiceTestSuite struct {
+	suite.Suite
+	ctx                         context.Context
+	cliCtx                      context.Context
+	options                     pgx.TxOptions
+	pool                        *pgxpool.Pool
+	errInternalServer           error
+	user                        modelentities.User
+	postgresUtilMock            *mockutils.PostgresUtilMock
+	userRepositoryMock          *mockrepositories.UserRepositoryMock
+	todoRepositoryMock          *mockrepositories.TodoRepositoryMock
+	securityEventRepositoryMock *mockrepositories.SecurityEventRepositoryMock
+	pgxTxMock                   *mockutils.PgxTxMock
+	adminService                services.AdminService
+}
+
+func TestAdminTestSuite(t *testing.T) {
+	suite.Run(t, new(AdminServiceTestSuite))
+}
+
+func (sut *AdminServiceTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.ctx = helpers.ContextWithPrincipal(context.Background(), helpers.Principal{Id: 1, Role: helpers.RoleAdmin})
+	sut.cliCtx = helpers.ContextWithPrincipal(context.Background(), helpers.Principal{Name: "cli", Role: helpers.RoleAdmin})
+	sut.pool = &pgxpool.Pool{}
+	sut.errInternalServer = errors.New("internal server error")
+}
+
+func (sut *AdminServiceTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.user = modelentities.User{
+		Id:    pgtype.Int4{Valid: true, Int32: 2},
+		Name:  pgtype.Text{Valid: true, String: "Jane Doe"},
+		Email: pgtype.Text{Valid: true, String: "jane@doe.com"},
+		Role:  pgtype.Text{Valid: true, String: helpers.RoleUser},
+	}
+	sut.postgresUtilMock = new(mockutils.PostgresUtilMock)
+	sut.userRepositoryMock = new(mockrepositories.UserRepositoryMock)
+	sut.todoRepositoryMock = new(mockrepositories.TodoRepositoryMock)
+	sut.securityEventRepositoryMock = new(mockrepositories.SecurityEventRepositoryMock)
+	sut.securityEventRepositoryMock.Mock.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(1, nil)
+	sut.pgxTxMock = new(mockutils.PgxTxMock)
+	sut.adminService = services.NewAdminService(sut.postgresUtilMock, sut.userRepositoryMock, sut.todoRepositoryMock, sut.securityEventRepositoryMock)
+}
+
+func (sut *AdminServiceTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *AdminServiceTestSuite) Test01FindUsersInvalidLimit() {
+	sut.T().Log("Test01FindUsersInvalidLimit")
+	httpCode, response := sut.adminService.FindUsers(sut.ctx, "", 1, 101)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *AdminServiceTestSuite) Test02FindUsersSuccess() {
+	sut.T().Log("Test02FindUsersSuccess")
+	sut.user.DisabledAt = pgtype.Timestamptz{Valid: true, Time: time.Now()}
+	sut.postgresUtilMock.Mock.On("GetPool").Return(sut.pool)
+	sut.userRepositoryMock.Mock.On("FindBySearchWithPagination", sut.pool, sut.ctx, "jane", 10, 10).Return([]modelentities.User{sut.user}, nil)
+	sut.userRepositoryMock.Mock.On("CountBySearch", sut.pool, sut.ctx, "jane").Return(11, nil)
+	sut.todoRepositoryMock.Mock.On("CountByUserIds", sut.pool, sut.ctx, []int{2}).Return(map[int]int{2: 3}, nil)
+	httpCode, response := sut.adminService.FindUsers(sut.ctx, "jane", 2, 10)
+	sut.Equal(httpCode, http.StatusOK)
+	getAdminUserResponse := response.(modelresponses.GetAdminUserResponse)
+	sut.Equal(getAdminUserResponse.Total, 11)
+	sut.Len(getAdminUserResponse.Data, 1)
+	sut.Equal(getAdminUserResponse.Data[0].NumberOfTodos, 3)
+	sut.Equal(*getAdminUserResponse.Data[0].DisabledAt, sut.user.DisabledAt.Time)
+}
+
+func (sut *AdminServiceTestSuite) Test03DisableOwnAccount() {
+	sut.T().Log("Test03DisableOwnAccount")
+	httpCode, response := sut.adminService.Disable(sut.ctx, 1)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(response, helpers.ToResponse("cannot disable your own account"))
+}
+
+func (sut *AdminServiceTestSuite) Test04DisableUserNotFound() {
+	sut.T().Log("Test04DisableUserNotFound")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 2).Return(modelentities.User{}, pgx.ErrNoRows)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, _ := sut.adminService.Disable(sut.ctx, 2)
+	sut.Equal(httpCode, http.StatusNotFound)
+}
+
+func (sut *AdminServiceTestSuite) Test05DisableSuccess() {
+	sut.T().Log("Test05DisableSuccess")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 2).Return(sut.user, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("Disable", sut.pgxTxMock, sut.ctx, 2).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, _ := sut.adminService.Disable(sut.ctx, 2)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.userRepositoryMock.Mock.AssertCalled(sut.T(), "Disable", sut.pgxTxMock, sut.ctx, 2)
+	sut.securityEventRepositoryMock.Mock.AssertCalled(sut.T(), "Create", sut.pgxTxMock, sut.ctx, mock.MatchedBy(func(securityEvent modelentities.SecurityEvent) bool {
+		return securityEvent.EventType.String == helpers.SecurityEventAccountDisabled && securityEvent.UserId.Int32 == 2 && securityEvent.Detail.String == "admin:1"
+	}))
+}
+
+func (sut *AdminServiceTestSuite) Test06EnableNotDisabled() {
+	sut.T().Log("Test06EnableNotDisabled")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 2).Return(sut.user, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, _ := sut.adminService.Enable(sut.ctx, 2)
+	sut.Equal(httpCode, http.StatusConflict)
+	sut.userRepositoryMock.Mock.AssertNotCalled(sut.T(), "Enable", sut.pgxTxMock, sut.ctx, 2)
+}
+
+func (sut *AdminServiceTestSuite) Test07EnableSuccess() {
+	sut.T().Log("Test07EnableSuccess")
+	sut.user.DisabledAt = pgtype.Timestamptz{Valid: true, Time: time.Now()}
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 2).Return(sut.user, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("Enable", sut.pgxTxMock, sut.ctx, 2).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, _ := sut.adminService.Enable(sut.ctx, 2)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.securityEventRepositoryMock.Mock.AssertCalled(sut.T(), "Create", sut.pgxTxMock, sut.ctx, mock.MatchedBy(func(securityEvent modelentities.SecurityEvent) bool {
+		return securityEvent.EventType.String == helpers.SecurityEventAccountEnabled && securityEvent.UserId.Int32 == 2 && securityEvent.Detail.String == "admin:1"
+	}))
+}
+
+func (sut *AdminServiceTestSuite) Test08LogoutUserNotFound() {
+	sut.T().Log("Test08LogoutUserNotFound")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	var rowsAffected int64
+	sut.userRepositoryMock.Mock.On("RevokeSessions", sut.pgxTxMock, sut.ctx, 2).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, _ := sut.adminService.Logout(sut.ctx, 2)
+	sut.Equal(httpCode, http.StatusNotFound)
+	sut.securityEventRepositoryMock.Mock.AssertNotCalled(sut.T(), "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (sut *AdminServiceTestSuite) Test09LogoutSuccess() {
+	sut.T().Log("Test09LogoutSuccess")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("RevokeSessions", sut.pgxTxMock, sut.ctx, 2).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, _ := sut.adminService.Logout(sut.ctx, 2)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.securityEventRepositoryMock.Mock.AssertCalled(sut.T(), "Create", sut.pgxTxMock, sut.ctx, mock.MatchedBy(func(securityEvent modelentities.SecurityEvent) bool {
+		return securityEvent.EventType.String == helpers.SecurityEventSessionsRevoked && securityEvent.UserId.Int32 == 2 && securityEvent.Detail.String == "admin:1"
+	}))
+}
+
+func (sut *AdminServiceTestSuite) Test10TodoStatistics() {
+	sut.T().Log("Test10TodoStatistics")
+	sut.postgresUtilMock.Mock.On("GetPool").Return(sut.pool)
+	todoStatistics := modelentities.TodoStatistics{NumberOfUsers: 4, NumberOfDisabledUsers: 1, NumberOfUsersWithTodos: 2, NumberOfTodos: 10}
+	sut.todoRepositoryMock.Mock.On("FindStatistics", sut.pool, sut.ctx).Return(todoStatistics, nil)
+	httpCode, response := sut.adminService.TodoStatistics(sut.ctx)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(response, modelresponses.TodoStatisticsResponse{
+		NumberOfUsers:          4,
+		NumberOfDisabledUsers:  1,
+		NumberOfUsersWithTodos: 2,
+		NumberOfTodos:          10,
+		AverageTodosPerUser:    2.5,
+	})
+}
+
+func (sut *AdminServiceTestSuite) Test11DisableFromCli() {
+	sut.T().Log("Test11DisableFromCli")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.cliCtx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.cliCtx, 2).Return(sut.user, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("Disable", sut.pgxTxMock, sut.cliCtx, 2).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.cliCtx, nil).Return(nil)
+	httpCode, _ := sut.adminService.Disable(sut.cliCtx, 2)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.securityEventRepositoryMock.Mock.AssertCalled(sut.T(), "Create", sut.pgxTxMock, sut.cliCtx, mock.MatchedBy(func(securityEvent modelentities.SecurityEvent) bool {
+		return securityEvent.EventType.String == helpers.SecurityEventAccountDisabled && securityEvent.UserId.Int32 == 2 && securityEvent.Detail.String == "cli"
+	}))
+}
+
+func (sut *AdminServiceTestSuite) Test12LogoutSecurityEventErrorRollsBack() {
+	sut.T().Log("Test12LogoutSecurityEventErrorRollsBack")
+	sut.securityEventRepositoryMock.Mock.ExpectedCalls = nil
+	sut.securityEventRepositoryMock.Mock.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(0, sut.errInternalServer)
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("RevokeSessions", sut.pgxTxMock, sut.ctx, 2).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer).Return(nil)
+	httpCode, _ := sut.adminService.Logout(sut.ctx, 2)
+	sut.Equal(httpCode, http.StatusInternalServerError)
+	sut.postgresUtilMock.Mock.AssertCalled(sut.T(), "CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer)
+}
+
+func (sut *AdminServiceTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *AdminServiceTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *AdminServiceTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
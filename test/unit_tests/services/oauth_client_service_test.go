@@ -0,0 +1,139 @@
+package services_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	modelrequests "todo-list-api/models/requests"
+	modelresponses "todo-list-api/models/responses"
+	"todo-list-api/services"
+	mockrepositories "todo-list-api/test/unit_tests/repositories/mocks"
+	mockutils "todo-list-api/test/unit_tests/utils/mocks"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type OauthClientServiceTestSuite struct {
+	suite.Suite
+	ctx                       context.Context
+	options                   pgx.TxOptions
+	validate                  *validator.Validate
+	createOauthClientRequest  modelrequests.CreateOauthClientRequest
+	postgresUtilMock          *mockutils.PostgresUtilMock
+	oauthClientRepositoryMock *mockrepositories.OauthClientRepositoryMock
+	pgxTxMock                 *mockutils.PgxTxMock
+	oauthClientService        services.OauthClientService
+}
+
+func TestOauthClientTestSuite(t *testing.T) {
+	suite.Run(t, new(OauthClientServiceTestSuite))
+}
+
+func (sut *OauthClientServiceTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.ctx = helpers.ContextWithPrincipal(context.Background(), helpers.Principal{Id: 1})
+	sut.validate = validator.New()
+}
+
+func (sut *OauthClientServiceTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.createOauthClientRequest = modelrequests.CreateOauthClientRequest{
+		Name:         "Calendar",
+		RedirectUris: []string{"https://calendar.example.com/callback"},
+		Scopes:       []string{"todos:read", "todos:read"},
+		Confidential: true,
+	}
+	sut.postgresUtilMock = new(mockutils.PostgresUtilMock)
+	sut.oauthClientRepositoryMock = new(mockrepositories.OauthClientRepositoryMock)
+	sut.pgxTxMock = new(mockutils.PgxTxMock)
+	sut.oauthClientService = services.NewOauthClientService(sut.postgresUtilMock, sut.validate, sut.oauthClientRepositoryMock)
+}
+
+func (sut *OauthClientServiceTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *OauthClientServiceTestSuite) Test01CreateValidationError() {
+	sut.T().Log("Test01CreateValidationError")
+	sut.createOauthClientRequest.RedirectUris = nil
+	httpCode, response := sut.oauthClientService.Create(sut.ctx, sut.createOauthClientRequest)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *OauthClientServiceTestSuite) Test02CreateInsecureRedirectUri() {
+	sut.T().Log("Test02CreateInsecureRedirectUri")
+	for _, redirectUri := range []string{"http://calendar.example.com/callback", "https://calendar.example.com/callback#fragment", "calendar:/callback"} {
+		sut.createOauthClientRequest.RedirectUris = []string{redirectUri}
+		httpCode, _ := sut.oauthClientService.Create(sut.ctx, sut.createOauthClientRequest)
+		sut.Equal(httpCode, http.StatusBadRequest)
+	}
+}
+
+func (sut *OauthClientServiceTestSuite) Test03CreateUnknownScope() {
+	sut.T().Log("Test03CreateUnknownScope")
+	sut.createOauthClientRequest.Scopes = []string{"todos:read", "users:manage"}
+	httpCode, response := sut.oauthClientService.Create(sut.ctx, sut.createOauthClientRequest)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(response, helpers.ToResponse("unknown scope users:manage"))
+}
+
+func (sut *OauthClientServiceTestSuite) Test04CreateConfidentialSuccess() {
+	sut.T().Log("Test04CreateConfidentialSuccess")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.oauthClientRepositoryMock.Mock.On("Create", sut.pgxTxMock, sut.ctx, mock.Anything).Return(1, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, response := sut.oauthClientService.Create(sut.ctx, sut.createOauthClientRequest)
+	sut.Equal(httpCode, http.StatusCreated)
+	createOauthClientResponse := response.(modelresponses.CreateOauthClientResponse)
+	sut.NotEqual(createOauthClientResponse.ClientId, "")
+	sut.NotEqual(createOauthClientResponse.ClientSecret, "")
+	sut.True(createOauthClientResponse.Confidential)
+	sut.Equal(createOauthClientResponse.Scopes, []string{"todos:read"})
+	// only the hash of the secret is stored
+	oauthClient := sut.oauthClientRepositoryMock.Mock.Calls[0].Arguments.Get(2).(modelentities.OauthClient)
+	sut.Equal(oauthClient.ClientSecretHash.String, helpers.HashToken(createOauthClientResponse.ClientSecret))
+	sut.Equal(oauthClient.UserId.Int32, int32(1))
+}
+
+func (sut *OauthClientServiceTestSuite) Test05CreatePublicSuccess() {
+	sut.T().Log("Test05CreatePublicSuccess")
+	sut.createOauthClientRequest.Confidential = false
+	sut.createOauthClientRequest.RedirectUris = []string{"http://127.0.0.1:8000/callback"}
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.oauthClientRepositoryMock.Mock.On("Create", sut.pgxTxMock, sut.ctx, mock.Anything).Return(1, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, response := sut.oauthClientService.Create(sut.ctx, sut.createOauthClientRequest)
+	sut.Equal(httpCode, http.StatusCreated)
+	createOauthClientResponse := response.(modelresponses.CreateOauthClientResponse)
+	sut.Equal(createOauthClientResponse.ClientSecret, "")
+	sut.False(createOauthClientResponse.Confidential)
+}
+
+func (sut *OauthClientServiceTestSuite) Test06DeleteNotFound() {
+	sut.T().Log("Test06DeleteNotFound")
+	var rowsAffected int64
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.oauthClientRepositoryMock.Mock.On("DeleteByClientIdAndUserId", sut.pgxTxMock, sut.ctx, "clientId", 1).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, response := sut.oauthClientService.Delete(sut.ctx, "clientId")
+	sut.Equal(httpCode, http.StatusNotFound)
+	sut.Equal(response, helpers.ToResponse("cannot find client"))
+}
+
+func (sut *OauthClientServiceTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *OauthClientServiceTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *OauthClientServiceTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
@@ -0,0 +1,413 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	modelrequests "todo-list-api/models/requests"
+	modelresponses "todo-list-api/models/responses"
+	"todo-list-api/services"
+	mockhelpers "todo-list-api/test/unit_tests/helpers/mocks"
+	mockrepositories "todo-list-api/test/unit_tests/repositories/mocks"
+	mockutils "todo-list-api/test/unit_tests/utils/mocks"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type OauthServiceTestSuite struct {
+	suite.Suite
+	config                               config.Config
+	ctx                                  context.Context
+	options                              pgx.TxOptions
+	pool                                 *pgxpool.Pool
+	errInternalServer                    error
+	codeVerifier                         string
+	codeChallenge                        string
+	oauthClient                          modelentities.OauthClient
+	oauthAuthorizationCode               modelentities.OauthAuthorizationCode
+	oauthGrant                           modelentities.OauthGrant
+	user                                 modelentities.User
+	authorizeRequest                     modelrequests.AuthorizeRequest
+	postgresUtilMock                     *mockutils.PostgresUtilMock
+	userRepositoryMock                   *mockrepositories.UserRepositoryMock
+	oauthClientRepositoryMock            *mockrepositories.OauthClientRepositoryMock
+	oauthAuthorizationCodeRepositoryMock *mockrepositories.OauthAuthorizationCodeRepositoryMock
+	oauthGrantRepositoryMock             *mockrepositories.OauthGrantRepositoryMock
+	jwtHelperMock                        *mockhelpers.JwtHelperMock
+	pgxTxMock                            *mockutils.PgxTxMock
+	oauthService                         services.OauthService
+}
+
+func TestOauthTestSuite(t *testing.T) {
+	suite.Run(t, new(OauthServiceTestSuite))
+}
+
+func (sut *OauthServiceTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.ctx = helpers.ContextWithPrincipal(context.Background(), helpers.Principal{Id: 1})
+	sut.pool = &pgxpool.Pool{}
+	sut.errInternalServer = errors.New("internal server error")
+	sut.codeVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	sut.codeChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	sut.config = config.Default()
+}
+
+func (sut *OauthServiceTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.oauthClient = modelentities.OauthClient{
+		Id:               pgtype.Int4{Valid: true, Int32: 3},
+		ClientId:         pgtype.Text{Valid: true, String: "clientId"},
+		ClientSecretHash: pgtype.Text{Valid: true, String: helpers.HashToken("clientSecret")},
+		UserId:           pgtype.Int4{Valid: true, Int32: 2},
+		Name:             pgtype.Text{Valid: true, String: "Calendar"},
+		RedirectUris:     []string{"https://calendar.example.com/callback"},
+		Scopes:           []string{"profile:read", "todos:read"},
+	}
+	sut.oauthAuthorizationCode = modelentities.OauthAuthorizationCode{
+		Id:            pgtype.Int4{Valid: true, Int32: 4},
+		CodeHash:      pgtype.Text{Valid: true, String: helpers.HashToken("code")},
+		ClientId:      pgtype.Text{Valid: true, String: "clientId"},
+		UserId:        pgtype.Int4{Valid: true, Int32: 1},
+		RedirectUri:   pgtype.Text{Valid: true, String: "https://calendar.example.com/callback"},
+		Scopes:        []string{"todos:read"},
+		CodeChallenge: pgtype.Text{Valid: true, String: sut.codeChallenge},
+		ExpiresAt:     pgtype.Timestamptz{Valid: true, Time: time.Now().Add(time.Minute)},
+	}
+	sut.oauthGrant = modelentities.OauthGrant{
+		Id:                    pgtype.Int4{Valid: true, Int32: 7},
+		ClientId:              pgtype.Text{Valid: true, String: "clientId"},
+		UserId:                pgtype.Int4{Valid: true, Int32: 1},
+		Scopes:                []string{"profile:read", "todos:read"},
+		RefreshTokenHash:      pgtype.Text{Valid: true, String: helpers.HashToken("refreshToken")},
+		RefreshTokenExpiresAt: pgtype.Timestamptz{Valid: true, Time: time.Now().Add(time.Hour)},
+	}
+	sut.user = modelentities.User{
+		Id:    pgtype.Int4{Valid: true, Int32: 1},
+		Name:  pgtype.Text{Valid: true, String: "John Doe"},
+		Email: pgtype.Text{Valid: true, String: "john@doe.com"},
+	}
+	sut.authorizeRequest = modelrequests.AuthorizeRequest{
+		ResponseType:        "code",
+		ClientId:            "clientId",
+		RedirectUri:         "https://calendar.example.com/callback",
+		Scope:               "todos:read",
+		State:               "state",
+		CodeChallenge:       sut.codeChallenge,
+		CodeChallengeMethod: "S256",
+	}
+	sut.postgresUtilMock = new(mockutils.PostgresUtilMock)
+	sut.userRepositoryMock = new(mockrepositories.UserRepositoryMock)
+	sut.oauthClientRepositoryMock = new(mockrepositories.OauthClientRepositoryMock)
+	sut.oauthAuthorizationCodeRepositoryMock = new(mockrepositories.OauthAuthorizationCodeRepositoryMock)
+	sut.oauthGrantRepositoryMock = new(mockrepositories.OauthGrantRepositoryMock)
+	sut.jwtHelperMock = new(mockhelpers.JwtHelperMock)
+	sut.pgxTxMock = new(mockutils.PgxTxMock)
+	sut.oauthService = services.NewOauthService(sut.postgresUtilMock, sut.userRepositoryMock, sut.oauthClientRepositoryMock, sut.oauthAuthorizationCodeRepositoryMock, sut.oauthGrantRepositoryMock, sut.jwtHelperMock, sut.config)
+	sut.postgresUtilMock.Mock.On("GetPool").Return(sut.pool)
+}
+
+func (sut *OauthServiceTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *OauthServiceTestSuite) mockClient() {
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.oauthClientRepositoryMock.Mock.On("FindByClientId", sut.pgxTxMock, sut.ctx, "clientId").Return(sut.oauthClient, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+}
+
+func (sut *OauthServiceTestSuite) Test01AuthorizeUnknownClient() {
+	sut.T().Log("Test01AuthorizeUnknownClient")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.oauthClientRepositoryMock.Mock.On("FindByClientId", sut.pgxTxMock, sut.ctx, "clientId").Return(modelentities.OauthClient{}, pgx.ErrNoRows)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, response := sut.oauthService.Authorize(sut.ctx, sut.authorizeRequest)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(response, helpers.ToResponse("unknown client"))
+}
+
+func (sut *OauthServiceTestSuite) Test02AuthorizeUnregisteredRedirectUri() {
+	sut.T().Log("Test02AuthorizeUnregisteredRedirectUri")
+	sut.mockClient()
+	sut.authorizeRequest.RedirectUri = "https://attacker.example.com/callback"
+	httpCode, response := sut.oauthService.Authorize(sut.ctx, sut.authorizeRequest)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(response, helpers.ToResponse("redirect uri is not registered for this client"))
+}
+
+func (sut *OauthServiceTestSuite) Test03AuthorizeWithoutPkce() {
+	sut.T().Log("Test03AuthorizeWithoutPkce")
+	sut.mockClient()
+	sut.authorizeRequest.CodeChallengeMethod = "plain"
+	httpCode, response := sut.oauthService.Authorize(sut.ctx, sut.authorizeRequest)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	oauthErrorResponse := response.(modelresponses.OauthErrorResponse)
+	sut.Equal(oauthErrorResponse.Error, "invalid_request")
+	redirectUri, err := url.Parse(oauthErrorResponse.RedirectUri)
+	sut.Require().NoError(err)
+	sut.Equal(redirectUri.Host, "calendar.example.com")
+	sut.Equal(redirectUri.Query().Get("error"), "invalid_request")
+	sut.Equal(redirectUri.Query().Get("state"), "state")
+}
+
+func (sut *OauthServiceTestSuite) Test04AuthorizeScopeNotAllowed() {
+	sut.T().Log("Test04AuthorizeScopeNotAllowed")
+	sut.mockClient()
+	sut.authorizeRequest.Scope = "todos:read todos:write"
+	httpCode, response := sut.oauthService.Authorize(sut.ctx, sut.authorizeRequest)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(response.(modelresponses.OauthErrorResponse).Error, "invalid_scope")
+}
+
+func (sut *OauthServiceTestSuite) Test05AuthorizeSuccess() {
+	sut.T().Log("Test05AuthorizeSuccess")
+	sut.mockClient()
+	sut.authorizeRequest.Scope = ""
+	httpCode, response := sut.oauthService.Authorize(sut.ctx, sut.authorizeRequest)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(response, modelresponses.OauthConsentResponse{
+		Client: modelresponses.OauthConsentClientResponse{ClientId: "clientId", Name: "Calendar"},
+		Scopes: []modelresponses.OauthScopeResponse{
+			{Name: "profile:read", Description: helpers.OauthScopes["profile:read"]},
+			{Name: "todos:read", Description: helpers.OauthScopes["todos:read"]},
+		},
+		RedirectUri: "https://calendar.example.com/callback",
+		State:       "state",
+	})
+}
+
+func (sut *OauthServiceTestSuite) Test06ApproveDenied() {
+	sut.T().Log("Test06ApproveDenied")
+	sut.mockClient()
+	httpCode, response := sut.oauthService.Approve(sut.ctx, sut.authorizeRequest)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(response, modelresponses.OauthRedirectResponse{RedirectUri: "https://calendar.example.com/callback?error=access_denied&state=state"})
+	sut.oauthAuthorizationCodeRepositoryMock.Mock.AssertNotCalled(sut.T(), "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (sut *OauthServiceTestSuite) Test07ApproveSuccess() {
+	sut.T().Log("Test07ApproveSuccess")
+	sut.mockClient()
+	sut.authorizeRequest.Approve = true
+	sut.oauthAuthorizationCodeRepositoryMock.Mock.On("Create", sut.pgxTxMock, sut.ctx, mock.Anything).Return(4, nil)
+	httpCode, response := sut.oauthService.Approve(sut.ctx, sut.authorizeRequest)
+	sut.Equal(httpCode, http.StatusOK)
+	redirectUri, err := url.Parse(response.(modelresponses.OauthRedirectResponse).RedirectUri)
+	sut.Require().NoError(err)
+	code := redirectUri.Query().Get("code")
+	sut.NotEqual(code, "")
+	sut.Equal(redirectUri.Query().Get("state"), "state")
+	oauthAuthorizationCode := sut.oauthAuthorizationCodeRepositoryMock.Mock.Calls[0].Arguments.Get(2).(modelentities.OauthAuthorizationCode)
+	sut.Equal(oauthAuthorizationCode.CodeHash.String, helpers.HashToken(code))
+	sut.Equal(oauthAuthorizationCode.UserId.Int32, int32(1))
+	sut.Equal(oauthAuthorizationCode.Scopes, []string{"todos:read"})
+	sut.Equal(oauthAuthorizationCode.CodeChallenge.String, sut.codeChallenge)
+}
+
+func (sut *OauthServiceTestSuite) Test08TokenUnsupportedGrantType() {
+	sut.T().Log("Test08TokenUnsupportedGrantType")
+	httpCode, response := sut.oauthService.Token(sut.ctx, modelrequests.TokenRequest{GrantType: "password"})
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(response.(modelresponses.OauthErrorResponse).Error, "unsupported_grant_type")
+}
+
+func (sut *OauthServiceTestSuite) Test09TokenWrongClientSecret() {
+	sut.T().Log("Test09TokenWrongClientSecret")
+	sut.mockClient()
+	httpCode, response := sut.oauthService.Token(sut.ctx, modelrequests.TokenRequest{GrantType: "authorization_code", ClientId: "clientId", ClientSecret: "otherSecret"})
+	sut.Equal(httpCode, http.StatusUnauthorized)
+	sut.Equal(response.(modelresponses.OauthErrorResponse).Error, "invalid_client")
+}
+
+func (sut *OauthServiceTestSuite) Test10TokenCodeReplayRevokesGrant() {
+	sut.T().Log("Test10TokenCodeReplayRevokesGrant")
+	sut.mockClient()
+	sut.oauthAuthorizationCode.UsedAt = pgtype.Timestamptz{Valid: true, Time: time.Now()}
+	sut.oauthAuthorizationCode.GrantId = pgtype.Int4{Valid: true, Int32: 7}
+	sut.oauthAuthorizationCodeRepositoryMock.Mock.On("FindByCodeHash", sut.pgxTxMock, sut.ctx, helpers.HashToken("code")).Return(sut.oauthAuthorizationCode, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.oauthGrantRepositoryMock.Mock.On("Revoke", sut.pgxTxMock, sut.ctx, 7).Return(rowsAffected, nil)
+	httpCode, response := sut.oauthService.Token(sut.ctx, modelrequests.TokenRequest{
+		GrantType:    "authorization_code",
+		Code:         "code",
+		RedirectUri:  "https://calendar.example.com/callback",
+		CodeVerifier: sut.codeVerifier,
+		ClientId:     "clientId",
+		ClientSecret: "clientSecret",
+	})
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(response.(modelresponses.OauthErrorResponse).Error, "invalid_grant")
+	sut.oauthGrantRepositoryMock.Mock.AssertCalled(sut.T(), "Revoke", sut.pgxTxMock, sut.ctx, 7)
+	sut.postgresUtilMock.Mock.AssertCalled(sut.T(), "CommitOrRollback", sut.pgxTxMock, sut.ctx, nil)
+}
+
+func (sut *OauthServiceTestSuite) Test11TokenWrongCodeVerifier() {
+	sut.T().Log("Test11TokenWrongCodeVerifier")
+	sut.mockClient()
+	sut.oauthAuthorizationCodeRepositoryMock.Mock.On("FindByCodeHash", sut.pgxTxMock, sut.ctx, helpers.HashToken("code")).Return(sut.oauthAuthorizationCode, nil)
+	httpCode, response := sut.oauthService.Token(sut.ctx, modelrequests.TokenRequest{
+		GrantType:    "authorization_code",
+		Code:         "code",
+		RedirectUri:  "https://calendar.example.com/callback",
+		CodeVerifier: sut.codeVerifier[1:] + "x",
+		ClientId:     "clientId",
+		ClientSecret: "clientSecret",
+	})
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(response.(modelresponses.OauthErrorResponse).Error, "invalid_grant")
+	sut.oauthGrantRepositoryMock.Mock.AssertNotCalled(sut.T(), "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (sut *OauthServiceTestSuite) Test12TokenAuthorizationCodeSuccess() {
+	sut.T().Log("Test12TokenAuthorizationCodeSuccess")
+	sut.mockClient()
+	sut.oauthAuthorizationCodeRepositoryMock.Mock.On("FindByCodeHash", sut.pgxTxMock, sut.ctx, helpers.HashToken("code")).Return(sut.oauthAuthorizationCode, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.oauthGrantRepositoryMock.Mock.On("Create", sut.pgxTxMock, sut.ctx, mock.Anything).Return(7, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.oauthAuthorizationCodeRepositoryMock.Mock.On("UpdateUsedAt", sut.pgxTxMock, sut.ctx, 7, 4).Return(rowsAffected, nil)
+	sut.jwtHelperMock.Mock.On("GenerateOauthAccessToken", 1, "clientId", "todos:read", 7, 15).Return("oauthAccessToken", nil)
+	httpCode, response := sut.oauthService.Token(sut.ctx, modelrequests.TokenRequest{
+		GrantType:    "authorization_code",
+		Code:         "code",
+		RedirectUri:  "https://calendar.example.com/callback",
+		CodeVerifier: sut.codeVerifier,
+		ClientId:     "clientId",
+		ClientSecret: "clientSecret",
+	})
+	sut.Equal(httpCode, http.StatusOK)
+	oauthTokenResponse := response.(modelresponses.OauthTokenResponse)
+	sut.Equal(oauthTokenResponse.AccessToken, "oauthAccessToken")
+	sut.Equal(oauthTokenResponse.TokenType, "Bearer")
+	sut.Equal(oauthTokenResponse.ExpiresIn, 900)
+	sut.Equal(oauthTokenResponse.Scope, "todos:read")
+	oauthGrant := sut.oauthGrantRepositoryMock.Mock.Calls[0].Arguments.Get(2).(modelentities.OauthGrant)
+	sut.Equal(oauthGrant.RefreshTokenHash.String, helpers.HashToken(oauthTokenResponse.RefreshToken))
+	sut.Equal(oauthGrant.Scopes, []string{"todos:read"})
+}
+
+func (sut *OauthServiceTestSuite) Test13TokenRefreshWiderScope() {
+	sut.T().Log("Test13TokenRefreshWiderScope")
+	sut.mockClient()
+	sut.oauthGrantRepositoryMock.Mock.On("FindByRefreshTokenHash", sut.pgxTxMock, sut.ctx, helpers.HashToken("refreshToken")).Return(sut.oauthGrant, nil)
+	httpCode, response := sut.oauthService.Token(sut.ctx, modelrequests.TokenRequest{
+		GrantType:    "refresh_token",
+		RefreshToken: "refreshToken",
+		Scope:        "todos:read todos:write",
+		ClientId:     "clientId",
+		ClientSecret: "clientSecret",
+	})
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(response.(modelresponses.OauthErrorResponse).Error, "invalid_scope")
+}
+
+func (sut *OauthServiceTestSuite) Test14TokenRefreshSuccess() {
+	sut.T().Log("Test14TokenRefreshSuccess")
+	sut.mockClient()
+	sut.oauthGrantRepositoryMock.Mock.On("FindByRefreshTokenHash", sut.pgxTxMock, sut.ctx, helpers.HashToken("refreshToken")).Return(sut.oauthGrant, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.oauthGrantRepositoryMock.Mock.On("UpdateRefreshToken", sut.pgxTxMock, sut.ctx, mock.Anything, mock.Anything, 7).Return(rowsAffected, nil)
+	sut.jwtHelperMock.Mock.On("GenerateOauthAccessToken", 1, "clientId", "todos:read", 7, 15).Return("oauthAccessToken", nil)
+	httpCode, response := sut.oauthService.Token(sut.ctx, modelrequests.TokenRequest{
+		GrantType:    "refresh_token",
+		RefreshToken: "refreshToken",
+		Scope:        "todos:read",
+		ClientId:     "clientId",
+		ClientSecret: "clientSecret",
+	})
+	sut.Equal(httpCode, http.StatusOK)
+	oauthTokenResponse := response.(modelresponses.OauthTokenResponse)
+	sut.Equal(oauthTokenResponse.Scope, "todos:read")
+	sut.NotEqual(oauthTokenResponse.RefreshToken, "refreshToken")
+	sut.oauthGrantRepositoryMock.Mock.AssertCalled(sut.T(), "UpdateRefreshToken", sut.pgxTxMock, sut.ctx, helpers.HashToken(oauthTokenResponse.RefreshToken), mock.Anything, 7)
+}
+
+func (sut *OauthServiceTestSuite) Test15RevokeAccessToken() {
+	sut.T().Log("Test15RevokeAccessToken")
+	sut.mockClient()
+	sut.jwtHelperMock.Mock.On("ParseOauthAccessToken", "oauthAccessToken").Return(&helpers.OauthAccessTokenCustomClaims{Id: 1, ClientId: "clientId", GrantId: 7}, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.oauthGrantRepositoryMock.Mock.On("Revoke", sut.pgxTxMock, sut.ctx, 7).Return(rowsAffected, nil)
+	httpCode, _ := sut.oauthService.Revoke(sut.ctx, modelrequests.RevokeTokenRequest{Token: "oauthAccessToken", ClientId: "clientId", ClientSecret: "clientSecret"})
+	sut.Equal(httpCode, http.StatusOK)
+	sut.oauthGrantRepositoryMock.Mock.AssertCalled(sut.T(), "Revoke", sut.pgxTxMock, sut.ctx, 7)
+}
+
+func (sut *OauthServiceTestSuite) Test16RevokeRefreshTokenOfAnotherClient() {
+	sut.T().Log("Test16RevokeRefreshTokenOfAnotherClient")
+	sut.mockClient()
+	sut.oauthGrant.ClientId = pgtype.Text{Valid: true, String: "otherClientId"}
+	sut.jwtHelperMock.Mock.On("ParseOauthAccessToken", "refreshToken").Return((*helpers.OauthAccessTokenCustomClaims)(nil), errors.New("token is malformed"))
+	sut.oauthGrantRepositoryMock.Mock.On("FindByRefreshTokenHash", sut.pgxTxMock, sut.ctx, helpers.HashToken("refreshToken")).Return(sut.oauthGrant, nil)
+	httpCode, _ := sut.oauthService.Revoke(sut.ctx, modelrequests.RevokeTokenRequest{Token: "refreshToken", ClientId: "clientId", ClientSecret: "clientSecret"})
+	sut.Equal(httpCode, http.StatusOK)
+	sut.oauthGrantRepositoryMock.Mock.AssertNotCalled(sut.T(), "Revoke", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (sut *OauthServiceTestSuite) Test17IntrospectActiveAccessToken() {
+	sut.T().Log("Test17IntrospectActiveAccessToken")
+	sut.mockClient()
+	issuedAt := time.Now().Truncate(time.Second)
+	claims := &helpers.OauthAccessTokenCustomClaims{
+		Id:       1,
+		ClientId: "clientId",
+		Scope:    "todos:read",
+		GrantId:  7,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "1",
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(issuedAt.Add(15 * time.Minute)),
+		},
+	}
+	sut.jwtHelperMock.Mock.On("ParseOauthAccessToken", "oauthAccessToken").Return(claims, nil)
+	sut.oauthGrantRepositoryMock.Mock.On("FindActiveById", sut.pool, sut.ctx, 7).Return(sut.oauthGrant, nil)
+	httpCode, response := sut.oauthService.Introspect(sut.ctx, modelrequests.IntrospectTokenRequest{Token: "oauthAccessToken", ClientId: "clientId", ClientSecret: "clientSecret"})
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(response, modelresponses.IntrospectTokenResponse{
+		Active:    true,
+		Scope:     "todos:read",
+		ClientId:  "clientId",
+		Sub:       "1",
+		Exp:       issuedAt.Add(15 * time.Minute).Unix(),
+		Iat:       issuedAt.Unix(),
+		TokenType: "Bearer",
+	})
+}
+
+func (sut *OauthServiceTestSuite) Test18IntrospectRevokedAccessToken() {
+	sut.T().Log("Test18IntrospectRevokedAccessToken")
+	sut.mockClient()
+	sut.jwtHelperMock.Mock.On("ParseOauthAccessToken", "oauthAccessToken").Return(&helpers.OauthAccessTokenCustomClaims{Id: 1, ClientId: "clientId", GrantId: 7}, nil)
+	sut.oauthGrantRepositoryMock.Mock.On("FindActiveById", sut.pool, sut.ctx, 7).Return(modelentities.OauthGrant{}, pgx.ErrNoRows)
+	httpCode, response := sut.oauthService.Introspect(sut.ctx, modelrequests.IntrospectTokenRequest{Token: "oauthAccessToken", ClientId: "clientId", ClientSecret: "clientSecret"})
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(response, modelresponses.IntrospectTokenResponse{Active: false})
+}
+
+func (sut *OauthServiceTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *OauthServiceTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *OauthServiceTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
@@ -0,0 +1,275 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	"todo-list-api/services"
+	mockhelpers "todo-list-api/test/unit_tests/helpers/mocks"
+	mockrepositories "todo-list-api/test/unit_tests/repositories/mocks"
+	mockutils "todo-list-api/test/unit_tests/utils/mocks"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type OidcServiceTestSuite struct {
+	suite.Suite
+	metricsHelper               helpers.MetricsHelper
+	config                      config.Config
+	ctx                         context.Context
+	options                     pgx.TxOptions
+	errInternalServer           error
+	user                        modelentities.User
+	identity                    helpers.OidcIdentity
+	claims                      *helpers.OidcStateTokenCustomClaims
+	postgresUtilMock            *mockutils.PostgresUtilMock
+	userRepositoryMock          *mockrepositories.UserRepositoryMock
+	securityEventRepositoryMock *mockrepositories.SecurityEventRepositoryMock
+	userIdentityRepositoryMock  *mockrepositories.UserIdentityRepositoryMock
+	passwordHasherMock          *mockhelpers.PasswordHasherMock
+	jwtHelperMock               *mockhelpers.JwtHelperMock
+	oidcHelperMock              *mockhelpers.OidcHelperMock
+	pgxTxMock                   *mockutils.PgxTxMock
+	oidcService                 services.OidcService
+}
+
+func TestOidcTestSuite(t *testing.T) {
+	suite.Run(t, new(OidcServiceTestSuite))
+}
+
+func (sut *OidcServiceTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.ctx = context.Background()
+	sut.errInternalServer = errors.New("internal server error")
+	sut.config = config.Default()
+}
+
+func (sut *OidcServiceTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.metricsHelper = helpers.NewMetricsHelper()
+	sut.user = modelentities.User{
+		Id:              pgtype.Int4{Valid: true, Int32: 1},
+		Name:            pgtype.Text{Valid: true, String: "John Doe"},
+		Email:           pgtype.Text{Valid: true, String: "john@doe.com"},
+		Password:        pgtype.Text{Valid: true, String: "passwordHash"},
+		EmailVerifiedAt: pgtype.Timestamptz{Valid: true, Time: time.Now()},
+	}
+	sut.identity = helpers.OidcIdentity{
+		Subject:       "subject-1",
+		Email:         "john@doe.com",
+		EmailVerified: true,
+		Name:          "John Doe",
+	}
+	sut.claims = &helpers.OidcStateTokenCustomClaims{
+		Provider:     "google",
+		State:        "state",
+		Nonce:        "nonce",
+		CodeVerifier: "codeVerifier",
+	}
+	sut.postgresUtilMock = new(mockutils.PostgresUtilMock)
+	sut.userRepositoryMock = new(mockrepositories.UserRepositoryMock)
+	sut.securityEventRepositoryMock = new(mockrepositories.SecurityEventRepositoryMock)
+	sut.securityEventRepositoryMock.Mock.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(1, nil)
+	sut.userIdentityRepositoryMock = new(mockrepositories.UserIdentityRepositoryMock)
+	sut.passwordHasherMock = new(mockhelpers.PasswordHasherMock)
+	sut.jwtHelperMock = new(mockhelpers.JwtHelperMock)
+	sut.oidcHelperMock = new(mockhelpers.OidcHelperMock)
+	sut.pgxTxMock = new(mockutils.PgxTxMock)
+	sut.oidcService = services.NewOidcService(sut.postgresUtilMock, sut.userRepositoryMock, sut.userIdentityRepositoryMock, sut.passwordHasherMock, sut.jwtHelperMock, sut.oidcHelperMock, sut.securityEventRepositoryMock, sut.metricsHelper, sut.config)
+}
+
+func (sut *OidcServiceTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *OidcServiceTestSuite) mockCallbackUntilBeginTx() {
+	sut.jwtHelperMock.Mock.On("ParseOidcStateToken", "oidcStateToken").Return(sut.claims, nil)
+	sut.oidcHelperMock.Mock.On("Exchange", sut.ctx, "google", "code", "codeVerifier", "nonce").Return(sut.identity, nil)
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+}
+
+func (sut *OidcServiceTestSuite) mockIssueTokens() {
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", 1, "John Doe", "john@doe.com", 15).Return("accessToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", 1, 1).Return("refreshToken", nil)
+	sut.userRepositoryMock.Mock.On("UpdateRefreshToken", sut.pgxTxMock, sut.ctx, "refreshToken", 1).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+}
+
+func (sut *OidcServiceTestSuite) Test01StartUnknownProvider() {
+	sut.T().Log("Test01StartUnknownProvider")
+	sut.oidcHelperMock.Mock.On("AuthCodeUrl", sut.ctx, "other", mock.Anything, mock.Anything, mock.Anything).Return("", helpers.ErrUnknownOidcProvider)
+	httpCode, authCodeUrl, oidcStateToken, _ := sut.oidcService.Start(sut.ctx, "other")
+	sut.Equal(httpCode, http.StatusNotFound)
+	sut.Equal(authCodeUrl, "")
+	sut.Equal(oidcStateToken, "")
+}
+
+func (sut *OidcServiceTestSuite) Test02StartSuccess() {
+	sut.T().Log("Test02StartSuccess")
+	sut.oidcHelperMock.Mock.On("AuthCodeUrl", sut.ctx, "google", mock.Anything, mock.Anything, mock.Anything).Return("https://accounts.google.com/o/oauth2/v2/auth?state=state", nil)
+	sut.jwtHelperMock.Mock.On("GenerateOidcStateToken", "google", mock.Anything, mock.Anything, mock.Anything, 10).Return("oidcStateToken", nil)
+	httpCode, authCodeUrl, oidcStateToken, _ := sut.oidcService.Start(sut.ctx, "google")
+	sut.Equal(httpCode, http.StatusFound)
+	sut.Equal(authCodeUrl, "https://accounts.google.com/o/oauth2/v2/auth?state=state")
+	sut.Equal(oidcStateToken, "oidcStateToken")
+	// the state, nonce and code verifier given to the provider are the ones kept in the state token
+	authCodeUrlArguments := sut.oidcHelperMock.Mock.Calls[0].Arguments
+	sut.jwtHelperMock.Mock.AssertCalled(sut.T(), "GenerateOidcStateToken", "google", authCodeUrlArguments.String(2), authCodeUrlArguments.String(3), authCodeUrlArguments.String(4), 10)
+	sut.Len(authCodeUrlArguments.String(4), 43)
+}
+
+func (sut *OidcServiceTestSuite) Test03CallbackWithoutStateCookie() {
+	sut.T().Log("Test03CallbackWithoutStateCookie")
+	httpCode, accessToken, _, response := sut.oidcService.Callback(sut.ctx, "google", "code", "state", "")
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(accessToken, "")
+	sut.Equal(response, helpers.ToResponse("invalid oidc state"))
+}
+
+func (sut *OidcServiceTestSuite) Test04CallbackStateMismatch() {
+	sut.T().Log("Test04CallbackStateMismatch")
+	sut.jwtHelperMock.Mock.On("ParseOidcStateToken", "oidcStateToken").Return(sut.claims, nil)
+	httpCode, _, _, response := sut.oidcService.Callback(sut.ctx, "google", "code", "otherState", "oidcStateToken")
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(response, helpers.ToResponse("invalid oidc state"))
+	sut.oidcHelperMock.Mock.AssertNotCalled(sut.T(), "Exchange", sut.ctx, "google", "code", "codeVerifier", "nonce")
+}
+
+func (sut *OidcServiceTestSuite) Test05CallbackProviderMismatch() {
+	sut.T().Log("Test05CallbackProviderMismatch")
+	sut.jwtHelperMock.Mock.On("ParseOidcStateToken", "oidcStateToken").Return(sut.claims, nil)
+	httpCode, _, _, _ := sut.oidcService.Callback(sut.ctx, "keycloak", "code", "state", "oidcStateToken")
+	sut.Equal(httpCode, http.StatusBadRequest)
+}
+
+func (sut *OidcServiceTestSuite) Test06CallbackExchangeError() {
+	sut.T().Log("Test06CallbackExchangeError")
+	sut.jwtHelperMock.Mock.On("ParseOidcStateToken", "oidcStateToken").Return(sut.claims, nil)
+	sut.oidcHelperMock.Mock.On("Exchange", sut.ctx, "google", "code", "codeVerifier", "nonce").Return(helpers.OidcIdentity{}, errors.New("id token nonce does not match"))
+	httpCode, accessToken, _, _ := sut.oidcService.Callback(sut.ctx, "google", "code", "state", "oidcStateToken")
+	sut.Equal(httpCode, http.StatusUnauthorized)
+	sut.Equal(accessToken, "")
+}
+
+func (sut *OidcServiceTestSuite) Test07CallbackLinkedIdentity() {
+	sut.T().Log("Test07CallbackLinkedIdentity")
+	sut.mockCallbackUntilBeginTx()
+	userIdentity := modelentities.UserIdentity{Id: pgtype.Int4{Valid: true, Int32: 5}, UserId: pgtype.Int4{Valid: true, Int32: 1}}
+	sut.userIdentityRepositoryMock.Mock.On("FindByProviderAndSubject", sut.pgxTxMock, sut.ctx, "google", "subject-1").Return(userIdentity, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userIdentityRepositoryMock.Mock.On("UpdateLastLoginAt", sut.pgxTxMock, sut.ctx, "john@doe.com", 5).Return(rowsAffected, nil)
+	sut.mockIssueTokens()
+	httpCode, accessToken, refreshToken, _ := sut.oidcService.Callback(sut.ctx, "google", "code", "state", "oidcStateToken")
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(accessToken, "accessToken")
+	sut.Equal(refreshToken, "refreshToken")
+}
+
+func (sut *OidcServiceTestSuite) Test08CallbackUnverifiedProviderEmail() {
+	sut.T().Log("Test08CallbackUnverifiedProviderEmail")
+	sut.identity.EmailVerified = false
+	sut.mockCallbackUntilBeginTx()
+	sut.userIdentityRepositoryMock.Mock.On("FindByProviderAndSubject", sut.pgxTxMock, sut.ctx, "google", "subject-1").Return(modelentities.UserIdentity{}, pgx.ErrNoRows)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, accessToken, _, _ := sut.oidcService.Callback(sut.ctx, "google", "code", "state", "oidcStateToken")
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(accessToken, "")
+	sut.userRepositoryMock.Mock.AssertNotCalled(sut.T(), "FindByEmail", sut.pgxTxMock, sut.ctx, "john@doe.com")
+}
+
+func (sut *OidcServiceTestSuite) Test09CallbackExistingUserWithUnverifiedEmail() {
+	sut.T().Log("Test09CallbackExistingUserWithUnverifiedEmail")
+	sut.user.EmailVerifiedAt = pgtype.Timestamptz{}
+	sut.mockCallbackUntilBeginTx()
+	sut.userIdentityRepositoryMock.Mock.On("FindByProviderAndSubject", sut.pgxTxMock, sut.ctx, "google", "subject-1").Return(modelentities.UserIdentity{}, pgx.ErrNoRows)
+	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, "john@doe.com").Return(sut.user, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, accessToken, _, _ := sut.oidcService.Callback(sut.ctx, "google", "code", "state", "oidcStateToken")
+	sut.Equal(httpCode, http.StatusConflict)
+	sut.Equal(accessToken, "")
+	sut.userIdentityRepositoryMock.Mock.AssertNotCalled(sut.T(), "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (sut *OidcServiceTestSuite) Test10CallbackLinksExistingUser() {
+	sut.T().Log("Test10CallbackLinksExistingUser")
+	sut.mockCallbackUntilBeginTx()
+	sut.userIdentityRepositoryMock.Mock.On("FindByProviderAndSubject", sut.pgxTxMock, sut.ctx, "google", "subject-1").Return(modelentities.UserIdentity{}, pgx.ErrNoRows)
+	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, "john@doe.com").Return(sut.user, nil)
+	userIdentity := modelentities.UserIdentity{
+		UserId:   pgtype.Int4{Valid: true, Int32: 1},
+		Provider: pgtype.Text{Valid: true, String: "google"},
+		Subject:  pgtype.Text{Valid: true, String: "subject-1"},
+		Email:    pgtype.Text{Valid: true, String: "john@doe.com"},
+	}
+	sut.userIdentityRepositoryMock.Mock.On("Create", sut.pgxTxMock, sut.ctx, userIdentity).Return(1, nil)
+	sut.mockIssueTokens()
+	httpCode, accessToken, _, _ := sut.oidcService.Callback(sut.ctx, "google", "code", "state", "oidcStateToken")
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(accessToken, "accessToken")
+	sut.userRepositoryMock.Mock.AssertNotCalled(sut.T(), "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (sut *OidcServiceTestSuite) Test11CallbackCreatesUser() {
+	sut.T().Log("Test11CallbackCreatesUser")
+	sut.mockCallbackUntilBeginTx()
+	sut.userIdentityRepositoryMock.Mock.On("FindByProviderAndSubject", sut.pgxTxMock, sut.ctx, "google", "subject-1").Return(modelentities.UserIdentity{}, pgx.ErrNoRows)
+	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, "john@doe.com").Return(modelentities.User{}, pgx.ErrNoRows)
+	sut.passwordHasherMock.Mock.On("Hash", mock.Anything).Return("randomPasswordHash", nil)
+	user := modelentities.User{
+		Name:     pgtype.Text{Valid: true, String: "John Doe"},
+		Email:    pgtype.Text{Valid: true, String: "john@doe.com"},
+		Password: pgtype.Text{Valid: true, String: "randomPasswordHash"},
+	}
+	sut.userRepositoryMock.Mock.On("Create", sut.pgxTxMock, sut.ctx, user).Return(1, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("UpdateEmailVerifiedAt", sut.pgxTxMock, sut.ctx, 1).Return(rowsAffected, nil)
+	sut.userIdentityRepositoryMock.Mock.On("Create", sut.pgxTxMock, sut.ctx, mock.Anything).Return(1, nil)
+	sut.mockIssueTokens()
+	httpCode, accessToken, refreshToken, _ := sut.oidcService.Callback(sut.ctx, "google", "code", "state", "oidcStateToken")
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(accessToken, "accessToken")
+	sut.Equal(refreshToken, "refreshToken")
+	sut.userRepositoryMock.Mock.AssertCalled(sut.T(), "UpdateEmailVerifiedAt", sut.pgxTxMock, sut.ctx, 1)
+}
+
+func (sut *OidcServiceTestSuite) Test12CallbackDisabledUser() {
+	sut.T().Log("Test12CallbackDisabledUser")
+	sut.user.DisabledAt = pgtype.Timestamptz{Valid: true, Time: time.Now()}
+	sut.mockCallbackUntilBeginTx()
+	userIdentity := modelentities.UserIdentity{Id: pgtype.Int4{Valid: true, Int32: 5}, UserId: pgtype.Int4{Valid: true, Int32: 1}}
+	sut.userIdentityRepositoryMock.Mock.On("FindByProviderAndSubject", sut.pgxTxMock, sut.ctx, "google", "subject-1").Return(userIdentity, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userIdentityRepositoryMock.Mock.On("UpdateLastLoginAt", sut.pgxTxMock, sut.ctx, "john@doe.com", 5).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, accessToken, _, response := sut.oidcService.Callback(sut.ctx, "google", "code", "state", "oidcStateToken")
+	sut.Equal(httpCode, http.StatusForbidden)
+	sut.Equal(accessToken, "")
+	sut.Equal(response, helpers.ToResponse("account is disabled"))
+}
+
+func (sut *OidcServiceTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *OidcServiceTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *OidcServiceTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
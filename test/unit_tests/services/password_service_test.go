@@ -0,0 +1,238 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	modelrequests "todo-list-api/models/requests"
+	modelresponses "todo-list-api/models/responses"
+	"todo-list-api/services"
+	mockhelpers "todo-list-api/test/unit_tests/helpers/mocks"
+	mockrepositories "todo-list-api/test/unit_tests/repositories/mocks"
+	mockutils "todo-list-api/test/unit_tests/utils/mocks"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type PasswordServiceTestSuite struct {
+	suite.Suite
+	config                           config.Config
+	ctx                              context.Context
+	options                          pgx.TxOptions
+	errInternalServer                error
+	forgotPasswordRequest            modelrequests.ForgotPasswordRequest
+	resetPasswordRequest             modelrequests.ResetPasswordRequest
+	user                             modelentities.User
+	passwordResetToken               modelentities.PasswordResetToken
+	postgresUtilMock                 *mockutils.PostgresUtilMock
+	validate                         *validator.Validate
+	userRepositoryMock               *mockrepositories.UserRepositoryMock
+	securityEventRepositoryMock      *mockrepositories.SecurityEventRepositoryMock
+	passwordResetTokenRepositoryMock *mockrepositories.PasswordResetTokenRepositoryMock
+	passwordHasherMock               *mockhelpers.PasswordHasherMock
+	mailQueueMock                    *mockhelpers.MailQueueMock
+	passwordPolicyHelperMock         *mockhelpers.PasswordPolicyHelperMock
+	pgxTxMock                        *mockutils.PgxTxMock
+	passwordService                  services.PasswordService
+}
+
+func TestPasswordTestSuite(t *testing.T) {
+	suite.Run(t, new(PasswordServiceTestSuite))
+}
+
+func (sut *PasswordServiceTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.ctx = context.Background()
+	sut.errInternalServer = errors.New("internal server error")
+	sut.config = config.Default()
+}
+
+func (sut *PasswordServiceTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.forgotPasswordRequest = modelrequests.ForgotPasswordRequest{
+		Email: "john@doe.com",
+	}
+	sut.resetPasswordRequest = modelrequests.ResetPasswordRequest{
+		Token:    "token",
+		Password: "new-password",
+	}
+	sut.user = modelentities.User{
+		Id:       pgtype.Int4{Valid: true, Int32: 1},
+		Name:     pgtype.Text{Valid: true, String: "John Doe"},
+		Email:    pgtype.Text{Valid: true, String: "john@doe.com"},
+		Password: pgtype.Text{Valid: true, String: "password"},
+	}
+	sut.passwordResetToken = modelentities.PasswordResetToken{
+		Id:        pgtype.Int4{Valid: true, Int32: 1},
+		UserId:    pgtype.Int4{Valid: true, Int32: 1},
+		TokenHash: pgtype.Text{Valid: true, String: helpers.HashToken("token")},
+		ExpiresAt: pgtype.Timestamptz{Valid: true, Time: time.Now().Add(time.Minute)},
+	}
+	sut.postgresUtilMock = new(mockutils.PostgresUtilMock)
+	sut.validate = validator.New()
+	sut.userRepositoryMock = new(mockrepositories.UserRepositoryMock)
+	sut.securityEventRepositoryMock = new(mockrepositories.SecurityEventRepositoryMock)
+	sut.securityEventRepositoryMock.Mock.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(1, nil)
+	sut.passwordResetTokenRepositoryMock = new(mockrepositories.PasswordResetTokenRepositoryMock)
+	sut.passwordHasherMock = new(mockhelpers.PasswordHasherMock)
+	sut.mailQueueMock = new(mockhelpers.MailQueueMock)
+	sut.passwordPolicyHelperMock = new(mockhelpers.PasswordPolicyHelperMock)
+	sut.pgxTxMock = new(mockutils.PgxTxMock)
+	sut.passwordService = services.NewPasswordService(sut.postgresUtilMock, sut.validate, sut.userRepositoryMock, sut.passwordResetTokenRepositoryMock, sut.passwordHasherMock, sut.mailQueueMock, sut.passwordPolicyHelperMock, sut.securityEventRepositoryMock, sut.config)
+}
+
+func (sut *PasswordServiceTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *PasswordServiceTestSuite) Test01ForgotPasswordValidationError() {
+	sut.T().Log("Test01ForgotPasswordValidationError")
+	sut.forgotPasswordRequest = modelrequests.ForgotPasswordRequest{}
+	httpCode, response := sut.passwordService.ForgotPassword(sut.ctx, sut.forgotPasswordRequest)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *PasswordServiceTestSuite) Test02ForgotPasswordUnknownEmail() {
+	sut.T().Log("Test02ForgotPasswordUnknownEmail")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	var user modelentities.User
+	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, sut.forgotPasswordRequest.Email).Return(user, pgx.ErrNoRows)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, pgx.ErrNoRows).Return(nil)
+	httpCode, response := sut.passwordService.ForgotPassword(sut.ctx, sut.forgotPasswordRequest)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.NotEqual(response, nil)
+	sut.mailQueueMock.Mock.AssertNotCalled(sut.T(), "Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (sut *PasswordServiceTestSuite) Test03ForgotPasswordCommitError() {
+	sut.T().Log("Test03ForgotPasswordCommitError")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, sut.forgotPasswordRequest.Email).Return(sut.user, nil)
+	sut.passwordResetTokenRepositoryMock.Mock.On("Create", sut.pgxTxMock, sut.ctx, mock.Anything).Return(1, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(sut.errInternalServer)
+	httpCode, response := sut.passwordService.ForgotPassword(sut.ctx, sut.forgotPasswordRequest)
+	sut.Equal(httpCode, http.StatusInternalServerError)
+	sut.NotEqual(response, nil)
+	sut.mailQueueMock.Mock.AssertNotCalled(sut.T(), "Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (sut *PasswordServiceTestSuite) Test04ForgotPasswordSuccess() {
+	sut.T().Log("Test04ForgotPasswordSuccess")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, sut.forgotPasswordRequest.Email).Return(sut.user, nil)
+	sut.passwordResetTokenRepositoryMock.Mock.On("Create", sut.pgxTxMock, sut.ctx, mock.Anything).Return(1, nil)
+	sut.mailQueueMock.Mock.On("Send", sut.ctx, sut.user.Email.String, "Reset your password", mock.Anything).Return()
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, response := sut.passwordService.ForgotPassword(sut.ctx, sut.forgotPasswordRequest)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.NotEqual(response, nil)
+
+	passwordResetToken := sut.passwordResetTokenRepositoryMock.Mock.Calls[0].Arguments.Get(2).(modelentities.PasswordResetToken)
+	body := sut.mailQueueMock.Mock.Calls[0].Arguments.String(3)
+	sut.Equal(passwordResetToken.UserId, sut.user.Id)
+	sut.True(passwordResetToken.ExpiresAt.Time.After(time.Now().Add(29 * time.Minute)))
+	sut.NotContains(body, passwordResetToken.TokenHash.String)
+}
+
+func (sut *PasswordServiceTestSuite) Test05ResetPasswordInvalidToken() {
+	sut.T().Log("Test05ResetPasswordInvalidToken")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	var passwordResetToken modelentities.PasswordResetToken
+	sut.passwordResetTokenRepositoryMock.Mock.On("FindByTokenHash", sut.pgxTxMock, sut.ctx, helpers.HashToken("token")).Return(passwordResetToken, pgx.ErrNoRows)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, pgx.ErrNoRows).Return(nil)
+	httpCode, response := sut.passwordService.ResetPassword(sut.ctx, sut.resetPasswordRequest)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *PasswordServiceTestSuite) Test06ResetPasswordExpiredToken() {
+	sut.T().Log("Test06ResetPasswordExpiredToken")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.passwordResetToken.ExpiresAt = pgtype.Timestamptz{Valid: true, Time: time.Now().Add(-time.Minute)}
+	sut.passwordResetTokenRepositoryMock.Mock.On("FindByTokenHash", sut.pgxTxMock, sut.ctx, helpers.HashToken("token")).Return(sut.passwordResetToken, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, response := sut.passwordService.ResetPassword(sut.ctx, sut.resetPasswordRequest)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *PasswordServiceTestSuite) Test07ResetPasswordUsedToken() {
+	sut.T().Log("Test07ResetPasswordUsedToken")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.passwordResetToken.UsedAt = pgtype.Timestamptz{Valid: true, Time: time.Now()}
+	sut.passwordResetTokenRepositoryMock.Mock.On("FindByTokenHash", sut.pgxTxMock, sut.ctx, helpers.HashToken("token")).Return(sut.passwordResetToken, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, response := sut.passwordService.ResetPassword(sut.ctx, sut.resetPasswordRequest)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *PasswordServiceTestSuite) Test08ResetPasswordUpdatePasswordError() {
+	sut.T().Log("Test08ResetPasswordUpdatePasswordError")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.passwordResetTokenRepositoryMock.Mock.On("FindByTokenHash", sut.pgxTxMock, sut.ctx, helpers.HashToken("token")).Return(sut.passwordResetToken, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.passwordPolicyHelperMock.Mock.On("Check", sut.resetPasswordRequest.Password, sut.user.Name.String, sut.user.Email.String).Return([]modelresponses.PasswordPolicyViolation(nil), nil)
+	sut.passwordHasherMock.Mock.On("Hash", sut.resetPasswordRequest.Password).Return("hashed", nil)
+	var rowsAffected int64
+	sut.userRepositoryMock.Mock.On("UpdatePassword", sut.pgxTxMock, sut.ctx, "hashed", 1).Return(rowsAffected, sut.errInternalServer)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer).Return(nil)
+	httpCode, response := sut.passwordService.ResetPassword(sut.ctx, sut.resetPasswordRequest)
+	sut.Equal(httpCode, http.StatusInternalServerError)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *PasswordServiceTestSuite) Test09ResetPasswordSuccess() {
+	sut.T().Log("Test09ResetPasswordSuccess")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.passwordResetTokenRepositoryMock.Mock.On("FindByTokenHash", sut.pgxTxMock, sut.ctx, helpers.HashToken("token")).Return(sut.passwordResetToken, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.passwordPolicyHelperMock.Mock.On("Check", sut.resetPasswordRequest.Password, sut.user.Name.String, sut.user.Email.String).Return([]modelresponses.PasswordPolicyViolation(nil), nil)
+	sut.passwordHasherMock.Mock.On("Hash", sut.resetPasswordRequest.Password).Return("hashed", nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("UpdatePassword", sut.pgxTxMock, sut.ctx, "hashed", 1).Return(rowsAffected, nil)
+	sut.passwordResetTokenRepositoryMock.Mock.On("UpdateUsedAtByUserId", sut.pgxTxMock, sut.ctx, 1).Return(rowsAffected, nil)
+	sut.userRepositoryMock.Mock.On("RevokeSessions", sut.pgxTxMock, sut.ctx, 1).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, response := sut.passwordService.ResetPassword(sut.ctx, sut.resetPasswordRequest)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.NotEqual(response, nil)
+	sut.userRepositoryMock.Mock.AssertCalled(sut.T(), "RevokeSessions", sut.pgxTxMock, sut.ctx, 1)
+}
+
+func (sut *PasswordServiceTestSuite) Test10ResetPasswordPolicyViolation() {
+	sut.T().Log("Test10ResetPasswordPolicyViolation")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.passwordResetTokenRepositoryMock.Mock.On("FindByTokenHash", sut.pgxTxMock, sut.ctx, helpers.HashToken("token")).Return(sut.passwordResetToken, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	violations := []modelresponses.PasswordPolicyViolation{{Code: "breached", Message: "password has appeared in a data breach, choose another one"}}
+	sut.passwordPolicyHelperMock.Mock.On("Check", sut.resetPasswordRequest.Password, sut.user.Name.String, sut.user.Email.String).Return(violations, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, response := sut.passwordService.ResetPassword(sut.ctx, sut.resetPasswordRequest)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(response.(modelresponses.PasswordPolicyResponse).Violations, violations)
+	sut.userRepositoryMock.Mock.AssertNotCalled(sut.T(), "UpdatePassword", sut.pgxTxMock, sut.ctx, mock.Anything, 1)
+}
+
+func (sut *PasswordServiceTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *PasswordServiceTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *PasswordServiceTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
@@ -0,0 +1,191 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	"todo-list-api/services"
+	mockrepositories "todo-list-api/test/unit_tests/repositories/mocks"
+	mockutils "todo-list-api/test/unit_tests/utils/mocks"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/suite"
+)
+
+type PrincipalServiceTestSuite struct {
+	suite.Suite
+	ctx                      context.Context
+	pool                     *pgxpool.Pool
+	errInternalServer        error
+	issuedAt                 time.Time
+	claims                   *helpers.AccessTokenCustomClaims
+	user                     modelentities.User
+	oauthClaims              *helpers.OauthAccessTokenCustomClaims
+	oauthGrant               modelentities.OauthGrant
+	postgresUtilMock         *mockutils.PostgresUtilMock
+	userRepositoryMock       *mockrepositories.UserRepositoryMock
+	oauthGrantRepositoryMock *mockrepositories.OauthGrantRepositoryMock
+	principalService         services.PrincipalService
+}
+
+func TestPrincipalTestSuite(t *testing.T) {
+	suite.Run(t, new(PrincipalServiceTestSuite))
+}
+
+func (sut *PrincipalServiceTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.ctx = context.Background()
+	sut.pool = &pgxpool.Pool{}
+	sut.errInternalServer = errors.New("internal server error")
+}
+
+func (sut *PrincipalServiceTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.issuedAt = time.Now().Truncate(time.Second)
+	sut.claims = &helpers.AccessTokenCustomClaims{
+		Id:               1,
+		Name:             "John Doe",
+		Email:            "john@doe.com",
+		RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(sut.issuedAt)},
+	}
+	sut.user = modelentities.User{
+		Id:    pgtype.Int4{Valid: true, Int32: 1},
+		Name:  pgtype.Text{Valid: true, String: "John Doe"},
+		Email: pgtype.Text{Valid: true, String: "john@doe.com"},
+		Role:  pgtype.Text{Valid: true, String: helpers.RoleAdmin},
+	}
+	sut.oauthClaims = &helpers.OauthAccessTokenCustomClaims{
+		Id:               1,
+		ClientId:         "clientId",
+		Scope:            "todos:read profile:read",
+		GrantId:          7,
+		RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(sut.issuedAt)},
+	}
+	sut.oauthGrant = modelentities.OauthGrant{
+		Id:       pgtype.Int4{Valid: true, Int32: 7},
+		ClientId: pgtype.Text{Valid: true, String: "clientId"},
+		UserId:   pgtype.Int4{Valid: true, Int32: 1},
+		Scopes:   []string{"profile:read", "todos:read"},
+	}
+	sut.postgresUtilMock = new(mockutils.PostgresUtilMock)
+	sut.userRepositoryMock = new(mockrepositories.UserRepositoryMock)
+	sut.oauthGrantRepositoryMock = new(mockrepositories.OauthGrantRepositoryMock)
+	sut.principalService = services.NewPrincipalService(sut.postgresUtilMock, sut.userRepositoryMock, sut.oauthGrantRepositoryMock)
+	sut.postgresUtilMock.Mock.On("GetPool").Return(sut.pool)
+}
+
+func (sut *PrincipalServiceTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *PrincipalServiceTestSuite) Test01FindByAccessTokenUserNotFound() {
+	sut.T().Log("Test01FindByAccessTokenUserNotFound")
+	sut.userRepositoryMock.Mock.On("FindSessionById", sut.pool, sut.ctx, 1).Return(modelentities.User{}, pgx.ErrNoRows)
+	httpCode, _, response := sut.principalService.FindByAccessToken(sut.ctx, sut.claims)
+	sut.Equal(httpCode, http.StatusUnauthorized)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *PrincipalServiceTestSuite) Test02FindByAccessTokenError() {
+	sut.T().Log("Test02FindByAccessTokenError")
+	sut.userRepositoryMock.Mock.On("FindSessionById", sut.pool, sut.ctx, 1).Return(modelentities.User{}, sut.errInternalServer)
+	httpCode, _, response := sut.principalService.FindByAccessToken(sut.ctx, sut.claims)
+	sut.Equal(httpCode, http.StatusInternalServerError)
+	sut.Equal(response, helpers.ToResponse(sut.errInternalServer.Error()))
+}
+
+func (sut *PrincipalServiceTestSuite) Test03FindByAccessTokenDisabled() {
+	sut.T().Log("Test03FindByAccessTokenDisabled")
+	sut.user.DisabledAt = pgtype.Timestamptz{Valid: true, Time: sut.issuedAt.Add(-time.Hour)}
+	sut.userRepositoryMock.Mock.On("FindSessionById", sut.pool, sut.ctx, 1).Return(sut.user, nil)
+	httpCode, _, response := sut.principalService.FindByAccessToken(sut.ctx, sut.claims)
+	sut.Equal(httpCode, http.StatusForbidden)
+	sut.Equal(response, helpers.ToResponse("account is disabled"))
+}
+
+func (sut *PrincipalServiceTestSuite) Test04FindByAccessTokenIssuedBeforeRevocation() {
+	sut.T().Log("Test04FindByAccessTokenIssuedBeforeRevocation")
+	sut.user.SessionsRevokedAt = pgtype.Timestamptz{Valid: true, Time: sut.issuedAt.Add(time.Second)}
+	sut.userRepositoryMock.Mock.On("FindSessionById", sut.pool, sut.ctx, 1).Return(sut.user, nil)
+	httpCode, _, _ := sut.principalService.FindByAccessToken(sut.ctx, sut.claims)
+	sut.Equal(httpCode, http.StatusUnauthorized)
+}
+
+func (sut *PrincipalServiceTestSuite) Test05FindByAccessTokenIssuedAfterRevocation() {
+	sut.T().Log("Test05FindByAccessTokenIssuedAfterRevocation")
+	sut.user.SessionsRevokedAt = pgtype.Timestamptz{Valid: true, Time: sut.issuedAt.Add(-time.Second)}
+	sut.userRepositoryMock.Mock.On("FindSessionById", sut.pool, sut.ctx, 1).Return(sut.user, nil)
+	httpCode, principal, _ := sut.principalService.FindByAccessToken(sut.ctx, sut.claims)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(principal, helpers.Principal{Id: 1, Name: "John Doe", Email: "john@doe.com", Role: helpers.RoleAdmin})
+}
+
+func (sut *PrincipalServiceTestSuite) Test06FindByOauthAccessTokenGrantRevoked() {
+	sut.T().Log("Test06FindByOauthAccessTokenGrantRevoked")
+	sut.oauthGrantRepositoryMock.Mock.On("FindActiveById", sut.pool, sut.ctx, 7).Return(modelentities.OauthGrant{}, pgx.ErrNoRows)
+	httpCode, _, response := sut.principalService.FindByOauthAccessToken(sut.ctx, sut.oauthClaims)
+	sut.Equal(httpCode, http.StatusUnauthorized)
+	sut.Equal(response, helpers.ToResponse("Unauthorized"))
+	sut.userRepositoryMock.Mock.AssertNotCalled(sut.T(), "FindSessionById", sut.pool, sut.ctx, 1)
+}
+
+func (sut *PrincipalServiceTestSuite) Test07FindByOauthAccessTokenGrantOfAnotherClient() {
+	sut.T().Log("Test07FindByOauthAccessTokenGrantOfAnotherClient")
+	sut.oauthGrant.ClientId = pgtype.Text{Valid: true, String: "otherClientId"}
+	sut.oauthGrantRepositoryMock.Mock.On("FindActiveById", sut.pool, sut.ctx, 7).Return(sut.oauthGrant, nil)
+	httpCode, _, _ := sut.principalService.FindByOauthAccessToken(sut.ctx, sut.oauthClaims)
+	sut.Equal(httpCode, http.StatusUnauthorized)
+}
+
+func (sut *PrincipalServiceTestSuite) Test08FindByOauthAccessTokenDisabled() {
+	sut.T().Log("Test08FindByOauthAccessTokenDisabled")
+	sut.user.DisabledAt = pgtype.Timestamptz{Valid: true, Time: sut.issuedAt.Add(-time.Hour)}
+	sut.oauthGrantRepositoryMock.Mock.On("FindActiveById", sut.pool, sut.ctx, 7).Return(sut.oauthGrant, nil)
+	sut.userRepositoryMock.Mock.On("FindSessionById", sut.pool, sut.ctx, 1).Return(sut.user, nil)
+	httpCode, _, response := sut.principalService.FindByOauthAccessToken(sut.ctx, sut.oauthClaims)
+	sut.Equal(httpCode, http.StatusForbidden)
+	sut.Equal(response, helpers.ToResponse("account is disabled"))
+}
+
+func (sut *PrincipalServiceTestSuite) Test09FindByOauthAccessTokenSuccess() {
+	sut.T().Log("Test09FindByOauthAccessTokenSuccess")
+	sut.oauthGrantRepositoryMock.Mock.On("FindActiveById", sut.pool, sut.ctx, 7).Return(sut.oauthGrant, nil)
+	sut.userRepositoryMock.Mock.On("FindSessionById", sut.pool, sut.ctx, 1).Return(sut.user, nil)
+	httpCode, principal, _ := sut.principalService.FindByOauthAccessToken(sut.ctx, sut.oauthClaims)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(principal, helpers.Principal{
+		Id:       1,
+		Name:     "John Doe",
+		Email:    "john@doe.com",
+		ClientId: "clientId",
+		Scopes:   []string{"profile:read", "todos:read"},
+	})
+}
+
+func (sut *PrincipalServiceTestSuite) Test10FindByAccessTokenRevokedAndIssuedInTheSameSecond() {
+	sut.T().Log("Test10FindByAccessTokenRevokedAndIssuedInTheSameSecond")
+	sut.user.SessionsRevokedAt = pgtype.Timestamptz{Valid: true, Time: sut.issuedAt}
+	sut.userRepositoryMock.Mock.On("FindSessionById", sut.pool, sut.ctx, 1).Return(sut.user, nil)
+	httpCode, principal, _ := sut.principalService.FindByAccessToken(sut.ctx, sut.claims)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(principal.Id, 1)
+}
+
+func (sut *PrincipalServiceTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *PrincipalServiceTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *PrincipalServiceTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
@@ -0,0 +1,300 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	modelrequests "todo-list-api/models/requests"
+	modelresponses "todo-list-api/models/responses"
+	"todo-list-api/services"
+	mockhelpers "todo-list-api/test/unit_tests/helpers/mocks"
+	mockrepositories "todo-list-api/test/unit_tests/repositories/mocks"
+	mockutils "todo-list-api/test/unit_tests/utils/mocks"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ProfileServiceTestSuite struct {
+	suite.Suite
+	config                      config.Config
+	ctx                         context.Context
+	options                     pgx.TxOptions
+	errInternalServer           error
+	user                        modelentities.User
+	changePasswordRequest       modelrequests.ChangePasswordRequest
+	changeEmailRequest          modelrequests.ChangeEmailRequest
+	postgresUtilMock            *mockutils.PostgresUtilMock
+	validate                    *validator.Validate
+	userRepositoryMock          *mockrepositories.UserRepositoryMock
+	securityEventRepositoryMock *mockrepositories.SecurityEventRepositoryMock
+	passwordHasherMock          *mockhelpers.PasswordHasherMock
+	passwordPolicyHelperMock    *mockhelpers.PasswordPolicyHelperMock
+	jwtHelperMock               *mockhelpers.JwtHelperMock
+	mailerMock                  *mockhelpers.MailerMock
+	pgxTxMock                   *mockutils.PgxTxMock
+	profileService              services.ProfileService
+}
+
+func TestProfileTestSuite(t *testing.T) {
+	suite.Run(t, new(ProfileServiceTestSuite))
+}
+
+func (sut *ProfileServiceTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.ctx = helpers.ContextWithPrincipal(context.Background(), helpers.Principal{Id: 1, Name: "John Doe", Email: "john@doe.com"})
+	sut.errInternalServer = errors.New("internal server error")
+	sut.config = config.Default()
+}
+
+func (sut *ProfileServiceTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.user = modelentities.User{
+		Id:              pgtype.Int4{Valid: true, Int32: 1},
+		Name:            pgtype.Text{Valid: true, String: "John Doe"},
+		Email:           pgtype.Text{Valid: true, String: "john@doe.com"},
+		Password:        pgtype.Text{Valid: true, String: "passwordHash"},
+		EmailVerifiedAt: pgtype.Timestamptz{Valid: true, Time: time.Now()},
+	}
+	sut.changePasswordRequest = modelrequests.ChangePasswordRequest{
+		CurrentPassword: "password",
+		NewPassword:     "New1Password",
+	}
+	sut.changeEmailRequest = modelrequests.ChangeEmailRequest{
+		Email:    "jane@doe.com",
+		Password: "password",
+	}
+	sut.postgresUtilMock = new(mockutils.PostgresUtilMock)
+	sut.validate = validator.New()
+	sut.userRepositoryMock = new(mockrepositories.UserRepositoryMock)
+	sut.securityEventRepositoryMock = new(mockrepositories.SecurityEventRepositoryMock)
+	sut.securityEventRepositoryMock.Mock.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(1, nil)
+	sut.passwordHasherMock = new(mockhelpers.PasswordHasherMock)
+	sut.passwordPolicyHelperMock = new(mockhelpers.PasswordPolicyHelperMock)
+	sut.jwtHelperMock = new(mockhelpers.JwtHelperMock)
+	sut.mailerMock = new(mockhelpers.MailerMock)
+	sut.pgxTxMock = new(mockutils.PgxTxMock)
+	sut.profileService = services.NewProfileService(sut.postgresUtilMock, sut.validate, sut.userRepositoryMock, sut.passwordHasherMock, sut.passwordPolicyHelperMock, sut.jwtHelperMock, sut.mailerMock, sut.securityEventRepositoryMock, sut.config)
+}
+
+func (sut *ProfileServiceTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *ProfileServiceTestSuite) Test01GetWithoutPrincipal() {
+	sut.T().Log("Test01GetWithoutPrincipal")
+	httpCode, response := sut.profileService.Get(context.Background())
+	sut.Equal(httpCode, http.StatusInternalServerError)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *ProfileServiceTestSuite) Test02GetFindByIdError() {
+	sut.T().Log("Test02GetFindByIdError")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	var user modelentities.User
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(user, sut.errInternalServer)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer).Return(nil)
+	httpCode, response := sut.profileService.Get(sut.ctx)
+	sut.Equal(httpCode, http.StatusInternalServerError)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *ProfileServiceTestSuite) Test03GetSuccess() {
+	sut.T().Log("Test03GetSuccess")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, response := sut.profileService.Get(sut.ctx)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(response, modelresponses.ProfileResponse{
+		Id:               1,
+		Name:             "John Doe",
+		Email:            "john@doe.com",
+		EmailVerified:    true,
+		TwoFactorEnabled: false,
+	})
+}
+
+func (sut *ProfileServiceTestSuite) Test04UpdateValidationError() {
+	sut.T().Log("Test04UpdateValidationError")
+	httpCode, response := sut.profileService.Update(sut.ctx, modelrequests.UpdateProfileRequest{Name: strings.Repeat("a", 51)})
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *ProfileServiceTestSuite) Test05UpdateSuccess() {
+	sut.T().Log("Test05UpdateSuccess")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("UpdateName", sut.pgxTxMock, sut.ctx, "Johnny Doe", 1).Return(rowsAffected, nil)
+	sut.user.Name = pgtype.Text{Valid: true, String: "Johnny Doe"}
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, response := sut.profileService.Update(sut.ctx, modelrequests.UpdateProfileRequest{Name: "Johnny Doe"})
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(response.(modelresponses.ProfileResponse).Name, "Johnny Doe")
+}
+
+func (sut *ProfileServiceTestSuite) Test06ChangePasswordWrongCurrentPassword() {
+	sut.T().Log("Test06ChangePasswordWrongCurrentPassword")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", "passwordHash", "password").Return(false, helpers.ErrPasswordMismatch)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, helpers.ErrPasswordMismatch).Return(nil)
+	httpCode, accessToken, refreshToken, response := sut.profileService.ChangePassword(sut.ctx, sut.changePasswordRequest)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(accessToken, "")
+	sut.Equal(refreshToken, "")
+	sut.Equal(response, helpers.ToResponse("wrong password"))
+	sut.userRepositoryMock.Mock.AssertNotCalled(sut.T(), "UpdatePassword", sut.pgxTxMock, sut.ctx, mock.Anything, 1)
+}
+
+func (sut *ProfileServiceTestSuite) Test07ChangePasswordPolicyViolation() {
+	sut.T().Log("Test07ChangePasswordPolicyViolation")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", "passwordHash", "password").Return(false, nil)
+	violations := []modelresponses.PasswordPolicyViolation{{Code: "too_short", Message: "password must be at least 8 characters"}}
+	sut.passwordPolicyHelperMock.Mock.On("Check", "New1Password", "John Doe", "john@doe.com").Return(violations, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, _, _, response := sut.profileService.ChangePassword(sut.ctx, sut.changePasswordRequest)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(response.(modelresponses.PasswordPolicyResponse).Violations, violations)
+}
+
+func (sut *ProfileServiceTestSuite) Test08ChangePasswordSuccessRevokesOtherSessions() {
+	sut.T().Log("Test08ChangePasswordSuccessRevokesOtherSessions")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", "passwordHash", "password").Return(false, nil)
+	sut.passwordPolicyHelperMock.Mock.On("Check", "New1Password", "John Doe", "john@doe.com").Return([]modelresponses.PasswordPolicyViolation(nil), nil)
+	sut.passwordHasherMock.Mock.On("Hash", "New1Password").Return("newPasswordHash", nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("UpdatePassword", sut.pgxTxMock, sut.ctx, "newPasswordHash", 1).Return(rowsAffected, nil)
+	sut.userRepositoryMock.Mock.On("RevokeSessions", sut.pgxTxMock, sut.ctx, 1).Return(rowsAffected, nil)
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", 1, "John Doe", "john@doe.com", 15).Return("accessToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", 1, 1).Return("refreshToken", nil)
+	sut.userRepositoryMock.Mock.On("UpdateRefreshToken", sut.pgxTxMock, sut.ctx, "refreshToken", 1).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, accessToken, refreshToken, response := sut.profileService.ChangePassword(sut.ctx, sut.changePasswordRequest)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(accessToken, "accessToken")
+	sut.Equal(refreshToken, "refreshToken")
+	sut.NotEqual(response, nil)
+	sut.userRepositoryMock.Mock.AssertCalled(sut.T(), "RevokeSessions", sut.pgxTxMock, sut.ctx, 1)
+	sut.userRepositoryMock.Mock.AssertCalled(sut.T(), "UpdateRefreshToken", sut.pgxTxMock, sut.ctx, "refreshToken", 1)
+}
+
+func (sut *ProfileServiceTestSuite) Test09ChangeEmailWrongPassword() {
+	sut.T().Log("Test09ChangeEmailWrongPassword")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", "passwordHash", "password").Return(false, helpers.ErrPasswordMismatch)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, helpers.ErrPasswordMismatch).Return(nil)
+	httpCode, response := sut.profileService.ChangeEmail(sut.ctx, sut.changeEmailRequest)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *ProfileServiceTestSuite) Test10ChangeEmailAlreadyRegistered() {
+	sut.T().Log("Test10ChangeEmailAlreadyRegistered")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", "passwordHash", "password").Return(false, nil)
+	otherUser := modelentities.User{Id: pgtype.Int4{Valid: true, Int32: 2}}
+	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, "jane@doe.com").Return(otherUser, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, response := sut.profileService.ChangeEmail(sut.ctx, sut.changeEmailRequest)
+	sut.Equal(httpCode, http.StatusConflict)
+	sut.NotEqual(response, nil)
+	sut.userRepositoryMock.Mock.AssertNotCalled(sut.T(), "UpdateEmail", sut.pgxTxMock, sut.ctx, "jane@doe.com", 1)
+}
+
+func (sut *ProfileServiceTestSuite) Test11ChangeEmailSuccess() {
+	sut.T().Log("Test11ChangeEmailSuccess")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", "passwordHash", "password").Return(false, nil)
+	var user modelentities.User
+	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, "jane@doe.com").Return(user, pgx.ErrNoRows)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("UpdateEmail", sut.pgxTxMock, sut.ctx, "jane@doe.com", 1).Return(rowsAffected, nil)
+	sut.jwtHelperMock.Mock.On("GenerateEmailVerificationToken", 1, "jane@doe.com", 1440).Return("emailVerificationToken", nil)
+	sut.mailerMock.Mock.On("Send", "jane@doe.com", "Verify your email", mock.Anything).Return(nil)
+	sut.mailerMock.Mock.On("Send", "john@doe.com", "Your email was changed", mock.Anything).Return(nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, response := sut.profileService.ChangeEmail(sut.ctx, sut.changeEmailRequest)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.NotEqual(response, nil)
+	sut.mailerMock.Mock.AssertCalled(sut.T(), "Send", "jane@doe.com", "Verify your email", mock.Anything)
+	sut.mailerMock.Mock.AssertCalled(sut.T(), "Send", "john@doe.com", "Your email was changed", mock.Anything)
+}
+
+func (sut *ProfileServiceTestSuite) Test12ChangePasswordRefusesOlderAccessTokens() {
+	sut.T().Log("Test12ChangePasswordRefusesOlderAccessTokens")
+	jwtConfig := sut.config.Jwt
+	jwtConfig.Secret = "secret"
+	jwtHelper := helpers.NewJwtHelper(jwtConfig)
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", "passwordHash", "password").Return(false, nil)
+	sut.passwordPolicyHelperMock.Mock.On("Check", "New1Password", "John Doe", "john@doe.com").Return([]modelresponses.PasswordPolicyViolation(nil), nil)
+	sut.passwordHasherMock.Mock.On("Hash", "New1Password").Return("newPasswordHash", nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("UpdatePassword", sut.pgxTxMock, sut.ctx, "newPasswordHash", 1).Return(rowsAffected, nil)
+	var sessionsRevokedAt time.Time
+	sut.userRepositoryMock.Mock.On("RevokeSessions", sut.pgxTxMock, sut.ctx, 1).Return(rowsAffected, nil).Run(func(arguments mock.Arguments) {
+		sessionsRevokedAt = time.Now().Truncate(time.Second)
+	})
+	sut.userRepositoryMock.Mock.On("UpdateRefreshToken", sut.pgxTxMock, sut.ctx, mock.Anything, 1).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	profileService := services.NewProfileService(sut.postgresUtilMock, sut.validate, sut.userRepositoryMock, sut.passwordHasherMock, sut.passwordPolicyHelperMock, jwtHelper, sut.mailerMock, sut.securityEventRepositoryMock, sut.config)
+	httpCode, accessToken, _, _ := profileService.ChangePassword(sut.ctx, sut.changePasswordRequest)
+	sut.Require().Equal(httpCode, http.StatusOK)
+
+	pool := &pgxpool.Pool{}
+	revokedUser := sut.user
+	revokedUser.SessionsRevokedAt = pgtype.Timestamptz{Valid: true, Time: sessionsRevokedAt}
+	postgresUtilMock := new(mockutils.PostgresUtilMock)
+	postgresUtilMock.Mock.On("GetPool").Return(pool)
+	userRepositoryMock := new(mockrepositories.UserRepositoryMock)
+	userRepositoryMock.Mock.On("FindSessionById", pool, sut.ctx, 1).Return(revokedUser, nil)
+	principalService := services.NewPrincipalService(postgresUtilMock, userRepositoryMock, new(mockrepositories.OauthGrantRepositoryMock))
+
+	claims, err := jwtHelper.ParseAccessToken(accessToken)
+	sut.Require().NoError(err)
+	httpCode, _, _ = principalService.FindByAccessToken(sut.ctx, claims)
+	sut.Equal(httpCode, http.StatusOK)
+	// a token of the second before the change
+	olderClaims := *claims
+	olderClaims.IssuedAt = jwt.NewNumericDate(sessionsRevokedAt.Add(-time.Second))
+	httpCode, _, _ = principalService.FindByAccessToken(sut.ctx, &olderClaims)
+	sut.Equal(httpCode, http.StatusUnauthorized)
+}
+
+func (sut *ProfileServiceTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *ProfileServiceTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *ProfileServiceTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
@@ -0,0 +1,160 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	modelrequests "todo-list-api/models/requests"
+	modelresponses "todo-list-api/models/responses"
+	"todo-list-api/services"
+	mockrepositories "todo-list-api/test/unit_tests/repositories/mocks"
+	mockutils "todo-list-api/test/unit_tests/utils/mocks"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type SecurityEventServiceTestSuite struct {
+	suite.Suite
+	ctx                         context.Context
+	pool                        *pgxpool.Pool
+	errInternalServer           error
+	securityEvent               modelentities.SecurityEvent
+	postgresUtilMock            *mockutils.PostgresUtilMock
+	securityEventRepositoryMock *mockrepositories.SecurityEventRepositoryMock
+	securityEventService        services.SecurityEventService
+}
+
+func TestSecurityEventTestSuite(t *testing.T) {
+	suite.Run(t, new(SecurityEventServiceTestSuite))
+}
+
+func (sut *SecurityEventServiceTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.ctx = helpers.ContextWithPrincipal(context.Background(), helpers.Principal{Id: 1})
+	sut.pool = &pgxpool.Pool{}
+	sut.errInternalServer = errors.New("internal server error")
+}
+
+func (sut *SecurityEventServiceTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.securityEvent = modelentities.SecurityEvent{
+		Id:        pgtype.Int4{Valid: true, Int32: 7},
+		UserId:    pgtype.Int4{Valid: true, Int32: 1},
+		EventType: pgtype.Text{Valid: true, String: helpers.SecurityEventLoginSucceeded},
+		Email:     pgtype.Text{Valid: true, String: "john@doe.com"},
+		Detail:    pgtype.Text{Valid: true, String: "password"},
+		IpAddress: pgtype.Text{Valid: true, String: "10.0.0.1"},
+		UserAgent: pgtype.Text{Valid: true, String: "curl/8.0"},
+		CreatedAt: pgtype.Timestamptz{Valid: true, Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	sut.postgresUtilMock = new(mockutils.PostgresUtilMock)
+	sut.securityEventRepositoryMock = new(mockrepositories.SecurityEventRepositoryMock)
+	sut.securityEventService = services.NewSecurityEventService(sut.postgresUtilMock, sut.securityEventRepositoryMock)
+}
+
+func (sut *SecurityEventServiceTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *SecurityEventServiceTestSuite) Test01FindMineInvalidPage() {
+	sut.T().Log("Test01FindMineInvalidPage")
+	httpCode, response := sut.securityEventService.FindMine(sut.ctx, 0, 20)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *SecurityEventServiceTestSuite) Test02FindMineOnlyListsOwnEvents() {
+	sut.T().Log("Test02FindMineOnlyListsOwnEvents")
+	securityEventFilter := modelentities.SecurityEventFilter{UserId: 1}
+	sut.postgresUtilMock.Mock.On("GetPool").Return(sut.pool)
+	sut.securityEventRepositoryMock.Mock.On("FindByFilterWithPagination", sut.pool, sut.ctx, securityEventFilter, 20, 20).Return([]modelentities.SecurityEvent{sut.securityEvent}, nil)
+	sut.securityEventRepositoryMock.Mock.On("CountByFilter", sut.pool, sut.ctx, securityEventFilter).Return(21, nil)
+	httpCode, response := sut.securityEventService.FindMine(sut.ctx, 2, 20)
+	sut.Equal(httpCode, http.StatusOK)
+	userId := 1
+	sut.Equal(response, modelresponses.GetSecurityEventResponse{
+		Data: []modelresponses.SecurityEventResponse{{
+			Id:        7,
+			UserId:    &userId,
+			EventType: helpers.SecurityEventLoginSucceeded,
+			Email:     "john@doe.com",
+			Detail:    "password",
+			IpAddress: "10.0.0.1",
+			UserAgent: "curl/8.0",
+			CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		}},
+		Page:  2,
+		Limit: 20,
+		Total: 21,
+	})
+}
+
+func (sut *SecurityEventServiceTestSuite) Test03FindMineRepositoryError() {
+	sut.T().Log("Test03FindMineRepositoryError")
+	sut.postgresUtilMock.Mock.On("GetPool").Return(sut.pool)
+	sut.securityEventRepositoryMock.Mock.On("FindByFilterWithPagination", sut.pool, sut.ctx, mock.Anything, 0, 20).Return([]modelentities.SecurityEvent{}, sut.errInternalServer)
+	httpCode, response := sut.securityEventService.FindMine(sut.ctx, 1, 20)
+	sut.Equal(httpCode, http.StatusInternalServerError)
+	sut.Equal(response, helpers.ToResponse(sut.errInternalServer.Error()))
+}
+
+func (sut *SecurityEventServiceTestSuite) Test04FindAllUnknownEventType() {
+	sut.T().Log("Test04FindAllUnknownEventType")
+	httpCode, response := sut.securityEventService.FindAll(sut.ctx, modelrequests.FindSecurityEventsRequest{EventType: "unknown", Page: 1, Limit: 20})
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(response, helpers.ToResponse("unknown event type unknown"))
+}
+
+func (sut *SecurityEventServiceTestSuite) Test05FindAllInvalidFrom() {
+	sut.T().Log("Test05FindAllInvalidFrom")
+	httpCode, response := sut.securityEventService.FindAll(sut.ctx, modelrequests.FindSecurityEventsRequest{From: "yesterday", Page: 1, Limit: 20})
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(response, helpers.ToResponse("from must be an RFC 3339 time"))
+}
+
+func (sut *SecurityEventServiceTestSuite) Test06FindAllWithFilter() {
+	sut.T().Log("Test06FindAllWithFilter")
+	securityEventFilter := modelentities.SecurityEventFilter{
+		UserId:    1,
+		EventType: helpers.SecurityEventLoginFailed,
+		IpAddress: "10.0.0.1",
+		From:      pgtype.Timestamptz{Valid: true, Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	sut.postgresUtilMock.Mock.On("GetPool").Return(sut.pool)
+	sut.securityEventRepositoryMock.Mock.On("FindByFilterWithPagination", sut.pool, sut.ctx, securityEventFilter, 0, 50).Return([]modelentities.SecurityEvent{}, nil)
+	sut.securityEventRepositoryMock.Mock.On("CountByFilter", sut.pool, sut.ctx, securityEventFilter).Return(0, nil)
+	httpCode, response := sut.securityEventService.FindAll(sut.ctx, modelrequests.FindSecurityEventsRequest{
+		UserId:    1,
+		EventType: helpers.SecurityEventLoginFailed,
+		IpAddress: "10.0.0.1",
+		From:      "2024-01-01T00:00:00Z",
+		Page:      1,
+		Limit:     50,
+	})
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(response, modelresponses.GetSecurityEventResponse{
+		Data:  []modelresponses.SecurityEventResponse{},
+		Page:  1,
+		Limit: 50,
+		Total: 0,
+	})
+}
+
+func (sut *SecurityEventServiceTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *SecurityEventServiceTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *SecurityEventServiceTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
@@ -0,0 +1,444 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	modelentities "todo-list-api/models/entities"
+	modelrequests "todo-list-api/models/requests"
+	modelresponses "todo-list-api/models/responses"
+	"todo-list-api/services"
+	mockhelpers "todo-list-api/test/unit_tests/helpers/mocks"
+	mockrepositories "todo-list-api/test/unit_tests/repositories/mocks"
+	mockutils "todo-list-api/test/unit_tests/utils/mocks"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type TwoFactorServiceTestSuite struct {
+	suite.Suite
+	metricsHelper               helpers.MetricsHelper
+	config                      config.Config
+	ctx                         context.Context
+	principalCtx                context.Context
+	options                     pgx.TxOptions
+	errInternalServer           error
+	user                        modelentities.User
+	mfaClaims                   *helpers.MfaPendingTokenCustomClaims
+	postgresUtilMock            *mockutils.PostgresUtilMock
+	validate                    *validator.Validate
+	userRepositoryMock          *mockrepositories.UserRepositoryMock
+	securityEventRepositoryMock *mockrepositories.SecurityEventRepositoryMock
+	recoveryCodeRepositoryMock  *mockrepositories.RecoveryCodeRepositoryMock
+	loginAttemptRepositoryMock  *mockrepositories.LoginAttemptRepositoryMock
+	mfaChallengeRepositoryMock  *mockrepositories.MfaChallengeRepositoryMock
+	passwordHasherMock          *mockhelpers.PasswordHasherMock
+	jwtHelperMock               *mockhelpers.JwtHelperMock
+	totpHelperMock              *mockhelpers.TotpHelperMock
+	pgxTxMock                   *mockutils.PgxTxMock
+	twoFactorService            services.TwoFactorService
+}
+
+func TestTwoFactorTestSuite(t *testing.T) {
+	suite.Run(t, new(TwoFactorServiceTestSuite))
+}
+
+func (sut *TwoFactorServiceTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.ctx = context.Background()
+	sut.principalCtx = helpers.ContextWithPrincipal(sut.ctx, helpers.Principal{Id: 1})
+	sut.errInternalServer = errors.New("internal server error")
+	sut.config = config.Default()
+}
+
+func (sut *TwoFactorServiceTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.metricsHelper = helpers.NewMetricsHelper()
+	sut.user = modelentities.User{
+		Id:       pgtype.Int4{Valid: true, Int32: 1},
+		Name:     pgtype.Text{Valid: true, String: "John Doe"},
+		Email:    pgtype.Text{Valid: true, String: "john@doe.com"},
+		Password: pgtype.Text{Valid: true, String: "password"},
+	}
+	sut.postgresUtilMock = new(mockutils.PostgresUtilMock)
+	sut.validate = validator.New()
+	sut.userRepositoryMock = new(mockrepositories.UserRepositoryMock)
+	sut.securityEventRepositoryMock = new(mockrepositories.SecurityEventRepositoryMock)
+	sut.securityEventRepositoryMock.Mock.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(1, nil)
+	sut.mfaClaims = &helpers.MfaPendingTokenCustomClaims{Id: 1, RegisteredClaims: jwt.RegisteredClaims{ID: "jti", ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute))}}
+	sut.recoveryCodeRepositoryMock = new(mockrepositories.RecoveryCodeRepositoryMock)
+	sut.loginAttemptRepositoryMock = new(mockrepositories.LoginAttemptRepositoryMock)
+	sut.mfaChallengeRepositoryMock = new(mockrepositories.MfaChallengeRepositoryMock)
+	sut.passwordHasherMock = new(mockhelpers.PasswordHasherMock)
+	sut.jwtHelperMock = new(mockhelpers.JwtHelperMock)
+	sut.totpHelperMock = new(mockhelpers.TotpHelperMock)
+	sut.pgxTxMock = new(mockutils.PgxTxMock)
+	sut.twoFactorService = services.NewTwoFactorService(sut.postgresUtilMock, sut.validate, sut.userRepositoryMock, sut.recoveryCodeRepositoryMock, sut.loginAttemptRepositoryMock, sut.mfaChallengeRepositoryMock, sut.passwordHasherMock, sut.jwtHelperMock, sut.totpHelperMock, sut.securityEventRepositoryMock, sut.metricsHelper, sut.config)
+}
+
+func (sut *TwoFactorServiceTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *TwoFactorServiceTestSuite) Test01EnrollAlreadyEnabled() {
+	sut.T().Log("Test01EnrollAlreadyEnabled")
+	sut.user.TotpEnabledAt = pgtype.Timestamptz{Valid: true, Time: time.Now()}
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.principalCtx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.principalCtx, 1).Return(sut.user, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.principalCtx, nil).Return(nil)
+	httpCode, response := sut.twoFactorService.Enroll(sut.principalCtx)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *TwoFactorServiceTestSuite) Test02EnrollSuccess() {
+	sut.T().Log("Test02EnrollSuccess")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.principalCtx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.principalCtx, 1).Return(sut.user, nil)
+	sut.totpHelperMock.Mock.On("GenerateSecret").Return("SECRET", nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("UpdateTotpSecret", sut.pgxTxMock, sut.principalCtx, "SECRET", 1).Return(rowsAffected, nil)
+	sut.totpHelperMock.Mock.On("GenerateUri", "SECRET", sut.user.Email.String).Return("otpauth://totp/todo-list-api:john@doe.com?secret=SECRET")
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.principalCtx, nil).Return(nil)
+	httpCode, response := sut.twoFactorService.Enroll(sut.principalCtx)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(response, modelresponses.EnrollTwoFactorResponse{
+		Secret:     "SECRET",
+		OtpauthUri: "otpauth://totp/todo-list-api:john@doe.com?secret=SECRET",
+	})
+}
+
+func (sut *TwoFactorServiceTestSuite) Test03ConfirmWrongCode() {
+	sut.T().Log("Test03ConfirmWrongCode")
+	sut.user.TotpSecret = pgtype.Text{Valid: true, String: "SECRET"}
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.principalCtx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.principalCtx, 1).Return(sut.user, nil)
+	sut.totpHelperMock.Mock.On("Validate", "SECRET", "123456", mock.Anything).Return(int64(0), false)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.principalCtx, nil).Return(nil)
+	httpCode, response := sut.twoFactorService.Confirm(sut.principalCtx, modelrequests.ConfirmTwoFactorRequest{Code: "123456"})
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.NotEqual(response, nil)
+	sut.userRepositoryMock.Mock.AssertNotCalled(sut.T(), "EnableTotp", sut.pgxTxMock, sut.principalCtx, 1)
+}
+
+func (sut *TwoFactorServiceTestSuite) Test04ConfirmSuccessStoresHashedRecoveryCodes() {
+	sut.T().Log("Test04ConfirmSuccessStoresHashedRecoveryCodes")
+	sut.user.TotpSecret = pgtype.Text{Valid: true, String: "SECRET"}
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.principalCtx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.principalCtx, 1).Return(sut.user, nil)
+	sut.totpHelperMock.Mock.On("Validate", "SECRET", "123456", mock.Anything).Return(int64(100), true)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("UpdateTotpLastUsedStep", sut.pgxTxMock, sut.principalCtx, int64(100), 1).Return(rowsAffected, nil)
+	sut.userRepositoryMock.Mock.On("EnableTotp", sut.pgxTxMock, sut.principalCtx, 1).Return(rowsAffected, nil)
+	sut.totpHelperMock.Mock.On("GenerateRecoveryCodes").Return([]string{"aaaaaaaa-bbbbbbbb"}, nil)
+	sut.recoveryCodeRepositoryMock.Mock.On("DeleteByUserId", sut.pgxTxMock, sut.principalCtx, 1).Return(rowsAffected, nil)
+	recoveryCode := modelentities.RecoveryCode{
+		UserId:   pgtype.Int4{Valid: true, Int32: 1},
+		CodeHash: pgtype.Text{Valid: true, String: helpers.HashToken("aaaaaaaa-bbbbbbbb")},
+	}
+	sut.recoveryCodeRepositoryMock.Mock.On("Create", sut.pgxTxMock, sut.principalCtx, recoveryCode).Return(1, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.principalCtx, nil).Return(nil)
+	httpCode, response := sut.twoFactorService.Confirm(sut.principalCtx, modelrequests.ConfirmTwoFactorRequest{Code: "123456"})
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(response.(modelresponses.ConfirmTwoFactorResponse).RecoveryCodes, []string{"aaaaaaaa-bbbbbbbb"})
+	sut.securityEventRepositoryMock.Mock.AssertCalled(sut.T(), "Create", sut.pgxTxMock, sut.principalCtx, mock.MatchedBy(func(securityEvent modelentities.SecurityEvent) bool {
+		return securityEvent.EventType.String == helpers.SecurityEventTwoFactorEnabled && securityEvent.UserId.Int32 == 1
+	}))
+}
+
+func (sut *TwoFactorServiceTestSuite) Test05DisableWrongPassword() {
+	sut.T().Log("Test05DisableWrongPassword")
+	sut.user.TotpSecret = pgtype.Text{Valid: true, String: "SECRET"}
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.principalCtx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.principalCtx, 1).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", sut.user.Password.String, "wrong").Return(false, helpers.ErrPasswordMismatch)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.principalCtx, helpers.ErrPasswordMismatch).Return(nil)
+	httpCode, response := sut.twoFactorService.Disable(sut.principalCtx, modelrequests.DisableTwoFactorRequest{Password: "wrong"})
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.NotEqual(response, nil)
+	sut.userRepositoryMock.Mock.AssertNotCalled(sut.T(), "DisableTotp", sut.pgxTxMock, sut.principalCtx, 1)
+}
+
+func (sut *TwoFactorServiceTestSuite) Test06LoginInvalidMfaToken() {
+	sut.T().Log("Test06LoginInvalidMfaToken")
+	var claims *helpers.MfaPendingTokenCustomClaims
+	sut.jwtHelperMock.Mock.On("ParseMfaPendingToken", "accessToken").Return(claims, sut.errInternalServer)
+	httpCode, accessToken, refreshToken, response := sut.twoFactorService.Login(sut.ctx, modelrequests.LoginTwoFactorRequest{MfaToken: "accessToken", Code: "123456"})
+	sut.Equal(httpCode, http.StatusUnauthorized)
+	sut.Equal(accessToken, "")
+	sut.Equal(refreshToken, "")
+	sut.NotEqual(response, nil)
+}
+
+func (sut *TwoFactorServiceTestSuite) Test07LoginReplayedCode() {
+	sut.T().Log("Test07LoginReplayedCode")
+	sut.user.TotpSecret = pgtype.Text{Valid: true, String: "SECRET"}
+	sut.user.TotpEnabledAt = pgtype.Timestamptz{Valid: true, Time: time.Now()}
+	sut.jwtHelperMock.Mock.On("ParseMfaPendingToken", "mfaToken").Return(sut.mfaClaims, nil)
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.expectMfaChallenge(modelentities.MfaChallenge{})
+	sut.totpHelperMock.Mock.On("Validate", "SECRET", "123456", mock.Anything).Return(int64(100), true)
+	var rowsAffected int64
+	sut.userRepositoryMock.Mock.On("UpdateTotpLastUsedStep", sut.pgxTxMock, sut.ctx, int64(100), 1).Return(rowsAffected, nil)
+	sut.mfaChallengeRepositoryMock.Mock.On("IncrementFailedCount", sut.pgxTxMock, sut.ctx, "jti").Return(1, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("IncrementFailedCount", sut.pgxTxMock, sut.ctx, "email:john@doe.com", sut.config.Login.AttemptWindow).Return(1, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, accessToken, refreshToken, response := sut.twoFactorService.Login(sut.ctx, modelrequests.LoginTwoFactorRequest{MfaToken: "mfaToken", Code: "123456"})
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(accessToken, "")
+	sut.Equal(refreshToken, "")
+	sut.NotEqual(response, nil)
+	sut.securityEventRepositoryMock.Mock.AssertCalled(sut.T(), "Create", sut.pgxTxMock, sut.ctx, mock.MatchedBy(func(securityEvent modelentities.SecurityEvent) bool {
+		return securityEvent.EventType.String == helpers.SecurityEventLoginFailed && securityEvent.Detail.String == "reused_code"
+	}))
+}
+
+func (sut *TwoFactorServiceTestSuite) Test08LoginRecoveryCodeSuccess() {
+	sut.T().Log("Test08LoginRecoveryCodeSuccess")
+	sut.user.TotpSecret = pgtype.Text{Valid: true, String: "SECRET"}
+	sut.user.TotpEnabledAt = pgtype.Timestamptz{Valid: true, Time: time.Now()}
+	sut.jwtHelperMock.Mock.On("ParseMfaPendingToken", "mfaToken").Return(sut.mfaClaims, nil)
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.expectMfaChallenge(modelentities.MfaChallenge{})
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.recoveryCodeRepositoryMock.Mock.On("UpdateUsedAtByUserIdAndCodeHash", sut.pgxTxMock, sut.ctx, 1, helpers.HashToken("aaaaaaaa-bbbbbbbb")).Return(rowsAffected, nil)
+	sut.mfaChallengeRepositoryMock.Mock.On("UpdateUsedAt", sut.pgxTxMock, sut.ctx, "jti").Return(rowsAffected, nil)
+	sut.mfaChallengeRepositoryMock.Mock.On("DeleteExpiredByUserId", sut.pgxTxMock, sut.ctx, 1).Return(rowsAffected, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("DeleteByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(rowsAffected, nil)
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", 1, sut.user.Name.String, sut.user.Email.String, 15).Return("accessToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", 1, 1).Return("refreshToken", nil)
+	sut.userRepositoryMock.Mock.On("UpdateRefreshToken", sut.pgxTxMock, sut.ctx, "refreshToken", 1).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, accessToken, refreshToken, response := sut.twoFactorService.Login(sut.ctx, modelrequests.LoginTwoFactorRequest{MfaToken: "mfaToken", RecoveryCode: " AAAAAAAA-BBBBBBBB "})
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(accessToken, "accessToken")
+	sut.Equal(refreshToken, "refreshToken")
+	sut.NotEqual(response, nil)
+	sut.securityEventRepositoryMock.Mock.AssertCalled(sut.T(), "Create", sut.pgxTxMock, sut.ctx, mock.MatchedBy(func(securityEvent modelentities.SecurityEvent) bool {
+		return securityEvent.EventType.String == helpers.SecurityEventLoginSucceeded && securityEvent.Detail.String == "recovery_code"
+	}))
+	sut.mfaChallengeRepositoryMock.Mock.AssertCalled(sut.T(), "UpdateUsedAt", sut.pgxTxMock, sut.ctx, "jti")
+}
+
+func (sut *TwoFactorServiceTestSuite) Test09LoginDisabledAccount() {
+	sut.T().Log("Test09LoginDisabledAccount")
+	sut.user.TotpSecret = pgtype.Text{Valid: true, String: "SECRET"}
+	sut.user.TotpEnabledAt = pgtype.Timestamptz{Valid: true, Time: time.Now()}
+	sut.user.DisabledAt = pgtype.Timestamptz{Valid: true, Time: time.Now()}
+	sut.jwtHelperMock.Mock.On("ParseMfaPendingToken", "mfaToken").Return(sut.mfaClaims, nil)
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, accessToken, refreshToken, response := sut.twoFactorService.Login(sut.ctx, modelrequests.LoginTwoFactorRequest{MfaToken: "mfaToken", Code: "123456"})
+	sut.Equal(httpCode, http.StatusForbidden)
+	sut.Equal(accessToken, "")
+	sut.Equal(refreshToken, "")
+	sut.Equal(response, helpers.ToResponse("account is disabled"))
+	sut.totpHelperMock.Mock.AssertNotCalled(sut.T(), "Validate", "SECRET", "123456", mock.Anything)
+}
+
+func (sut *TwoFactorServiceTestSuite) Test10DisableSuccess() {
+	sut.T().Log("Test10DisableSuccess")
+	sut.user.TotpSecret = pgtype.Text{Valid: true, String: "SECRET"}
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.principalCtx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.principalCtx, 1).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", sut.user.Password.String, "password").Return(false, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("DisableTotp", sut.pgxTxMock, sut.principalCtx, 1).Return(rowsAffected, nil)
+	sut.recoveryCodeRepositoryMock.Mock.On("DeleteByUserId", sut.pgxTxMock, sut.principalCtx, 1).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.principalCtx, nil).Return(nil)
+	httpCode, response := sut.twoFactorService.Disable(sut.principalCtx, modelrequests.DisableTwoFactorRequest{Password: "password"})
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(response, helpers.ToResponse("two factor authentication disabled"))
+	sut.securityEventRepositoryMock.Mock.AssertCalled(sut.T(), "Create", sut.pgxTxMock, sut.principalCtx, mock.MatchedBy(func(securityEvent modelentities.SecurityEvent) bool {
+		return securityEvent.EventType.String == helpers.SecurityEventTwoFactorDisabled && securityEvent.UserId.Int32 == 1
+	}))
+}
+
+func (sut *TwoFactorServiceTestSuite) Test11LoginWrongCodeCountsFailure() {
+	sut.T().Log("Test11LoginWrongCodeCountsFailure")
+	sut.user.TotpSecret = pgtype.Text{Valid: true, String: "SECRET"}
+	sut.user.TotpEnabledAt = pgtype.Timestamptz{Valid: true, Time: time.Now()}
+	sut.jwtHelperMock.Mock.On("ParseMfaPendingToken", "mfaToken").Return(sut.mfaClaims, nil)
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.expectMfaChallenge(modelentities.MfaChallenge{})
+	sut.totpHelperMock.Mock.On("Validate", "SECRET", "000000", mock.Anything).Return(int64(0), false)
+	sut.mfaChallengeRepositoryMock.Mock.On("IncrementFailedCount", sut.pgxTxMock, sut.ctx, "jti").Return(1, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("IncrementFailedCount", sut.pgxTxMock, sut.ctx, "email:john@doe.com", sut.config.Login.AttemptWindow).Return(sut.config.Login.MaxAttempts, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.loginAttemptRepositoryMock.Mock.On("UpdateLockedUntil", sut.pgxTxMock, sut.ctx, "email:john@doe.com", sut.config.Login.LockoutTime).Return(rowsAffected, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("CreateLockoutEvent", sut.pgxTxMock, sut.ctx, mock.Anything).Return(1, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, accessToken, refreshToken, response := sut.twoFactorService.Login(sut.ctx, modelrequests.LoginTwoFactorRequest{MfaToken: "mfaToken", Code: "000000"})
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(accessToken, "")
+	sut.Equal(refreshToken, "")
+	sut.Equal(response, helpers.ToResponse("wrong code"))
+	sut.mfaChallengeRepositoryMock.Mock.AssertCalled(sut.T(), "IncrementFailedCount", sut.pgxTxMock, sut.ctx, "jti")
+	sut.loginAttemptRepositoryMock.Mock.AssertCalled(sut.T(), "UpdateLockedUntil", sut.pgxTxMock, sut.ctx, "email:john@doe.com", sut.config.Login.LockoutTime)
+	sut.securityEventRepositoryMock.Mock.AssertCalled(sut.T(), "Create", sut.pgxTxMock, sut.ctx, mock.MatchedBy(func(securityEvent modelentities.SecurityEvent) bool {
+		return securityEvent.EventType.String == helpers.SecurityEventAccountLocked && securityEvent.UserId.Int32 == 1
+	}))
+}
+
+func (sut *TwoFactorServiceTestSuite) Test12LoginLocked() {
+	sut.T().Log("Test12LoginLocked")
+	sut.user.TotpSecret = pgtype.Text{Valid: true, String: "SECRET"}
+	sut.user.TotpEnabledAt = pgtype.Timestamptz{Valid: true, Time: time.Now()}
+	sut.jwtHelperMock.Mock.On("ParseMfaPendingToken", "mfaToken").Return(sut.mfaClaims, nil)
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	loginAttempt := modelentities.LoginAttempt{LockedUntil: pgtype.Timestamptz{Valid: true, Time: time.Now().Add(time.Minute)}}
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(loginAttempt, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, accessToken, refreshToken, response := sut.twoFactorService.Login(sut.ctx, modelrequests.LoginTwoFactorRequest{MfaToken: "mfaToken", Code: "123456"})
+	sut.Equal(httpCode, http.StatusTooManyRequests)
+	sut.Equal(accessToken, "")
+	sut.Equal(refreshToken, "")
+	sut.True(response.(modelresponses.RetryAfterResponse).RetryAfter > 0)
+	sut.totpHelperMock.Mock.AssertNotCalled(sut.T(), "Validate", "SECRET", "123456", mock.Anything)
+}
+
+func (sut *TwoFactorServiceTestSuite) Test13LoginUsedMfaToken() {
+	sut.T().Log("Test13LoginUsedMfaToken")
+	sut.user.TotpSecret = pgtype.Text{Valid: true, String: "SECRET"}
+	sut.user.TotpEnabledAt = pgtype.Timestamptz{Valid: true, Time: time.Now()}
+	sut.jwtHelperMock.Mock.On("ParseMfaPendingToken", "mfaToken").Return(sut.mfaClaims, nil)
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.expectMfaChallenge(modelentities.MfaChallenge{UsedAt: pgtype.Timestamptz{Valid: true, Time: time.Now()}})
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, accessToken, refreshToken, response := sut.twoFactorService.Login(sut.ctx, modelrequests.LoginTwoFactorRequest{MfaToken: "mfaToken", Code: "123456"})
+	sut.Equal(httpCode, http.StatusUnauthorized)
+	sut.Equal(accessToken, "")
+	sut.Equal(refreshToken, "")
+	sut.Equal(response, helpers.ToResponse("invalid or expired mfa token"))
+	sut.totpHelperMock.Mock.AssertNotCalled(sut.T(), "Validate", "SECRET", "123456", mock.Anything)
+}
+
+func (sut *TwoFactorServiceTestSuite) Test14LoginTooManyWrongCodes() {
+	sut.T().Log("Test14LoginTooManyWrongCodes")
+	sut.user.TotpSecret = pgtype.Text{Valid: true, String: "SECRET"}
+	sut.user.TotpEnabledAt = pgtype.Timestamptz{Valid: true, Time: time.Now()}
+	sut.jwtHelperMock.Mock.On("ParseMfaPendingToken", "mfaToken").Return(sut.mfaClaims, nil)
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.expectMfaChallenge(modelentities.MfaChallenge{FailedCount: pgtype.Int4{Valid: true, Int32: int32(sut.config.Jwt.MfaMaxAttempts)}})
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, accessToken, refreshToken, response := sut.twoFactorService.Login(sut.ctx, modelrequests.LoginTwoFactorRequest{MfaToken: "mfaToken", Code: "123456"})
+	sut.Equal(httpCode, http.StatusUnauthorized)
+	sut.Equal(accessToken, "")
+	sut.Equal(refreshToken, "")
+	sut.Equal(response, helpers.ToResponse("invalid or expired mfa token"))
+	sut.totpHelperMock.Mock.AssertNotCalled(sut.T(), "Validate", "SECRET", "123456", mock.Anything)
+}
+
+func (sut *TwoFactorServiceTestSuite) Test15DisableVerifyError() {
+	sut.T().Log("Test15DisableVerifyError")
+	sut.user.TotpSecret = pgtype.Text{Valid: true, String: "SECRET"}
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.principalCtx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.principalCtx, 1).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", sut.user.Password.String, "password").Return(false, sut.errInternalServer)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.principalCtx, sut.errInternalServer).Return(nil)
+	httpCode, response := sut.twoFactorService.Disable(sut.principalCtx, modelrequests.DisableTwoFactorRequest{Password: "password"})
+	sut.Equal(httpCode, http.StatusInternalServerError)
+	sut.Equal(response, helpers.ToResponse(sut.errInternalServer.Error()))
+	sut.userRepositoryMock.Mock.AssertNotCalled(sut.T(), "DisableTotp", sut.pgxTxMock, sut.principalCtx, 1)
+}
+
+func (sut *TwoFactorServiceTestSuite) Test16LoginWrongCodesWithNewMfaTokensLockAccount() {
+	sut.T().Log("Test16LoginWrongCodesWithNewMfaTokensLockAccount")
+	sut.user.TotpSecret = pgtype.Text{Valid: true, String: "SECRET"}
+	sut.user.TotpEnabledAt = pgtype.Timestamptz{Valid: true, Time: time.Now()}
+	userService := services.NewUserService(sut.postgresUtilMock, sut.validate, sut.userRepositoryMock, sut.passwordHasherMock, sut.jwtHelperMock, new(mockhelpers.MailerMock), sut.loginAttemptRepositoryMock, new(mockhelpers.PasswordPolicyHelperMock), sut.securityEventRepositoryMock, sut.metricsHelper, sut.config)
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, mock.Anything).Return(nil)
+	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, "john@doe.com").Return(sut.user, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", "password", "password").Return(false, nil)
+	sut.totpHelperMock.Mock.On("Validate", "SECRET", "000000", mock.Anything).Return(int64(0), false)
+	sut.mfaChallengeRepositoryMock.Mock.On("FindOrCreate", sut.pgxTxMock, sut.ctx, mock.Anything, 1, mock.Anything).Return(modelentities.MfaChallenge{}, nil)
+	sut.mfaChallengeRepositoryMock.Mock.On("IncrementFailedCount", sut.pgxTxMock, sut.ctx, mock.Anything).Return(1, nil)
+	for i, mfaToken := range []string{"mfaToken1", "mfaToken2"} {
+		sut.jwtHelperMock.Mock.On("GenerateMfaPendingToken", 1, sut.config.Jwt.MfaPendingTokenTime).Return(mfaToken, nil).Once()
+		mfaClaims := *sut.mfaClaims
+		mfaClaims.ID = "jti" + strconv.Itoa(i+1)
+		sut.jwtHelperMock.Mock.On("ParseMfaPendingToken", mfaToken).Return(&mfaClaims, nil)
+	}
+
+	// the counter of the email as the database keeps it
+	var loginAttempt modelentities.LoginAttempt
+	findByAttemptKey := sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com")
+	findByAttemptKey.Run(func(arguments mock.Arguments) {
+		findByAttemptKey.ReturnArguments = mock.Arguments{loginAttempt, nil}
+	})
+	incrementFailedCount := sut.loginAttemptRepositoryMock.Mock.On("IncrementFailedCount", sut.pgxTxMock, sut.ctx, "email:john@doe.com", sut.config.Login.AttemptWindow)
+	incrementFailedCount.Run(func(arguments mock.Arguments) {
+		loginAttempt.FailedCount.Int32++
+		incrementFailedCount.ReturnArguments = mock.Arguments{int(loginAttempt.FailedCount.Int32), nil}
+	})
+	sut.loginAttemptRepositoryMock.Mock.On("UpdateLockedUntil", sut.pgxTxMock, sut.ctx, "email:john@doe.com", mock.Anything).Return(int64(1), nil).Run(func(arguments mock.Arguments) {
+		loginAttempt.LockedUntil = pgtype.Timestamptz{Valid: true, Time: time.Now().Add(time.Duration(arguments.Int(3)) * time.Second)}
+	})
+	sut.loginAttemptRepositoryMock.Mock.On("CreateLockoutEvent", sut.pgxTxMock, sut.ctx, mock.Anything).Return(1, nil)
+
+	httpCodes := []int{}
+	for _, mfaToken := range []string{"mfaToken1", "mfaToken2"} {
+		httpCode, _, _, response := userService.Login(sut.ctx, modelrequests.LoginRequest{Email: "john@doe.com", Password: "password"})
+		httpCodes = append(httpCodes, httpCode)
+		if httpCode != http.StatusOK {
+			break
+		}
+		sut.Equal(response.(modelresponses.MfaRequiredResponse).MfaToken, mfaToken)
+		for range sut.config.Jwt.MfaMaxAttempts {
+			httpCode, _, _, _ = sut.twoFactorService.Login(sut.ctx, modelrequests.LoginTwoFactorRequest{MfaToken: mfaToken, Code: "000000"})
+			httpCodes = append(httpCodes, httpCode)
+		}
+	}
+	httpCode, _, _, _ := userService.Login(sut.ctx, modelrequests.LoginRequest{Email: "john@doe.com", Password: "password"})
+	httpCodes = append(httpCodes, httpCode)
+	sut.Equal(httpCodes, []int{
+		http.StatusOK, http.StatusBadRequest, http.StatusBadRequest, http.StatusBadRequest,
+		http.StatusOK, http.StatusBadRequest, http.StatusBadRequest, http.StatusTooManyRequests,
+		http.StatusTooManyRequests,
+	})
+	sut.Equal(int(loginAttempt.FailedCount.Int32), sut.config.Login.MaxAttempts)
+	sut.loginAttemptRepositoryMock.Mock.AssertNotCalled(sut.T(), "DeleteByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com")
+}
+
+// expectMfaChallenge lets the email pass the lockout check and returns mfaChallenge for the token.
+func (sut *TwoFactorServiceTestSuite) expectMfaChallenge(mfaChallenge modelentities.MfaChallenge) {
+	var loginAttempt modelentities.LoginAttempt
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(loginAttempt, pgx.ErrNoRows)
+	sut.mfaChallengeRepositoryMock.Mock.On("FindOrCreate", sut.pgxTxMock, sut.ctx, "jti", 1, sut.mfaClaims.ExpiresAt.Time).Return(mfaChallenge, nil)
+}
+
+func (sut *TwoFactorServiceTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *TwoFactorServiceTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *TwoFactorServiceTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
@@ -4,9 +4,14 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
 	modelentities "todo-list-api/models/entities"
 	modelrequests "todo-list-api/models/requests"
+	modelresponses "todo-list-api/models/responses"
 	"todo-list-api/services"
 	mockhelpers "todo-list-api/test/unit_tests/helpers/mocks"
 	mockrepositories "todo-list-api/test/unit_tests/repositories/mocks"
@@ -16,28 +21,34 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserServiceTestSuite struct {
 	suite.Suite
-	ctx                   context.Context
-	options               pgx.TxOptions
-	tx                    pgx.Tx
-	pool                  *pgxpool.Pool
-	errInternalServer     error
-	errRowsAffectedNotOne error
-	registerRequest       modelrequests.RegisterRequest
-	loginRequest          modelrequests.LoginRequest
-	user                  modelentities.User
-	postgresUtilMock      *mockutils.PostgresUtilMock
-	validate              *validator.Validate
-	userRepositoryMock    *mockrepositories.UserRepositoryMock
-	bcryptHelperMock      *mockhelpers.BcryptHelperMock
-	jwtHelperMock         *mockhelpers.JwtHelperMock
-	pgxTxMock             *mockutils.PgxTxMock
-	userService           services.UserService
+	metricsHelper               helpers.MetricsHelper
+	config                      config.Config
+	ctx                         context.Context
+	options                     pgx.TxOptions
+	tx                          pgx.Tx
+	pool                        *pgxpool.Pool
+	errInternalServer           error
+	errRowsAffectedNotOne       error
+	registerRequest             modelrequests.RegisterRequest
+	loginRequest                modelrequests.LoginRequest
+	user                        modelentities.User
+	postgresUtilMock            *mockutils.PostgresUtilMock
+	validate                    *validator.Validate
+	userRepositoryMock          *mockrepositories.UserRepositoryMock
+	securityEventRepositoryMock *mockrepositories.SecurityEventRepositoryMock
+	passwordHasherMock          *mockhelpers.PasswordHasherMock
+	jwtHelperMock               *mockhelpers.JwtHelperMock
+	mailerMock                  *mockhelpers.MailerMock
+	loginAttemptRepositoryMock  *mockrepositories.LoginAttemptRepositoryMock
+	passwordPolicyHelperMock    *mockhelpers.PasswordPolicyHelperMock
+	pgxTxMock                   *mockutils.PgxTxMock
+	userService                 services.UserService
 }
 
 func TestUserTestSuite(t *testing.T) {
@@ -49,10 +60,12 @@ func (sut *UserServiceTestSuite) SetupSuite() {
 	sut.ctx = context.Background()
 	sut.errInternalServer = errors.New("internal server error")
 	sut.errRowsAffectedNotOne = errors.New("rows affected not one")
+	sut.config = config.Default()
 }
 
 func (sut *UserServiceTestSuite) SetupTest() {
 	sut.T().Log("SetupTest")
+	sut.metricsHelper = helpers.NewMetricsHelper()
 	sut.registerRequest = modelrequests.RegisterRequest{
 		Name:     "John Doe",
 		Email:    "john@doe.com",
@@ -73,16 +86,27 @@ func (sut *UserServiceTestSuite) SetupTest() {
 	sut.postgresUtilMock = new(mockutils.PostgresUtilMock)
 	sut.validate = validator.New()
 	sut.userRepositoryMock = new(mockrepositories.UserRepositoryMock)
-	sut.bcryptHelperMock = new(mockhelpers.BcryptHelperMock)
+	sut.securityEventRepositoryMock = new(mockrepositories.SecurityEventRepositoryMock)
+	sut.securityEventRepositoryMock.Mock.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(1, nil)
+	sut.passwordHasherMock = new(mockhelpers.PasswordHasherMock)
 	sut.jwtHelperMock = new(mockhelpers.JwtHelperMock)
+	sut.mailerMock = new(mockhelpers.MailerMock)
+	sut.loginAttemptRepositoryMock = new(mockrepositories.LoginAttemptRepositoryMock)
+	sut.passwordPolicyHelperMock = new(mockhelpers.PasswordPolicyHelperMock)
 	sut.pgxTxMock = new(mockutils.PgxTxMock)
-	sut.userService = services.NewUserService(sut.postgresUtilMock, sut.validate, sut.userRepositoryMock, sut.bcryptHelperMock, sut.jwtHelperMock)
+	sut.userService = services.NewUserService(sut.postgresUtilMock, sut.validate, sut.userRepositoryMock, sut.passwordHasherMock, sut.jwtHelperMock, sut.mailerMock, sut.loginAttemptRepositoryMock, sut.passwordPolicyHelperMock, sut.securityEventRepositoryMock, sut.metricsHelper, sut.config)
 }
 
 func (sut *UserServiceTestSuite) BeforeTest(suiteName, testName string) {
 	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
 }
 
+func (sut *UserServiceTestSuite) scrapeMetrics() string {
+	recorder := httptest.NewRecorder()
+	sut.metricsHelper.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return recorder.Body.String()
+}
+
 func (sut *UserServiceTestSuite) Test01RegisterValidationError() {
 	sut.T().Log("Test01RegisterValidationError")
 	sut.registerRequest = modelrequests.RegisterRequest{}
@@ -95,6 +119,7 @@ func (sut *UserServiceTestSuite) Test01RegisterValidationError() {
 
 func (sut *UserServiceTestSuite) Test02RegisterBeginTxError() {
 	sut.T().Log("Test02RegisterBeginTxError")
+	sut.passwordPolicyHelperMock.Mock.On("Check", sut.registerRequest.Password, sut.registerRequest.Name, sut.registerRequest.Email).Return([]modelresponses.PasswordPolicyViolation(nil), nil)
 	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, sut.errInternalServer)
 	httpCode, accessToken, refreshToken, response := sut.userService.Register(sut.ctx, sut.registerRequest)
 	sut.Equal(httpCode, 500)
@@ -105,9 +130,10 @@ func (sut *UserServiceTestSuite) Test02RegisterBeginTxError() {
 
 func (sut *UserServiceTestSuite) Test03RegisterGenerateFromPasswordError() {
 	sut.T().Log("Test03RegisterGenerateFromPasswordError")
+	sut.passwordPolicyHelperMock.Mock.On("Check", sut.registerRequest.Password, sut.registerRequest.Name, sut.registerRequest.Email).Return([]modelresponses.PasswordPolicyViolation(nil), nil)
 	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
 	// should return []uint8{}, or there will be error on commit or rollback or the error will not be going to commit or rollback
-	sut.bcryptHelperMock.Mock.On("GenerateFromPassword", []byte(sut.registerRequest.Password), bcrypt.DefaultCost).Return([]uint8{}, sut.errInternalServer)
+	sut.passwordHasherMock.Mock.On("Hash", sut.registerRequest.Password).Return("", sut.errInternalServer)
 	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer).Return(nil)
 	httpCode, accessToken, refreshToken, response := sut.userService.Register(sut.ctx, sut.registerRequest)
 	sut.Equal(httpCode, 500)
@@ -118,8 +144,9 @@ func (sut *UserServiceTestSuite) Test03RegisterGenerateFromPasswordError() {
 
 func (sut *UserServiceTestSuite) Test04RegisterGenerateFromPasswordErrorCommitOrRollbackError() {
 	sut.T().Log("Test04RegisterGenerateFromPasswordErrorCommitOrRollbackError")
+	sut.passwordPolicyHelperMock.Mock.On("Check", sut.registerRequest.Password, sut.registerRequest.Name, sut.registerRequest.Email).Return([]modelresponses.PasswordPolicyViolation(nil), nil)
 	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
-	sut.bcryptHelperMock.Mock.On("GenerateFromPassword", []byte(sut.registerRequest.Password), bcrypt.DefaultCost).Return([]uint8{}, sut.errInternalServer)
+	sut.passwordHasherMock.Mock.On("Hash", sut.registerRequest.Password).Return("", sut.errInternalServer)
 	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer).Return(sut.errInternalServer)
 	httpCode, accessToken, refreshToken, response := sut.userService.Register(sut.ctx, sut.registerRequest)
 	sut.Equal(httpCode, 500)
@@ -130,9 +157,10 @@ func (sut *UserServiceTestSuite) Test04RegisterGenerateFromPasswordErrorCommitOr
 
 func (sut *UserServiceTestSuite) Test05RegisterUserRepositoryCreateError() {
 	sut.T().Log("Test05RegisterUserRepositoryCreateError")
+	sut.passwordPolicyHelperMock.Mock.On("Check", sut.registerRequest.Password, sut.registerRequest.Name, sut.registerRequest.Email).Return([]modelresponses.PasswordPolicyViolation(nil), nil)
 	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
 	passwordByte := []byte{112, 97, 115, 115, 119, 111, 114, 100}
-	sut.bcryptHelperMock.Mock.On("GenerateFromPassword", []byte(sut.registerRequest.Password), bcrypt.DefaultCost).Return(passwordByte, nil)
+	sut.passwordHasherMock.Mock.On("Hash", sut.registerRequest.Password).Return(string(passwordByte), nil)
 	sut.user.Id = pgtype.Int4{Valid: false, Int32: 0}
 	sut.user.RefreshToken = pgtype.Text{Valid: false, String: ""}
 	var lastInsertedId int
@@ -147,16 +175,17 @@ func (sut *UserServiceTestSuite) Test05RegisterUserRepositoryCreateError() {
 
 func (sut *UserServiceTestSuite) Test06RegisterAccessTokenError() {
 	sut.T().Log("Test06RegisterAccessTokenError")
+	sut.passwordPolicyHelperMock.Mock.On("Check", sut.registerRequest.Password, sut.registerRequest.Name, sut.registerRequest.Email).Return([]modelresponses.PasswordPolicyViolation(nil), nil)
 	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
 	passwordByte := []byte{112, 97, 115, 115, 119, 111, 114, 100}
-	sut.bcryptHelperMock.Mock.On("GenerateFromPassword", []byte(sut.registerRequest.Password), bcrypt.DefaultCost).Return(passwordByte, nil)
+	sut.passwordHasherMock.Mock.On("Hash", sut.registerRequest.Password).Return(string(passwordByte), nil)
 	sut.user.Id = pgtype.Int4{Valid: false, Int32: 0}
 	sut.user.RefreshToken = pgtype.Text{Valid: false, String: ""}
 	sut.userRepositoryMock.Mock.On("Create", sut.pgxTxMock, sut.ctx, sut.user).Return(1, nil)
 	jwtAccessTokenTime := 15
 	sut.user.Id = pgtype.Int4{Valid: true, Int32: 1}
 	var accessToken string
-	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime, "secret").Return(accessToken, sut.errInternalServer)
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime).Return(accessToken, sut.errInternalServer)
 	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer).Return(nil)
 	httpCode, accessToken, refreshToken, response := sut.userService.Register(sut.ctx, sut.registerRequest)
 	sut.Equal(httpCode, 500)
@@ -167,9 +196,10 @@ func (sut *UserServiceTestSuite) Test06RegisterAccessTokenError() {
 
 func (sut *UserServiceTestSuite) Test07RegisterGenerateRefreshTokenError() {
 	sut.T().Log("Test07RegisterGenerateRefreshTokenError")
+	sut.passwordPolicyHelperMock.Mock.On("Check", sut.registerRequest.Password, sut.registerRequest.Name, sut.registerRequest.Email).Return([]modelresponses.PasswordPolicyViolation(nil), nil)
 	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
 	passwordByte := []byte{112, 97, 115, 115, 119, 111, 114, 100}
-	sut.bcryptHelperMock.Mock.On("GenerateFromPassword", []byte(sut.registerRequest.Password), bcrypt.DefaultCost).Return(passwordByte, nil)
+	sut.passwordHasherMock.Mock.On("Hash", sut.registerRequest.Password).Return(string(passwordByte), nil)
 	sut.user.Id = pgtype.Int4{Valid: false, Int32: 0}
 	sut.user.RefreshToken = pgtype.Text{Valid: false, String: ""}
 	sut.userRepositoryMock.Mock.On("Create", sut.pgxTxMock, sut.ctx, sut.user).Return(1, nil)
@@ -177,10 +207,10 @@ func (sut *UserServiceTestSuite) Test07RegisterGenerateRefreshTokenError() {
 	sut.user.Id = pgtype.Int4{Valid: true, Int32: 1}
 	var accessToken string
 	accessToken = "accessToken"
-	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime, "secret").Return(accessToken, nil)
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime).Return(accessToken, nil)
 	jwtRefreshTokenTime := 1
 	var refreshToken string
-	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), jwtRefreshTokenTime, "secret").Return(refreshToken, sut.errInternalServer)
+	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), jwtRefreshTokenTime).Return(refreshToken, sut.errInternalServer)
 	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer).Return(nil)
 	httpCode, accessToken, refreshToken, response := sut.userService.Register(sut.ctx, sut.registerRequest)
 	sut.Equal(httpCode, 500)
@@ -191,17 +221,18 @@ func (sut *UserServiceTestSuite) Test07RegisterGenerateRefreshTokenError() {
 
 func (sut *UserServiceTestSuite) Test08RegisterUpdateRefreshTokenError() {
 	sut.T().Log("Test08RegisterUpdateRefreshTokenError")
+	sut.passwordPolicyHelperMock.Mock.On("Check", sut.registerRequest.Password, sut.registerRequest.Name, sut.registerRequest.Email).Return([]modelresponses.PasswordPolicyViolation(nil), nil)
 	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
 	passwordByte := []byte{112, 97, 115, 115, 119, 111, 114, 100}
-	sut.bcryptHelperMock.Mock.On("GenerateFromPassword", []byte(sut.registerRequest.Password), bcrypt.DefaultCost).Return(passwordByte, nil)
+	sut.passwordHasherMock.Mock.On("Hash", sut.registerRequest.Password).Return(string(passwordByte), nil)
 	sut.user.Id = pgtype.Int4{Valid: false, Int32: 0}
 	sut.user.RefreshToken = pgtype.Text{Valid: false, String: ""}
 	sut.userRepositoryMock.Mock.On("Create", sut.pgxTxMock, sut.ctx, sut.user).Return(1, nil)
 	jwtAccessTokenTime := 15
 	sut.user.Id = pgtype.Int4{Valid: true, Int32: 1}
-	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime, "secret").Return("accessToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime).Return("accessToken", nil)
 	jwtRefreshTokenTime := 1
-	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), jwtRefreshTokenTime, "secret").Return("refreshToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), jwtRefreshTokenTime).Return("refreshToken", nil)
 	var rowsAffected int64
 	sut.userRepositoryMock.Mock.On("UpdateRefreshToken", sut.pgxTxMock, sut.ctx, "refreshToken", int(sut.user.Id.Int32)).Return(rowsAffected, sut.errInternalServer)
 	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer).Return(nil)
@@ -214,17 +245,18 @@ func (sut *UserServiceTestSuite) Test08RegisterUpdateRefreshTokenError() {
 
 func (sut *UserServiceTestSuite) Test09RegisterUpdateRefreshTokenRowsAffectedNotOne() {
 	sut.T().Log("Test09RegisterUpdateRefreshTokenRowsAffectedNotOne")
+	sut.passwordPolicyHelperMock.Mock.On("Check", sut.registerRequest.Password, sut.registerRequest.Name, sut.registerRequest.Email).Return([]modelresponses.PasswordPolicyViolation(nil), nil)
 	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
 	passwordByte := []byte{112, 97, 115, 115, 119, 111, 114, 100}
-	sut.bcryptHelperMock.Mock.On("GenerateFromPassword", []byte(sut.registerRequest.Password), bcrypt.DefaultCost).Return(passwordByte, nil)
+	sut.passwordHasherMock.Mock.On("Hash", sut.registerRequest.Password).Return(string(passwordByte), nil)
 	sut.user.Id = pgtype.Int4{Valid: false, Int32: 0}
 	sut.user.RefreshToken = pgtype.Text{Valid: false, String: ""}
 	sut.userRepositoryMock.Mock.On("Create", sut.pgxTxMock, sut.ctx, sut.user).Return(1, nil)
 	jwtAccessTokenTime := 15
 	sut.user.Id = pgtype.Int4{Valid: true, Int32: 1}
-	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime, "secret").Return("accessToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime).Return("accessToken", nil)
 	jwtRefreshTokenTime := 1
-	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), jwtRefreshTokenTime, "secret").Return("refreshToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), jwtRefreshTokenTime).Return("refreshToken", nil)
 	var rowsAffected int64
 	rowsAffected = 0
 	sut.userRepositoryMock.Mock.On("UpdateRefreshToken", sut.pgxTxMock, sut.ctx, "refreshToken", int(sut.user.Id.Int32)).Return(rowsAffected, nil)
@@ -238,20 +270,24 @@ func (sut *UserServiceTestSuite) Test09RegisterUpdateRefreshTokenRowsAffectedNot
 
 func (sut *UserServiceTestSuite) Test10RegisterSuccess() {
 	sut.T().Log("Test10RegisterSuccess")
+	sut.passwordPolicyHelperMock.Mock.On("Check", sut.registerRequest.Password, sut.registerRequest.Name, sut.registerRequest.Email).Return([]modelresponses.PasswordPolicyViolation(nil), nil)
 	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
 	passwordByte := []byte{112, 97, 115, 115, 119, 111, 114, 100}
-	sut.bcryptHelperMock.Mock.On("GenerateFromPassword", []byte(sut.registerRequest.Password), bcrypt.DefaultCost).Return(passwordByte, nil)
+	sut.passwordHasherMock.Mock.On("Hash", sut.registerRequest.Password).Return(string(passwordByte), nil)
 	sut.user.Id = pgtype.Int4{Valid: false, Int32: 0}
 	sut.user.RefreshToken = pgtype.Text{Valid: false, String: ""}
 	sut.userRepositoryMock.Mock.On("Create", sut.pgxTxMock, sut.ctx, sut.user).Return(1, nil)
 	jwtAccessTokenTime := 15
 	sut.user.Id = pgtype.Int4{Valid: true, Int32: 1}
-	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime, "secret").Return("accessToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime).Return("accessToken", nil)
 	jwtRefreshTokenTime := 1
-	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), jwtRefreshTokenTime, "secret").Return("refreshToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), jwtRefreshTokenTime).Return("refreshToken", nil)
 	var rowsAffected int64
 	rowsAffected = 1
 	sut.userRepositoryMock.Mock.On("UpdateRefreshToken", sut.pgxTxMock, sut.ctx, "refreshToken", int(sut.user.Id.Int32)).Return(rowsAffected, nil)
+	sut.userRepositoryMock.Mock.On("UpdateEmailVerificationSentAt", sut.pgxTxMock, sut.ctx, int(sut.user.Id.Int32), 0).Return(rowsAffected, nil)
+	sut.jwtHelperMock.Mock.On("GenerateEmailVerificationToken", int(sut.user.Id.Int32), sut.user.Email.String, 1440).Return("emailVerificationToken", nil)
+	sut.mailerMock.Mock.On("Send", sut.user.Email.String, "Verify your email", mock.Anything).Return(nil)
 	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
 	httpCode, accessToken, refreshToken, response := sut.userService.Register(sut.ctx, sut.registerRequest)
 	sut.Equal(httpCode, http.StatusCreated)
@@ -283,6 +319,7 @@ func (sut *UserServiceTestSuite) Test12LoginBeginTxError() {
 func (sut *UserServiceTestSuite) Test13LoginFindByEmailError() {
 	sut.T().Log("Test13LoginFindByEmailError")
 	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(modelentities.LoginAttempt{}, pgx.ErrNoRows)
 	var user modelentities.User
 	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, sut.loginRequest.Email).Return(user, sut.errInternalServer)
 	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer).Return(nil)
@@ -296,9 +333,13 @@ func (sut *UserServiceTestSuite) Test13LoginFindByEmailError() {
 func (sut *UserServiceTestSuite) Test14LoginFindByEmailWrongEmailOrPassword() {
 	sut.T().Log("Test14LoginFindByEmailWrongEmailOrPassword")
 	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(modelentities.LoginAttempt{}, pgx.ErrNoRows)
 	var user modelentities.User
 	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, sut.loginRequest.Email).Return(user, pgx.ErrNoRows)
-	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, pgx.ErrNoRows).Return(nil)
+	sut.passwordHasherMock.Mock.On("DummyHash").Return("dummyHash")
+	sut.passwordHasherMock.Mock.On("Verify", "dummyHash", sut.loginRequest.Password).Return(false, helpers.ErrPasswordMismatch)
+	sut.loginAttemptRepositoryMock.Mock.On("IncrementFailedCount", sut.pgxTxMock, sut.ctx, "email:john@doe.com", 900).Return(1, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
 	httpCode, accessToken, refreshToken, response := sut.userService.Login(sut.ctx, sut.loginRequest)
 	sut.Equal(httpCode, http.StatusBadRequest)
 	sut.Equal(accessToken, "")
@@ -309,9 +350,11 @@ func (sut *UserServiceTestSuite) Test14LoginFindByEmailWrongEmailOrPassword() {
 func (sut *UserServiceTestSuite) Test15LoginCompareHashAndPassword() {
 	sut.T().Log("Test15LoginCompareHashAndPassword")
 	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(modelentities.LoginAttempt{}, pgx.ErrNoRows)
 	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, sut.loginRequest.Email).Return(sut.user, nil)
-	sut.bcryptHelperMock.Mock.On("CompareHashAndPassword", []byte(sut.user.Password.String), []byte(sut.loginRequest.Password)).Return(sut.errInternalServer)
-	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer).Return(nil)
+	sut.passwordHasherMock.Mock.On("Verify", sut.user.Password.String, sut.loginRequest.Password).Return(false, helpers.ErrPasswordMismatch)
+	sut.loginAttemptRepositoryMock.Mock.On("IncrementFailedCount", sut.pgxTxMock, sut.ctx, "email:john@doe.com", 900).Return(1, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
 	httpCode, accessToken, refreshToken, response := sut.userService.Login(sut.ctx, sut.loginRequest)
 	sut.Equal(httpCode, http.StatusBadRequest)
 	sut.Equal(accessToken, "")
@@ -322,10 +365,13 @@ func (sut *UserServiceTestSuite) Test15LoginCompareHashAndPassword() {
 func (sut *UserServiceTestSuite) Test16LoginGenerateAccessTokenError() {
 	sut.T().Log("Test16LoginGenerateAccessTokenError")
 	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(modelentities.LoginAttempt{}, pgx.ErrNoRows)
 	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, sut.loginRequest.Email).Return(sut.user, nil)
-	sut.bcryptHelperMock.Mock.On("CompareHashAndPassword", []byte(sut.user.Password.String), []byte(sut.loginRequest.Password)).Return(nil)
+	sut.passwordHasherMock.Mock.On("Verify", sut.user.Password.String, sut.loginRequest.Password).Return(false, nil)
+	var deletedRows int64
+	sut.loginAttemptRepositoryMock.Mock.On("DeleteByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(deletedRows, nil)
 	jwtAccessTokenTime := 15
-	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime, "secret").Return("", sut.errInternalServer)
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime).Return("", sut.errInternalServer)
 	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer).Return(nil)
 	httpCode, accessToken, refreshToken, response := sut.userService.Login(sut.ctx, sut.loginRequest)
 	sut.Equal(httpCode, http.StatusInternalServerError)
@@ -337,12 +383,15 @@ func (sut *UserServiceTestSuite) Test16LoginGenerateAccessTokenError() {
 func (sut *UserServiceTestSuite) Test17LoginGenerateRefreshTokenError() {
 	sut.T().Log("Test17LoginGenerateRefreshTokenError")
 	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(modelentities.LoginAttempt{}, pgx.ErrNoRows)
 	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, sut.loginRequest.Email).Return(sut.user, nil)
-	sut.bcryptHelperMock.Mock.On("CompareHashAndPassword", []byte(sut.user.Password.String), []byte(sut.loginRequest.Password)).Return(nil)
+	sut.passwordHasherMock.Mock.On("Verify", sut.user.Password.String, sut.loginRequest.Password).Return(false, nil)
+	var deletedRows int64
+	sut.loginAttemptRepositoryMock.Mock.On("DeleteByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(deletedRows, nil)
 	jwtAccessTokenTime := 15
-	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime, "secret").Return("accessToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime).Return("accessToken", nil)
 	jwtRefreshTokenTime := 1
-	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), jwtRefreshTokenTime, "secret").Return("", sut.errInternalServer)
+	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), jwtRefreshTokenTime).Return("", sut.errInternalServer)
 	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer).Return(nil)
 	httpCode, accessToken, refreshToken, response := sut.userService.Login(sut.ctx, sut.loginRequest)
 	sut.Equal(httpCode, http.StatusInternalServerError)
@@ -354,12 +403,15 @@ func (sut *UserServiceTestSuite) Test17LoginGenerateRefreshTokenError() {
 func (sut *UserServiceTestSuite) Test18LoginUpdateRefreshTokenError() {
 	sut.T().Log("Test18LoginUpdateRefreshTokenError")
 	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(modelentities.LoginAttempt{}, pgx.ErrNoRows)
 	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, sut.loginRequest.Email).Return(sut.user, nil)
-	sut.bcryptHelperMock.Mock.On("CompareHashAndPassword", []byte(sut.user.Password.String), []byte(sut.loginRequest.Password)).Return(nil)
+	sut.passwordHasherMock.Mock.On("Verify", sut.user.Password.String, sut.loginRequest.Password).Return(false, nil)
+	var deletedRows int64
+	sut.loginAttemptRepositoryMock.Mock.On("DeleteByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(deletedRows, nil)
 	jwtAccessTokenTime := 15
-	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime, "secret").Return("accessToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime).Return("accessToken", nil)
 	jwtRefreshTokenTime := 1
-	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), jwtRefreshTokenTime, "secret").Return("refreshToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), jwtRefreshTokenTime).Return("refreshToken", nil)
 	var rowsAffected int64
 	sut.userRepositoryMock.Mock.On("UpdateRefreshToken", sut.pgxTxMock, sut.ctx, "refreshToken", int(sut.user.Id.Int32)).Return(rowsAffected, sut.errInternalServer)
 	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer).Return(nil)
@@ -373,12 +425,15 @@ func (sut *UserServiceTestSuite) Test18LoginUpdateRefreshTokenError() {
 func (sut *UserServiceTestSuite) Test19LoginUpdateRefreshTokenRowsAffected() {
 	sut.T().Log("Test19LoginUpdateRefreshTokenRowsAffected")
 	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(modelentities.LoginAttempt{}, pgx.ErrNoRows)
 	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, sut.loginRequest.Email).Return(sut.user, nil)
-	sut.bcryptHelperMock.Mock.On("CompareHashAndPassword", []byte(sut.user.Password.String), []byte(sut.loginRequest.Password)).Return(nil)
+	sut.passwordHasherMock.Mock.On("Verify", sut.user.Password.String, sut.loginRequest.Password).Return(false, nil)
+	var deletedRows int64
+	sut.loginAttemptRepositoryMock.Mock.On("DeleteByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(deletedRows, nil)
 	jwtAccessTokenTime := 15
-	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime, "secret").Return("accessToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime).Return("accessToken", nil)
 	jwtRefreshTokenTime := 1
-	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), jwtRefreshTokenTime, "secret").Return("refreshToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), jwtRefreshTokenTime).Return("refreshToken", nil)
 	var rowsAffected int64
 	sut.userRepositoryMock.Mock.On("UpdateRefreshToken", sut.pgxTxMock, sut.ctx, "refreshToken", int(sut.user.Id.Int32)).Return(rowsAffected, nil)
 	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errRowsAffectedNotOne).Return(nil)
@@ -392,12 +447,15 @@ func (sut *UserServiceTestSuite) Test19LoginUpdateRefreshTokenRowsAffected() {
 func (sut *UserServiceTestSuite) Test20LoginSuccess() {
 	sut.T().Log("Test20LoginSuccess")
 	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(modelentities.LoginAttempt{}, pgx.ErrNoRows)
 	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, sut.loginRequest.Email).Return(sut.user, nil)
-	sut.bcryptHelperMock.Mock.On("CompareHashAndPassword", []byte(sut.user.Password.String), []byte(sut.loginRequest.Password)).Return(nil)
+	sut.passwordHasherMock.Mock.On("Verify", sut.user.Password.String, sut.loginRequest.Password).Return(false, nil)
+	var deletedRows int64
+	sut.loginAttemptRepositoryMock.Mock.On("DeleteByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(deletedRows, nil)
 	jwtAccessTokenTime := 15
-	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime, "secret").Return("accessToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime).Return("accessToken", nil)
 	jwtRefreshTokenTime := 1
-	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), jwtRefreshTokenTime, "secret").Return("refreshToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), jwtRefreshTokenTime).Return("refreshToken", nil)
 	var rowsAffected int64
 	rowsAffected = 1
 	sut.userRepositoryMock.Mock.On("UpdateRefreshToken", sut.pgxTxMock, sut.ctx, "refreshToken", int(sut.user.Id.Int32)).Return(rowsAffected, nil)
@@ -407,6 +465,7 @@ func (sut *UserServiceTestSuite) Test20LoginSuccess() {
 	sut.Equal(accessToken, "accessToken")
 	sut.Equal(refreshToken, "refreshToken")
 	sut.NotEqual(response, nil)
+	sut.Contains(sut.scrapeMetrics(), `logins_total{reason="password",result="succeeded"} 1`)
 }
 
 func (sut *UserServiceTestSuite) Test21RefreshTokenFindByRefreshTokenError() {
@@ -439,7 +498,7 @@ func (sut *UserServiceTestSuite) Test23RefreshTokenGenerateAccessTokenError() {
 	refreshToken := "refreshToken"
 	sut.userRepositoryMock.Mock.On("FindByRefreshToken", sut.pool, sut.ctx, refreshToken).Return(sut.user, nil)
 	jwtAccessTokenTime := 15
-	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime, "secret").Return("", sut.errInternalServer)
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime).Return("", sut.errInternalServer)
 	httpCode, accessToken, response := sut.userService.RefreshToken(sut.ctx, refreshToken)
 	sut.Equal(httpCode, http.StatusInternalServerError)
 	sut.Equal(accessToken, "")
@@ -452,11 +511,348 @@ func (sut *UserServiceTestSuite) Test24RefreshTokenSuccess() {
 	refreshToken := "refreshToken"
 	sut.userRepositoryMock.Mock.On("FindByRefreshToken", sut.pool, sut.ctx, refreshToken).Return(sut.user, nil)
 	jwtAccessTokenTime := 15
-	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime, "secret").Return("accessToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, jwtAccessTokenTime).Return("accessToken", nil)
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
 	httpCode, accessToken, response := sut.userService.RefreshToken(sut.ctx, refreshToken)
 	sut.Equal(httpCode, http.StatusOK)
 	sut.Equal(accessToken, "accessToken")
 	sut.NotEqual(response, nil)
+	sut.securityEventRepositoryMock.Mock.AssertCalled(sut.T(), "Create", sut.pgxTxMock, sut.ctx, mock.MatchedBy(func(securityEvent modelentities.SecurityEvent) bool {
+		return securityEvent.EventType.String == helpers.SecurityEventTokenRefreshed && securityEvent.UserId.Int32 == 1
+	}))
+}
+
+func (sut *UserServiceTestSuite) Test25RegisterSendVerificationEmailError() {
+	sut.T().Log("Test25RegisterSendVerificationEmailError")
+	sut.passwordPolicyHelperMock.Mock.On("Check", sut.registerRequest.Password, sut.registerRequest.Name, sut.registerRequest.Email).Return([]modelresponses.PasswordPolicyViolation(nil), nil)
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	passwordByte := []byte{112, 97, 115, 115, 119, 111, 114, 100}
+	sut.passwordHasherMock.Mock.On("Hash", sut.registerRequest.Password).Return(string(passwordByte), nil)
+	sut.user.Id = pgtype.Int4{Valid: false, Int32: 0}
+	sut.user.RefreshToken = pgtype.Text{Valid: false, String: ""}
+	sut.userRepositoryMock.Mock.On("Create", sut.pgxTxMock, sut.ctx, sut.user).Return(1, nil)
+	sut.user.Id = pgtype.Int4{Valid: true, Int32: 1}
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, 15).Return("accessToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), 1).Return("refreshToken", nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("UpdateRefreshToken", sut.pgxTxMock, sut.ctx, "refreshToken", int(sut.user.Id.Int32)).Return(rowsAffected, nil)
+	sut.userRepositoryMock.Mock.On("UpdateEmailVerificationSentAt", sut.pgxTxMock, sut.ctx, int(sut.user.Id.Int32), 0).Return(rowsAffected, nil)
+	sut.jwtHelperMock.Mock.On("GenerateEmailVerificationToken", int(sut.user.Id.Int32), sut.user.Email.String, 1440).Return("emailVerificationToken", nil)
+	sut.mailerMock.Mock.On("Send", sut.user.Email.String, "Verify your email", mock.Anything).Return(sut.errInternalServer)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer).Return(nil)
+	httpCode, accessToken, refreshToken, response := sut.userService.Register(sut.ctx, sut.registerRequest)
+	sut.Equal(httpCode, http.StatusInternalServerError)
+	sut.Equal(accessToken, "")
+	sut.Equal(refreshToken, "")
+	sut.NotEqual(response, nil)
+}
+
+func (sut *UserServiceTestSuite) Test26VerifyEmailInvalidToken() {
+	sut.T().Log("Test26VerifyEmailInvalidToken")
+	var claims *helpers.EmailVerificationTokenCustomClaims
+	sut.jwtHelperMock.Mock.On("ParseEmailVerificationToken", "emailVerificationToken").Return(claims, sut.errInternalServer)
+	httpCode, response := sut.userService.VerifyEmail(sut.ctx, "emailVerificationToken")
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *UserServiceTestSuite) Test27VerifyEmailChangedEmail() {
+	sut.T().Log("Test27VerifyEmailChangedEmail")
+	claims := &helpers.EmailVerificationTokenCustomClaims{Id: 1, Email: "old@doe.com"}
+	sut.jwtHelperMock.Mock.On("ParseEmailVerificationToken", "emailVerificationToken").Return(claims, nil)
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, response := sut.userService.VerifyEmail(sut.ctx, "emailVerificationToken")
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.NotEqual(response, nil)
+	sut.userRepositoryMock.Mock.AssertNotCalled(sut.T(), "UpdateEmailVerifiedAt", sut.pgxTxMock, sut.ctx, 1)
+}
+
+func (sut *UserServiceTestSuite) Test28VerifyEmailSuccess() {
+	sut.T().Log("Test28VerifyEmailSuccess")
+	claims := &helpers.EmailVerificationTokenCustomClaims{Id: 1, Email: sut.user.Email.String}
+	sut.jwtHelperMock.Mock.On("ParseEmailVerificationToken", "emailVerificationToken").Return(claims, nil)
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, sut.ctx, 1).Return(sut.user, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("UpdateEmailVerifiedAt", sut.pgxTxMock, sut.ctx, 1).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, response := sut.userService.VerifyEmail(sut.ctx, "emailVerificationToken")
+	sut.Equal(httpCode, http.StatusOK)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *UserServiceTestSuite) Test29ResendVerificationEmailThrottled() {
+	sut.T().Log("Test29ResendVerificationEmailThrottled")
+	ctx := helpers.ContextWithPrincipal(sut.ctx, helpers.Principal{Id: 1})
+	sut.user.EmailVerificationSentAt = pgtype.Timestamptz{Valid: true, Time: time.Now().Add(-10 * time.Second)}
+	sut.postgresUtilMock.Mock.On("BeginTx", ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, ctx, 1).Return(sut.user, nil)
+	var rowsAffected int64
+	sut.userRepositoryMock.Mock.On("UpdateEmailVerificationSentAt", sut.pgxTxMock, ctx, 1, 60).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, ctx, nil).Return(nil)
+	httpCode, response := sut.userService.ResendVerificationEmail(ctx)
+	sut.Equal(httpCode, http.StatusTooManyRequests)
+	retryAfterResponse, ok := response.(modelresponses.RetryAfterResponse)
+	sut.True(ok)
+	sut.InDelta(retryAfterResponse.RetryAfter, 50, 2)
+	sut.mailerMock.Mock.AssertNotCalled(sut.T(), "Send", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (sut *UserServiceTestSuite) Test30ResendVerificationEmailSuccess() {
+	sut.T().Log("Test30ResendVerificationEmailSuccess")
+	ctx := helpers.ContextWithPrincipal(sut.ctx, helpers.Principal{Id: 1})
+	sut.postgresUtilMock.Mock.On("BeginTx", ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.userRepositoryMock.Mock.On("FindById", sut.pgxTxMock, ctx, 1).Return(sut.user, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("UpdateEmailVerificationSentAt", sut.pgxTxMock, ctx, 1, 60).Return(rowsAffected, nil)
+	sut.jwtHelperMock.Mock.On("GenerateEmailVerificationToken", 1, sut.user.Email.String, 1440).Return("emailVerificationToken", nil)
+	sut.mailerMock.Mock.On("Send", sut.user.Email.String, "Verify your email", mock.Anything).Return(nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, ctx, nil).Return(nil)
+	httpCode, response := sut.userService.ResendVerificationEmail(ctx)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.NotEqual(response, nil)
+}
+
+func (sut *UserServiceTestSuite) Test31LoginTwoFactorRequired() {
+	sut.T().Log("Test31LoginTwoFactorRequired")
+	sut.user.TotpEnabledAt = pgtype.Timestamptz{Valid: true, Time: time.Now()}
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(modelentities.LoginAttempt{}, pgx.ErrNoRows)
+	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, sut.loginRequest.Email).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", sut.user.Password.String, sut.loginRequest.Password).Return(false, nil)
+	sut.jwtHelperMock.Mock.On("GenerateMfaPendingToken", int(sut.user.Id.Int32), 5).Return("mfaToken", nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, accessToken, refreshToken, response := sut.userService.Login(sut.ctx, sut.loginRequest)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(accessToken, "")
+	sut.Equal(refreshToken, "")
+	sut.Equal(response, modelresponses.MfaRequiredResponse{
+		Message:     "two factor authentication code required",
+		MfaRequired: true,
+		MfaToken:    "mfaToken",
+	})
+	sut.jwtHelperMock.Mock.AssertNotCalled(sut.T(), "GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, 15)
+	sut.loginAttemptRepositoryMock.Mock.AssertNotCalled(sut.T(), "DeleteByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com")
+}
+
+func (sut *UserServiceTestSuite) Test32LoginLocked() {
+	sut.T().Log("Test32LoginLocked")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	loginAttempt := modelentities.LoginAttempt{
+		AttemptKey:  pgtype.Text{Valid: true, String: "email:john@doe.com"},
+		FailedCount: pgtype.Int4{Valid: true, Int32: 5},
+		LockedUntil: pgtype.Timestamptz{Valid: true, Time: time.Now().Add(time.Minute)},
+	}
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(loginAttempt, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, accessToken, refreshToken, response := sut.userService.Login(sut.ctx, sut.loginRequest)
+	sut.Equal(httpCode, http.StatusTooManyRequests)
+	sut.Equal(accessToken, "")
+	sut.Equal(refreshToken, "")
+	sut.Equal(response.(modelresponses.RetryAfterResponse).RetryAfter, 60)
+	sut.userRepositoryMock.Mock.AssertNotCalled(sut.T(), "FindByEmail", sut.pgxTxMock, sut.ctx, sut.loginRequest.Email)
+	sut.securityEventRepositoryMock.Mock.AssertCalled(sut.T(), "Create", sut.pgxTxMock, sut.ctx, mock.MatchedBy(func(securityEvent modelentities.SecurityEvent) bool {
+		return securityEvent.EventType.String == helpers.SecurityEventLoginFailed && securityEvent.Detail.String == "locked" && !securityEvent.UserId.Valid && securityEvent.Email.String == "john@doe.com"
+	}))
+}
+
+func (sut *UserServiceTestSuite) Test33LoginIpLocked() {
+	sut.T().Log("Test33LoginIpLocked")
+	ctx := helpers.ContextWithClientInfo(sut.ctx, helpers.ClientInfo{IpAddress: "10.0.0.1"})
+	sut.postgresUtilMock.Mock.On("BeginTx", ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, ctx, "email:john@doe.com").Return(modelentities.LoginAttempt{}, pgx.ErrNoRows)
+	loginAttempt := modelentities.LoginAttempt{
+		AttemptKey:  pgtype.Text{Valid: true, String: "ip:10.0.0.1"},
+		FailedCount: pgtype.Int4{Valid: true, Int32: 20},
+		LockedUntil: pgtype.Timestamptz{Valid: true, Time: time.Now().Add(30 * time.Second)},
+	}
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, ctx, "ip:10.0.0.1").Return(loginAttempt, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, ctx, nil).Return(nil)
+	httpCode, _, _, response := sut.userService.Login(ctx, sut.loginRequest)
+	sut.Equal(httpCode, http.StatusTooManyRequests)
+	sut.Equal(response.(modelresponses.RetryAfterResponse).RetryAfter, 30)
+}
+
+func (sut *UserServiceTestSuite) Test34LoginFailureLocksAccount() {
+	sut.T().Log("Test34LoginFailureLocksAccount")
+	ctx := helpers.ContextWithClientInfo(sut.ctx, helpers.ClientInfo{IpAddress: "10.0.0.1"})
+	sut.postgresUtilMock.Mock.On("BeginTx", ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, ctx, "email:john@doe.com").Return(modelentities.LoginAttempt{FailedCount: pgtype.Int4{Valid: true, Int32: 5}}, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, ctx, "ip:10.0.0.1").Return(modelentities.LoginAttempt{}, pgx.ErrNoRows)
+	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, ctx, sut.loginRequest.Email).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", sut.user.Password.String, sut.loginRequest.Password).Return(false, helpers.ErrPasswordMismatch)
+	sut.loginAttemptRepositoryMock.Mock.On("IncrementFailedCount", sut.pgxTxMock, ctx, "email:john@doe.com", 900).Return(6, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("IncrementFailedCount", sut.pgxTxMock, ctx, "ip:10.0.0.1", 900).Return(6, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.loginAttemptRepositoryMock.Mock.On("UpdateLockedUntil", sut.pgxTxMock, ctx, "email:john@doe.com", 120).Return(rowsAffected, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("CreateLockoutEvent", sut.pgxTxMock, ctx, mock.MatchedBy(func(loginLockoutEvent modelentities.LoginLockoutEvent) bool {
+		return loginLockoutEvent.AttemptKey.String == "email:john@doe.com" && loginLockoutEvent.IpAddress.String == "10.0.0.1" && loginLockoutEvent.FailedCount.Int32 == 6
+	})).Return(1, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, ctx, nil).Return(nil)
+	httpCode, accessToken, refreshToken, response := sut.userService.Login(ctx, sut.loginRequest)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(accessToken, "")
+	sut.Equal(refreshToken, "")
+	sut.NotEqual(response, nil)
+	sut.loginAttemptRepositoryMock.Mock.AssertNotCalled(sut.T(), "UpdateLockedUntil", sut.pgxTxMock, ctx, "ip:10.0.0.1", mock.Anything)
+	sut.securityEventRepositoryMock.Mock.AssertCalled(sut.T(), "Create", sut.pgxTxMock, ctx, mock.MatchedBy(func(securityEvent modelentities.SecurityEvent) bool {
+		return securityEvent.EventType.String == helpers.SecurityEventLoginFailed && securityEvent.Detail.String == "wrong_password" && securityEvent.IpAddress.String == "10.0.0.1"
+	}))
+	sut.securityEventRepositoryMock.Mock.AssertCalled(sut.T(), "Create", sut.pgxTxMock, ctx, mock.MatchedBy(func(securityEvent modelentities.SecurityEvent) bool {
+		return securityEvent.EventType.String == helpers.SecurityEventAccountLocked && securityEvent.Detail.String == "email:john@doe.com" && securityEvent.UserId.Int32 == 1
+	}))
+	sut.Contains(sut.scrapeMetrics(), `logins_total{reason="wrong_password",result="failed"} 1`)
+}
+
+func (sut *UserServiceTestSuite) Test35RegisterPasswordPolicyViolation() {
+	sut.T().Log("Test35RegisterPasswordPolicyViolation")
+	violations := []modelresponses.PasswordPolicyViolation{{Code: "contains_name", Message: "password must not contain the name"}}
+	sut.passwordPolicyHelperMock.Mock.On("Check", sut.registerRequest.Password, sut.registerRequest.Name, sut.registerRequest.Email).Return(violations, nil)
+	httpCode, accessToken, refreshToken, response := sut.userService.Register(sut.ctx, sut.registerRequest)
+	sut.Equal(httpCode, http.StatusBadRequest)
+	sut.Equal(accessToken, "")
+	sut.Equal(refreshToken, "")
+	sut.Equal(response, modelresponses.PasswordPolicyResponse{
+		Message:    "password does not meet the password policy",
+		Violations: violations,
+	})
+	sut.postgresUtilMock.Mock.AssertNotCalled(sut.T(), "BeginTx", sut.ctx, sut.options)
+}
+
+func (sut *UserServiceTestSuite) Test36LoginRehashesOutdatedPasswordHash() {
+	sut.T().Log("Test36LoginRehashesOutdatedPasswordHash")
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(modelentities.LoginAttempt{}, pgx.ErrNoRows)
+	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, sut.loginRequest.Email).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", sut.user.Password.String, sut.loginRequest.Password).Return(true, nil)
+	sut.passwordHasherMock.Mock.On("Hash", sut.loginRequest.Password).Return("$argon2id$rehashed", nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("UpdatePassword", sut.pgxTxMock, sut.ctx, "$argon2id$rehashed", int(sut.user.Id.Int32)).Return(rowsAffected, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("DeleteByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(rowsAffected, nil)
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, 15).Return("accessToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), 1).Return("refreshToken", nil)
+	sut.userRepositoryMock.Mock.On("UpdateRefreshToken", sut.pgxTxMock, sut.ctx, "refreshToken", int(sut.user.Id.Int32)).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, accessToken, refreshToken, _ := sut.userService.Login(sut.ctx, sut.loginRequest)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(accessToken, "accessToken")
+	sut.Equal(refreshToken, "refreshToken")
+	sut.userRepositoryMock.Mock.AssertCalled(sut.T(), "UpdatePassword", sut.pgxTxMock, sut.ctx, "$argon2id$rehashed", int(sut.user.Id.Int32))
+}
+
+func (sut *UserServiceTestSuite) Test37LoginCancelsScheduledDeletion() {
+	sut.T().Log("Test37LoginCancelsScheduledDeletion")
+	sut.user.DeletionScheduledAt = pgtype.Timestamptz{Valid: true, Time: time.Now().Add(24 * time.Hour)}
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(modelentities.LoginAttempt{}, pgx.ErrNoRows)
+	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, sut.loginRequest.Email).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", sut.user.Password.String, sut.loginRequest.Password).Return(false, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.loginAttemptRepositoryMock.Mock.On("DeleteByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(rowsAffected, nil)
+	sut.userRepositoryMock.Mock.On("CancelDeletion", sut.pgxTxMock, sut.ctx, int(sut.user.Id.Int32)).Return(rowsAffected, nil)
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, 15).Return("accessToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), 1).Return("refreshToken", nil)
+	sut.userRepositoryMock.Mock.On("UpdateRefreshToken", sut.pgxTxMock, sut.ctx, "refreshToken", int(sut.user.Id.Int32)).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, _, _, _ := sut.userService.Login(sut.ctx, sut.loginRequest)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.userRepositoryMock.Mock.AssertCalled(sut.T(), "CancelDeletion", sut.pgxTxMock, sut.ctx, int(sut.user.Id.Int32))
+}
+
+func (sut *UserServiceTestSuite) Test38LoginDisabledAccount() {
+	sut.T().Log("Test38LoginDisabledAccount")
+	sut.user.DisabledAt = pgtype.Timestamptz{Valid: true, Time: time.Now()}
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(modelentities.LoginAttempt{}, pgx.ErrNoRows)
+	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, sut.loginRequest.Email).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", sut.user.Password.String, sut.loginRequest.Password).Return(false, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, nil).Return(nil)
+	httpCode, accessToken, refreshToken, response := sut.userService.Login(sut.ctx, sut.loginRequest)
+	sut.Equal(httpCode, http.StatusForbidden)
+	sut.Equal(accessToken, "")
+	sut.Equal(refreshToken, "")
+	sut.Equal(response, helpers.ToResponse("account is disabled"))
+	sut.jwtHelperMock.Mock.AssertNotCalled(sut.T(), "GenerateRefreshToken", int(sut.user.Id.Int32), 1)
+}
+
+func (sut *UserServiceTestSuite) Test39LoginRecordsSecurityEvent() {
+	sut.T().Log("Test39LoginRecordsSecurityEvent")
+	ctx := helpers.ContextWithClientInfo(sut.ctx, helpers.ClientInfo{UserAgent: "curl/8.0"})
+	sut.postgresUtilMock.Mock.On("BeginTx", ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, ctx, "email:john@doe.com").Return(modelentities.LoginAttempt{}, pgx.ErrNoRows)
+	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, ctx, sut.loginRequest.Email).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", sut.user.Password.String, sut.loginRequest.Password).Return(false, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.loginAttemptRepositoryMock.Mock.On("DeleteByAttemptKey", sut.pgxTxMock, ctx, "email:john@doe.com").Return(rowsAffected, nil)
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, 15).Return("accessToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), 1).Return("refreshToken", nil)
+	sut.userRepositoryMock.Mock.On("UpdateRefreshToken", sut.pgxTxMock, ctx, "refreshToken", int(sut.user.Id.Int32)).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, ctx, nil).Return(nil)
+	httpCode, _, _, _ := sut.userService.Login(ctx, sut.loginRequest)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.securityEventRepositoryMock.Mock.AssertCalled(sut.T(), "Create", sut.pgxTxMock, ctx, mock.MatchedBy(func(securityEvent modelentities.SecurityEvent) bool {
+		return securityEvent.EventType.String == helpers.SecurityEventLoginSucceeded && securityEvent.Detail.String == "password" && securityEvent.UserId.Int32 == 1 && securityEvent.UserAgent.String == "curl/8.0"
+	}))
+}
+
+func (sut *UserServiceTestSuite) Test40LoginSecurityEventErrorRollsBack() {
+	sut.T().Log("Test40LoginSecurityEventErrorRollsBack")
+	sut.securityEventRepositoryMock = new(mockrepositories.SecurityEventRepositoryMock)
+	sut.securityEventRepositoryMock.Mock.On("Create", sut.pgxTxMock, sut.ctx, mock.Anything).Return(0, sut.errInternalServer)
+	sut.userService = services.NewUserService(sut.postgresUtilMock, sut.validate, sut.userRepositoryMock, sut.passwordHasherMock, sut.jwtHelperMock, sut.mailerMock, sut.loginAttemptRepositoryMock, sut.passwordPolicyHelperMock, sut.securityEventRepositoryMock, sut.metricsHelper, sut.config)
+	sut.postgresUtilMock.Mock.On("BeginTx", sut.ctx, sut.options).Return(sut.pgxTxMock, nil)
+	sut.loginAttemptRepositoryMock.Mock.On("FindByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(modelentities.LoginAttempt{}, pgx.ErrNoRows)
+	sut.userRepositoryMock.Mock.On("FindByEmail", sut.pgxTxMock, sut.ctx, sut.loginRequest.Email).Return(sut.user, nil)
+	sut.passwordHasherMock.Mock.On("Verify", sut.user.Password.String, sut.loginRequest.Password).Return(false, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.loginAttemptRepositoryMock.Mock.On("DeleteByAttemptKey", sut.pgxTxMock, sut.ctx, "email:john@doe.com").Return(rowsAffected, nil)
+	sut.jwtHelperMock.Mock.On("GenerateAccessToken", int(sut.user.Id.Int32), sut.user.Name.String, sut.user.Email.String, 15).Return("accessToken", nil)
+	sut.jwtHelperMock.Mock.On("GenerateRefreshToken", int(sut.user.Id.Int32), 1).Return("refreshToken", nil)
+	sut.userRepositoryMock.Mock.On("UpdateRefreshToken", sut.pgxTxMock, sut.ctx, "refreshToken", int(sut.user.Id.Int32)).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, sut.ctx, sut.errInternalServer).Return(nil)
+	httpCode, accessToken, refreshToken, _ := sut.userService.Login(sut.ctx, sut.loginRequest)
+	sut.Equal(httpCode, http.StatusInternalServerError)
+	sut.Equal(accessToken, "")
+	sut.Equal(refreshToken, "")
+}
+
+func (sut *UserServiceTestSuite) Test41LogoutSuccess() {
+	sut.T().Log("Test41LogoutSuccess")
+	ctx := helpers.ContextWithPrincipal(sut.ctx, helpers.Principal{Id: 1, Email: "john@doe.com"})
+	sut.postgresUtilMock.Mock.On("BeginTx", ctx, sut.options).Return(sut.pgxTxMock, nil)
+	var rowsAffected int64
+	rowsAffected = 1
+	sut.userRepositoryMock.Mock.On("RevokeSessions", sut.pgxTxMock, ctx, 1).Return(rowsAffected, nil)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, ctx, nil).Return(nil)
+	httpCode, response := sut.userService.Logout(ctx)
+	sut.Equal(httpCode, http.StatusOK)
+	sut.Equal(response, helpers.ToResponse("successfully logged out"))
+	sut.securityEventRepositoryMock.Mock.AssertCalled(sut.T(), "Create", sut.pgxTxMock, ctx, mock.MatchedBy(func(securityEvent modelentities.SecurityEvent) bool {
+		return securityEvent.EventType.String == helpers.SecurityEventLoggedOut && securityEvent.UserId.Int32 == 1
+	}))
+}
+
+func (sut *UserServiceTestSuite) Test42LogoutRevokeSessionsError() {
+	sut.T().Log("Test42LogoutRevokeSessionsError")
+	ctx := helpers.ContextWithPrincipal(sut.ctx, helpers.Principal{Id: 1})
+	sut.postgresUtilMock.Mock.On("BeginTx", ctx, sut.options).Return(sut.pgxTxMock, nil)
+	var rowsAffected int64
+	sut.userRepositoryMock.Mock.On("RevokeSessions", sut.pgxTxMock, ctx, 1).Return(rowsAffected, sut.errInternalServer)
+	sut.postgresUtilMock.Mock.On("CommitOrRollback", sut.pgxTxMock, ctx, sut.errInternalServer).Return(nil)
+	httpCode, _ := sut.userService.Logout(ctx)
+	sut.Equal(httpCode, http.StatusInternalServerError)
+	sut.securityEventRepositoryMock.Mock.AssertNotCalled(sut.T(), "Create", sut.pgxTxMock, ctx, mock.Anything)
 }
 
 func (sut *UserServiceTestSuite) AfterTest(suiteName, testName string) {
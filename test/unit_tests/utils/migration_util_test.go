@@ -0,0 +1,112 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"todo-list-api/databases/migrations"
+	"todo-list-api/utils"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MigrationUtilTestSuite struct {
+	suite.Suite
+	directory string
+}
+
+func TestMigrationUtilTestSuite(t *testing.T) {
+	suite.Run(t, new(MigrationUtilTestSuite))
+}
+
+func (sut *MigrationUtilTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+}
+
+func (sut *MigrationUtilTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.directory = sut.T().TempDir()
+}
+
+func (sut *MigrationUtilTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *MigrationUtilTestSuite) Test01EmbeddedMigrationsAreNumberedWithoutGaps() {
+	sut.T().Log("Test01EmbeddedMigrationsAreNumberedWithoutGaps")
+	embeddedMigrations, err := utils.ReadMigrations(migrations.Files)
+	sut.Require().NoError(err)
+	sut.Require().NotEmpty(embeddedMigrations)
+	for i, migration := range embeddedMigrations {
+		sut.Equal(migration.Version, i+1)
+	}
+	sut.Equal(embeddedMigrations[0].Name, "create_users_and_todos")
+}
+
+func (sut *MigrationUtilTestSuite) Test02ReadMigrationsInOrder() {
+	sut.T().Log("Test02ReadMigrationsInOrder")
+	readMigrations, err := utils.ReadMigrations(fstest.MapFS{
+		"000002_add_done.up.sql":       {Data: []byte("ALTER TABLE todos ADD done BOOLEAN;")},
+		"000002_add_done.down.sql":     {Data: []byte("ALTER TABLE todos DROP done;")},
+		"000001_create_todos.up.sql":   {Data: []byte("CREATE TABLE todos (id INT);")},
+		"000001_create_todos.down.sql": {Data: []byte("DROP TABLE todos;")},
+		"README.md":                    {Data: []byte("notes")},
+	})
+	sut.Require().NoError(err)
+	sut.Equal(readMigrations, []utils.Migration{
+		{Version: 1, Name: "create_todos", Up: "CREATE TABLE todos (id INT);", Down: "DROP TABLE todos;"},
+		{Version: 2, Name: "add_done", Up: "ALTER TABLE todos ADD done BOOLEAN;", Down: "ALTER TABLE todos DROP done;"},
+	})
+}
+
+func (sut *MigrationUtilTestSuite) Test03ReadMigrationsWithoutDownFile() {
+	sut.T().Log("Test03ReadMigrationsWithoutDownFile")
+	_, err := utils.ReadMigrations(fstest.MapFS{
+		"000001_create_todos.up.sql": {Data: []byte("CREATE TABLE todos (id INT);")},
+	})
+	sut.Require().Error(err)
+	sut.Equal(err.Error(), "migration 1_create_todos needs a non empty up and down file")
+}
+
+func (sut *MigrationUtilTestSuite) Test04ReadMigrationsWithTwoNames() {
+	sut.T().Log("Test04ReadMigrationsWithTwoNames")
+	_, err := utils.ReadMigrations(fstest.MapFS{
+		"000001_create_todos.up.sql":   {Data: []byte("CREATE TABLE todos (id INT);")},
+		"000001_create_tasks.down.sql": {Data: []byte("DROP TABLE tasks;")},
+	})
+	sut.Require().Error(err)
+	sut.Equal(err.Error(), "migration 1 has two names, create_tasks and create_todos")
+}
+
+func (sut *MigrationUtilTestSuite) Test05CreateMigrationAfterTheLastVersion() {
+	sut.T().Log("Test05CreateMigrationAfterTheLastVersion")
+	sut.Require().NoError(os.WriteFile(filepath.Join(sut.directory, "000007_create_todos.up.sql"), []byte("CREATE TABLE todos (id INT);"), 0600))
+	sut.Require().NoError(os.WriteFile(filepath.Join(sut.directory, "000007_create_todos.down.sql"), []byte("DROP TABLE todos;"), 0600))
+	upPath, downPath, err := utils.CreateMigration(sut.directory, "add_done")
+	sut.Require().NoError(err)
+	sut.Equal(upPath, filepath.Join(sut.directory, "000008_add_done.up.sql"))
+	sut.Equal(downPath, filepath.Join(sut.directory, "000008_add_done.down.sql"))
+	sut.FileExists(upPath)
+	sut.FileExists(downPath)
+}
+
+func (sut *MigrationUtilTestSuite) Test06CreateMigrationInvalidName() {
+	sut.T().Log("Test06CreateMigrationInvalidName")
+	_, _, err := utils.CreateMigration(sut.directory, "Add Done")
+	sut.Require().Error(err)
+	entries, _ := os.ReadDir(sut.directory)
+	sut.Empty(entries)
+}
+
+func (sut *MigrationUtilTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *MigrationUtilTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+}
+
+func (sut *MigrationUtilTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
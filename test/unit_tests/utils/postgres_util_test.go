@@ -0,0 +1,123 @@
+package utils_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	"todo-list-api/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type PostgresUtilTestSuite struct {
+	suite.Suite
+	ctx          context.Context
+	output       *bytes.Buffer
+	queryLogger  *utils.QueryLogger
+	queryTracer  *utils.QueryTracer
+	spanRecorder *tracetest.SpanRecorder
+}
+
+func TestPostgresUtilTestSuite(t *testing.T) {
+	suite.Run(t, new(PostgresUtilTestSuite))
+}
+
+func (sut *PostgresUtilTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+}
+
+func (sut *PostgresUtilTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.output = new(bytes.Buffer)
+	sut.ctx = helpers.ContextWithLogger(context.Background(), helpers.NewLogger(config.LogConfig{Level: "debug"}, sut.output))
+	sut.queryLogger = &utils.QueryLogger{}
+	sut.queryTracer = &utils.QueryTracer{}
+	sut.spanRecorder = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sut.spanRecorder)))
+}
+
+func (sut *PostgresUtilTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *PostgresUtilTestSuite) Test01QueryLoggerLogsQueriesWithoutArguments() {
+	sut.T().Log("Test01QueryLoggerLogsQueriesWithoutArguments")
+	ctx := sut.queryLogger.TraceQueryStart(sut.ctx, nil, pgx.TraceQueryStartData{SQL: "UPDATE users SET password = $1 WHERE id = $2;", Args: []any{"hash", 1}})
+	sut.queryLogger.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("UPDATE 1")})
+	sut.Contains(sut.output.String(), `"level":"DEBUG"`)
+	sut.Contains(sut.output.String(), `"sql":"UPDATE users SET password = $1 WHERE id = $2;"`)
+	sut.Contains(sut.output.String(), `"rows":1`)
+	sut.NotContains(sut.output.String(), "hash")
+}
+
+func (sut *PostgresUtilTestSuite) Test02QueryLoggerLogsFailedQueries() {
+	sut.T().Log("Test02QueryLoggerLogsFailedQueries")
+	ctx := sut.queryLogger.TraceQueryStart(sut.ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT 1;"})
+	sut.queryLogger.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("connection refused")})
+	sut.Contains(sut.output.String(), `"level":"ERROR"`)
+	sut.Contains(sut.output.String(), `"error":"connection refused"`)
+}
+
+func (sut *PostgresUtilTestSuite) Test03QueryLoggerIgnoresNoRows() {
+	sut.T().Log("Test03QueryLoggerIgnoresNoRows")
+	ctx := sut.queryLogger.TraceQueryStart(sut.ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT 1;"})
+	sut.queryLogger.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: pgx.ErrNoRows})
+	sut.Contains(sut.output.String(), `"level":"DEBUG"`)
+}
+
+func (sut *PostgresUtilTestSuite) Test04QueryTracerRecordsQueriesWithoutArguments() {
+	sut.T().Log("Test04QueryTracerRecordsQueriesWithoutArguments")
+	ctx := sut.queryTracer.TraceQueryStart(sut.ctx, nil, pgx.TraceQueryStartData{SQL: " update users SET password = $1 WHERE id = $2;", Args: []any{"hash", 1}})
+	sut.queryTracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("UPDATE 1")})
+	spans := sut.spanRecorder.Ended()
+	sut.Require().Len(spans, 1)
+	sut.Equal(spans[0].Name(), "UPDATE")
+	sut.Contains(spans[0].Attributes(), attribute.String("db.system", "postgresql"))
+	sut.Contains(spans[0].Attributes(), attribute.String("db.query.text", " update users SET password = $1 WHERE id = $2;"))
+	sut.Contains(spans[0].Attributes(), attribute.Int64("db.rows_affected", 1))
+	sut.Equal(spans[0].Status().Code, codes.Unset)
+}
+
+func (sut *PostgresUtilTestSuite) Test05QueryTracerRecordsFailedQueries() {
+	sut.T().Log("Test05QueryTracerRecordsFailedQueries")
+	ctx := sut.queryTracer.TraceQueryStart(sut.ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT 1;"})
+	sut.queryTracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("connection refused")})
+	ctx = sut.queryTracer.TraceQueryStart(sut.ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT 1;"})
+	sut.queryTracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: pgx.ErrNoRows})
+	spans := sut.spanRecorder.Ended()
+	sut.Require().Len(spans, 2)
+	sut.Equal(spans[0].Status(), sdktrace.Status{Code: codes.Error, Description: "connection refused"})
+	sut.Equal(spans[1].Status().Code, codes.Unset)
+}
+
+func (sut *PostgresUtilTestSuite) Test06QueryTracerRecordsNothingWhenNotSampled() {
+	sut.T().Log("Test06QueryTracerRecordsNothingWhenNotSampled")
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.NeverSample()), sdktrace.WithSpanProcessor(sut.spanRecorder)))
+	ctx := sut.queryTracer.TraceQueryStart(sut.ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT 1;"})
+	sut.queryTracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	sut.Empty(sut.spanRecorder.Ended())
+}
+
+func (sut *PostgresUtilTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *PostgresUtilTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+	otel.SetTracerProvider(noop.NewTracerProvider())
+}
+
+func (sut *PostgresUtilTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
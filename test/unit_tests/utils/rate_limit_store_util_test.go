@@ -0,0 +1,104 @@
+package utils_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-list-api/helpers"
+	mockrepositories "todo-list-api/test/unit_tests/repositories/mocks"
+	mockutils "todo-list-api/test/unit_tests/utils/mocks"
+	"todo-list-api/utils"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/suite"
+)
+
+type PostgresRateLimitStoreTestSuite struct {
+	suite.Suite
+	ctx                     context.Context
+	pool                    *pgxpool.Pool
+	now                     time.Time
+	policy                  helpers.RateLimitPolicy
+	errInternalServer       error
+	postgresUtilMock        *mockutils.PostgresUtilMock
+	rateLimitRepositoryMock *mockrepositories.RateLimitRepositoryMock
+	rateLimitStore          helpers.RateLimitStore
+}
+
+func TestPostgresRateLimitStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(PostgresRateLimitStoreTestSuite))
+}
+
+func (sut *PostgresRateLimitStoreTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.ctx = context.Background()
+	sut.pool = &pgxpool.Pool{}
+	sut.now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sut.policy = helpers.RateLimitPolicy{Limit: 10, Window: time.Minute}
+	sut.errInternalServer = errors.New("internal server error")
+}
+
+func (sut *PostgresRateLimitStoreTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.postgresUtilMock = new(mockutils.PostgresUtilMock)
+	sut.rateLimitRepositoryMock = new(mockrepositories.RateLimitRepositoryMock)
+	sut.rateLimitStore = utils.NewPostgresRateLimitStore(sut.postgresUtilMock, sut.rateLimitRepositoryMock)
+	sut.postgresUtilMock.Mock.On("GetPool").Return(sut.pool)
+}
+
+func (sut *PostgresRateLimitStoreTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *PostgresRateLimitStoreTestSuite) Test01TakeAllowed() {
+	sut.T().Log("Test01TakeAllowed")
+	sut.rateLimitRepositoryMock.Mock.On("DeleteExpired", sut.pool, sut.ctx, sut.now).Return(int64(3), nil)
+	sut.rateLimitRepositoryMock.Mock.On("TakeToken", sut.pool, sut.ctx, "default|ip:10.0.0.1", 10, 60.0, sut.now).Return(9.0, true, nil)
+	result, err := sut.rateLimitStore.Take(sut.ctx, "default|ip:10.0.0.1", sut.policy, sut.now)
+	sut.NoError(err)
+	sut.Equal(result, helpers.RateLimitResult{
+		Allowed:    true,
+		Limit:      10,
+		Remaining:  9,
+		ResetAfter: 6 * time.Second,
+	})
+}
+
+func (sut *PostgresRateLimitStoreTestSuite) Test02TakeDenied() {
+	sut.T().Log("Test02TakeDenied")
+	sut.rateLimitRepositoryMock.Mock.On("DeleteExpired", sut.pool, sut.ctx, sut.now).Return(int64(0), nil)
+	sut.rateLimitRepositoryMock.Mock.On("TakeToken", sut.pool, sut.ctx, "default|user:1", 10, 60.0, sut.now).Return(0.5, false, nil)
+	result, err := sut.rateLimitStore.Take(sut.ctx, "default|user:1", sut.policy, sut.now)
+	sut.NoError(err)
+	sut.False(result.Allowed)
+	sut.Equal(result.RetryAfter, 3*time.Second)
+}
+
+func (sut *PostgresRateLimitStoreTestSuite) Test03SweepsAtMostOnceAMinute() {
+	sut.T().Log("Test03SweepsAtMostOnceAMinute")
+	sut.rateLimitRepositoryMock.Mock.On("DeleteExpired", sut.pool, sut.ctx, sut.now).Return(int64(0), nil)
+	sut.rateLimitRepositoryMock.Mock.On("DeleteExpired", sut.pool, sut.ctx, sut.now.Add(time.Minute)).Return(int64(0), nil)
+	for _, now := range []time.Time{sut.now, sut.now.Add(time.Second), sut.now.Add(59 * time.Second), sut.now.Add(time.Minute)} {
+		sut.rateLimitRepositoryMock.Mock.On("TakeToken", sut.pool, sut.ctx, "key", 10, 60.0, now).Return(9.0, true, nil)
+		_, err := sut.rateLimitStore.Take(sut.ctx, "key", sut.policy, now)
+		sut.NoError(err)
+	}
+	sut.rateLimitRepositoryMock.Mock.AssertNumberOfCalls(sut.T(), "DeleteExpired", 2)
+}
+
+func (sut *PostgresRateLimitStoreTestSuite) Test04TakeTokenError() {
+	sut.T().Log("Test04TakeTokenError")
+	sut.rateLimitRepositoryMock.Mock.On("DeleteExpired", sut.pool, sut.ctx, sut.now).Return(int64(0), nil)
+	sut.rateLimitRepositoryMock.Mock.On("TakeToken", sut.pool, sut.ctx, "key", 10, 60.0, sut.now).Return(0.0, false, sut.errInternalServer)
+	_, err := sut.rateLimitStore.Take(sut.ctx, "key", sut.policy, sut.now)
+	sut.Equal(err, sut.errInternalServer)
+}
+
+func (sut *PostgresRateLimitStoreTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *PostgresRateLimitStoreTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+}
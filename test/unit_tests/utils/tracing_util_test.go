@@ -0,0 +1,96 @@
+package utils_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
+	"todo-list-api/utils"
+
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type TracingUtilTestSuite struct {
+	suite.Suite
+	ctx           context.Context
+	tracingConfig config.TracingConfig
+}
+
+func TestTracingUtilTestSuite(t *testing.T) {
+	suite.Run(t, new(TracingUtilTestSuite))
+}
+
+func (sut *TracingUtilTestSuite) SetupSuite() {
+	sut.T().Log("SetupSuite")
+	sut.ctx = context.Background()
+}
+
+func (sut *TracingUtilTestSuite) SetupTest() {
+	sut.T().Log("SetupTest")
+	sut.tracingConfig = config.Default().Tracing
+}
+
+func (sut *TracingUtilTestSuite) BeforeTest(suiteName, testName string) {
+	sut.T().Log("BeforeTest: " + suiteName + " " + testName)
+}
+
+func (sut *TracingUtilTestSuite) Test01NoneRecordsNothing() {
+	sut.T().Log("Test01NoneRecordsNothing")
+	tracingUtil, err := utils.NewTracing(sut.tracingConfig)
+	sut.Require().NoError(err)
+	_, span := helpers.StartSpan(sut.ctx, "TodoService.Create")
+	sut.False(span.IsRecording())
+	sut.NoError(tracingUtil.Shutdown(sut.ctx))
+	sut.Contains(otel.GetTextMapPropagator().Fields(), "traceparent")
+}
+
+func (sut *TracingUtilTestSuite) Test02FileExporter() {
+	sut.T().Log("Test02FileExporter")
+	sut.tracingConfig.Exporter = "file"
+	sut.tracingConfig.FilePath = filepath.Join(sut.T().TempDir(), "traces.json")
+	tracingUtil, err := utils.NewTracing(sut.tracingConfig)
+	sut.Require().NoError(err)
+	_, span := helpers.StartSpan(sut.ctx, "TodoService.Create")
+	helpers.EndSpan(span, http.StatusCreated, nil)
+	sut.Require().NoError(tracingUtil.Shutdown(sut.ctx))
+	traces, err := os.ReadFile(sut.tracingConfig.FilePath)
+	sut.Require().NoError(err)
+	sut.Contains(string(traces), `"Name":"TodoService.Create"`)
+	sut.Contains(string(traces), `"Value":"todo-list-api"`)
+}
+
+func (sut *TracingUtilTestSuite) Test03SamplePercentZero() {
+	sut.T().Log("Test03SamplePercentZero")
+	sut.tracingConfig.Exporter = "file"
+	sut.tracingConfig.FilePath = filepath.Join(sut.T().TempDir(), "traces.json")
+	sut.tracingConfig.SamplePercent = 0
+	tracingUtil, err := utils.NewTracing(sut.tracingConfig)
+	sut.Require().NoError(err)
+	_, span := helpers.StartSpan(sut.ctx, "TodoService.Create")
+	sut.False(span.IsRecording())
+	helpers.EndSpan(span, http.StatusCreated, nil)
+	sut.Require().NoError(tracingUtil.Shutdown(sut.ctx))
+	traces, err := os.ReadFile(sut.tracingConfig.FilePath)
+	sut.Require().NoError(err)
+	sut.Empty(traces)
+}
+
+func (sut *TracingUtilTestSuite) AfterTest(suiteName, testName string) {
+	sut.T().Log("AfterTest: " + suiteName + " " + testName)
+}
+
+func (sut *TracingUtilTestSuite) TearDownTest() {
+	sut.T().Log("TearDownTest")
+	otel.SetTracerProvider(noop.NewTracerProvider())
+}
+
+func (sut *TracingUtilTestSuite) TearDownSuite() {
+	sut.T().Log("TearDownSuite")
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+}
@@ -0,0 +1,274 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationLockKey is the key of the advisory lock that keeps two instances from migrating at the same time.
+const migrationLockKey int64 = 7370617279656473
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt pgtype.Timestamptz
+}
+
+type MigrationUtil interface {
+	Up(ctx context.Context) (applied []Migration, err error)
+	Down(ctx context.Context, steps int) (reverted []Migration, err error)
+	Status(ctx context.Context) (migrationStatuses []MigrationStatus, err error)
+}
+
+type MigrationUtilImplementation struct {
+	postgresUtil PostgresUtil
+	migrations   []Migration
+}
+
+func NewMigrationUtil(postgresUtil PostgresUtil, files fs.FS) (MigrationUtil, error) {
+	migrations, err := ReadMigrations(files)
+	if err != nil {
+		return nil, err
+	}
+	return &MigrationUtilImplementation{
+		postgresUtil: postgresUtil,
+		migrations:   migrations,
+	}, nil
+}
+
+// ReadMigrations reads the NNNNNN_name.up.sql and NNNNNN_name.down.sql files in the root of files, ordered by version.
+// Every version needs both files, other files are ignored.
+func ReadMigrations(files fs.FS) (migrations []Migration, err error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return
+	}
+	migrationsByVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, errRead := fs.ReadFile(files, entry.Name())
+		if errRead != nil {
+			return nil, errRead
+		}
+		migration, ok := migrationsByVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			migrationsByVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names, %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+	for _, migration := range migrationsByVersion {
+		if strings.TrimSpace(migration.Up) == "" || strings.TrimSpace(migration.Down) == "" {
+			return nil, fmt.Errorf("migration %d_%s needs a non empty up and down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return
+}
+
+// CreateMigration writes an empty up and down file to dir, numbered after the highest version already there.
+func CreateMigration(dir string, name string) (upPath string, downPath string, err error) {
+	if !regexp.MustCompile(`^[a-z0-9_]+$`).MatchString(name) {
+		err = errors.New("the name of a migration can only have lowercase letters, digits and underscores")
+		return
+	}
+	migrations, err := ReadMigrations(os.DirFS(dir))
+	if err != nil {
+		return
+	}
+	version := 1
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+	prefix := filepath.Join(dir, fmt.Sprintf("%06d_%s", version, name))
+	upPath = prefix + ".up.sql"
+	downPath = prefix + ".down.sql"
+	err = os.WriteFile(upPath, []byte("\n"), 0o644)
+	if err != nil {
+		return
+	}
+	err = os.WriteFile(downPath, []byte("\n"), 0o644)
+	return
+}
+
+func (util *MigrationUtilImplementation) Up(ctx context.Context) (applied []Migration, err error) {
+	err = util.withLock(ctx, func(conn *pgxpool.Conn) error {
+		appliedVersions, err := findAppliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range util.migrations {
+			if _, ok := appliedVersions[migration.Version]; ok {
+				continue
+			}
+			err = util.run(ctx, conn, migration.Up, `INSERT INTO schema_migrations (version,name) VALUES ($1,$2);`, migration.Version, migration.Name)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return
+}
+
+// Down reverts the last steps applied migrations, newest first.
+func (util *MigrationUtilImplementation) Down(ctx context.Context, steps int) (reverted []Migration, err error) {
+	err = util.withLock(ctx, func(conn *pgxpool.Conn) error {
+		appliedVersions, err := findAppliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(util.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := util.migrations[i]
+			if _, ok := appliedVersions[migration.Version]; !ok {
+				continue
+			}
+			for version := range appliedVersions {
+				if version > migration.Version {
+					return fmt.Errorf("migration %d is applied but its files are missing", version)
+				}
+			}
+			err = util.run(ctx, conn, migration.Down, `DELETE FROM schema_migrations WHERE version = $1;`, migration.Version)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			delete(appliedVersions, migration.Version)
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return
+}
+
+// Status lists every migration with the time it was applied, followed by applied versions that have no files.
+func (util *MigrationUtilImplementation) Status(ctx context.Context) (migrationStatuses []MigrationStatus, err error) {
+	err = util.withLock(ctx, func(conn *pgxpool.Conn) error {
+		appliedVersions, err := findAppliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range util.migrations {
+			migrationStatus := MigrationStatus{Version: migration.Version, Name: migration.Name}
+			if appliedVersion, ok := appliedVersions[migration.Version]; ok {
+				migrationStatus.AppliedAt = appliedVersion.AppliedAt
+				delete(appliedVersions, migration.Version)
+			}
+			migrationStatuses = append(migrationStatuses, migrationStatus)
+		}
+		for _, appliedVersion := range appliedVersions {
+			migrationStatuses = append(migrationStatuses, appliedVersion)
+		}
+		sort.SliceStable(migrationStatuses, func(i, j int) bool {
+			return migrationStatuses[i].Version < migrationStatuses[j].Version
+		})
+		return nil
+	})
+	return
+}
+
+// withLock runs fn on one connection holding the advisory lock, a second instance waits until the first is done and
+// then finds the migrations applied. The lock is released with the connection if the process dies.
+func (util *MigrationUtilImplementation) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) (err error) {
+	conn, err := util.postgresUtil.GetPool().Acquire(ctx)
+	if err != nil {
+		return
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, `SELECT pg_advisory_lock($1);`, migrationLockKey)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_, errUnlock := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1);`, migrationLockKey)
+		if err == nil {
+			err = errUnlock
+		}
+	}()
+
+	_, err = conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);`)
+	if err != nil {
+		return
+	}
+	return fn(conn)
+}
+
+// run executes the sql of a migration and the change to schema_migrations in one transaction.
+func (util *MigrationUtilImplementation) run(ctx context.Context, conn *pgxpool.Conn, sql string, query string, arguments ...any) (err error) {
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return
+	}
+	// a no-op once committed
+	defer tx.Rollback(context.Background())
+
+	_, err = tx.Exec(ctx, sql)
+	if err != nil {
+		return
+	}
+	_, err = tx.Exec(ctx, query, arguments...)
+	if err != nil {
+		return
+	}
+	return tx.Commit(ctx)
+}
+
+func findAppliedVersions(ctx context.Context, conn *pgxpool.Conn) (appliedVersions map[int]MigrationStatus, err error) {
+	rows, err := conn.Query(ctx, `SELECT version,name,applied_at FROM schema_migrations;`)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	appliedVersions = map[int]MigrationStatus{}
+	for rows.Next() {
+		var migrationStatus MigrationStatus
+		err = rows.Scan(&migrationStatus.Version, &migrationStatus.Name, &migrationStatus.AppliedAt)
+		if err != nil {
+			return
+		}
+		appliedVersions[migrationStatus.Version] = migrationStatus
+	}
+	err = rows.Err()
+	return
+}
@@ -4,14 +4,25 @@ import (
 	"context"
 	"errors"
 	"log"
-	"os"
-	"strconv"
+	"log/slog"
+	"net/url"
+	"strings"
 	"time"
+	"todo-list-api/config"
+	"todo-list-api/helpers"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/multitracer"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// ApplicationName is the application_name of the connections, a transaction of a request adds the request id.
+const ApplicationName = "todo-list-api"
+
 type PostgresUtil interface {
 	GetPool() *pgxpool.Pool
 	BeginTx(ctx context.Context, options pgx.TxOptions) (pgx.Tx, error)
@@ -23,36 +34,29 @@ type PostgresUtilImplementation struct {
 	pool *pgxpool.Pool
 }
 
-func NewPostgresConnection() PostgresUtil {
-	println(time.Now().String(), "postgres: connecting to", os.Getenv("POSTGRES_HOST"))
+func NewPostgresConnection(postgresConfig config.PostgresConfig) PostgresUtil {
+	slog.Info("postgres: connecting", slog.String("host", postgresConfig.Host))
 	ctx := context.Background()
-	connectionString := "postgres://" + os.Getenv("POSTGRES_USERNAME") + ":" + os.Getenv("POSTGRES_PASSWORD") + "@" + os.Getenv("POSTGRES_HOST") + "/" + os.Getenv("POSTGRES_DATABASE")
-	config, err := pgxpool.ParseConfig(connectionString)
-	if err != nil {
-		log.Fatalln("error when parse config: " + err.Error())
-	}
-
-	maxConnection, err := strconv.Atoi(os.Getenv("POSTGRES_MAX_CONNECTION"))
-	if err != nil {
-		log.Fatalln("error when converting max connection: " + err.Error())
-	}
-	config.MaxConns = int32(maxConnection)
-
-	maxConnectionIdletime, err := strconv.Atoi(os.Getenv("POSTGRES_MAX_IDLETIME"))
-	if err != nil {
-		log.Fatalln("error when converting max connection idletime: " + err.Error())
+	connectionUrl := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(postgresConfig.Username, postgresConfig.Password),
+		Host:   postgresConfig.Host,
+		Path:   "/" + postgresConfig.Database,
 	}
-	config.MaxConnIdleTime = time.Second * time.Duration(maxConnectionIdletime)
-
-	maxConnectionLifetime, err := strconv.Atoi(os.Getenv("POSTGRES_MAX_LIFETIME"))
+	poolConfig, err := pgxpool.ParseConfig(connectionUrl.String())
 	if err != nil {
-		log.Fatalln("error when converting max connection lifetime: " + err.Error())
+		log.Fatalln("error when parse config: " + err.Error())
 	}
-	config.MaxConnLifetime = time.Minute * time.Duration(maxConnectionLifetime)
-
-	pool, err := pgxpool.NewWithConfig(ctx, config)
+	poolConfig.MaxConns = int32(postgresConfig.MaxConnection)
+	poolConfig.MaxConnIdleTime = time.Second * time.Duration(postgresConfig.MaxIdletime)
+	poolConfig.MaxConnLifetime = time.Minute * time.Duration(postgresConfig.MaxLifetime)
+	// pgx takes a single tracer
+	poolConfig.ConnConfig.Tracer = multitracer.New(&QueryLogger{}, &QueryTracer{})
+	poolConfig.ConnConfig.RuntimeParams["application_name"] = ApplicationName
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
-		log.Fatalln("error when connecting to " + os.Getenv("POSTGRES_HOST") + ", error:" + err.Error())
+		log.Fatalln("error when connecting to " + postgresConfig.Host + ", error:" + err.Error())
 	}
 
 	err = pool.Ping(ctx)
@@ -60,7 +64,7 @@ func NewPostgresConnection() PostgresUtil {
 		log.Fatalln("error when pinging connection: " + err.Error())
 	}
 
-	println(time.Now().String(), "postgres: connected to", os.Getenv("POSTGRES_HOST"))
+	slog.Info("postgres: connected", slog.String("host", postgresConfig.Host))
 
 	return &PostgresUtilImplementation{
 		pool: pool,
@@ -71,13 +75,26 @@ func (util *PostgresUtilImplementation) GetPool() *pgxpool.Pool {
 	return util.pool
 }
 
+// BeginTx names the transaction after the request in application_name, so pg_stat_activity and a log_line_prefix with
+// %a show which request a slow or blocking query comes from.
 func (util *PostgresUtilImplementation) BeginTx(ctx context.Context, options pgx.TxOptions) (pgx.Tx, error) {
-	return util.pool.BeginTx(ctx, options)
+	tx, err := util.pool.BeginTx(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	if requestId, ok := helpers.RequestIdFromContext(ctx); ok {
+		_, err = tx.Exec(ctx, `SELECT set_config('application_name', $1, true);`, ApplicationName+" "+requestId)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return nil, err
+		}
+	}
+	return tx, nil
 }
 
 func (util *PostgresUtilImplementation) Close() {
 	util.pool.Close()
-	println(time.Now().String(), "postgres closed properly")
+	slog.Info("postgres: closed properly")
 }
 
 func (util *PostgresUtilImplementation) CommitOrRollback(tx pgx.Tx, ctx context.Context, err error) error {
@@ -99,3 +116,66 @@ func (util *PostgresUtilImplementation) CommitOrRollback(tx pgx.Tx, ctx context.
 		return nil
 	}
 }
+
+type queryLoggerContextKey struct{}
+
+type queryStart struct {
+	sql   string
+	start time.Time
+}
+
+// QueryLogger logs every query of the repositories with the logger of the request at debug level, and failed queries
+// at error level. The arguments are left out, they carry password hashes and tokens.
+type QueryLogger struct{}
+
+func (queryLogger *QueryLogger) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryLoggerContextKey{}, queryStart{sql: data.SQL, start: time.Now()})
+}
+
+func (queryLogger *QueryLogger) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	queryStart, _ := ctx.Value(queryLoggerContextKey{}).(queryStart)
+	attrs := []slog.Attr{
+		slog.String("sql", queryStart.sql),
+		slog.Float64("duration_ms", float64(time.Since(queryStart.start).Microseconds())/1000),
+	}
+	logger := helpers.LoggerFromContext(ctx)
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		logger.LogAttrs(ctx, slog.LevelError, "query", append(attrs, slog.String("error", data.Err.Error()))...)
+		return
+	}
+	logger.LogAttrs(ctx, slog.LevelDebug, "query", append(attrs, slog.Int64("rows", data.CommandTag.RowsAffected()))...)
+}
+
+type queryTracerContextKey struct{}
+
+// QueryTracer puts every query in a span of the trace of the request, named after its first keyword, like SELECT. The
+// arguments are left out like in QueryLogger.
+type QueryTracer struct{}
+
+func (queryTracer *QueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := "QUERY"
+	if fields := strings.Fields(data.SQL); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+	ctx, span := helpers.StartSpan(ctx, operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperationName(operation),
+		semconv.DBQueryText(data.SQL),
+	))
+	// a span that is not recorded is not in ctx, trace.SpanFromContext would return the span of the caller
+	return context.WithValue(ctx, queryTracerContextKey{}, span)
+}
+
+func (queryTracer *QueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	span, ok := ctx.Value(queryTracerContextKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	} else {
+		span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	}
+	span.End()
+}
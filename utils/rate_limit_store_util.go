@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+	"todo-list-api/helpers"
+	"todo-list-api/repositories"
+)
+
+type PostgresRateLimitStoreImplementation struct {
+	PostgresUtil        PostgresUtil
+	RateLimitRepository repositories.RateLimitRepository
+	mutex               sync.Mutex
+	sweptAt             time.Time
+}
+
+// NewPostgresRateLimitStore keeps the buckets in postgres so every instance of the api counts against the same limit.
+func NewPostgresRateLimitStore(postgresUtil PostgresUtil, rateLimitRepository repositories.RateLimitRepository) helpers.RateLimitStore {
+	return &PostgresRateLimitStoreImplementation{
+		PostgresUtil:        postgresUtil,
+		RateLimitRepository: rateLimitRepository,
+	}
+}
+
+func (store *PostgresRateLimitStoreImplementation) Take(ctx context.Context, key string, policy helpers.RateLimitPolicy, now time.Time) (result helpers.RateLimitResult, err error) {
+	err = store.sweep(ctx, now)
+	if err != nil {
+		return
+	}
+	remainingTokens, allowed, err := store.RateLimitRepository.TakeToken(store.PostgresUtil.GetPool(), ctx, key, policy.Limit, policy.Window.Seconds(), now)
+	if err != nil {
+		return
+	}
+	result = helpers.NewRateLimitResult(remainingTokens, allowed, policy)
+	return
+}
+
+// sweep deletes, at most once a minute per instance, the buckets that had time to fill up again.
+func (store *PostgresRateLimitStoreImplementation) sweep(ctx context.Context, now time.Time) (err error) {
+	store.mutex.Lock()
+	if now.Sub(store.sweptAt) < time.Minute {
+		store.mutex.Unlock()
+		return
+	}
+	store.sweptAt = now
+	store.mutex.Unlock()
+	_, err = store.RateLimitRepository.DeleteExpired(store.PostgresUtil.GetPool(), ctx, now)
+	return
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"todo-list-api/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+type TracingUtil interface {
+	Shutdown(ctx context.Context) error
+}
+
+type TracingUtilImplementation struct {
+	tracerProvider *sdktrace.TracerProvider
+	file           *os.File
+}
+
+// NewTracing sets up the global tracer provider for the exporter of tracingConfig and the w3c traceparent and baggage
+// propagators. With the exporter none the provider stays a no-op, but a traceparent of the caller is still passed on.
+// The otlp exporter speaks http/protobuf and also reads the standard OTEL_EXPORTER_OTLP_* variables, for headers or
+// a certificate. stdout and file write one span per line as json, for local debugging.
+func NewTracing(tracingConfig config.TracingConfig) (TracingUtil, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	util := &TracingUtilImplementation{}
+	if tracingConfig.Exporter == "none" {
+		return util, nil
+	}
+
+	var spanProcessor sdktrace.SpanProcessor
+	switch tracingConfig.Exporter {
+	case "otlp":
+		var options []otlptracehttp.Option
+		if tracingConfig.OtlpEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(tracingConfig.OtlpEndpoint))
+		}
+		exporter, err := otlptracehttp.New(context.Background(), options...)
+		if err != nil {
+			return nil, err
+		}
+		spanProcessor = sdktrace.NewBatchSpanProcessor(exporter)
+	case "stdout", "file":
+		writer := os.Stdout
+		if tracingConfig.Exporter == "file" {
+			file, err := os.OpenFile(tracingConfig.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+			if err != nil {
+				return nil, err
+			}
+			util.file = file
+			writer = file
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(writer))
+		if err != nil {
+			return nil, err
+		}
+		// spans are written as they end, a local run is often stopped before a batch is full
+		spanProcessor = sdktrace.NewSimpleSpanProcessor(exporter)
+	default:
+		return nil, errors.New("unknown tracing exporter " + tracingConfig.Exporter)
+	}
+
+	util.tracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(spanProcessor),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(float64(tracingConfig.SamplePercent)/100))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(tracingConfig.ServiceName))),
+	)
+	otel.SetTracerProvider(util.tracerProvider)
+	slog.Info("tracing: exporting", slog.String("exporter", tracingConfig.Exporter))
+	return util, nil
+}
+
+// Shutdown exports the spans that are left and closes the file of the file exporter.
+func (util *TracingUtilImplementation) Shutdown(ctx context.Context) error {
+	if util.tracerProvider == nil {
+		return nil
+	}
+	err := util.tracerProvider.Shutdown(ctx)
+	if util.file != nil {
+		err = errors.Join(err, util.file.Close())
+	}
+	return err
+}